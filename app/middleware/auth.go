@@ -69,3 +69,30 @@ func (m *Middleware) NullableAuth() fiber.Handler {
 		return c.Next()
 	}
 }
+
+// StreamAuth is Auth for WebSocket and event stream connections, which
+// browsers open without custom headers. The access token may be given in the
+// access_token query parameter instead.
+func (m *Middleware) StreamAuth() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token := c.Query("access_token")
+		if token == "" {
+			return m.Auth()(c)
+		}
+
+		claims, err := m.authService.ExtractAccessToken(token)
+		if err != nil {
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		c.Locals("userID", claims.UserID)
+
+		return c.Next()
+	}
+}
@@ -15,10 +15,19 @@ func ArticleRoutes(r fiber.Router, mw *middleware.Middleware, service article.Se
 	articles := r.Group("/api/v1/articles")
 
 	article.Post("/", mw.Auth(), createArticle(service))
-	article.Get("/:articleID", getArticleByID(service))
+	article.Get("/:articleID", mw.NullableAuth(), getArticleByID(service))
+	article.Patch("/:articleID", mw.Auth(), updateArticle(service))
 	article.Delete("/:articleID", mw.Auth(), deleteArticle(service))
-	articles.Get("/", getArticles(service))
-	articles.Get("/:category", getArticlesByCategory(service))
+	article.Post("/:articleID/submit", mw.Auth(), submitArticle(service))
+	article.Post("/:articleID/withdraw", mw.Auth(), withdrawArticle(service))
+	article.Post("/:articleID/reviews", mw.Auth(), reviewArticle(service))
+	article.Get("/:articleID/reviews", mw.Auth(), getArticleReviews(service))
+	article.Get("/:articleID/revisions", mw.Auth(), getArticleRevisions(service))
+	article.Get("/:articleID/revisions/diff", mw.Auth(), diffArticleRevisions(service))
+	article.Get("/:articleID/revisions/:revisionID", mw.Auth(), getArticleRevision(service))
+	article.Post("/:articleID/revisions/:revisionID/restore", mw.Auth(), restoreArticleRevision(service))
+	articles.Get("/", mw.NullableAuth(), getArticles(service))
+	articles.Get("/:category", mw.NullableAuth(), getArticlesByCategory(service))
 }
 
 func createArticle(service article.Service) fiber.Handler {
@@ -60,7 +69,26 @@ func createArticle(service article.Service) fiber.Handler {
 
 func getArticles(service article.Service) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		res, err := service.Get(c.Context())
+		page, err := getPageParams(c.Query)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		userID, _ := c.Locals("userID").(int64)
+		params := article.Params{
+			Params:   page,
+			ViewerID: userID,
+			Status:   c.Query("status"),
+			Tag:      c.Query("tag"),
+		}
+
+		res, err := service.Get(c.Context(), &params)
 		if err != nil {
 			logrus.Error(err)
 			return c.Status(app.Status(err)).JSON(app.Failure{
@@ -82,8 +110,9 @@ func getArticles(service article.Service) fiber.Handler {
 func getArticleByID(service article.Service) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		articleID, _ := strconv.ParseInt(c.Params("articleID"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
 
-		res, err := service.GetByID(c.Context(), articleID)
+		res, err := service.GetByID(c.Context(), articleID, userID)
 		if err != nil {
 			logrus.Error(err)
 			return c.Status(app.Status(err)).JSON(app.Failure{
@@ -104,7 +133,26 @@ func getArticleByID(service article.Service) fiber.Handler {
 
 func getArticlesByCategory(service article.Service) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		res, err := service.GetByCategory(c.Context(), c.Params("category"))
+		page, err := getPageParams(c.Query)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		userID, _ := c.Locals("userID").(int64)
+		params := article.Params{
+			Params:   page,
+			ViewerID: userID,
+			Status:   c.Query("status"),
+			Tag:      c.Query("tag"),
+		}
+
+		res, err := service.GetByCategory(c.Context(), categoryParam(c), &params)
 		if err != nil {
 			logrus.Error(err)
 			return c.Status(app.Status(err)).JSON(app.Failure{
@@ -145,3 +193,252 @@ func deleteArticle(service article.Service) fiber.Handler {
 		})
 	}
 }
+
+func submitArticle(service article.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		articleID, _ := strconv.ParseInt(c.Params("articleID"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
+
+		res, err := service.Submit(c.Context(), articleID, userID)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func withdrawArticle(service article.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		articleID, _ := strconv.ParseInt(c.Params("articleID"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
+
+		res, err := service.Withdraw(c.Context(), articleID, userID)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func reviewArticle(service article.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req article.ReviewArticleReq
+		err := c.BodyParser(&req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: []string{"Invalid json format"},
+				},
+			})
+		}
+
+		req.ArticleID, _ = strconv.ParseInt(c.Params("articleID"), 10, 64)
+		req.ReviewerID, _ = c.Locals("userID").(int64)
+
+		res, err := service.Review(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func getArticleReviews(service article.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		articleID, _ := strconv.ParseInt(c.Params("articleID"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
+
+		res, err := service.GetReviews(c.Context(), articleID, userID)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func updateArticle(service article.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req article.UpdateArticleReq
+		err := c.BodyParser(&req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: []string{"Invalid json format"},
+				},
+			})
+		}
+
+		req.ArticleID, _ = strconv.ParseInt(c.Params("articleID"), 10, 64)
+		req.UserID, _ = c.Locals("userID").(int64)
+
+		res, err := service.Update(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func getArticleRevisions(service article.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		articleID, _ := strconv.ParseInt(c.Params("articleID"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
+
+		res, err := service.GetRevisions(c.Context(), articleID, userID)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func getArticleRevision(service article.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		articleID, _ := strconv.ParseInt(c.Params("articleID"), 10, 64)
+		revisionID, _ := strconv.ParseInt(c.Params("revisionID"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
+
+		res, err := service.GetRevision(c.Context(), articleID, revisionID, userID)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func diffArticleRevisions(service article.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := article.DiffReq{
+			Mode: c.Query("mode"),
+		}
+		req.ArticleID, _ = strconv.ParseInt(c.Params("articleID"), 10, 64)
+		req.UserID, _ = c.Locals("userID").(int64)
+		req.From, _ = strconv.ParseInt(c.Query("from"), 10, 64)
+		req.To, _ = strconv.ParseInt(c.Query("to"), 10, 64)
+
+		res, err := service.Diff(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func restoreArticleRevision(service article.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		articleID, _ := strconv.ParseInt(c.Params("articleID"), 10, 64)
+		revisionID, _ := strconv.ParseInt(c.Params("revisionID"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
+
+		res, err := service.Restore(c.Context(), articleID, revisionID, userID)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
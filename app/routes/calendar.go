@@ -0,0 +1,122 @@
+package routes
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/app/middleware"
+	"github.com/bagus2x/recovy/calendar"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const mimeCalendar = "text/calendar; charset=utf-8"
+
+func CalendarRoutes(r fiber.Router, mw *middleware.Middleware, service calendar.Service) {
+	v1 := r.Group("/api/v1/calendar")
+
+	r.Get("/api/v1/webinar/:webinarID/calendar.ics", getWebinarCalendar(service))
+	v1.Get("/feed", mw.Auth(), getCalendarFeedToken(service))
+	v1.Post("/feed/reset", mw.Auth(), resetCalendarFeedToken(service))
+	v1.Get("/:token", getCalendarFeed(service))
+}
+
+func getWebinarCalendar(service calendar.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		webinarID, _ := strconv.ParseInt(c.Params("webinarID"), 10, 64)
+
+		res, err := service.GetWebinarCalendar(c.Context(), webinarID)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		c.Set(fiber.HeaderContentType, mimeCalendar)
+		c.Set(fiber.HeaderContentDisposition, `attachment; filename="webinar-`+strconv.FormatInt(webinarID, 10)+`.ics"`)
+
+		return c.Status(200).SendString(res)
+	}
+}
+
+func getCalendarFeed(service calendar.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token := strings.TrimSuffix(c.Params("token"), ".ics")
+
+		res, err := service.GetFeed(c.Context(), token)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		c.Set(fiber.HeaderContentType, mimeCalendar)
+
+		return c.Status(200).SendString(res)
+	}
+}
+
+func getCalendarFeedToken(service calendar.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, _ := c.Locals("userID").(int64)
+
+		res, err := service.GetFeedToken(c.Context(), userID)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    feedURL(c, res),
+		})
+	}
+}
+
+func resetCalendarFeedToken(service calendar.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, _ := c.Locals("userID").(int64)
+
+		res, err := service.ResetFeedToken(c.Context(), userID)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    feedURL(c, res),
+		})
+	}
+}
+
+func feedURL(c *fiber.Ctx, res calendar.FeedTokenResp) fiber.Map {
+	return fiber.Map{
+		"token":     res.Token,
+		"url":       c.BaseURL() + "/api/v1/calendar/" + res.Token + ".ics",
+		"createdAt": res.CreatedAt,
+	}
+}
@@ -15,10 +15,10 @@ func DiscussionRoutes(r fiber.Router, mw *middleware.Middleware, service discuss
 	discussions := r.Group("/api/v1/discussions")
 
 	discussion.Post("/", mw.Auth(), createDiscussion(service))
-	discussion.Get("/:discussionID", getDiscussionByID(service))
+	discussion.Get("/:discussionID", mw.NullableAuth(), getDiscussionByID(service))
 	discussion.Delete(":discussionID", mw.Auth(), deleteDiscussion(service))
-	discussions.Get("/", getDiscussions(service))
-	discussions.Get("/:category", getDiscussionsByCategory(service))
+	discussions.Get("/", mw.NullableAuth(), getDiscussions(service))
+	discussions.Get("/:category", mw.NullableAuth(), getDiscussionsByCategory(service))
 }
 
 func createDiscussion(service discussion.Service) fiber.Handler {
@@ -60,7 +60,20 @@ func createDiscussion(service discussion.Service) fiber.Handler {
 
 func getDiscussions(service discussion.Service) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		res, err := service.Get(c.Context())
+		page, err := getPageParams(c.Query)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+		userID, _ := c.Locals("userID").(int64)
+		params := discussion.Params{Params: page, ViewerID: userID}
+
+		res, err := service.Get(c.Context(), &params)
 		if err != nil {
 			logrus.Error(err)
 			return c.Status(app.Status(err)).JSON(app.Failure{
@@ -82,8 +95,9 @@ func getDiscussions(service discussion.Service) fiber.Handler {
 func getDiscussionByID(service discussion.Service) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		discussionID, _ := strconv.ParseInt(c.Params("discussionID"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
 
-		res, err := service.GetByID(c.Context(), discussionID)
+		res, err := service.GetByID(c.Context(), discussionID, userID)
 		if err != nil {
 			logrus.Error(err)
 			return c.Status(app.Status(err)).JSON(app.Failure{
@@ -104,7 +118,20 @@ func getDiscussionByID(service discussion.Service) fiber.Handler {
 
 func getDiscussionsByCategory(service discussion.Service) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		res, err := service.GetByCategory(c.Context(), c.Params("category"))
+		page, err := getPageParams(c.Query)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+		userID, _ := c.Locals("userID").(int64)
+		params := discussion.Params{Params: page, ViewerID: userID}
+
+		res, err := service.GetByCategory(c.Context(), categoryParam(c), &params)
 		if err != nil {
 			logrus.Error(err)
 			return c.Status(app.Status(err)).JSON(app.Failure{
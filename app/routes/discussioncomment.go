@@ -15,10 +15,11 @@ func DiscussionCommentRoutes(r fiber.Router, mw *middleware.Middleware, service
 	discussionComments := r.Group("/api/v1/discussion-comments")
 
 	discussionComment.Post("/", mw.Auth(), createDiscussionComment(service))
-	discussionComment.Get("/:discussionCommentID", getDiscussionCommentByID(service))
+	discussionComment.Get("/:discussionCommentID", mw.NullableAuth(), getDiscussionCommentByID(service))
+	discussionComment.Get("/:discussionCommentID/replies", mw.NullableAuth(), getDiscussionCommentReplies(service))
 	discussionComment.Delete("/:discussionCommentID", mw.Auth(), deleteDiscussionComment(service))
-	discussionComments.Get("/", getDiscussionComments(service))
-	discussionComments.Get("/:discussionID", getDiscussionCommentsByDiscussionID(service))
+	discussionComments.Get("/", mw.NullableAuth(), getDiscussionComments(service))
+	discussionComments.Get("/:discussionID", mw.NullableAuth(), getDiscussionCommentsByDiscussionID(service))
 }
 
 func createDiscussionComment(service discussioncomment.Service) fiber.Handler {
@@ -60,7 +61,20 @@ func createDiscussionComment(service discussioncomment.Service) fiber.Handler {
 
 func getDiscussionComments(service discussioncomment.Service) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		res, err := service.Get(c.Context())
+		page, err := getPageParams(c.Query)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+		userID, _ := c.Locals("userID").(int64)
+		params := discussioncomment.Params{Params: page, ViewerID: userID, View: c.Query("view")}
+
+		res, err := service.Get(c.Context(), &params)
 		if err != nil {
 			logrus.Error(err)
 			return c.Status(app.Status(err)).JSON(app.Failure{
@@ -82,8 +96,9 @@ func getDiscussionComments(service discussioncomment.Service) fiber.Handler {
 func getDiscussionCommentByID(service discussioncomment.Service) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		discussionID, _ := strconv.ParseInt(c.Params("discussionCommentID"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
 
-		res, err := service.GetByID(c.Context(), discussionID)
+		res, err := service.GetByID(c.Context(), discussionID, userID)
 		if err != nil {
 			logrus.Error(err)
 			return c.Status(app.Status(err)).JSON(app.Failure{
@@ -104,8 +119,56 @@ func getDiscussionCommentByID(service discussioncomment.Service) fiber.Handler {
 
 func getDiscussionCommentsByDiscussionID(service discussioncomment.Service) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		discussionCommentID, _ := strconv.ParseInt(c.Params("discussionID"), 10, 64)
-		res, err := service.GetByDiscussionID(c.Context(), discussionCommentID)
+		discussionID, _ := strconv.ParseInt(c.Params("discussionID"), 10, 64)
+		page, err := getPageParams(c.Query)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+		userID, _ := c.Locals("userID").(int64)
+		params := discussioncomment.Params{Params: page, ViewerID: userID, View: c.Query("view", discussioncomment.ViewFlat)}
+
+		res, err := service.GetByDiscussionID(c.Context(), discussionID, &params)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func getDiscussionCommentReplies(service discussioncomment.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		discussionCommentID, _ := strconv.ParseInt(c.Params("discussionCommentID"), 10, 64)
+		page, err := getPageParams(c.Query)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+		userID, _ := c.Locals("userID").(int64)
+		params := discussioncomment.Params{Params: page, ViewerID: userID, View: c.Query("view")}
+
+		res, err := service.GetReplies(c.Context(), discussionCommentID, &params)
 		if err != nil {
 			logrus.Error(err)
 			return c.Status(app.Status(err)).JSON(app.Failure{
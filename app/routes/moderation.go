@@ -0,0 +1,180 @@
+package routes
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/app/middleware"
+	"github.com/bagus2x/recovy/moderation"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+func ModerationRoutes(r fiber.Router, mw *middleware.Middleware, service moderation.Service) {
+	moderations := r.Group("/api/v1/moderation")
+
+	r.Post("/api/v1/reports", mw.Auth(), createReport(service))
+	moderations.Get("/queue", mw.Auth(), getModerationQueue(service))
+	moderations.Post("/actions", mw.Auth(), moderate(service))
+	moderations.Get("/actions", mw.Auth(), getModerationActions(service))
+}
+
+func createReport(service moderation.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req moderation.CreateReportReq
+		err := c.BodyParser(&req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: []string{"Invalid json format"},
+				},
+			})
+		}
+
+		userID, _ := c.Locals("userID").(int64)
+		req.ReporterID = userID
+
+		res, err := service.Report(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func getModerationQueue(service moderation.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		page, err := getPageParams(c.Query)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		userID, _ := c.Locals("userID").(int64)
+		params := moderation.QueueParams{
+			Params:      page,
+			ModeratorID: userID,
+			Priority:    c.Query("priority") == "true",
+		}
+		if types := c.Query("type"); types != "" {
+			params.Types = strings.Split(types, ",")
+		}
+
+		res, err := service.GetQueue(c.Context(), &params)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func moderate(service moderation.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req moderation.ModerateReq
+		err := c.BodyParser(&req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: []string{"Invalid json format"},
+				},
+			})
+		}
+
+		userID, _ := c.Locals("userID").(int64)
+		req.ModeratorID = userID
+
+		res, err := service.Moderate(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func getModerationActions(service moderation.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		page, err := getPageParams(c.Query)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		userID, _ := c.Locals("userID").(int64)
+		contentID, _ := strconv.ParseInt(c.Query("contentID"), 10, 64)
+		targetID, _ := strconv.ParseInt(c.Query("targetID"), 10, 64)
+		params := moderation.ActionParams{
+			Params:      page,
+			ModeratorID: userID,
+			ContentType: c.Query("contentType"),
+			ContentID:   contentID,
+			TargetID:    targetID,
+		}
+
+		res, err := service.GetActions(c.Context(), &params)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
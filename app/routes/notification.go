@@ -0,0 +1,87 @@
+package routes
+
+import (
+	"strconv"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/app/middleware"
+	"github.com/bagus2x/recovy/notification"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+func NotificationRoutes(r fiber.Router, mw *middleware.Middleware, service notification.Service) {
+	v1 := r.Group("/api/v1/notifications")
+
+	v1.Get("/", mw.Auth(), getNotifications(service))
+	v1.Patch("/read", mw.Auth(), markAllNotificationsRead(service))
+	v1.Patch("/:notificationID/read", mw.Auth(), markNotificationRead(service))
+}
+
+func getNotifications(service notification.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, _ := c.Locals("userID").(int64)
+
+		res, err := service.GetByUserID(c.Context(), userID)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func markNotificationRead(service notification.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		notificationID, _ := strconv.ParseInt(c.Params("notificationID"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
+
+		err := service.MarkRead(c.Context(), notificationID, userID)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+		})
+	}
+}
+
+func markAllNotificationsRead(service notification.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, _ := c.Locals("userID").(int64)
+
+		err := service.MarkAllRead(c.Context(), userID)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+		})
+	}
+}
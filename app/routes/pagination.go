@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"strconv"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/pagination"
+)
+
+func getPageParams(query func(key string, defaultValue ...string) string) (pagination.Params, error) {
+	params := pagination.Params{
+		Cursor:    query("cursor"),
+		Direction: query("direction"),
+	}
+
+	if str := query("limit"); str != "" {
+		limit, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return pagination.Params{}, app.NewError(err, app.EBadRequest, "limit must be a number")
+		}
+		params.Limit = limit
+	}
+
+	return params, nil
+}
@@ -0,0 +1,430 @@
+package routes
+
+import (
+	"strconv"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/app/middleware"
+	"github.com/bagus2x/recovy/playlist"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+func PlaylistRoutes(r fiber.Router, mw *middleware.Middleware, service playlist.Service) {
+	playlists := r.Group("/api/v1/playlists")
+
+	playlists.Post("/", mw.Auth(), createPlaylist(service))
+	playlists.Get("/", mw.Auth(), getOwnPlaylists(service))
+	playlists.Get("/shared/:shareToken", mw.NullableAuth(), getSharedPlaylist(service))
+	playlists.Get("/:playlistID", mw.NullableAuth(), getPlaylist(service))
+	playlists.Patch("/:playlistID", mw.Auth(), updatePlaylist(service))
+	playlists.Delete("/:playlistID", mw.Auth(), deletePlaylist(service))
+	playlists.Post("/:playlistID/items", mw.Auth(), addPlaylistItem(service))
+	playlists.Patch("/:playlistID/items/:podcastID", mw.Auth(), movePlaylistItem(service))
+	playlists.Delete("/:playlistID/items/:podcastID", mw.Auth(), removePlaylistItem(service))
+
+	r.Post("/api/v1/podcasts/:podcastID/playlists/:playlistID", mw.Auth(), addPodcastToPlaylist(service))
+}
+
+func createPlaylist(service playlist.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req playlist.CreatePlaylistReq
+		err := c.BodyParser(&req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: []string{"Invalid json format"},
+				},
+			})
+		}
+
+		userID, _ := c.Locals("userID").(int64)
+		req.OwnerID = userID
+
+		res, err := service.Create(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func getOwnPlaylists(service playlist.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, _ := c.Locals("userID").(int64)
+
+		res, err := service.GetByOwnerID(c.Context(), userID)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func getPlaylist(service playlist.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		playlistID, err := strconv.ParseInt(c.Params("playlistID"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		res, err := service.GetByID(c.Context(), playlistID)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func getSharedPlaylist(service playlist.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		res, err := service.GetByShareToken(c.Context(), c.Params("shareToken"))
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func updatePlaylist(service playlist.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		playlistID, err := strconv.ParseInt(c.Params("playlistID"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		var req playlist.UpdatePlaylistReq
+		err = c.BodyParser(&req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: []string{"Invalid json format"},
+				},
+			})
+		}
+
+		userID, _ := c.Locals("userID").(int64)
+		req.PlaylistID = playlistID
+		req.OwnerID = userID
+
+		res, err := service.Update(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func deletePlaylist(service playlist.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		playlistID, err := strconv.ParseInt(c.Params("playlistID"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		userID, _ := c.Locals("userID").(int64)
+
+		err = service.Delete(c.Context(), playlistID, userID)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+		})
+	}
+}
+
+func addPlaylistItem(service playlist.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		playlistID, err := strconv.ParseInt(c.Params("playlistID"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		var req playlist.AddItemReq
+		err = c.BodyParser(&req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: []string{"Invalid json format"},
+				},
+			})
+		}
+
+		userID, _ := c.Locals("userID").(int64)
+		req.PlaylistID = playlistID
+		req.OwnerID = userID
+
+		res, err := service.AddItem(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func addPodcastToPlaylist(service playlist.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		podcastID, err := strconv.ParseInt(c.Params("podcastID"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		playlistID, err := strconv.ParseInt(c.Params("playlistID"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		userID, _ := c.Locals("userID").(int64)
+		req := playlist.AddItemReq{
+			PlaylistID: playlistID,
+			PodcastID:  podcastID,
+			OwnerID:    userID,
+		}
+
+		res, err := service.AddItem(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func movePlaylistItem(service playlist.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		playlistID, err := strconv.ParseInt(c.Params("playlistID"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		podcastID, err := strconv.ParseInt(c.Params("podcastID"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		var req playlist.MoveItemReq
+		err = c.BodyParser(&req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: []string{"Invalid json format"},
+				},
+			})
+		}
+
+		userID, _ := c.Locals("userID").(int64)
+		req.PlaylistID = playlistID
+		req.PodcastID = podcastID
+		req.OwnerID = userID
+
+		err = service.MoveItem(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+		})
+	}
+}
+
+func removePlaylistItem(service playlist.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		playlistID, err := strconv.ParseInt(c.Params("playlistID"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		podcastID, err := strconv.ParseInt(c.Params("podcastID"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		userID, _ := c.Locals("userID").(int64)
+		req := playlist.RemoveItemReq{
+			PlaylistID: playlistID,
+			PodcastID:  podcastID,
+			OwnerID:    userID,
+		}
+
+		err = service.RemoveItem(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+		})
+	}
+}
@@ -17,8 +17,6 @@ func PodcastRoutes(r fiber.Router, mw *middleware.Middleware, service podcast.Se
 	v1.Get("/", mw.NullableAuth(), getPodcasts(service))
 	v1.Get("/:podcastID", mw.NullableAuth(), getPodcast(service))
 	v1.Delete("/:podcastID", mw.Auth(), deletePodcast(service))
-	v1.Patch("/:podcastID/star", mw.Auth(), starPodcast(service))
-	v1.Delete("/:podcastID/star", mw.Auth(), unstarPodcast(service))
 }
 
 func createPodcast(service podcast.Service) fiber.Handler {
@@ -56,80 +54,6 @@ func createPodcast(service podcast.Service) fiber.Handler {
 	}
 }
 
-func starPodcast(service podcast.Service) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		userID, _ := c.Locals("userID").(int64)
-		podcastID, err := strconv.ParseInt(c.Params("podcastID"), 10, 64)
-		if err != nil {
-			return c.Status(400).JSON(app.Failure{
-				Success: false,
-				Error: app.ErrorDetail{
-					Code:     app.EBadRequest,
-					Messages: app.ErrorMessage(err),
-				},
-			})
-		}
-
-		req := podcast.StarPodcastReq{
-			PodcastID: podcastID,
-			UserID:    userID,
-		}
-
-		err = service.StarPodcast(c.Context(), &req)
-		log.Println(err)
-		if err != nil {
-			return c.Status(app.Status(err)).JSON(app.Failure{
-				Success: false,
-				Error: app.ErrorDetail{
-					Code:     app.EBadRequest,
-					Messages: app.ErrorMessage(err),
-				},
-			})
-		}
-
-		return c.Status(200).JSON(app.Success{
-			Success: true,
-		})
-	}
-}
-
-func unstarPodcast(service podcast.Service) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		userID, _ := c.Locals("userID").(int64)
-		podcastID, err := strconv.ParseInt(c.Params("podcastID"), 10, 64)
-		if err != nil {
-			return c.Status(400).JSON(app.Failure{
-				Success: false,
-				Error: app.ErrorDetail{
-					Code:     app.EBadRequest,
-					Messages: app.ErrorMessage(err),
-				},
-			})
-		}
-
-		req := podcast.StarPodcastReq{
-			PodcastID: podcastID,
-			UserID:    userID,
-		}
-
-		err = service.UnstarPodcast(c.Context(), &req)
-		if err != nil {
-			log.Println(err)
-			return c.Status(app.Status(err)).JSON(app.Failure{
-				Success: false,
-				Error: app.ErrorDetail{
-					Code:     app.EBadRequest,
-					Messages: app.ErrorMessage(err),
-				},
-			})
-		}
-
-		return c.Status(200).JSON(app.Success{
-			Success: true,
-		})
-	}
-}
-
 func getPodcast(service podcast.Service) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		podcastID, err := strconv.ParseInt(c.Params("podcastID"), 10, 64)
@@ -174,6 +98,21 @@ func getPodcasts(service podcast.Service) fiber.Handler {
 			})
 		}
 
+		// starred is what liked was called before reactions.
+		if c.Query("liked") == "true" || c.Query("starred") == "true" {
+			userID, ok := c.Locals("userID").(int64)
+			if !ok {
+				return c.Status(401).JSON(app.Failure{
+					Success: false,
+					Error: app.ErrorDetail{
+						Code:     app.EUnauthorized,
+						Messages: []string{"Sign in to filter liked podcasts"},
+					},
+				})
+			}
+			params.LikedBy = userID
+		}
+
 		res, err := service.GetByParams(c.Context(), &params)
 		if err != nil {
 			log.Println("error nih: ", err)
@@ -227,36 +166,31 @@ func deletePodcast(service podcast.Service) fiber.Handler {
 func getPodcastParams(query func(key string, defaultValue ...string) string) (podcast.Params, error) {
 	var params podcast.Params
 
-	cursorStr := query("cursor")
-	if cursorStr != "" {
-		cursor, err := strconv.ParseInt(cursorStr, 10, 64)
-		if err != nil {
-			return podcast.Params{}, err
-		}
-		params.Cursor = cursor
+	ints := map[string]*int64{
+		"limit":        &params.Limit,
+		"author_id":    &params.AuthorID,
+		"created_from": &params.CreatedFrom,
+		"created_to":   &params.CreatedTo,
+		"min_duration": &params.MinDuration,
+		"max_duration": &params.MaxDuration,
 	}
-
-	limitStr := query("limit")
-	if limitStr != "" {
-		limit, err := strconv.ParseInt(limitStr, 10, 64)
-		if err != nil {
-			return podcast.Params{}, err
+	for key, dst := range ints {
+		str := query(key)
+		if str == "" {
+			continue
 		}
-		params.Limit = limit
-	}
 
-	authorStr := query("author_id")
-	if authorStr != "" {
-		authorID, err := strconv.ParseInt(authorStr, 10, 64)
+		v, err := strconv.ParseInt(str, 10, 64)
 		if err != nil {
-			return podcast.Params{}, err
+			return podcast.Params{}, app.NewError(err, app.EBadRequest, key+" must be a number")
 		}
-		params.AuthorID = authorID
+		*dst = v
 	}
 
+	params.Cursor = query("cursor")
 	params.Direction = query("direction")
+	params.Sort = query("sort")
 	params.Title = query("title")
 
-	log.Println(cursorStr)
 	return params, nil
 }
@@ -0,0 +1,142 @@
+package routes
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/app/middleware"
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/reaction"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+func ReactionRoutes(r fiber.Router, mw *middleware.Middleware, service reaction.Service) {
+	contents := map[string]string{
+		models.ContentArticle:    "/api/v1/article",
+		models.ContentDiscussion: "/api/v1/discussion",
+		models.ContentWebinar:    "/api/v1/webinar",
+		models.ContentPodcast:    "/api/v1/podcasts",
+		models.ContentComment:    "/api/v1/discussion-comment",
+	}
+
+	for contentType, prefix := range contents {
+		content := r.Group(prefix)
+
+		content.Put("/:contentID/like", mw.Auth(), react(service, models.ReactionLike, contentType))
+		content.Delete("/:contentID/like", mw.Auth(), unreact(service, models.ReactionLike, contentType))
+		content.Put("/:contentID/bookmark", mw.Auth(), react(service, models.ReactionBookmark, contentType))
+		content.Delete("/:contentID/bookmark", mw.Auth(), unreact(service, models.ReactionBookmark, contentType))
+	}
+
+	// Stars predate reactions: a podcast star is a like, a webinar star a
+	// bookmark.
+	r.Patch("/api/v1/podcasts/:contentID/star", mw.Auth(), react(service, models.ReactionLike, models.ContentPodcast))
+	r.Delete("/api/v1/podcasts/:contentID/star", mw.Auth(), unreact(service, models.ReactionLike, models.ContentPodcast))
+	r.Patch("/api/v1/webinar/:contentID/star", mw.Auth(), react(service, models.ReactionBookmark, models.ContentWebinar))
+	r.Delete("/api/v1/webinar/:contentID/star", mw.Auth(), unreact(service, models.ReactionBookmark, models.ContentWebinar))
+
+	r.Get("/api/v1/users/me/bookmarks", mw.Auth(), getBookmarks(service))
+}
+
+func react(service reaction.Service, kind, contentType string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		contentID, _ := strconv.ParseInt(c.Params("contentID"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
+
+		req := reaction.ReactReq{
+			Kind:        kind,
+			ContentType: contentType,
+			ContentID:   contentID,
+			UserID:      userID,
+		}
+
+		err := service.React(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+		})
+	}
+}
+
+func unreact(service reaction.Service, kind, contentType string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		contentID, _ := strconv.ParseInt(c.Params("contentID"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
+
+		req := reaction.ReactReq{
+			Kind:        kind,
+			ContentType: contentType,
+			ContentID:   contentID,
+			UserID:      userID,
+		}
+
+		err := service.Unreact(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+		})
+	}
+}
+
+func getBookmarks(service reaction.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		page, err := getPageParams(c.Query)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		userID, _ := c.Locals("userID").(int64)
+		params := reaction.Params{
+			Params: page,
+			UserID: userID,
+		}
+		if types := c.Query("type"); types != "" {
+			params.Types = strings.Split(types, ",")
+		}
+
+		res, err := service.GetBookmarks(c.Context(), &params)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package routes
+
+import (
+	"strconv"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/app/middleware"
+	"github.com/bagus2x/recovy/recommendation"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// RecommendationRoutes must be registered before PodcastRoutes, otherwise
+// /podcasts/recommended is matched by /podcasts/:podcastID.
+func RecommendationRoutes(r fiber.Router, mw *middleware.Middleware, service recommendation.Service) {
+	v1 := r.Group("/api/v1/podcasts")
+
+	v1.Get("/recommended", mw.NullableAuth(), getRecommendations(service))
+	v1.Post("/:podcastID/listen", mw.Auth(), recordListen(service))
+}
+
+func getRecommendations(service recommendation.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, _ := c.Locals("userID").(int64)
+
+		res, err := service.GetRecommendations(c.Context(), userID)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func recordListen(service recommendation.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		podcastID, err := strconv.ParseInt(c.Params("podcastID"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		userID, _ := c.Locals("userID").(int64)
+		req := recommendation.RecordListenReq{
+			PodcastID: podcastID,
+			UserID:    userID,
+		}
+
+		err = service.RecordListen(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package routes
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/search"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+func SearchRoutes(r fiber.Router, service search.Service) {
+	v1 := r.Group("/api/v1/search")
+
+	v1.Get("/", searchContent(service))
+}
+
+func searchContent(service search.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params := search.Params{
+			Query:  c.Query("q"),
+			Lang:   c.Query("lang"),
+			Cursor: c.Query("cursor"),
+		}
+		if types := c.Query("types"); types != "" {
+			params.Types = strings.Split(types, ",")
+		}
+		if limit := c.Query("limit"); limit != "" {
+			v, err := strconv.ParseInt(limit, 10, 64)
+			if err != nil {
+				return c.Status(400).JSON(app.Failure{
+					Success: false,
+					Error: app.ErrorDetail{
+						Code:     app.EBadRequest,
+						Messages: []string{"limit must be a number"},
+					},
+				})
+			}
+			params.Limit = v
+		}
+
+		res, err := service.Search(c.Context(), &params)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
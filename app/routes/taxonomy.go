@@ -0,0 +1,199 @@
+package routes
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/app/middleware"
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/taxonomy"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+func TaxonomyRoutes(r fiber.Router, mw *middleware.Middleware, service taxonomy.Service) {
+	kinds := map[string]string{
+		models.TermCategory: "categories",
+		models.TermTag:      "tags",
+	}
+
+	for kind, plural := range kinds {
+		term := r.Group("/api/v1/" + kind)
+		terms := r.Group("/api/v1/" + plural)
+
+		term.Post("/", mw.Auth(), createTerm(service, kind))
+		term.Patch("/:termID", mw.Auth(), updateTerm(service, kind))
+		term.Delete("/:termID", mw.Auth(), deleteTerm(service, kind))
+		term.Post("/:termID/merge", mw.Auth(), mergeTerm(service, kind))
+		terms.Get("/", getTerms(service, kind))
+	}
+}
+
+// categoryParam returns the category in the path as a slug, so links made
+// with the category name before the taxonomy, like /articles/Mental%20Health,
+// keep working.
+func categoryParam(c *fiber.Ctx) string {
+	category, err := url.PathUnescape(c.Params("category"))
+	if err != nil {
+		category = c.Params("category")
+	}
+
+	return taxonomy.Slugify(category)
+}
+
+func getTerms(service taxonomy.Service, kind string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		res, err := service.Get(c.Context(), kind, c.Query("lang"))
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func createTerm(service taxonomy.Service, kind string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req taxonomy.CreateTermReq
+		err := c.BodyParser(&req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: []string{"Invalid json format"},
+				},
+			})
+		}
+
+		req.UserID, _ = c.Locals("userID").(int64)
+		req.Kind = kind
+
+		res, err := service.Create(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func updateTerm(service taxonomy.Service, kind string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req taxonomy.UpdateTermReq
+		err := c.BodyParser(&req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: []string{"Invalid json format"},
+				},
+			})
+		}
+
+		req.UserID, _ = c.Locals("userID").(int64)
+		req.TermID, _ = strconv.ParseInt(c.Params("termID"), 10, 64)
+		req.Kind = kind
+
+		res, err := service.Update(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func mergeTerm(service taxonomy.Service, kind string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req taxonomy.MergeTermReq
+		err := c.BodyParser(&req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: []string{"Invalid json format"},
+				},
+			})
+		}
+
+		req.UserID, _ = c.Locals("userID").(int64)
+		req.TermID, _ = strconv.ParseInt(c.Params("termID"), 10, 64)
+		req.Kind = kind
+
+		err = service.Merge(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+		})
+	}
+}
+
+func deleteTerm(service taxonomy.Service, kind string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		termID, _ := strconv.ParseInt(c.Params("termID"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
+
+		err := service.Delete(c.Context(), kind, termID, userID)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+		})
+	}
+}
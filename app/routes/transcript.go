@@ -0,0 +1,187 @@
+package routes
+
+import (
+	"strconv"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/app/middleware"
+	"github.com/bagus2x/recovy/transcript"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+func TranscriptRoutes(r fiber.Router, mw *middleware.Middleware, service transcript.Service) {
+	v1 := r.Group("/api/v1/podcasts/:podcastID")
+
+	v1.Put("/transcript", mw.Auth(), uploadTranscript(service))
+	v1.Get("/transcript", getTranscript(service))
+	v1.Put("/chapters", mw.Auth(), uploadChapters(service))
+	v1.Get("/chapters", getChapters(service))
+}
+
+func uploadTranscript(service transcript.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		podcastID, err := strconv.ParseInt(c.Params("podcastID"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		var req transcript.UploadTranscriptReq
+		err = c.BodyParser(&req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: []string{"Invalid json format"},
+				},
+			})
+		}
+
+		userID, _ := c.Locals("userID").(int64)
+		req.PodcastID = podcastID
+		req.AuthorID = userID
+
+		res, err := service.UploadTranscript(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func getTranscript(service transcript.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		podcastID, err := strconv.ParseInt(c.Params("podcastID"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		format := c.Query("format", transcript.FormatVTT)
+
+		res, err := service.GetTranscript(c.Context(), podcastID, format)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		if format == transcript.FormatText {
+			c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+		} else {
+			c.Set(fiber.HeaderContentType, "text/vtt; charset=utf-8")
+		}
+
+		return c.Status(200).SendString(res)
+	}
+}
+
+func uploadChapters(service transcript.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		podcastID, err := strconv.ParseInt(c.Params("podcastID"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		var req transcript.UploadChaptersReq
+		err = c.BodyParser(&req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: []string{"Invalid json format"},
+				},
+			})
+		}
+
+		userID, _ := c.Locals("userID").(int64)
+		req.PodcastID = podcastID
+		req.AuthorID = userID
+
+		res, err := service.UploadChapters(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func getChapters(service transcript.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		podcastID, err := strconv.ParseInt(c.Params("podcastID"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		res, err := service.GetChapters(c.Context(), podcastID)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package routes
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/bagus2x/recovy/app"
 	"github.com/bagus2x/recovy/app/middleware"
@@ -15,10 +17,27 @@ func WebinarRoutes(r fiber.Router, mw *middleware.Middleware, service webinar.Se
 	webinars := r.Group("/api/v1/webinars")
 
 	webinar.Post("/", mw.Auth(), createWebinar(service))
-	webinar.Get("/:webinarID", getWebinarByID(service))
+	webinar.Get("/:webinarID", mw.NullableAuth(), getWebinarByID(service))
+	webinar.Patch("/:webinarID", mw.Auth(), updateWebinar(service))
 	webinar.Delete("/:webinarID", mw.Auth(), deleteWebinar(service))
-	webinars.Get("/", getWebinars(service))
-	webinars.Get("/:category", getWebinarsByCategory(service))
+	webinar.Post("/:webinarID/cancel", mw.Auth(), cancelWebinar(service))
+	webinar.Patch("/:webinarID/occurrences/:recurrenceID", mw.Auth(), updateWebinarOccurrence(service))
+	webinar.Post("/:webinarID/registration", mw.Auth(), registerWebinar(service))
+	webinar.Delete("/:webinarID/registration", mw.Auth(), cancelWebinarRegistration(service))
+	webinar.Get("/:webinarID/registrations", mw.Auth(), getWebinarRegistrants(service))
+	webinar.Post("/:webinarID/reminder", mw.Auth(), remindWebinar(service))
+	webinar.Delete("/:webinarID/reminder", mw.Auth(), cancelWebinarReminder(service))
+	webinar.Put("/:webinarID/speakers", mw.Auth(), replaceWebinarSpeakers(service))
+	webinar.Post("/:webinarID/cohosts", mw.Auth(), addWebinarCohost(service))
+	webinar.Delete("/:webinarID/cohosts/:userID", mw.Auth(), removeWebinarCohost(service))
+	webinar.Get("/:webinarID/check-in", mw.Auth(), getWebinarCheckIn(service))
+	webinar.Post("/:webinarID/check-in", mw.Auth(), checkInWebinar(service))
+	webinar.Get("/:webinarID/attendance", mw.Auth(), getWebinarAttendance(service))
+	webinar.Get("/:webinarID/certificate", mw.Auth(), getWebinarCertificate(service))
+	webinar.Get("/:webinarID/attendance/:userID/certificate", mw.Auth(), getWebinarCertificate(service))
+	webinars.Get("/", mw.NullableAuth(), getWebinars(service))
+	webinars.Get("/occurrences", getWebinarOccurrences(service))
+	webinars.Get("/:category", mw.NullableAuth(), getWebinarsByCategory(service))
 }
 
 func createWebinar(service webinar.Service) fiber.Handler {
@@ -60,7 +79,18 @@ func createWebinar(service webinar.Service) fiber.Handler {
 
 func getWebinars(service webinar.Service) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		res, err := service.Get(c.Context())
+		params, err := getWebinarParams(c.Query)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		res, err := service.GetByParams(c.Context(), &params, c.Query("tz"))
 		if err != nil {
 			logrus.Error(err)
 			return c.Status(app.Status(err)).JSON(app.Failure{
@@ -79,11 +109,45 @@ func getWebinars(service webinar.Service) fiber.Handler {
 	}
 }
 
+func getWebinarParams(query func(key string, defaultValue ...string) string) (webinar.Params, error) {
+	var params webinar.Params
+
+	ints := map[string]*int64{
+		"limit":     &params.Limit,
+		"author_id": &params.AuthorID,
+		"from":      &params.From,
+		"to":        &params.To,
+	}
+	for key, dst := range ints {
+		str := query(key)
+		if str == "" {
+			continue
+		}
+
+		v, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return webinar.Params{}, app.NewError(err, app.EBadRequest, key+" must be a number")
+		}
+		*dst = v
+	}
+
+	if status := query("status"); status != "" {
+		params.Statuses = strings.Split(status, ",")
+	}
+	params.Cursor = query("cursor")
+	params.Direction = query("direction")
+	params.Order = query("order")
+	params.Category = query("category")
+	params.Tag = query("tag")
+
+	return params, nil
+}
+
 func getWebinarByID(service webinar.Service) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		webinarID, _ := strconv.ParseInt(c.Params("webinarID"), 10, 64)
 
-		res, err := service.GetByID(c.Context(), webinarID)
+		res, err := service.GetByID(c.Context(), webinarID, c.Query("tz"))
 		if err != nil {
 			logrus.Error(err)
 			return c.Status(app.Status(err)).JSON(app.Failure{
@@ -104,7 +168,122 @@ func getWebinarByID(service webinar.Service) fiber.Handler {
 
 func getWebinarsByCategory(service webinar.Service) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		res, err := service.GetByCategory(c.Context(), c.Params("category"))
+		params, err := getWebinarParams(c.Query)
+		if err != nil {
+			return c.Status(400).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		res, err := service.GetByCategory(c.Context(), categoryParam(c), &params, c.Query("tz"))
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func getWebinarOccurrences(service webinar.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		from, _ := strconv.ParseInt(c.Query("from"), 10, 64)
+		to, _ := strconv.ParseInt(c.Query("to"), 10, 64)
+
+		req := webinar.OccurrencesReq{
+			From: from,
+			To:   to,
+		}
+
+		res, err := service.GetOccurrences(c.Context(), &req, c.Query("tz"))
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func updateWebinar(service webinar.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req webinar.UpdateWebinarReq
+		err := c.BodyParser(&req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: []string{"Invalid json format"},
+				},
+			})
+		}
+
+		req.WebinarID, _ = strconv.ParseInt(c.Params("webinarID"), 10, 64)
+		req.AuthorID, _ = c.Locals("userID").(int64)
+
+		res, err := service.Update(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func updateWebinarOccurrence(service webinar.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req webinar.UpdateOccurrenceReq
+		err := c.BodyParser(&req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: []string{"Invalid json format"},
+				},
+			})
+		}
+
+		req.WebinarID, _ = strconv.ParseInt(c.Params("webinarID"), 10, 64)
+		req.RecurrenceID, _ = strconv.ParseInt(c.Params("recurrenceID"), 10, 64)
+		req.AuthorID, _ = c.Locals("userID").(int64)
+
+		res, err := service.UpdateOccurrence(c.Context(), &req)
 		if err != nil {
 			logrus.Error(err)
 			return c.Status(app.Status(err)).JSON(app.Failure{
@@ -145,3 +324,383 @@ func deleteWebinar(service webinar.Service) fiber.Handler {
 		})
 	}
 }
+
+func registerWebinar(service webinar.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		webinarID, _ := strconv.ParseInt(c.Params("webinarID"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
+
+		occurrence, _ := strconv.ParseInt(c.Query("occurrence"), 10, 64)
+
+		req := webinar.RegisterReq{
+			WebinarID:  webinarID,
+			Occurrence: occurrence,
+			UserID:     userID,
+		}
+
+		res, err := service.Register(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func cancelWebinarRegistration(service webinar.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		webinarID, _ := strconv.ParseInt(c.Params("webinarID"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
+
+		occurrence, _ := strconv.ParseInt(c.Query("occurrence"), 10, 64)
+
+		err := service.CancelRegistration(c.Context(), webinarID, occurrence, userID)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+		})
+	}
+}
+
+func cancelWebinar(service webinar.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		webinarID, _ := strconv.ParseInt(c.Params("webinarID"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
+
+		err := service.Cancel(c.Context(), webinarID, userID)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+		})
+	}
+}
+
+func getWebinarRegistrants(service webinar.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		webinarID, _ := strconv.ParseInt(c.Params("webinarID"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
+
+		res, err := service.GetRegistrants(c.Context(), webinarID, userID)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func remindWebinar(service webinar.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		webinarID, _ := strconv.ParseInt(c.Params("webinarID"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
+
+		req := webinar.RemindReq{
+			WebinarID: webinarID,
+			UserID:    userID,
+		}
+
+		err := service.RemindMe(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+		})
+	}
+}
+
+func cancelWebinarReminder(service webinar.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		webinarID, _ := strconv.ParseInt(c.Params("webinarID"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
+
+		req := webinar.RemindReq{
+			WebinarID: webinarID,
+			UserID:    userID,
+		}
+
+		err := service.CancelReminder(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+		})
+	}
+}
+
+func replaceWebinarSpeakers(service webinar.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req webinar.ReplaceSpeakersReq
+		err := c.BodyParser(&req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: []string{"Invalid json format"},
+				},
+			})
+		}
+
+		req.WebinarID, _ = strconv.ParseInt(c.Params("webinarID"), 10, 64)
+		req.AuthorID, _ = c.Locals("userID").(int64)
+
+		res, err := service.ReplaceSpeakers(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func addWebinarCohost(service webinar.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req webinar.CohostReq
+		err := c.BodyParser(&req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: []string{"Invalid json format"},
+				},
+			})
+		}
+
+		req.WebinarID, _ = strconv.ParseInt(c.Params("webinarID"), 10, 64)
+		req.AuthorID, _ = c.Locals("userID").(int64)
+
+		err = service.AddCohost(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+		})
+	}
+}
+
+func removeWebinarCohost(service webinar.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		webinarID, _ := strconv.ParseInt(c.Params("webinarID"), 10, 64)
+		cohostID, _ := strconv.ParseInt(c.Params("userID"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
+
+		req := webinar.CohostReq{
+			WebinarID: webinarID,
+			AuthorID:  userID,
+			UserID:    cohostID,
+		}
+
+		err := service.RemoveCohost(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+		})
+	}
+}
+
+func getWebinarCheckIn(service webinar.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		webinarID, _ := strconv.ParseInt(c.Params("webinarID"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
+
+		res, err := service.GetCheckIn(c.Context(), webinarID, userID)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func checkInWebinar(service webinar.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req webinar.CheckInReq
+		err := c.BodyParser(&req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: []string{"Invalid json format"},
+				},
+			})
+		}
+
+		req.WebinarID, _ = strconv.ParseInt(c.Params("webinarID"), 10, 64)
+		req.UserID, _ = c.Locals("userID").(int64)
+
+		res, err := service.CheckIn(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func getWebinarAttendance(service webinar.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		webinarID, _ := strconv.ParseInt(c.Params("webinarID"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
+
+		res, err := service.GetAttendance(c.Context(), webinarID, userID)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+// getWebinarCertificate serves the certificate of the attendee in the path,
+// or of the user when there is none.
+func getWebinarCertificate(service webinar.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		webinarID, _ := strconv.ParseInt(c.Params("webinarID"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
+		occurrence, _ := strconv.ParseInt(c.Query("occurrence"), 10, 64)
+
+		attendeeID := userID
+		if c.Params("userID") != "" {
+			attendeeID, _ = strconv.ParseInt(c.Params("userID"), 10, 64)
+		}
+
+		res, err := service.GetCertificate(c.Context(), webinarID, occurrence, attendeeID, userID)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		c.Set(fiber.HeaderContentType, "application/pdf")
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="certificate-%d-%d.pdf"`, webinarID, attendeeID))
+
+		return c.Status(200).Send(res)
+	}
+}
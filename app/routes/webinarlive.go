@@ -0,0 +1,473 @@
+package routes
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/app/middleware"
+	"github.com/bagus2x/recovy/webinarlive"
+	"github.com/bagus2x/recovy/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// livePingPeriod keeps idle live connections open through proxies and
+// detects clients that went away.
+const livePingPeriod = 30 * time.Second
+
+func WebinarLiveRoutes(r fiber.Router, mw *middleware.Middleware, service webinarlive.Service) {
+	webinar := r.Group("/api/v1/webinar")
+
+	webinar.Get("/:webinarID/live", mw.StreamAuth(), streamWebinarLive(service))
+	webinar.Get("/:webinarID/questions", getWebinarQuestions(service))
+	webinar.Post("/:webinarID/questions", mw.Auth(), askWebinarQuestion(service))
+	webinar.Post("/:webinarID/questions/:questionID/upvote", mw.Auth(), upvoteWebinarQuestion(service))
+	webinar.Delete("/:webinarID/questions/:questionID/upvote", mw.Auth(), removeWebinarQuestionUpvote(service))
+	webinar.Post("/:webinarID/questions/:questionID/answer", mw.Auth(), answerWebinarQuestion(service))
+	webinar.Get("/:webinarID/polls", getWebinarPolls(service))
+	webinar.Post("/:webinarID/polls", mw.Auth(), createWebinarPoll(service))
+	webinar.Post("/:webinarID/polls/:pollID/vote", mw.Auth(), voteWebinarPoll(service))
+	webinar.Post("/:webinarID/polls/:pollID/close", mw.Auth(), closeWebinarPoll(service))
+}
+
+// streamWebinarLive upgrades to a WebSocket, over which clients also send
+// actions, or falls back to a server-sent event stream. Both start with a
+// snapshot of the occurrence followed by its events.
+func streamWebinarLive(service webinarlive.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		webinarID, _ := strconv.ParseInt(c.Params("webinarID"), 10, 64)
+		occurrence, _ := strconv.ParseInt(c.Query("occurrence"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
+
+		events, cancel, err := service.Subscribe(c.Context(), webinarID, occurrence)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		snapshot, err := service.GetSnapshot(c.Context(), webinarID, occurrence)
+		if err != nil {
+			cancel()
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		initial, err := json.Marshal(webinarlive.Event{Type: webinarlive.EventSnapshot, Snapshot: &snapshot})
+		if err != nil {
+			cancel()
+			return err
+		}
+
+		if websocket.IsUpgrade(c) {
+			err = websocket.Upgrade(c, func(conn *websocket.Conn) {
+				defer cancel()
+				serveWebinarLive(conn, service, webinarID, occurrence, userID, initial, events)
+			})
+			if err != nil {
+				cancel()
+				return c.Status(400).JSON(app.Failure{
+					Success: false,
+					Error: app.ErrorDetail{
+						Code:     app.EBadRequest,
+						Messages: []string{"Invalid WebSocket handshake"},
+					},
+				})
+			}
+
+			return nil
+		}
+
+		c.Set(fiber.HeaderContentType, "text/event-stream")
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+		c.Set("X-Accel-Buffering", "no")
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			defer cancel()
+			streamEvents(w, initial, events)
+		})
+
+		return nil
+	}
+}
+
+func serveWebinarLive(conn *websocket.Conn, service webinarlive.Service, webinarID, occurrence, userID int64, initial []byte, events <-chan []byte) {
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	conn.ReadTimeout = 2 * livePingPeriod
+	err := conn.WriteMessage(websocket.TextMessage, initial)
+	if err != nil {
+		return
+	}
+
+	go func() {
+		defer stop()
+
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			var msg webinarlive.Message
+			err = json.Unmarshal(data, &msg)
+			if err != nil {
+				err = app.NewError(err, app.EBadRequest, "Invalid json format")
+			} else {
+				err = service.Handle(ctx, webinarID, occurrence, userID, msg)
+			}
+			if err != nil {
+				logrus.Error(err)
+				writeLiveError(conn, err)
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(livePingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if conn.WriteMessage(websocket.TextMessage, event) != nil {
+				return
+			}
+		case <-ticker.C:
+			if conn.Ping() != nil {
+				return
+			}
+		}
+	}
+}
+
+func writeLiveError(conn *websocket.Conn, err error) {
+	event, _ := json.Marshal(webinarlive.Event{
+		Type: webinarlive.EventError,
+		Error: &app.ErrorDetail{
+			Code:     app.ErrorCode(err),
+			Messages: app.ErrorMessage(err),
+		},
+	})
+	conn.WriteMessage(websocket.TextMessage, event)
+}
+
+// streamEvents writes server-sent events until the client goes away, which
+// shows as a failed flush.
+func streamEvents(w *bufio.Writer, initial []byte, events <-chan []byte) {
+	fmt.Fprintf(w, "retry: 3000\n\ndata: %s\n\n", initial)
+	if w.Flush() != nil {
+		return
+	}
+
+	ticker := time.NewTicker(livePingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(w, "data: %s\n\n", event)
+		case <-ticker.C:
+			w.WriteString(": ping\n\n")
+		}
+
+		if w.Flush() != nil {
+			return
+		}
+	}
+}
+
+func getWebinarQuestions(service webinarlive.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		webinarID, _ := strconv.ParseInt(c.Params("webinarID"), 10, 64)
+		occurrence, _ := strconv.ParseInt(c.Query("occurrence"), 10, 64)
+
+		res, err := service.GetSnapshot(c.Context(), webinarID, occurrence)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res.Questions,
+		})
+	}
+}
+
+func askWebinarQuestion(service webinarlive.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req webinarlive.AskReq
+		err := c.BodyParser(&req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: []string{"Invalid json format"},
+				},
+			})
+		}
+
+		req.WebinarID, _ = strconv.ParseInt(c.Params("webinarID"), 10, 64)
+		req.UserID, _ = c.Locals("userID").(int64)
+
+		res, err := service.Ask(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func getQuestionReq(c *fiber.Ctx) webinarlive.QuestionReq {
+	webinarID, _ := strconv.ParseInt(c.Params("webinarID"), 10, 64)
+	questionID, _ := strconv.ParseInt(c.Params("questionID"), 10, 64)
+	userID, _ := c.Locals("userID").(int64)
+
+	return webinarlive.QuestionReq{
+		WebinarID:  webinarID,
+		QuestionID: questionID,
+		UserID:     userID,
+	}
+}
+
+func upvoteWebinarQuestion(service webinarlive.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := getQuestionReq(c)
+
+		res, err := service.Upvote(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func removeWebinarQuestionUpvote(service webinarlive.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := getQuestionReq(c)
+
+		res, err := service.RemoveUpvote(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func answerWebinarQuestion(service webinarlive.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := getQuestionReq(c)
+
+		res, err := service.Answer(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func getWebinarPolls(service webinarlive.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		webinarID, _ := strconv.ParseInt(c.Params("webinarID"), 10, 64)
+		occurrence, _ := strconv.ParseInt(c.Query("occurrence"), 10, 64)
+
+		res, err := service.GetSnapshot(c.Context(), webinarID, occurrence)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res.Polls,
+		})
+	}
+}
+
+func createWebinarPoll(service webinarlive.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req webinarlive.CreatePollReq
+		err := c.BodyParser(&req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: []string{"Invalid json format"},
+				},
+			})
+		}
+
+		req.WebinarID, _ = strconv.ParseInt(c.Params("webinarID"), 10, 64)
+		req.UserID, _ = c.Locals("userID").(int64)
+
+		res, err := service.CreatePoll(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func voteWebinarPoll(service webinarlive.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req webinarlive.VoteReq
+		err := c.BodyParser(&req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: []string{"Invalid json format"},
+				},
+			})
+		}
+
+		req.WebinarID, _ = strconv.ParseInt(c.Params("webinarID"), 10, 64)
+		req.PollID, _ = strconv.ParseInt(c.Params("pollID"), 10, 64)
+		req.UserID, _ = c.Locals("userID").(int64)
+
+		res, err := service.Vote(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
+
+func closeWebinarPoll(service webinarlive.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		webinarID, _ := strconv.ParseInt(c.Params("webinarID"), 10, 64)
+		pollID, _ := strconv.ParseInt(c.Params("pollID"), 10, 64)
+		userID, _ := c.Locals("userID").(int64)
+
+		req := webinarlive.PollReq{
+			WebinarID: webinarID,
+			PollID:    pollID,
+			UserID:    userID,
+		}
+
+		res, err := service.ClosePoll(c.Context(), &req)
+		if err != nil {
+			logrus.Error(err)
+			return c.Status(app.Status(err)).JSON(app.Failure{
+				Success: false,
+				Error: app.ErrorDetail{
+					Code:     app.EBadRequest,
+					Messages: app.ErrorMessage(err),
+				},
+			})
+		}
+
+		return c.Status(200).JSON(app.Success{
+			Success: true,
+			Data:    res,
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package article
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bagus2x/recovy/markdown"
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/sanitize"
+)
+
+const (
+	excerptLength  = 200
+	wordsPerMinute = 200
+)
+
+// render fills in the HTML, excerpt and reading time of the article from its
+// Markdown source.
+func render(article *models.Article) {
+	article.HTML = sanitize.HTML(markdown.HTML(article.Description))
+
+	text := sanitize.Text(article.HTML)
+	article.Excerpt = excerpt(text, excerptLength)
+	article.ReadingTime = readingTime(text)
+}
+
+// excerpt cuts the text at the last word that fits in n characters.
+func excerpt(text string, n int) string {
+	if utf8.RuneCountInString(text) <= n {
+		return text
+	}
+
+	cut := []rune(text)[:n]
+	if i := strings.LastIndexByte(string(cut), ' '); i > 0 {
+		return strings.TrimRight(string(cut)[:i], " ,.;:") + "…"
+	}
+
+	return string(cut) + "…"
+}
+
+// readingTime is rounded up to whole minutes.
+func readingTime(text string) int64 {
+	words := int64(len(strings.Fields(text)))
+	if words == 0 {
+		return 0
+	}
+
+	return (words + wordsPerMinute - 1) / wordsPerMinute
+}
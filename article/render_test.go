@@ -0,0 +1,32 @@
+package article
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bagus2x/recovy/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	article := models.Article{
+		Description: "# Hello\n\nSome **bold** text. <script>alert(1)</script>\n\n[x](javascript:alert(1))\n",
+	}
+	render(&article)
+
+	assert.Equal(t, "<h1>Hello</h1>\n<p>Some <strong>bold</strong> text. </p>\n<p><a rel=\"nofollow noopener noreferrer\">x</a></p>\n", article.HTML)
+	assert.Equal(t, "Hello Some bold text. x", article.Excerpt)
+	assert.Equal(t, int64(1), article.ReadingTime)
+}
+
+func TestExcerpt(t *testing.T) {
+	assert.Equal(t, "short", excerpt("short", 10))
+	assert.Equal(t, "one two…", excerpt("one two, three", 10))
+	assert.Equal(t, "abcdefghij…", excerpt(strings.Repeat("abcdefghij", 2), 10))
+}
+
+func TestReadingTime(t *testing.T) {
+	assert.Equal(t, int64(0), readingTime(""))
+	assert.Equal(t, int64(1), readingTime("one"))
+	assert.Equal(t, int64(2), readingTime(strings.Repeat("word ", 201)))
+}
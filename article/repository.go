@@ -3,16 +3,24 @@ package article
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 
 	"github.com/bagus2x/recovy/app"
 	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/pagination"
+	"github.com/bagus2x/recovy/queue"
 )
 
 type Repository interface {
 	Create(ctx context.Context, article *models.Article) error
 	FindByID(ctx context.Context, articleID int64) (models.Article, error)
-	Find(ctx context.Context) ([]models.Article, error)
-	FindByCategory(ctx context.Context, category string) ([]models.Article, error)
+	Update(ctx context.Context, article *models.Article, revision *models.ArticleRevision) error
+	Find(ctx context.Context, params *Params) ([]models.Article, pagination.Cursor, error)
+	UpdateStatus(ctx context.Context, article *models.Article, from string, review *models.ArticleReview, publish *models.Job, cancel string) error
+	FindReviews(ctx context.Context, articleID int64) ([]models.ArticleReview, error)
+	FindRevisions(ctx context.Context, articleID int64) ([]models.ArticleRevision, error)
+	FindRevision(ctx context.Context, articleID, revisionID int64) (models.ArticleRevision, error)
 	Delete(ctx context.Context, articleID int64) error
 }
 
@@ -29,32 +37,64 @@ func NewRepository(db *sql.DB) Repository {
 var create = `
 	INSERT INTO
 		Article
-		(author_id, picture, title, description, category, created_at, updated_at)
+		(author_id, picture, title, description, html, excerpt, reading_time, category, status, publish_at, published_at, hidden_at, created_at, updated_at)
 	VALUES
-		($1, $2, $3, $4, $5, $6, $7)
+		($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	RETURNING
 		id
 `
 
+// Create stores the article along with its first revision.
 func (r *repository) Create(ctx context.Context, article *models.Article) error {
-	err := r.db.QueryRowContext(
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(
 		ctx,
 		create,
 		article.Author.ID,
 		article.Picture,
 		article.Title,
 		article.Description,
+		article.HTML,
+		article.Excerpt,
+		article.ReadingTime,
 		article.Category,
+		article.Status,
+		article.PublishAt,
+		article.PublishedAt,
+		article.HiddenAt,
 		article.CreatedAt,
 		article.UpdatedAt,
 	).Scan(&article.ID)
+	if err != nil {
+		return err
+	}
 
-	return err
+	revision := models.ArticleRevision{
+		ArticleID:   article.ID,
+		User:        article.Author,
+		Title:       article.Title,
+		Description: article.Description,
+		Category:    article.Category,
+		CreatedAt:   article.CreatedAt,
+	}
+
+	err = createRevision(ctx, tx, &revision)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 var findByID = `
 	SELECT
-		a.id, au.id, au.name, au.picture, a.picture, a.title, a.description, a.category, a.created_at, a.updated_at
+		a.id, au.id, au.name, au.picture, a.picture, a.title, a.description, a.html, a.excerpt, a.reading_time, a.category,
+		a.status, a.publish_at, a.published_at, a.created_at, a.updated_at
 	FROM
 		Article a
 	JOIN
@@ -62,7 +102,7 @@ var findByID = `
 	ON
 		a.author_id = au.id
 	WHERE
-		a.id = $1
+		a.id = $1 AND a.hidden_at = 0
 `
 
 func (r *repository) FindByID(ctx context.Context, articleID int64) (models.Article, error) {
@@ -76,37 +116,114 @@ func (r *repository) FindByID(ctx context.Context, articleID int64) (models.Arti
 		&article.Picture,
 		&article.Title,
 		&article.Description,
+		&article.HTML,
+		&article.Excerpt,
+		&article.ReadingTime,
 		&article.Category,
+		&article.Status,
+		&article.PublishAt,
+		&article.PublishedAt,
 		&article.CreatedAt,
 		&article.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return models.Article{}, app.NewError(err, app.ENotFound)
+	} else if err != nil {
+		return models.Article{}, err
 	}
 
 	return article, nil
 }
 
-var find = `
-	SELECT
-		a.id, au.id, au.name, au.picture, a.picture, a.title, a.description, a.category, a.created_at, a.updated_at
-	FROM
-		Article a
-	JOIN
-		App_User au
-	ON
-		a.author_id = au.id
-	LIMIT
-		100
-`
+// find builds a keyset query listing articles newest first, with a.id as the
+// tie breaker. Published articles are listed to everyone, the others only to
+// their author and to editors.
+func find(params *Params, key *pagination.Key) (string, []interface{}) {
+	query := strings.Builder{}
+	args := make([]interface{}, 0)
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	fmt.Fprintf(&query, `
+		SELECT
+			a.id, au.id, au.name, au.picture, a.picture, a.title, a.description, a.html, a.excerpt, a.reading_time, a.category,
+			a.status, a.publish_at, a.published_at, a.created_at, a.updated_at
+		FROM
+			Article a
+		JOIN
+			App_User au
+		ON
+			a.author_id = au.id
+		WHERE
+			a.hidden_at = 0 AND (a.status = 'published' OR a.author_id = %s OR %s)`,
+		arg(params.ViewerID), arg(params.Editor),
+	)
+
+	// Dynamic query
+	if params.Status != "" {
+		fmt.Fprintf(&query, " AND a.status = %s ", arg(params.Status))
+	}
+	if params.Category != "" {
+		fmt.Fprintf(&query, " AND EXISTS (SELECT 1 FROM Article_Term at JOIN Term t ON t.id = at.term_id WHERE at.article_id = a.id AND t.kind = 'category' AND t.slug = %s) ", arg(params.Category))
+	}
+	if params.Tag != "" {
+		fmt.Fprintf(&query, " AND EXISTS (SELECT 1 FROM Article_Term at JOIN Term t ON t.id = at.term_id WHERE at.article_id = a.id AND t.kind = 'tag' AND t.slug = %s) ", arg(params.Tag))
+	}
+
+	// Cursor
+	op, order := params.Order(true)
+	if key != nil {
+		fmt.Fprintf(&query, " AND (a.created_at, a.id) %s (%s, %s) ", op, arg(key.Value), arg(key.ID))
+	}
+
+	// Limit
+	fmt.Fprintf(&query, " ORDER BY a.created_at %s, a.id %s LIMIT %s", order, order, arg(params.Limit))
+
+	return query.String(), args
+}
+
+// Find lists a page of articles, newest first. Category and Tag select the
+// articles linked to the term with that slug, whether it is their primary
+// category or not.
+func (r *repository) Find(ctx context.Context, params *Params) ([]models.Article, pagination.Cursor, error) {
+	var key *pagination.Key
+	err := params.Normalize(&key)
+	if err != nil {
+		return nil, pagination.Cursor{}, err
+	}
+
+	query, args := find(params, key)
+	articles, err := r.find(ctx, query, args...)
+	if err != nil {
+		return nil, pagination.Cursor{}, err
+	}
+
+	if params.Previous() {
+		pagination.Reverse(articles)
+	}
+
+	var cursor pagination.Cursor
+	if len(articles) > 0 {
+		first, last := articles[0], articles[len(articles)-1]
+		cursor = pagination.NewCursor(
+			pagination.Key{Value: first.CreatedAt, ID: first.ID},
+			pagination.Key{Value: last.CreatedAt, ID: last.ID},
+		)
+	}
+
+	return articles, cursor, nil
+}
 
-func (r *repository) Find(ctx context.Context) ([]models.Article, error) {
+func (r *repository) find(ctx context.Context, query string, args ...interface{}) ([]models.Article, error) {
 	articles := make([]models.Article, 0)
 
-	rows, err := r.db.QueryContext(ctx, find)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return articles, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		var article models.Article
@@ -119,7 +236,13 @@ func (r *repository) Find(ctx context.Context) ([]models.Article, error) {
 			&article.Picture,
 			&article.Title,
 			&article.Description,
+			&article.HTML,
+			&article.Excerpt,
+			&article.ReadingTime,
 			&article.Category,
+			&article.Status,
+			&article.PublishAt,
+			&article.PublishedAt,
 			&article.CreatedAt,
 			&article.UpdatedAt,
 		)
@@ -129,62 +252,305 @@ func (r *repository) Find(ctx context.Context) ([]models.Article, error) {
 
 		articles = append(articles, article)
 	}
-	if rows.Err() != nil {
-		return articles, err
+
+	return articles, rows.Err()
+}
+
+var update = `
+	UPDATE
+		Article
+	SET
+		picture = $2, title = $3, description = $4, html = $5, excerpt = $6, reading_time = $7, category = $8, updated_at = $9, hidden_at = $10
+	WHERE
+		id = $1
+`
+
+var createRevisionQuery = `
+	INSERT INTO
+		Article_Revision
+		(article_id, user_id, title, description, category, created_at)
+	VALUES
+		($1, $2, $3, $4, $5, $6)
+	RETURNING
+		id
+`
+
+// Update saves the edited article and, when its title, body or category
+// changed, the revision it makes.
+func (r *repository) Update(ctx context.Context, article *models.Article, revision *models.ArticleRevision) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(
+		ctx,
+		update,
+		article.ID,
+		article.Picture,
+		article.Title,
+		article.Description,
+		article.HTML,
+		article.Excerpt,
+		article.ReadingTime,
+		article.Category,
+		article.UpdatedAt,
+		article.HiddenAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return app.NewError(nil, app.ENotFound)
+	}
+
+	if revision != nil {
+		revision.ArticleID = article.ID
+		err = createRevision(ctx, tx, revision)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func createRevision(ctx context.Context, tx *sql.Tx, revision *models.ArticleRevision) error {
+	return tx.QueryRowContext(
+		ctx,
+		createRevisionQuery,
+		revision.ArticleID,
+		revision.User.ID,
+		revision.Title,
+		revision.Description,
+		revision.Category,
+		revision.CreatedAt,
+	).Scan(&revision.ID)
+}
+
+var updateStatus = `
+	UPDATE
+		Article
+	SET
+		status = $3, publish_at = $4, published_at = $5, updated_at = $6
+	WHERE
+		id = $1 AND status = $2
+`
+
+var createReview = `
+	INSERT INTO
+		Article_Review
+		(article_id, reviewer_id, decision, comment, created_at)
+	VALUES
+		($1, $2, $3, $4, $5)
+	RETURNING
+		id
+`
+
+// UpdateStatus moves the article from the given status to its current one,
+// recording the review that did so, if any. The jobs with the cancel
+// reference are removed and the publish job is enqueued along with it, so
+// the status and its publish job never disagree. It fails with Econflict
+// when the article is no longer in that status.
+func (r *repository) UpdateStatus(ctx context.Context, article *models.Article, from string, review *models.ArticleReview, publish *models.Job, cancel string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(
+		ctx,
+		updateStatus,
+		article.ID,
+		from,
+		article.Status,
+		article.PublishAt,
+		article.PublishedAt,
+		article.UpdatedAt,
+	)
+	if err != nil {
+		return err
 	}
 
-	return articles, nil
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return app.NewError(nil, app.Econflict)
+	}
+
+	if review != nil {
+		review.ArticleID = article.ID
+		err = tx.QueryRowContext(
+			ctx,
+			createReview,
+			review.ArticleID,
+			review.Reviewer.ID,
+			review.Decision,
+			review.Comment,
+			review.CreatedAt,
+		).Scan(&review.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if cancel != "" {
+		err = queue.CancelByReferenceTx(ctx, tx, cancel)
+		if err != nil {
+			return err
+		}
+	}
+
+	if publish != nil {
+		err = queue.EnqueueTx(ctx, tx, publish)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
-var findByCategory = `
+var findReviews = `
 	SELECT
-		a.id, au.id, au.name, au.picture, a.picture, a.title, a.description, a.category, a.created_at, a.updated_at
+		ar.id, ar.article_id, COALESCE(au.id, 0), COALESCE(au.name, ''), COALESCE(au.picture, ''), ar.decision, ar.comment, ar.created_at
 	FROM
-		Article a
-	JOIN
+		Article_Review ar
+	LEFT JOIN
 		App_User au
 	ON
-		a.author_id = au.id
+		ar.reviewer_id = au.id
 	WHERE
-		a.category = $1
-	LIMIT
-		100
+		ar.article_id = $1
+	ORDER BY
+		ar.created_at DESC, ar.id DESC
 `
 
-func (r *repository) FindByCategory(ctx context.Context, category string) ([]models.Article, error) {
-	articles := make([]models.Article, 0)
+// FindReviews returns the newest reviews first.
+func (r *repository) FindReviews(ctx context.Context, articleID int64) ([]models.ArticleReview, error) {
+	reviews := make([]models.ArticleReview, 0)
 
-	rows, err := r.db.QueryContext(ctx, findByCategory, category)
+	rows, err := r.db.QueryContext(ctx, findReviews, articleID)
 	if err != nil {
-		return articles, err
+		return reviews, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
-		var article models.Article
+		var review models.ArticleReview
 
 		err := rows.Scan(
-			&article.ID,
-			&article.Author.ID,
-			&article.Author.Name,
-			&article.Author.Picture,
-			&article.Picture,
-			&article.Title,
-			&article.Description,
-			&article.Category,
-			&article.CreatedAt,
-			&article.UpdatedAt,
+			&review.ID,
+			&review.ArticleID,
+			&review.Reviewer.ID,
+			&review.Reviewer.Name,
+			&review.Reviewer.Picture,
+			&review.Decision,
+			&review.Comment,
+			&review.CreatedAt,
 		)
 		if err != nil {
-			return articles, err
+			return reviews, err
 		}
 
-		articles = append(articles, article)
+		reviews = append(reviews, review)
 	}
-	if rows.Err() != nil {
-		return articles, err
+
+	return reviews, rows.Err()
+}
+
+var findRevisions = `
+	SELECT
+		ar.id, ar.article_id, COALESCE(au.id, 0), COALESCE(au.name, ''), COALESCE(au.picture, ''), ar.title, ar.description, ar.category, ar.created_at
+	FROM
+		Article_Revision ar
+	LEFT JOIN
+		App_User au
+	ON
+		ar.user_id = au.id
+	WHERE
+		ar.article_id = $1
+	ORDER BY
+		ar.id DESC
+`
+
+// FindRevisions returns the newest revisions first.
+func (r *repository) FindRevisions(ctx context.Context, articleID int64) ([]models.ArticleRevision, error) {
+	revisions := make([]models.ArticleRevision, 0)
+
+	rows, err := r.db.QueryContext(ctx, findRevisions, articleID)
+	if err != nil {
+		return revisions, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var revision models.ArticleRevision
+
+		err := rows.Scan(
+			&revision.ID,
+			&revision.ArticleID,
+			&revision.User.ID,
+			&revision.User.Name,
+			&revision.User.Picture,
+			&revision.Title,
+			&revision.Description,
+			&revision.Category,
+			&revision.CreatedAt,
+		)
+		if err != nil {
+			return revisions, err
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, rows.Err()
+}
+
+var findRevision = `
+	SELECT
+		ar.id, ar.article_id, COALESCE(au.id, 0), COALESCE(au.name, ''), COALESCE(au.picture, ''), ar.title, ar.description, ar.category, ar.created_at
+	FROM
+		Article_Revision ar
+	LEFT JOIN
+		App_User au
+	ON
+		ar.user_id = au.id
+	WHERE
+		ar.article_id = $1 AND ar.id = $2
+`
+
+func (r *repository) FindRevision(ctx context.Context, articleID, revisionID int64) (models.ArticleRevision, error) {
+	var revision models.ArticleRevision
+
+	err := r.db.QueryRowContext(ctx, findRevision, articleID, revisionID).Scan(
+		&revision.ID,
+		&revision.ArticleID,
+		&revision.User.ID,
+		&revision.User.Name,
+		&revision.User.Picture,
+		&revision.Title,
+		&revision.Description,
+		&revision.Category,
+		&revision.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return models.ArticleRevision{}, app.NewError(err, app.ENotFound)
+	} else if err != nil {
+		return models.ArticleRevision{}, err
 	}
 
-	return articles, nil
+	return revision, nil
 }
 
 var delete = `
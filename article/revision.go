@@ -0,0 +1,357 @@
+package article
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/diff"
+	"github.com/bagus2x/recovy/filter"
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/taxonomy"
+)
+
+// Update lets the author or an editor edit an article; once it is approved,
+// only an editor can. A change of its title, body or category is recorded as
+// a new revision. A changed title or body goes through the content filter,
+// which may hold the article for review.
+func (s *service) Update(ctx context.Context, req *UpdateArticleReq) (GetArticleResp, error) {
+	err := req.Validate()
+	if err != nil {
+		return GetArticleResp{}, err
+	}
+
+	article, err := s.findWritable(ctx, req.ArticleID, req.UserID)
+	if err != nil {
+		return GetArticleResp{}, err
+	}
+
+	updated := article
+	if req.Picture != nil {
+		updated.Picture = *req.Picture
+	}
+	if req.Title != nil {
+		updated.Title = *req.Title
+	}
+	if req.Description != nil {
+		updated.Description = *req.Description
+	}
+
+	filtered, err := s.filter(ctx, article, &updated, req.UserID)
+	if err != nil {
+		return GetArticleResp{}, err
+	}
+
+	retagged := req.Category != nil || req.Categories != nil || req.Tags != nil
+	var terms []models.Term
+	if retagged {
+		current, err := s.taxonomyService.Terms(ctx, models.ContentArticle, article.ID)
+		if err != nil {
+			return GetArticleResp{}, err
+		}
+
+		categories, tags := taxonomy.Revise(current[article.ID], article.Category, req.Category, req.Categories, req.Tags)
+		terms, err = s.taxonomyService.Resolve(ctx, categories, tags)
+		if err != nil {
+			return GetArticleResp{}, err
+		}
+		updated.Category = terms[0].Slug
+	}
+
+	err = s.save(ctx, article, &updated, req.UserID)
+	if err != nil {
+		return GetArticleResp{}, err
+	}
+
+	if retagged {
+		err = s.taxonomyService.Link(ctx, models.ContentArticle, article.ID, terms)
+		if err != nil {
+			return GetArticleResp{}, err
+		}
+	}
+
+	return s.filteredResp(ctx, updated, filtered, req.UserID)
+}
+
+// filter runs the content filter on the title and body of the edited
+// article when they changed, masking them in place and hiding the article
+// when it is held.
+func (s *service) filter(ctx context.Context, old models.Article, article *models.Article, userID int64) (filter.Result, error) {
+	if article.Title == old.Title && article.Description == old.Description {
+		return filter.Result{}, nil
+	}
+
+	post := filter.Post{
+		ContentType: models.ContentArticle,
+		ID:          article.ID,
+		AuthorID:    userID,
+		Title:       article.Title,
+		Body:        article.Description,
+	}
+	filtered, err := s.contentFilter.Run(ctx, &post)
+	if err != nil {
+		return filter.Result{}, err
+	}
+
+	article.Title, article.Description = post.Title, post.Body
+	if filtered.Held {
+		article.HiddenAt = time.Now().Unix()
+	}
+
+	return filtered, nil
+}
+
+// filteredResp reports a held article to the moderators and tells the user
+// why it was held.
+func (s *service) filteredResp(ctx context.Context, article models.Article, filtered filter.Result, userID int64) (GetArticleResp, error) {
+	if filtered.Held {
+		err := s.moderationService.Hold(ctx, models.ContentArticle, article.ID, strings.Join(filtered.Reasons, "; "))
+		if err != nil {
+			return GetArticleResp{}, err
+		}
+	}
+
+	resp, err := s.articleResp(ctx, article, userID)
+	if err != nil {
+		return GetArticleResp{}, err
+	}
+	resp.Held, resp.HeldReasons = filtered.Held, filtered.Reasons
+
+	return resp, nil
+}
+
+// save stores the edited article, with a new revision when its title, body or
+// category differ from the old one.
+func (s *service) save(ctx context.Context, old models.Article, article *models.Article, userID int64) error {
+	revised := article.Title != old.Title || article.Description != old.Description || article.Category != old.Category
+	if !revised && article.Picture == old.Picture {
+		return nil
+	}
+
+	article.UpdatedAt = time.Now().Unix()
+	render(article)
+
+	var revision *models.ArticleRevision
+	if revised {
+		revision = &models.ArticleRevision{
+			User:        models.User{ID: userID},
+			Title:       article.Title,
+			Description: article.Description,
+			Category:    article.Category,
+			CreatedAt:   article.UpdatedAt,
+		}
+	}
+
+	err := s.articleRepo.Update(ctx, article, revision)
+	if app.ErrorCode(err) == app.ENotFound {
+		return app.NewError(err, app.ENotFound, "Article not found")
+	}
+
+	return err
+}
+
+// findEditable returns the article when the user is its author or an
+// editor.
+func (s *service) findEditable(ctx context.Context, articleID, userID int64) (models.Article, error) {
+	article, err := s.articleRepo.FindByID(ctx, articleID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return models.Article{}, app.NewError(err, app.ENotFound, "Article not found")
+	} else if err != nil {
+		return models.Article{}, err
+	}
+
+	if article.Author.ID != userID {
+		editor, err := s.isEditor(ctx, userID)
+		if err != nil {
+			return models.Article{}, err
+		}
+		if !editor {
+			return models.Article{}, app.NewError(nil, app.EForbidden, "Forbidden access, article not found")
+		}
+	}
+
+	return article, nil
+}
+
+// findWritable is findEditable for changes to the article. Once an article is
+// approved, only editors can change it, so what readers see has been
+// reviewed: its author withdraws a scheduled article to a draft first, and a
+// published one is left as it was approved.
+func (s *service) findWritable(ctx context.Context, articleID, userID int64) (models.Article, error) {
+	article, err := s.findEditable(ctx, articleID, userID)
+	if err != nil || !approved(article.Status) {
+		return article, err
+	}
+
+	editor, err := s.isEditor(ctx, userID)
+	if err != nil {
+		return models.Article{}, err
+	}
+	if editor {
+		return article, nil
+	}
+
+	if article.Status == models.ArticleScheduled {
+		return models.Article{}, app.NewError(nil, app.Econflict, "Article has been approved, withdraw it to a draft to edit it")
+	}
+
+	return models.Article{}, app.NewError(nil, app.Econflict, "Article has been published and can only be edited by an editor")
+}
+
+// approved reports whether the article has passed review.
+func approved(status string) bool {
+	return status == models.ArticleScheduled || status == models.ArticlePublished
+}
+
+// GetRevisions lists the revisions of an article, newest first, to its author
+// and to editors.
+func (s *service) GetRevisions(ctx context.Context, articleID, userID int64) ([]Revision, error) {
+	_, err := s.findEditable(ctx, articleID, userID)
+	if err != nil {
+		return make([]Revision, 0), err
+	}
+
+	revisions, err := s.articleRepo.FindRevisions(ctx, articleID)
+	if err != nil {
+		return make([]Revision, 0), err
+	}
+
+	resp := make([]Revision, 0, len(revisions))
+	for _, revision := range revisions {
+		revision.Description = ""
+		resp = append(resp, toRevision(revision))
+	}
+
+	return resp, nil
+}
+
+func (s *service) GetRevision(ctx context.Context, articleID, revisionID, userID int64) (Revision, error) {
+	_, err := s.findEditable(ctx, articleID, userID)
+	if err != nil {
+		return Revision{}, err
+	}
+
+	revision, err := s.findRevision(ctx, articleID, revisionID)
+	if err != nil {
+		return Revision{}, err
+	}
+
+	return toRevision(revision), nil
+}
+
+func (s *service) findRevision(ctx context.Context, articleID, revisionID int64) (models.ArticleRevision, error) {
+	revision, err := s.articleRepo.FindRevision(ctx, articleID, revisionID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return models.ArticleRevision{}, app.NewError(err, app.ENotFound, "Revision not found")
+	}
+
+	return revision, err
+}
+
+// Diff compares two revisions of an article.
+func (s *service) Diff(ctx context.Context, req *DiffReq) (RevisionDiff, error) {
+	err := req.Validate()
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+
+	_, err = s.findEditable(ctx, req.ArticleID, req.UserID)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+
+	from, err := s.findRevision(ctx, req.ArticleID, req.From)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+
+	to, err := s.findRevision(ctx, req.ArticleID, req.To)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+
+	description := diff.Lines(from.Description, to.Description)
+	if req.Mode == "word" {
+		description = diff.Words(from.Description, to.Description)
+	}
+
+	from.Description, to.Description = "", ""
+
+	return RevisionDiff{
+		From:        toRevision(from),
+		To:          toRevision(to),
+		Title:       diff.Words(from.Title, to.Title),
+		Description: description,
+		Category:    diff.Words(from.Category, to.Category),
+	}, nil
+}
+
+// Restore brings back the title, body and category of an old revision as a
+// new revision, so the history in between is kept. A category that no longer
+// exists is not restored. The restored title and body go through the content
+// filter as an edit does.
+func (s *service) Restore(ctx context.Context, articleID, revisionID, userID int64) (GetArticleResp, error) {
+	article, err := s.findWritable(ctx, articleID, userID)
+	if err != nil {
+		return GetArticleResp{}, err
+	}
+
+	revision, err := s.findRevision(ctx, articleID, revisionID)
+	if err != nil {
+		return GetArticleResp{}, err
+	}
+
+	restored := article
+	restored.Title = revision.Title
+	restored.Description = revision.Description
+
+	filtered, err := s.filter(ctx, article, &restored, userID)
+	if err != nil {
+		return GetArticleResp{}, err
+	}
+
+	current, err := s.taxonomyService.Terms(ctx, models.ContentArticle, article.ID)
+	if err != nil {
+		return GetArticleResp{}, err
+	}
+
+	categories, tags := taxonomy.Revise(current[article.ID], revision.Category, nil, nil, nil)
+	terms, err := s.taxonomyService.Resolve(ctx, categories, tags)
+	if app.ErrorCode(err) == app.EBadRequest {
+		terms = nil
+	} else if err != nil {
+		return GetArticleResp{}, err
+	} else {
+		restored.Category = terms[0].Slug
+	}
+
+	err = s.save(ctx, article, &restored, userID)
+	if err != nil {
+		return GetArticleResp{}, err
+	}
+
+	if terms != nil {
+		err = s.taxonomyService.Link(ctx, models.ContentArticle, article.ID, terms)
+		if err != nil {
+			return GetArticleResp{}, err
+		}
+	}
+
+	return s.filteredResp(ctx, restored, filtered, userID)
+}
+
+func toRevision(revision models.ArticleRevision) Revision {
+	return Revision{
+		ID: revision.ID,
+		Author: Author{
+			ID:      revision.User.ID,
+			Name:    revision.User.Name,
+			Picture: revision.User.Picture,
+		},
+		Title:       revision.Title,
+		Description: revision.Description,
+		Category:    revision.Category,
+		CreatedAt:   revision.CreatedAt,
+	}
+}
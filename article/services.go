@@ -2,27 +2,64 @@ package article
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/auth"
+	"github.com/bagus2x/recovy/filter"
 	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/moderation"
+	"github.com/bagus2x/recovy/queue"
+	"github.com/bagus2x/recovy/reaction"
+	"github.com/bagus2x/recovy/taxonomy"
 )
 
 type Service interface {
 	Create(ctx context.Context, req *CreateArticleReq) (CreateArticleResp, error)
-	GetByID(ctx context.Context, articleID int64) (GetArticleResp, error)
-	GetByCategory(ctx context.Context, category string) ([]GetArticleResp, error)
-	Get(ctx context.Context) ([]GetArticleResp, error)
+	GetByID(ctx context.Context, articleID, viewerID int64) (GetArticleResp, error)
+	GetByCategory(ctx context.Context, category string, params *Params) (GetArticlesResp, error)
+	Get(ctx context.Context, params *Params) (GetArticlesResp, error)
+	Update(ctx context.Context, req *UpdateArticleReq) (GetArticleResp, error)
+	GetRevisions(ctx context.Context, articleID, userID int64) ([]Revision, error)
+	GetRevision(ctx context.Context, articleID, revisionID, userID int64) (Revision, error)
+	Diff(ctx context.Context, req *DiffReq) (RevisionDiff, error)
+	Restore(ctx context.Context, articleID, revisionID, userID int64) (GetArticleResp, error)
+	Submit(ctx context.Context, articleID, authorID int64) (GetArticleResp, error)
+	Withdraw(ctx context.Context, articleID, authorID int64) (GetArticleResp, error)
+	Review(ctx context.Context, req *ReviewArticleReq) (Review, error)
+	GetReviews(ctx context.Context, articleID, userID int64) ([]Review, error)
+	HandlePublish(ctx context.Context, job models.Job) error
 	Delete(ctx context.Context, articleID, authorID int64) error
 }
 
 type service struct {
-	articleRepo Repository
+	articleRepo       Repository
+	userRepo          auth.Repository
+	jobRepo           queue.Repository
+	taxonomyService   taxonomy.Service
+	reactionService   reaction.Service
+	moderationService moderation.Service
+	contentFilter     *filter.Pipeline
 }
 
-func NewService(articleRepo Repository) Service {
+func NewService(
+	articleRepo Repository,
+	userRepo auth.Repository,
+	jobRepo queue.Repository,
+	taxonomyService taxonomy.Service,
+	reactionService reaction.Service,
+	moderationService moderation.Service,
+	contentFilter *filter.Pipeline,
+) Service {
 	return &service{
-		articleRepo: articleRepo,
+		articleRepo:       articleRepo,
+		userRepo:          userRepo,
+		jobRepo:           jobRepo,
+		taxonomyService:   taxonomyService,
+		reactionService:   reactionService,
+		moderationService: moderationService,
+		contentFilter:     contentFilter,
 	}
 }
 
@@ -32,30 +69,82 @@ func (s *service) Create(ctx context.Context, req *CreateArticleReq) (CreateArti
 		return CreateArticleResp{}, err
 	}
 
+	author, err := s.userRepo.FindByID(ctx, req.AuthorID)
+	if err != nil {
+		return CreateArticleResp{}, err
+	}
+	if author.IsSuspended(time.Now().Unix()) {
+		return CreateArticleResp{}, app.NewError(nil, app.EForbidden, "Your account is suspended")
+	}
+
+	terms, err := s.taxonomyService.Resolve(ctx, append([]string{req.Category}, req.Categories...), req.Tags)
+	if err != nil {
+		return CreateArticleResp{}, err
+	}
+
+	post := filter.Post{
+		ContentType: models.ContentArticle,
+		AuthorID:    req.AuthorID,
+		Title:       req.Title,
+		Body:        req.Description,
+	}
+	filtered, err := s.contentFilter.Run(ctx, &post)
+	if err != nil {
+		return CreateArticleResp{}, err
+	}
+
 	article := models.Article{
 		Author: models.User{
 			ID: req.AuthorID,
 		},
 		Picture:     req.Picture,
-		Title:       req.Title,
-		Description: req.Description,
-		Category:    req.Category,
+		Title:       post.Title,
+		Description: post.Body,
+		Category:    terms[0].Slug,
+		Status:      models.ArticleDraft,
 		CreatedAt:   time.Now().Unix(),
 		UpdatedAt:   time.Now().Unix(),
 	}
+	if filtered.Held {
+		article.HiddenAt = article.CreatedAt
+	}
+	render(&article)
 
 	err = s.articleRepo.Create(ctx, &article)
 	if err != nil {
 		return CreateArticleResp{}, err
 	}
 
+	err = s.taxonomyService.Link(ctx, models.ContentArticle, article.ID, terms)
+	if err != nil {
+		return CreateArticleResp{}, err
+	}
+	categories, tags := taxonomy.Split(terms)
+
+	if filtered.Held {
+		err = s.moderationService.Hold(ctx, models.ContentArticle, article.ID, strings.Join(filtered.Reasons, "; "))
+		if err != nil {
+			return CreateArticleResp{}, err
+		}
+	}
+
 	res := CreateArticleResp{
 		ID:          article.ID,
 		AuthorID:    article.Author.ID,
 		Picture:     article.Picture,
 		Title:       article.Title,
 		Description: article.Description,
+		HTML:        article.HTML,
+		Excerpt:     article.Excerpt,
+		ReadingTime: article.ReadingTime,
 		Category:    article.Category,
+		Categories:  categories,
+		Tags:        tags,
+		Status:      article.Status,
+		PublishAt:   article.PublishAt,
+		PublishedAt: article.PublishedAt,
+		Held:        filtered.Held,
+		HeldReasons: filtered.Reasons,
 		CreatedAt:   article.CreatedAt,
 		UpdatedAt:   article.UpdatedAt,
 	}
@@ -63,7 +152,9 @@ func (s *service) Create(ctx context.Context, req *CreateArticleReq) (CreateArti
 	return res, nil
 }
 
-func (s *service) GetByID(ctx context.Context, articleID int64) (GetArticleResp, error) {
+// GetByID hides articles that are not published from everyone but their
+// author and editors.
+func (s *service) GetByID(ctx context.Context, articleID, viewerID int64) (GetArticleResp, error) {
 	article, err := s.articleRepo.FindByID(ctx, articleID)
 	if app.ErrorCode(err) == app.ENotFound {
 		return GetArticleResp{}, app.NewError(err, app.ENotFound, "Article not found")
@@ -71,73 +162,181 @@ func (s *service) GetByID(ctx context.Context, articleID int64) (GetArticleResp,
 		return GetArticleResp{}, err
 	}
 
-	resp := GetArticleResp{
-		ID: article.ID,
-		Author: Author{
-			ID:      article.Author.ID,
-			Name:    article.Author.Name,
-			Picture: article.Author.Picture,
-		},
-		Picture:     article.Picture,
-		Title:       article.Title,
-		Description: article.Description,
-		Category:    article.Category,
-		CreatedAt:   article.CreatedAt,
-		UpdatedAt:   article.UpdatedAt,
+	if article.Status != models.ArticlePublished && article.Author.ID != viewerID {
+		editor, err := s.isEditor(ctx, viewerID)
+		if err != nil {
+			return GetArticleResp{}, err
+		}
+		if !editor {
+			return GetArticleResp{}, app.NewError(nil, app.ENotFound, "Article not found")
+		}
 	}
 
-	return resp, nil
+	return s.articleResp(ctx, article, viewerID)
+}
+
+func (s *service) GetByCategory(ctx context.Context, category string, params *Params) (GetArticlesResp, error) {
+	params.Category = category
+
+	return s.Get(ctx, params)
 }
 
-func (s *service) GetByCategory(ctx context.Context, category string) ([]GetArticleResp, error) {
-	articles, err := s.articleRepo.FindByCategory(ctx, category)
+func (s *service) Get(ctx context.Context, params *Params) (GetArticlesResp, error) {
+	err := s.resolveViewer(ctx, params)
 	if err != nil {
-		return make([]GetArticleResp, 0), err
+		return GetArticlesResp{}, err
 	}
 
-	resp := make([]GetArticleResp, 0)
-	for _, article := range articles {
-		resp = append(resp, GetArticleResp{
-			ID: article.ID,
-			Author: Author{
-				ID:      article.Author.ID,
-				Name:    article.Author.Name,
-				Picture: article.Author.Picture,
-			},
-			Picture:     article.Picture,
-			Title:       article.Title,
-			Description: article.Description,
-			Category:    article.Category,
-			CreatedAt:   article.CreatedAt,
-			UpdatedAt:   article.UpdatedAt,
-		})
+	articles, cursor, err := s.articleRepo.Find(ctx, params)
+	if err != nil {
+		return GetArticlesResp{}, err
 	}
 
-	return resp, nil
+	resp, err := s.articlesResp(ctx, articles, params.ViewerID)
+	if err != nil {
+		return GetArticlesResp{}, err
+	}
+
+	return GetArticlesResp{Cursor: cursor, Articles: resp}, nil
 }
 
-func (s *service) Get(ctx context.Context) ([]GetArticleResp, error) {
-	articles, err := s.articleRepo.Find(ctx)
+// resolveViewer sets whether the viewer of the list is an editor.
+func (s *service) resolveViewer(ctx context.Context, params *Params) error {
+	editor, err := s.isEditor(ctx, params.ViewerID)
 	if err != nil {
-		return make([]GetArticleResp, 0), err
+		return err
 	}
+	params.Editor = editor
 
-	resp := make([]GetArticleResp, 0)
-	for _, article := range articles {
-		resp = append(resp, GetArticleResp{
-			ID: article.ID,
-			Author: Author{
-				ID:      article.Author.ID,
-				Name:    article.Author.Name,
-				Picture: article.Author.Picture,
-			},
-			Picture:     article.Picture,
-			Title:       article.Title,
-			Description: article.Description,
-			Category:    article.Category,
-			CreatedAt:   article.CreatedAt,
-			UpdatedAt:   article.UpdatedAt,
-		})
+	return nil
+}
+
+func (s *service) isEditor(ctx context.Context, userID int64) (bool, error) {
+	if userID == 0 {
+		return false, nil
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return user.IsEditor(), nil
+}
+
+// Submit sends a draft or rejected article to the editors for review.
+func (s *service) Submit(ctx context.Context, articleID, authorID int64) (GetArticleResp, error) {
+	article, err := s.findOwn(ctx, articleID, authorID)
+	if err != nil {
+		return GetArticleResp{}, err
+	}
+
+	err = s.transition(ctx, &article, models.ArticleSubmitted, nil)
+	if err != nil {
+		return GetArticleResp{}, err
+	}
+
+	return s.articleResp(ctx, article, authorID)
+}
+
+// Withdraw turns an article that is not published yet back into a draft.
+func (s *service) Withdraw(ctx context.Context, articleID, authorID int64) (GetArticleResp, error) {
+	article, err := s.findOwn(ctx, articleID, authorID)
+	if err != nil {
+		return GetArticleResp{}, err
+	}
+
+	err = s.transition(ctx, &article, models.ArticleDraft, nil)
+	if err != nil {
+		return GetArticleResp{}, err
+	}
+
+	return s.articleResp(ctx, article, authorID)
+}
+
+func (s *service) findOwn(ctx context.Context, articleID, authorID int64) (models.Article, error) {
+	article, err := s.articleRepo.FindByID(ctx, articleID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return models.Article{}, app.NewError(err, app.ENotFound, "Article not found")
+	} else if err != nil {
+		return models.Article{}, err
+	}
+
+	if article.Author.ID != authorID {
+		return models.Article{}, app.NewError(nil, app.EForbidden, "Forbidden access, article not found")
+	}
+
+	return article, nil
+}
+
+// Review lets an editor approve or reject a submitted article. Editors cannot
+// review their own articles.
+func (s *service) Review(ctx context.Context, req *ReviewArticleReq) (Review, error) {
+	err := req.Validate()
+	if err != nil {
+		return Review{}, err
+	}
+
+	reviewer, err := s.userRepo.FindByID(ctx, req.ReviewerID)
+	if err != nil && app.ErrorCode(err) != app.ENotFound {
+		return Review{}, err
+	}
+	if !reviewer.IsEditor() {
+		return Review{}, app.NewError(nil, app.EForbidden, "Only editors can review articles")
+	}
+
+	article, err := s.articleRepo.FindByID(ctx, req.ArticleID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return Review{}, app.NewError(err, app.ENotFound, "Article not found")
+	} else if err != nil {
+		return Review{}, err
+	}
+
+	if article.Author.ID == reviewer.ID {
+		return Review{}, app.NewError(nil, app.EForbidden, "Editors cannot review their own articles")
+	}
+
+	review := models.ArticleReview{
+		Reviewer:  reviewer,
+		Decision:  req.Decision,
+		Comment:   req.Comment,
+		CreatedAt: time.Now().Unix(),
+	}
+
+	to := models.ArticleRejected
+	if req.Decision == models.ReviewApproved {
+		to = models.ArticlePublished
+		if req.PublishAt > review.CreatedAt {
+			to = models.ArticleScheduled
+			article.PublishAt = req.PublishAt
+		}
+	}
+
+	err = s.transition(ctx, &article, to, &review)
+	if err != nil {
+		return Review{}, err
+	}
+
+	return toReview(review), nil
+}
+
+// GetReviews lists the reviews of an article to its author and to editors.
+func (s *service) GetReviews(ctx context.Context, articleID, userID int64) ([]Review, error) {
+	_, err := s.findEditable(ctx, articleID, userID)
+	if err != nil {
+		return make([]Review, 0), err
+	}
+
+	reviews, err := s.articleRepo.FindReviews(ctx, articleID)
+	if err != nil {
+		return make([]Review, 0), err
+	}
+
+	resp := make([]Review, 0, len(reviews))
+	for _, review := range reviews {
+		resp = append(resp, toReview(review))
 	}
 
 	return resp, nil
@@ -155,5 +354,93 @@ func (s *service) Delete(ctx context.Context, articleID, authorID int64) error {
 		return app.NewError(nil, app.EForbidden, "Forbidden access, article not found")
 	}
 
-	return s.articleRepo.Delete(ctx, articleID)
+	err = s.articleRepo.Delete(ctx, articleID)
+	if err != nil {
+		return err
+	}
+
+	return s.jobRepo.CancelByReference(ctx, publishReference(articleID))
+}
+
+func (s *service) articleResp(ctx context.Context, article models.Article, viewerID int64) (GetArticleResp, error) {
+	resp, err := s.articlesResp(ctx, []models.Article{article}, viewerID)
+	if err != nil {
+		return GetArticleResp{}, err
+	}
+
+	return resp[0], nil
+}
+
+// articlesResp adds the terms of the articles and their reactions, with
+// those of the viewer.
+func (s *service) articlesResp(ctx context.Context, articles []models.Article, viewerID int64) ([]GetArticleResp, error) {
+	ids := make([]int64, 0, len(articles))
+	for _, article := range articles {
+		ids = append(ids, article.ID)
+	}
+
+	terms, err := s.taxonomyService.Terms(ctx, models.ContentArticle, ids...)
+	if err != nil {
+		return make([]GetArticleResp, 0), err
+	}
+
+	summaries, err := s.reactionService.Summaries(ctx, models.ContentArticle, viewerID, ids...)
+	if err != nil {
+		return make([]GetArticleResp, 0), err
+	}
+
+	resp := make([]GetArticleResp, 0, len(articles))
+	for _, article := range articles {
+		res := toArticleResp(article, terms[article.ID])
+		res.Summary = summaries[article.ID]
+		resp = append(resp, res)
+	}
+
+	return resp, nil
+}
+
+// toArticleResp renders articles written before they were stored as Markdown
+// on the fly.
+func toArticleResp(article models.Article, terms []models.Term) GetArticleResp {
+	categories, tags := taxonomy.Split(terms)
+	if article.HTML == "" && article.Description != "" {
+		render(&article)
+	}
+
+	return GetArticleResp{
+		ID: article.ID,
+		Author: Author{
+			ID:      article.Author.ID,
+			Name:    article.Author.Name,
+			Picture: article.Author.Picture,
+		},
+		Picture:     article.Picture,
+		Title:       article.Title,
+		Description: article.Description,
+		HTML:        article.HTML,
+		Excerpt:     article.Excerpt,
+		ReadingTime: article.ReadingTime,
+		Category:    article.Category,
+		Categories:  categories,
+		Tags:        tags,
+		Status:      article.Status,
+		PublishAt:   article.PublishAt,
+		PublishedAt: article.PublishedAt,
+		CreatedAt:   article.CreatedAt,
+		UpdatedAt:   article.UpdatedAt,
+	}
+}
+
+func toReview(review models.ArticleReview) Review {
+	return Review{
+		ID: review.ID,
+		Reviewer: Author{
+			ID:      review.Reviewer.ID,
+			Name:    review.Reviewer.Name,
+			Picture: review.Reviewer.Picture,
+		},
+		Decision:  review.Decision,
+		Comment:   review.Comment,
+		CreatedAt: review.CreatedAt,
+	}
 }
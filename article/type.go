@@ -2,15 +2,25 @@ package article
 
 import (
 	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/diff"
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/pagination"
+	"github.com/bagus2x/recovy/reaction"
+	"github.com/bagus2x/recovy/taxonomy"
 	"github.com/go-playground/validator/v10"
 )
 
+// CreateArticleReq takes the article body as Markdown in Description.
+// Category is the primary category, Categories any others; each is a slug,
+// name or translated name of a managed category.
 type CreateArticleReq struct {
-	AuthorID    int64  `json:"author_id" validate:"required,gt=0"`
-	Picture     string `json:"picture" validate:"lte=512"`
-	Title       string `json:"title" validate:"required,lte=255"`
-	Description string `json:"description" validate:"required"`
-	Category    string `json:"category" validate:"required,lte=128"`
+	AuthorID    int64    `json:"author_id" validate:"required,gt=0"`
+	Picture     string   `json:"picture" validate:"lte=512"`
+	Title       string   `json:"title" validate:"required,lte=255"`
+	Description string   `json:"description" validate:"required,lte=100000"`
+	Category    string   `json:"category" validate:"required,lte=128"`
+	Categories  []string `json:"categories" validate:"max=10,dive,lte=128"`
+	Tags        []string `json:"tags" validate:"max=10,dive,lte=128"`
 }
 
 func (r *CreateArticleReq) Validate() error {
@@ -20,15 +30,27 @@ func (r *CreateArticleReq) Validate() error {
 	return app.ValidateAndTranslate(validate, err)
 }
 
+// CreateArticleResp has Held set when the content filter held the article for
+// review, for HeldReasons.
 type CreateArticleResp struct {
-	ID          int64  `json:"id"`
-	AuthorID    int64  `json:"author_id"`
-	Picture     string `json:"picture"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Category    string `json:"category"`
-	CreatedAt   int64  `json:"createdAt"`
-	UpdatedAt   int64  `json:"updatedAt"`
+	ID          int64           `json:"id"`
+	AuthorID    int64           `json:"author_id"`
+	Picture     string          `json:"picture"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	HTML        string          `json:"html"`
+	Excerpt     string          `json:"excerpt"`
+	ReadingTime int64           `json:"readingTime"`
+	Category    string          `json:"category"`
+	Categories  []taxonomy.Term `json:"categories"`
+	Tags        []taxonomy.Term `json:"tags"`
+	Status      string          `json:"status"`
+	PublishAt   int64           `json:"publishAt"`
+	PublishedAt int64           `json:"publishedAt"`
+	Held        bool            `json:"held"`
+	HeldReasons []string        `json:"heldReasons,omitempty"`
+	CreatedAt   int64           `json:"createdAt"`
+	UpdatedAt   int64           `json:"updatedAt"`
 }
 
 type Author struct {
@@ -37,13 +59,134 @@ type Author struct {
 	Picture string `json:"picture"`
 }
 
+// GetArticleResp carries the Markdown source of the article in Description
+// and its sanitized rendering in HTML. ReadingTime is in minutes. Held is set
+// when an update held the article for review, for HeldReasons.
 type GetArticleResp struct {
+	ID          int64           `json:"id"`
+	Author      Author          `json:"author"`
+	Picture     string          `json:"picture"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	HTML        string          `json:"html"`
+	Excerpt     string          `json:"excerpt"`
+	ReadingTime int64           `json:"readingTime"`
+	Category    string          `json:"category"`
+	Categories  []taxonomy.Term `json:"categories"`
+	Tags        []taxonomy.Term `json:"tags"`
+	Status      string          `json:"status"`
+	PublishAt   int64           `json:"publishAt"`
+	PublishedAt int64           `json:"publishedAt"`
+	Held        bool            `json:"held,omitempty"`
+	HeldReasons []string        `json:"heldReasons,omitempty"`
+	CreatedAt   int64           `json:"createdAt"`
+	UpdatedAt   int64           `json:"updatedAt"`
+	reaction.Summary
+}
+
+// Params filters the article list. Articles that are not published are only
+// listed to their author and to editors. Category and Tag are slugs.
+type Params struct {
+	pagination.Params
+	ViewerID int64
+	Editor   bool
+	Status   string
+	Category string
+	Tag      string
+}
+
+type GetArticlesResp struct {
+	Cursor   pagination.Cursor `json:"cursor"`
+	Articles []GetArticleResp  `json:"articles"`
+}
+
+// ReviewArticleReq approves or rejects a submitted article. An approved
+// article is published at PublishAt, or right away when it is 0 or has
+// passed.
+type ReviewArticleReq struct {
+	ArticleID  int64  `json:"articleID" validate:"required,gt=0"`
+	ReviewerID int64  `json:"reviewerID" validate:"required,gt=0"`
+	Decision   string `json:"decision" validate:"required,oneof=approved rejected"`
+	Comment    string `json:"comment" validate:"lte=5000"`
+	PublishAt  int64  `json:"publishAt" validate:"gte=0"`
+}
+
+func (r *ReviewArticleReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+	if err != nil {
+		return app.ValidateAndTranslate(validate, err)
+	}
+
+	if r.Decision == models.ReviewRejected && r.Comment == "" {
+		return app.NewError(nil, app.EBadRequest, "Comment is required when rejecting an article")
+	}
+
+	return nil
+}
+
+type Review struct {
+	ID        int64  `json:"id"`
+	Reviewer  Author `json:"reviewer"`
+	Decision  string `json:"decision"`
+	Comment   string `json:"comment"`
+	CreatedAt int64  `json:"createdAt"`
+}
+
+// UpdateArticleReq edits the fields that are set. Description is Markdown.
+// Categories and Tags replace the other categories and the tags of the
+// article.
+type UpdateArticleReq struct {
+	ArticleID   int64    `json:"articleID" validate:"required,gt=0"`
+	UserID      int64    `json:"userID" validate:"required,gt=0"`
+	Picture     *string  `json:"picture" validate:"omitempty,lte=512"`
+	Title       *string  `json:"title" validate:"omitempty,min=1,lte=255"`
+	Description *string  `json:"description" validate:"omitempty,min=1,lte=100000"`
+	Category    *string  `json:"category" validate:"omitempty,min=1,lte=128"`
+	Categories  []string `json:"categories" validate:"omitempty,max=10,dive,lte=128"`
+	Tags        []string `json:"tags" validate:"omitempty,max=10,dive,lte=128"`
+}
+
+func (r *UpdateArticleReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+
+	return app.ValidateAndTranslate(validate, err)
+}
+
+// Revision is a version of an article. Description is only filled in when a
+// single revision is requested.
+type Revision struct {
 	ID          int64  `json:"id"`
 	Author      Author `json:"author"`
-	Picture     string `json:"picture"`
 	Title       string `json:"title"`
-	Description string `json:"description"`
+	Description string `json:"description,omitempty"`
 	Category    string `json:"category"`
 	CreatedAt   int64  `json:"createdAt"`
-	UpdatedAt   int64  `json:"updatedAt"`
+}
+
+// DiffReq compares revision From of an article with revision To. Mode is
+// either "line", the default, or "word" and applies to the body; the title
+// and category are always compared word by word.
+type DiffReq struct {
+	ArticleID int64  `validate:"required,gt=0"`
+	UserID    int64  `validate:"required,gt=0"`
+	From      int64  `validate:"required,gt=0"`
+	To        int64  `validate:"required,gt=0"`
+	Mode      string `validate:"omitempty,oneof=line word"`
+}
+
+func (r *DiffReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+
+	return app.ValidateAndTranslate(validate, err)
+}
+
+type RevisionDiff struct {
+	From        Revision  `json:"from"`
+	To          Revision  `json:"to"`
+	Title       []diff.Op `json:"title"`
+	Description []diff.Op `json:"description"`
+	Category    []diff.Op `json:"category"`
 }
@@ -0,0 +1,138 @@
+package article
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+)
+
+// KindPublish is the job kind of scheduled article publications.
+const KindPublish = "article.publish"
+
+// transitions are the statuses an article can move to from each status. An
+// author submits a draft for review and can withdraw it back to a draft until
+// it is published; an editor approves or rejects it, and the approved ones
+// are published right away or when their time comes.
+var transitions = map[string][]string{
+	models.ArticleDraft:     {models.ArticleSubmitted},
+	models.ArticleSubmitted: {models.ArticleDraft, models.ArticleRejected, models.ArticleScheduled, models.ArticlePublished},
+	models.ArticleRejected:  {models.ArticleSubmitted, models.ArticleDraft},
+	models.ArticleScheduled: {models.ArticleDraft, models.ArticlePublished},
+}
+
+func canTransition(from, to string) bool {
+	for _, status := range transitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+type publishPayload struct {
+	ArticleID int64 `json:"articleID"`
+}
+
+func publishReference(articleID int64) string {
+	return fmt.Sprintf("article:%d", articleID)
+}
+
+// transition moves the article to the given status, recording the review that
+// did so, if any.
+func (s *service) transition(ctx context.Context, article *models.Article, to string, review *models.ArticleReview) error {
+	from := article.Status
+	if !canTransition(from, to) {
+		return app.NewError(nil, app.Econflict, fmt.Sprintf("A %s article cannot be %s", from, to))
+	}
+
+	now := time.Now().Unix()
+	article.Status = to
+	article.UpdatedAt = now
+	if to == models.ArticlePublished {
+		article.PublishedAt = now
+	}
+	if to != models.ArticleScheduled && to != models.ArticlePublished {
+		article.PublishAt = 0
+	}
+
+	var cancel string
+	if from == models.ArticleScheduled {
+		cancel = publishReference(article.ID)
+	}
+	var publish *models.Job
+	if to == models.ArticleScheduled {
+		job, err := publishJob(*article)
+		if err != nil {
+			return err
+		}
+		publish = &job
+	}
+
+	err := s.articleRepo.UpdateStatus(ctx, article, from, review, publish, cancel)
+	if app.ErrorCode(err) == app.Econflict {
+		return app.NewError(err, app.Econflict, "Article has been changed by someone else, try again")
+	}
+
+	return err
+}
+
+// publishJob is the job that publishes the scheduled article at its time.
+func publishJob(article models.Article) (models.Job, error) {
+	payload, err := json.Marshal(publishPayload{
+		ArticleID: article.ID,
+	})
+	if err != nil {
+		return models.Job{}, err
+	}
+
+	job := models.Job{
+		Kind:      KindPublish,
+		Key:       fmt.Sprintf("article-publish:%d", article.ID),
+		Reference: publishReference(article.ID),
+		Payload:   payload,
+		RunAt:     article.PublishAt,
+		CreatedAt: time.Now().Unix(),
+		UpdatedAt: time.Now().Unix(),
+	}
+
+	return job, nil
+}
+
+// HandlePublish publishes a scheduled article. Articles that were since
+// withdrawn or deleted are left alone.
+func (s *service) HandlePublish(ctx context.Context, job models.Job) error {
+	var payload publishPayload
+	err := json.Unmarshal(job.Payload, &payload)
+	if err != nil {
+		return err
+	}
+
+	article, err := s.articleRepo.FindByID(ctx, payload.ArticleID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if article.Status != models.ArticleScheduled {
+		return nil
+	}
+
+	from := article.Status
+	article.Status = models.ArticlePublished
+	article.PublishedAt = time.Now().Unix()
+	article.UpdatedAt = article.PublishedAt
+
+	// The job being run is removed by the queue once it succeeds.
+	err = s.articleRepo.UpdateStatus(ctx, &article, from, nil, nil, "")
+	if app.ErrorCode(err) == app.Econflict {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,30 @@
+package article
+
+import (
+	"testing"
+
+	"github.com/bagus2x/recovy/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransition(t *testing.T) {
+	assert.True(t, canTransition(models.ArticleDraft, models.ArticleSubmitted))
+	assert.True(t, canTransition(models.ArticleSubmitted, models.ArticleRejected))
+	assert.True(t, canTransition(models.ArticleSubmitted, models.ArticleScheduled))
+	assert.True(t, canTransition(models.ArticleRejected, models.ArticleSubmitted))
+	assert.True(t, canTransition(models.ArticleScheduled, models.ArticlePublished))
+
+	assert.False(t, canTransition(models.ArticleDraft, models.ArticlePublished))
+	assert.False(t, canTransition(models.ArticleRejected, models.ArticlePublished))
+	assert.False(t, canTransition(models.ArticlePublished, models.ArticleDraft))
+	assert.False(t, canTransition(models.ArticleSubmitted, models.ArticleSubmitted))
+}
+
+func TestApproved(t *testing.T) {
+	assert.True(t, approved(models.ArticleScheduled))
+	assert.True(t, approved(models.ArticlePublished))
+
+	assert.False(t, approved(models.ArticleDraft))
+	assert.False(t, approved(models.ArticleSubmitted))
+	assert.False(t, approved(models.ArticleRejected))
+}
@@ -51,7 +51,7 @@ func (r *repository) Create(ctx context.Context, user *models.User) error {
 
 var findByID = `
 	SELECT
-		id, name, email, picture, password, created_at, updated_at
+		id, name, email, picture, password, role, created_at, updated_at, suspended_until
 	FROM
 		App_User
 	WHERE
@@ -67,8 +67,10 @@ func (r *repository) FindByID(ctx context.Context, userID int64) (models.User, e
 		&user.Email,
 		&user.Picture,
 		&user.Password,
+		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.SuspendedUntil,
 	)
 	if err == sql.ErrNoRows {
 		return models.User{}, app.NewError(err, app.ENotFound)
@@ -81,7 +83,7 @@ func (r *repository) FindByID(ctx context.Context, userID int64) (models.User, e
 
 var findByEmail = `
 	SELECT
-		id, name, email, picture, password, created_at, updated_at
+		id, name, email, picture, password, role, created_at, updated_at, suspended_until
 	FROM
 		App_User
 	WHERE
@@ -97,8 +99,10 @@ func (r *repository) FindByEmail(ctx context.Context, email string) (models.User
 		&user.Email,
 		&user.Picture,
 		&user.Password,
+		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.SuspendedUntil,
 	)
 	if err == sql.ErrNoRows {
 		return models.User{}, app.NewError(err, app.ENotFound)
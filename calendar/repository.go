@@ -0,0 +1,84 @@
+package calendar
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+)
+
+type Repository interface {
+	SaveToken(ctx context.Context, token *models.CalendarToken) error
+	FindTokenByUserID(ctx context.Context, userID int64) (models.CalendarToken, error)
+	FindTokenByToken(ctx context.Context, token string) (models.CalendarToken, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+var saveToken = `
+	INSERT INTO
+		Calendar_Token
+		(user_id, token, created_at)
+	VALUES
+		($1, $2, $3)
+	ON CONFLICT (user_id) DO UPDATE SET
+		token = EXCLUDED.token, created_at = EXCLUDED.created_at
+`
+
+func (r *repository) SaveToken(ctx context.Context, token *models.CalendarToken) error {
+	_, err := r.db.ExecContext(ctx, saveToken, token.User.ID, token.Token, token.CreatedAt)
+
+	return err
+}
+
+var findTokenByUserID = `
+	SELECT
+		user_id, token, created_at
+	FROM
+		Calendar_Token
+	WHERE
+		user_id = $1
+`
+
+func (r *repository) FindTokenByUserID(ctx context.Context, userID int64) (models.CalendarToken, error) {
+	return r.findToken(ctx, findTokenByUserID, userID)
+}
+
+var findTokenByToken = `
+	SELECT
+		user_id, token, created_at
+	FROM
+		Calendar_Token
+	WHERE
+		token = $1
+`
+
+func (r *repository) FindTokenByToken(ctx context.Context, token string) (models.CalendarToken, error) {
+	return r.findToken(ctx, findTokenByToken, token)
+}
+
+func (r *repository) findToken(ctx context.Context, query string, arg interface{}) (models.CalendarToken, error) {
+	var token models.CalendarToken
+
+	err := r.db.QueryRowContext(ctx, query, arg).Scan(
+		&token.User.ID,
+		&token.Token,
+		&token.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return models.CalendarToken{}, app.NewError(err, app.ENotFound)
+	} else if err != nil {
+		return models.CalendarToken{}, err
+	}
+
+	return token, nil
+}
@@ -0,0 +1,198 @@
+package calendar
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/ical"
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/webinar"
+)
+
+// Timezone is the default zone of feeds. Events are still written in the
+// zone of their webinar.
+const Timezone = "Asia/Jakarta"
+
+type Service interface {
+	GetWebinarCalendar(ctx context.Context, webinarID int64) (string, error)
+	GetFeed(ctx context.Context, token string) (string, error)
+	GetFeedToken(ctx context.Context, userID int64) (FeedTokenResp, error)
+	ResetFeedToken(ctx context.Context, userID int64) (FeedTokenResp, error)
+}
+
+type service struct {
+	calendarRepo Repository
+	webinarRepo  webinar.Repository
+}
+
+func NewService(calendarRepo Repository, webinarRepo webinar.Repository) Service {
+	return &service{
+		calendarRepo: calendarRepo,
+		webinarRepo:  webinarRepo,
+	}
+}
+
+func (s *service) GetWebinarCalendar(ctx context.Context, webinarID int64) (string, error) {
+	w, err := s.webinarRepo.FindByID(ctx, webinarID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return "", app.NewError(err, app.ENotFound, "Webinar not found")
+	} else if err != nil {
+		return "", err
+	}
+
+	return encode(w.Title, []models.Webinar{w})
+}
+
+// GetFeed renders every webinar the token's owner registered for or bookmarked.
+func (s *service) GetFeed(ctx context.Context, token string) (string, error) {
+	calendarToken, err := s.calendarRepo.FindTokenByToken(ctx, token)
+	if app.ErrorCode(err) == app.ENotFound {
+		return "", app.NewError(err, app.ENotFound, "Calendar not found")
+	} else if err != nil {
+		return "", err
+	}
+
+	webinars, err := s.webinarRepo.FindByAttendeeID(ctx, calendarToken.User.ID)
+	if err != nil {
+		return "", err
+	}
+
+	return encode("Recovy Webinars", webinars)
+}
+
+func (s *service) GetFeedToken(ctx context.Context, userID int64) (FeedTokenResp, error) {
+	token, err := s.calendarRepo.FindTokenByUserID(ctx, userID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return s.ResetFeedToken(ctx, userID)
+	} else if err != nil {
+		return FeedTokenResp{}, err
+	}
+
+	return FeedTokenResp{Token: token.Token, CreatedAt: token.CreatedAt}, nil
+}
+
+// ResetFeedToken replaces the user's feed token, revoking the old feed URL.
+func (s *service) ResetFeedToken(ctx context.Context, userID int64) (FeedTokenResp, error) {
+	b := make([]byte, 20)
+	_, err := rand.Read(b)
+	if err != nil {
+		return FeedTokenResp{}, err
+	}
+
+	token := models.CalendarToken{
+		User:      models.User{ID: userID},
+		Token:     hex.EncodeToString(b),
+		CreatedAt: time.Now().Unix(),
+	}
+
+	err = s.calendarRepo.SaveToken(ctx, &token)
+	if err != nil {
+		return FeedTokenResp{}, err
+	}
+
+	return FeedTokenResp{Token: token.Token, CreatedAt: token.CreatedAt}, nil
+}
+
+func encode(name string, webinars []models.Webinar) (string, error) {
+	loc, err := time.LoadLocation(Timezone)
+	if err != nil {
+		return "", err
+	}
+
+	cal := ical.Calendar{
+		Name:     name,
+		Location: loc,
+		Events:   make([]ical.Event, 0, len(webinars)),
+	}
+	for _, w := range webinars {
+		cal.Events = append(cal.Events, webinarEvents(w)...)
+	}
+
+	return cal.Encode(), nil
+}
+
+// webinarEvents returns one event per session. Sessions are identified by
+// their position so that a calendar keeps them apart across edits.
+func webinarEvents(w models.Webinar) []ical.Event {
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	status := "CONFIRMED"
+	if w.CancelledAt > 0 {
+		status = "CANCELLED"
+	}
+
+	if w.Recurrence != "" && len(w.Sessions) > 0 {
+		return recurringEvents(w, loc, status)
+	}
+
+	events := make([]ical.Event, 0, len(w.Sessions))
+	for i, session := range w.Sessions {
+		summary := w.Title
+		if len(w.Sessions) > 1 {
+			summary = fmt.Sprintf("%s (%d/%d)", w.Title, i+1, len(w.Sessions))
+		}
+
+		events = append(events, ical.Event{
+			Location:    loc,
+			UID:         fmt.Sprintf("webinar-%d-%d@recovy", w.ID, i+1),
+			Sequence:    w.Sequence,
+			Stamp:       time.Unix(w.UpdatedAt, 0),
+			Start:       time.Unix(session.StartAt, 0),
+			End:         time.Unix(session.EndAt, 0),
+			Summary:     summary,
+			Description: w.Description,
+			Status:      status,
+		})
+	}
+
+	return events
+}
+
+// recurringEvents returns the series of a recurring webinar with its
+// cancelled occurrences excluded, followed by one event per moved
+// occurrence.
+func recurringEvents(w models.Webinar, loc *time.Location, status string) []ical.Event {
+	uid := fmt.Sprintf("webinar-%d-1@recovy", w.ID)
+	series := ical.Event{
+		Location:    loc,
+		UID:         uid,
+		Sequence:    w.Sequence,
+		Stamp:       time.Unix(w.UpdatedAt, 0),
+		Start:       time.Unix(w.Sessions[0].StartAt, 0),
+		End:         time.Unix(w.Sessions[0].EndAt, 0),
+		Summary:     w.Title,
+		Description: w.Description,
+		Status:      status,
+		RRule:       w.Recurrence,
+	}
+
+	events := []ical.Event{series}
+	for _, exception := range w.Exceptions {
+		if exception.Cancelled {
+			events[0].ExDates = append(events[0].ExDates, time.Unix(exception.RecurrenceID, 0))
+			continue
+		}
+
+		events = append(events, ical.Event{
+			Location:     loc,
+			UID:          uid,
+			Sequence:     w.Sequence,
+			Stamp:        time.Unix(exception.UpdatedAt, 0),
+			Start:        time.Unix(exception.StartAt, 0),
+			End:          time.Unix(exception.EndAt, 0),
+			Summary:      w.Title,
+			Description:  w.Description,
+			Status:       status,
+			RecurrenceID: time.Unix(exception.RecurrenceID, 0),
+		})
+	}
+
+	return events
+}
@@ -0,0 +1,6 @@
+package calendar
+
+type FeedTokenResp struct {
+	Token     string `json:"token"`
+	CreatedAt int64  `json:"createdAt"`
+}
@@ -19,6 +19,15 @@ type Config struct {
 	dbUser               string
 	dbPassword           string
 	sslMode              string
+	pubSub               string
+	smtpHost             string
+	smtpPort             string
+	smtpUsername         string
+	smtpPassword         string
+	mailFrom             string
+	commentMaxDepth      string
+	crisisTermsFile      string
+	filterConfigFile     string
 }
 
 func New() *Config {
@@ -35,6 +44,15 @@ func New() *Config {
 		dbUser:               mustGetEnv("DB_USERNAME"),
 		dbPassword:           mustGetEnv("DB_PASSWORD"),
 		sslMode:              mustGetEnv("SSL_MODE"),
+		pubSub:               getEnv("PUBSUB", "memory"),
+		smtpHost:             getEnv("SMTP_HOST", ""),
+		smtpPort:             getEnv("SMTP_PORT", "587"),
+		smtpUsername:         getEnv("SMTP_USERNAME", ""),
+		smtpPassword:         getEnv("SMTP_PASSWORD", ""),
+		mailFrom:             getEnv("MAIL_FROM", ""),
+		commentMaxDepth:      getEnv("COMMENT_MAX_DEPTH", "5"),
+		crisisTermsFile:      getEnv("CRISIS_TERMS_FILE", ""),
+		filterConfigFile:     getEnv("FILTER_CONFIG_FILE", ""),
 	}
 }
 
@@ -101,6 +119,64 @@ func (c *Config) DatabaseConnection() string {
 	)
 }
 
+// PubSub selects how live events reach other instances: "memory" for a
+// single instance, "postgres" for LISTEN/NOTIFY.
+func (c *Config) PubSub() string {
+	return c.pubSub
+}
+
+// SMTPHost is empty when emails are only logged.
+func (c *Config) SMTPHost() string {
+	return c.smtpHost
+}
+
+func (c *Config) SMTPPort() string {
+	return c.smtpPort
+}
+
+func (c *Config) SMTPUsername() string {
+	return c.smtpUsername
+}
+
+func (c *Config) SMTPPassword() string {
+	return c.smtpPassword
+}
+
+func (c *Config) MailFrom() string {
+	return c.mailFrom
+}
+
+// CommentMaxDepth is how deep replies to discussion comments can be nested.
+func (c *Config) CommentMaxDepth() int {
+	res, err := strconv.Atoi(c.commentMaxDepth)
+	if err != nil || res < 0 {
+		panic("Comment max depth must be filled with a number of at least 0")
+	}
+
+	return res
+}
+
+// CrisisTermsFile is a JSON file of the crisis terms to detect, in place of
+// the built-in ones when set.
+func (c *Config) CrisisTermsFile() string {
+	return c.crisisTermsFile
+}
+
+// FilterConfigFile is a JSON file of the content filter config, in place of
+// the built-in one when set.
+func (c *Config) FilterConfigFile() string {
+	return c.filterConfigFile
+}
+
+func getEnv(key, fallback string) string {
+	res := os.Getenv(key)
+	if res == "" {
+		return fallback
+	}
+
+	return res
+}
+
 func mustGetEnv(key string) string {
 	res := os.Getenv(key)
 	if res == "" {
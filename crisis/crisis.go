@@ -0,0 +1,275 @@
+// Package crisis detects posts that mention suicide or self-harm, in any of
+// the configured languages, so help can be offered right away.
+package crisis
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Hotline is a service to reach for help, by Phone or at URL.
+type Hotline struct {
+	Name  string `json:"name"`
+	Phone string `json:"phone,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+// Resources are shown with a post that mentions a crisis.
+type Resources struct {
+	Message  string    `json:"message"`
+	Hotlines []Hotline `json:"hotlines"`
+}
+
+// Language lists what signals a crisis in one language. Phrases match whole
+// words, Patterns are regular expressions over the normalized text: lower
+// case words separated by single spaces, apostrophes dropped. A match is
+// ignored when one of the Negations comes shortly before it in the same
+// clause, as in "I would never hurt myself".
+type Language struct {
+	Phrases   []string  `json:"phrases"`
+	Patterns  []string  `json:"patterns"`
+	Negations []string  `json:"negations"`
+	Resources Resources `json:"resources"`
+}
+
+// Config holds the languages by code. Window is how many words before a
+// match are searched for a negation. Fallback is the language whose
+// resources are shown for an unknown one.
+type Config struct {
+	Window    int                 `json:"window"`
+	Fallback  string              `json:"fallback"`
+	Languages map[string]Language `json:"languages"`
+}
+
+// Match is what the detector found in a text. Language is empty when
+// nothing was found.
+type Match struct {
+	Language string
+	Terms    []string
+}
+
+func (m Match) Flagged() bool {
+	return m.Language != ""
+}
+
+type language struct {
+	code      string
+	phrases   [][]string
+	patterns  []*regexp.Regexp
+	negations [][]string
+	resources Resources
+}
+
+type Detector struct {
+	window    int
+	fallback  string
+	languages []language
+}
+
+// New compiles the config into a Detector.
+func New(cfg Config) (*Detector, error) {
+	d := &Detector{
+		window:   cfg.Window,
+		fallback: cfg.Fallback,
+	}
+	if d.window <= 0 {
+		d.window = 3
+	}
+
+	codes := make([]string, 0, len(cfg.Languages))
+	for code := range cfg.Languages {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		lang := cfg.Languages[code]
+		compiled := language{
+			code:      code,
+			resources: lang.Resources,
+		}
+
+		for _, phrase := range lang.Phrases {
+			if words := strings.Fields(normalize(phrase)); len(words) > 0 {
+				compiled.phrases = append(compiled.phrases, words)
+			}
+		}
+		for _, negation := range lang.Negations {
+			if words := strings.Fields(normalize(negation)); len(words) > 0 {
+				compiled.negations = append(compiled.negations, words)
+			}
+		}
+		for _, pattern := range lang.Patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("crisis: %s pattern %q: %w", code, pattern, err)
+			}
+			compiled.patterns = append(compiled.patterns, re)
+		}
+
+		d.languages = append(d.languages, compiled)
+	}
+
+	return d, nil
+}
+
+// Default returns a Detector of the built-in English and Indonesian lists.
+func Default() *Detector {
+	d, err := New(DefaultConfig)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+// Load returns a Detector of the config in the JSON file at path.
+func Load(path string) (*Detector, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	err = json.Unmarshal(b, &cfg)
+	if err != nil {
+		return nil, fmt.Errorf("crisis: %s: %w", path, err)
+	}
+
+	return New(cfg)
+}
+
+// Detect searches each clause of the text for the phrases and patterns of
+// every language. The language of the match is the first, by code, with
+// terms found.
+func (d *Detector) Detect(text string) Match {
+	clauses := splitClauses(text)
+
+	for _, lang := range d.languages {
+		terms := make([]string, 0)
+		seen := make(map[string]bool)
+		found := func(term string) {
+			if !seen[term] {
+				seen[term] = true
+				terms = append(terms, term)
+			}
+		}
+
+		for _, words := range clauses {
+			for _, phrase := range lang.phrases {
+				for i := 0; i+len(phrase) <= len(words); i++ {
+					if equal(words[i:i+len(phrase)], phrase) && !d.negated(lang, words, i) {
+						found(strings.Join(phrase, " "))
+					}
+				}
+			}
+
+			clause := strings.Join(words, " ")
+			for _, re := range lang.patterns {
+				for _, loc := range re.FindAllStringIndex(clause, -1) {
+					i := strings.Count(clause[:loc[0]], " ")
+					if !d.negated(lang, words, i) {
+						found(clause[loc[0]:loc[1]])
+					}
+				}
+			}
+		}
+
+		if len(terms) > 0 {
+			return Match{Language: lang.code, Terms: terms}
+		}
+	}
+
+	return Match{}
+}
+
+// Resources returns the resources of the language, or of the fallback
+// language when it has none. It returns nil when code is empty, as it is for
+// a text where nothing was found.
+func (d *Detector) Resources(code string) *Resources {
+	if code == "" {
+		return nil
+	}
+
+	var fallback *Resources
+	for i, lang := range d.languages {
+		if lang.code == code {
+			return &d.languages[i].resources
+		}
+		if lang.code == d.fallback {
+			fallback = &d.languages[i].resources
+		}
+	}
+
+	return fallback
+}
+
+// negated reports whether a negation ends within the window of words before
+// words[i].
+func (d *Detector) negated(lang language, words []string, i int) bool {
+	from := i - d.window
+	if from < 0 {
+		from = 0
+	}
+
+	for _, negation := range lang.negations {
+		for j := from; j+len(negation) <= i; j++ {
+			if equal(words[j:j+len(negation)], negation) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// splitClauses cuts the text at punctuation and line breaks into clauses of
+// normalized words, so that "I'm not okay, I want to die" is not read as a
+// negation.
+func splitClauses(text string) [][]string {
+	clauses := make([][]string, 0)
+	for _, clause := range strings.FieldsFunc(text, func(r rune) bool {
+		return strings.ContainsRune(".,;:!?()\n", r)
+	}) {
+		if words := strings.Fields(normalize(clause)); len(words) > 0 {
+			clauses = append(clauses, words)
+		}
+	}
+
+	return clauses
+}
+
+// normalize lowercases the text, drops apostrophes and turns anything but
+// letters and digits into spaces.
+func normalize(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r == '\'' || r == '’':
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,54 @@
+package crisis
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectCorpus(t *testing.T) {
+	f, err := os.Open("testdata/corpus.txt")
+	require.NoError(t, err)
+	defer f.Close()
+
+	detector := Default()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(line, "\t", 2)
+		require.Len(t, fields, 2, line)
+
+		want := fields[0]
+		if want == "-" {
+			want = ""
+		}
+		assert.Equal(t, want, detector.Detect(fields[1]).Language, fields[1])
+	}
+	require.NoError(t, scanner.Err())
+}
+
+func TestDetectTerms(t *testing.T) {
+	match := Default().Detect("I want to die. I want to die, I keep thinking about suicide")
+	assert.True(t, match.Flagged())
+	assert.Equal(t, []string{"want to die", "suicide"}, match.Terms)
+}
+
+func TestNewInvalidPattern(t *testing.T) {
+	_, err := New(Config{Languages: map[string]Language{"en": {Patterns: []string{"("}}}})
+	assert.Error(t, err)
+}
+
+func TestResources(t *testing.T) {
+	detector := Default()
+	assert.Equal(t, DefaultConfig.Languages["en"].Resources, *detector.Resources("en"))
+	assert.Equal(t, DefaultConfig.Languages["id"].Resources, *detector.Resources("fr"))
+	assert.Nil(t, detector.Resources(""))
+}
@@ -0,0 +1,113 @@
+package crisis
+
+// DefaultConfig covers English and Indonesian. Deployments can replace it
+// with their own lists through Load.
+var DefaultConfig = Config{
+	Window:   3,
+	Fallback: "id",
+	Languages: map[string]Language{
+		"en": {
+			Phrases: []string{
+				"suicide",
+				"suicidal",
+				"kill myself",
+				"killing myself",
+				"end my life",
+				"ending my life",
+				"take my own life",
+				"want to die",
+				"wanna die",
+				"wish i was dead",
+				"wish i were dead",
+				"better off dead",
+				"dont want to live",
+				"dont want to be alive",
+				"no reason to live",
+				"self harm",
+				"selfharm",
+				"hurt myself",
+				"hurting myself",
+				"cut myself",
+				"cutting myself",
+				"overdose",
+			},
+			Patterns: []string{
+				`\bkms\b`,
+				`\b(?:end|ending) it all\b`,
+				`\bnot (?:be|being) (?:here|around) anymore\b`,
+			},
+			Negations: []string{
+				"not",
+				"never",
+				"no",
+				"dont",
+				"didnt",
+				"wont",
+				"wouldnt",
+				"cant",
+				"stopped",
+				"no longer",
+			},
+			Resources: Resources{
+				Message: "It sounds like you are going through a lot right now. You are not alone, and talking to someone can help. If you are in danger, please call your local emergency number.",
+				Hotlines: []Hotline{
+					{Name: "Emergency services", Phone: "112"},
+					{Name: "Find a Helpline", URL: "https://findahelpline.com"},
+				},
+			},
+		},
+		"id": {
+			Phrases: []string{
+				"bunuh diri",
+				"membunuh diri",
+				"membunuh diriku",
+				"ingin mati",
+				"pengen mati",
+				"pingin mati",
+				"mau mati",
+				"lebih baik mati",
+				"mengakhiri hidup",
+				"mengakhiri hidupku",
+				"akhiri hidup",
+				"ga mau hidup lagi",
+				"gak mau hidup lagi",
+				"tidak mau hidup lagi",
+				"tidak ingin hidup lagi",
+				"capek hidup",
+				"lelah hidup",
+				"menyakiti diri",
+				"menyakiti diri sendiri",
+				"melukai diri",
+				"melukai diri sendiri",
+				"sayat tangan",
+				"menyayat tangan",
+				"overdosis",
+			},
+			Patterns: []string{
+				`\b(?:gantung|minum racun) diri\b`,
+				`\bsudah tidak (?:kuat|sanggup) hidup\b`,
+			},
+			Negations: []string{
+				"tidak",
+				"tak",
+				"ga",
+				"gak",
+				"nggak",
+				"enggak",
+				"bukan",
+				"jangan",
+				"belum",
+				"tidak pernah",
+				"tidak akan",
+			},
+			Resources: Resources{
+				Message: "Sepertinya kamu sedang melalui masa yang sangat berat. Kamu tidak sendirian, dan bercerita kepada seseorang bisa membantu. Jika kamu dalam bahaya, segera hubungi layanan darurat.",
+				Hotlines: []Hotline{
+					{Name: "Layanan SEJIWA", Phone: "119 ext 8"},
+					{Name: "Layanan Darurat", Phone: "112"},
+					{Name: "Find a Helpline", URL: "https://findahelpline.com/countries/id"},
+				},
+			},
+		},
+	},
+}
@@ -0,0 +1,2 @@
+DROP TABLE Playlist_Item;
+DROP TABLE Playlist;
\ No newline at end of file
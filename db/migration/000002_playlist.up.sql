@@ -0,0 +1,21 @@
+CREATE TABLE Playlist (
+    id SERIAL PRIMARY KEY,
+    owner_id INT NOT NULL REFERENCES App_User(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description VARCHAR(512) NOT NULL DEFAULT '',
+    public BOOLEAN NOT NULL DEFAULT FALSE,
+    share_token VARCHAR(64) NOT NULL UNIQUE,
+    created_at INT NOT NULL,
+    updated_at INT NOT NULL
+);
+
+CREATE TABLE Playlist_Item (
+    id SERIAL PRIMARY KEY,
+    playlist_id INT NOT NULL REFERENCES Playlist(id) ON DELETE CASCADE,
+    podcast_id INT NOT NULL REFERENCES Podcast(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    created_at INT NOT NULL,
+    UNIQUE(playlist_id, podcast_id)
+);
+
+CREATE INDEX playlist_item_position_idx ON Playlist_Item(playlist_id, position);
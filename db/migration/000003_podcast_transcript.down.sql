@@ -0,0 +1,2 @@
+DROP TABLE Podcast_Chapter;
+DROP TABLE Podcast_Transcript;
\ No newline at end of file
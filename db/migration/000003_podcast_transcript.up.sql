@@ -0,0 +1,17 @@
+CREATE TABLE Podcast_Transcript (
+    podcast_id INT PRIMARY KEY REFERENCES Podcast(id) ON DELETE CASCADE,
+    vtt TEXT NOT NULL,
+    plain_text TEXT NOT NULL,
+    search TSVECTOR GENERATED ALWAYS AS (to_tsvector('simple', plain_text)) STORED,
+    created_at INT NOT NULL,
+    updated_at INT NOT NULL
+);
+
+CREATE INDEX podcast_transcript_search_idx ON Podcast_Transcript USING GIN(search);
+
+CREATE TABLE Podcast_Chapter (
+    podcast_id INT PRIMARY KEY REFERENCES Podcast(id) ON DELETE CASCADE,
+    chapters JSONB NOT NULL,
+    created_at INT NOT NULL,
+    updated_at INT NOT NULL
+);
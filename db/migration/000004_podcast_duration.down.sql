@@ -0,0 +1,3 @@
+DROP INDEX starred_podcast_user_idx;
+DROP INDEX podcast_created_at_idx;
+ALTER TABLE Podcast DROP COLUMN duration;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE Podcast ADD COLUMN duration INT NOT NULL DEFAULT 0;
+
+CREATE INDEX podcast_created_at_idx ON Podcast(created_at, id);
+CREATE INDEX starred_podcast_user_idx ON Starred_Podcast(user_id);
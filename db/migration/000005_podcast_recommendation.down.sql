@@ -0,0 +1,2 @@
+DROP TABLE Podcast_Similarity;
+DROP TABLE Podcast_Listen;
\ No newline at end of file
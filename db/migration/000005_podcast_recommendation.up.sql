@@ -0,0 +1,17 @@
+CREATE TABLE Podcast_Listen (
+    podcast_id INT NOT NULL REFERENCES Podcast(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES App_User(id) ON DELETE CASCADE,
+    plays INT NOT NULL DEFAULT 1,
+    last_listened_at INT NOT NULL,
+    PRIMARY KEY(podcast_id, user_id)
+);
+
+CREATE INDEX podcast_listen_user_idx ON Podcast_Listen(user_id);
+
+CREATE TABLE Podcast_Similarity (
+    podcast_id INT NOT NULL REFERENCES Podcast(id) ON DELETE CASCADE,
+    similar_podcast_id INT NOT NULL REFERENCES Podcast(id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    computed_at INT NOT NULL,
+    PRIMARY KEY(podcast_id, similar_podcast_id)
+);
@@ -0,0 +1,2 @@
+DROP TABLE Webinar_Registration;
+ALTER TABLE Webinar DROP COLUMN capacity;
\ No newline at end of file
@@ -0,0 +1,13 @@
+ALTER TABLE Webinar ADD COLUMN capacity INT NOT NULL DEFAULT 0;
+
+CREATE TABLE Webinar_Registration (
+    id SERIAL PRIMARY KEY,
+    webinar_id INT NOT NULL REFERENCES Webinar(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES App_User(id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL,
+    created_at INT NOT NULL,
+    updated_at INT NOT NULL,
+    UNIQUE(webinar_id, user_id)
+);
+
+CREATE INDEX webinar_registration_status_idx ON Webinar_Registration(webinar_id, status, created_at);
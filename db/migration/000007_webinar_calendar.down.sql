@@ -0,0 +1,3 @@
+DROP TABLE Calendar_Token;
+DROP TABLE Starred_Webinar;
+ALTER TABLE Webinar DROP COLUMN sequence;
\ No newline at end of file
@@ -0,0 +1,15 @@
+ALTER TABLE Webinar ADD COLUMN sequence INT NOT NULL DEFAULT 0;
+
+CREATE TABLE Starred_Webinar (
+    id SERIAL PRIMARY KEY,
+    webinar_id INT NOT NULL REFERENCES Webinar(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES App_User(id) ON DELETE CASCADE,
+    created_at INT NOT NULL,
+    UNIQUE(webinar_id, user_id)
+);
+
+CREATE TABLE Calendar_Token (
+    user_id INT PRIMARY KEY REFERENCES App_User(id) ON DELETE CASCADE,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    created_at INT NOT NULL
+);
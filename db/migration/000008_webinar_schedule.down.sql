@@ -0,0 +1,22 @@
+DROP INDEX webinar_start_at_idx;
+
+ALTER TABLE Webinar ADD COLUMN start_date INT NOT NULL DEFAULT 0;
+ALTER TABLE Webinar ADD COLUMN last_date INT NOT NULL DEFAULT 0;
+ALTER TABLE Webinar ADD COLUMN time VARCHAR(32) NOT NULL DEFAULT '';
+
+UPDATE
+    Webinar
+SET
+    start_date = start_at,
+    last_date = end_at,
+    time = to_char(to_timestamp(start_at) AT TIME ZONE timezone, 'HH24.MI') || ' ' || CASE timezone
+        WHEN 'Asia/Makassar' THEN 'WITA'
+        WHEN 'Asia/Jayapura' THEN 'WIT'
+        ELSE 'WIB'
+    END;
+
+DROP TABLE Webinar_Session;
+
+ALTER TABLE Webinar DROP COLUMN timezone;
+ALTER TABLE Webinar DROP COLUMN end_at;
+ALTER TABLE Webinar DROP COLUMN start_at;
@@ -0,0 +1,95 @@
+ALTER TABLE Webinar ADD COLUMN start_at INT NOT NULL DEFAULT 0;
+ALTER TABLE Webinar ADD COLUMN end_at INT NOT NULL DEFAULT 0;
+ALTER TABLE Webinar ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Jakarta';
+
+CREATE TABLE Webinar_Session (
+    id SERIAL PRIMARY KEY,
+    webinar_id INT NOT NULL REFERENCES Webinar(id) ON DELETE CASCADE,
+    start_at INT NOT NULL,
+    end_at INT NOT NULL,
+    CHECK (end_at > start_at)
+);
+
+CREATE INDEX webinar_session_webinar_idx ON Webinar_Session(webinar_id, start_at);
+
+-- Parses the free-text time of existing webinars, e.g. "19.00 WIB",
+-- "7pm", "19:00 - 21:00 WITA" or "10.00 s/d 12.00 WIT". Unknown zones fall
+-- back to WIB, a missing end time to a two hour session and unparseable text
+-- to 09:00.
+CREATE FUNCTION pg_temp.parse_webinar_time(
+    t TEXT, OUT tz TEXT, OUT start_time TIME, OUT end_time TIME
+) AS $$
+DECLARE
+    clock CONSTANT TEXT := '(\d{1,2})(?:[.:](\d{2}))?\s*(am|pm)?';
+    m TEXT[];
+    h INT;
+BEGIN
+    tz := CASE
+        WHEN t ~* '\mWITA\M' THEN 'Asia/Makassar'
+        WHEN t ~* '\mWIT\M' THEN 'Asia/Jayapura'
+        ELSE 'Asia/Jakarta'
+    END;
+
+    m := regexp_match(t, clock, 'i');
+    IF m IS NULL THEN
+        start_time := '09:00';
+    ELSE
+        h := m[1]::INT % 24;
+        IF lower(m[3]) = 'pm' AND h < 12 THEN h := h + 12; END IF;
+        IF lower(m[3]) = 'am' AND h = 12 THEN h := 0; END IF;
+        start_time := make_time(h, COALESCE(m[2], '0')::INT, 0);
+    END IF;
+
+    m := regexp_match(t, '(?:-|–|s/d|sampai|until|to)\s*' || clock, 'i');
+    IF m IS NULL THEN
+        end_time := start_time + INTERVAL '2 hours';
+    ELSE
+        h := m[1]::INT % 24;
+        IF lower(m[3]) = 'pm' AND h < 12 THEN h := h + 12; END IF;
+        IF lower(m[3]) = 'am' AND h = 12 THEN h := 0; END IF;
+        end_time := make_time(h, COALESCE(m[2], '0')::INT, 0);
+    END IF;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- One session per day between start_date and last_date, at the parsed time
+-- in the parsed zone.
+WITH parsed AS (
+    SELECT
+        w.id, p.tz, p.start_time, p.end_time,
+        (to_timestamp(w.start_date) AT TIME ZONE p.tz)::DATE AS first_day,
+        (to_timestamp(GREATEST(w.last_date, w.start_date)) AT TIME ZONE p.tz)::DATE AS last_day
+    FROM
+        Webinar w, pg_temp.parse_webinar_time(w.time) p
+), days AS (
+    SELECT
+        id, tz, start_time, end_time, generate_series(first_day, last_day, INTERVAL '1 day')::DATE AS day
+    FROM
+        parsed
+)
+INSERT INTO
+    Webinar_Session
+    (webinar_id, start_at, end_at)
+SELECT
+    id,
+    EXTRACT(EPOCH FROM ((day + start_time) AT TIME ZONE tz))::INT,
+    EXTRACT(EPOCH FROM ((day + end_time + CASE WHEN end_time <= start_time THEN INTERVAL '1 day' ELSE INTERVAL '0' END) AT TIME ZONE tz))::INT
+FROM
+    days;
+
+UPDATE
+    Webinar w
+SET
+    timezone = (SELECT tz FROM pg_temp.parse_webinar_time(w.time)),
+    start_at = s.start_at,
+    end_at = s.end_at
+FROM
+    (SELECT webinar_id, MIN(start_at) AS start_at, MAX(end_at) AS end_at FROM Webinar_Session GROUP BY webinar_id) s
+WHERE
+    s.webinar_id = w.id;
+
+ALTER TABLE Webinar DROP COLUMN start_date;
+ALTER TABLE Webinar DROP COLUMN last_date;
+ALTER TABLE Webinar DROP COLUMN time;
+
+CREATE INDEX webinar_start_at_idx ON Webinar(start_at, id);
@@ -0,0 +1,18 @@
+DROP INDEX webinar_end_at_idx;
+DROP INDEX webinar_registration_status_idx;
+
+DELETE FROM
+    Webinar_Registration a
+USING
+    Webinar_Registration b
+WHERE
+    a.webinar_id = b.webinar_id AND a.user_id = b.user_id AND a.id > b.id;
+ALTER TABLE Webinar_Registration DROP CONSTRAINT webinar_registration_webinar_id_user_id_occurrence_key;
+ALTER TABLE Webinar_Registration ADD CONSTRAINT webinar_registration_webinar_id_user_id_key UNIQUE (webinar_id, user_id);
+ALTER TABLE Webinar_Registration DROP COLUMN occurrence;
+
+CREATE INDEX webinar_registration_status_idx ON Webinar_Registration(webinar_id, status, created_at);
+
+DROP TABLE Webinar_Occurrence;
+
+ALTER TABLE Webinar DROP COLUMN recurrence;
@@ -0,0 +1,24 @@
+ALTER TABLE Webinar ADD COLUMN recurrence VARCHAR(255) NOT NULL DEFAULT '';
+
+-- An occurrence of a recurring webinar that was moved or cancelled. The
+-- occurrence is identified by its original start, as RECURRENCE-ID does.
+CREATE TABLE Webinar_Occurrence (
+    webinar_id INT NOT NULL REFERENCES Webinar(id) ON DELETE CASCADE,
+    recurrence_id INT NOT NULL,
+    start_at INT NOT NULL,
+    end_at INT NOT NULL,
+    cancelled BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at INT NOT NULL,
+    PRIMARY KEY (webinar_id, recurrence_id),
+    CHECK (end_at > start_at)
+);
+
+-- Registrations of a recurring webinar are per occurrence; a webinar that
+-- does not recur only has occurrence 0.
+ALTER TABLE Webinar_Registration ADD COLUMN occurrence INT NOT NULL DEFAULT 0;
+ALTER TABLE Webinar_Registration DROP CONSTRAINT webinar_registration_webinar_id_user_id_key;
+ALTER TABLE Webinar_Registration ADD CONSTRAINT webinar_registration_webinar_id_user_id_occurrence_key UNIQUE (webinar_id, user_id, occurrence);
+
+DROP INDEX webinar_registration_status_idx;
+CREATE INDEX webinar_registration_status_idx ON Webinar_Registration(webinar_id, occurrence, status, created_at);
+CREATE INDEX webinar_end_at_idx ON Webinar(end_at);
@@ -0,0 +1,4 @@
+DROP INDEX webinar_category_start_at_idx;
+DROP INDEX webinar_author_start_at_idx;
+
+ALTER TABLE Webinar DROP COLUMN cancelled_at;
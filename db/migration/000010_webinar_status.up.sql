@@ -0,0 +1,4 @@
+ALTER TABLE Webinar ADD COLUMN cancelled_at INT NOT NULL DEFAULT 0;
+
+CREATE INDEX webinar_author_start_at_idx ON Webinar(author_id, start_at, id);
+CREATE INDEX webinar_category_start_at_idx ON Webinar(category, start_at, id);
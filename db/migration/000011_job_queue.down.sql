@@ -0,0 +1,3 @@
+DROP TABLE Webinar_Reminder;
+DROP TABLE Dead_Job;
+DROP TABLE Job;
@@ -0,0 +1,36 @@
+CREATE TABLE Job (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(64) NOT NULL,
+    key VARCHAR(255) UNIQUE,
+    reference VARCHAR(255) NOT NULL DEFAULT '',
+    payload JSONB NOT NULL DEFAULT '{}',
+    run_at INT NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 5,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at INT NOT NULL,
+    updated_at INT NOT NULL
+);
+
+CREATE INDEX job_run_at_idx ON Job(run_at, id);
+CREATE INDEX job_reference_idx ON Job(reference);
+
+-- Jobs that failed max_attempts times, kept for inspection.
+CREATE TABLE Dead_Job (
+    id BIGINT PRIMARY KEY,
+    kind VARCHAR(64) NOT NULL,
+    key VARCHAR(255),
+    reference VARCHAR(255) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INT NOT NULL,
+    last_error TEXT NOT NULL,
+    created_at INT NOT NULL,
+    failed_at INT NOT NULL
+);
+
+CREATE TABLE Webinar_Reminder (
+    webinar_id INT NOT NULL REFERENCES Webinar(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES App_User(id) ON DELETE CASCADE,
+    created_at INT NOT NULL,
+    PRIMARY KEY (webinar_id, user_id)
+);
@@ -0,0 +1,7 @@
+DROP TABLE Webinar_Attendance;
+
+ALTER TABLE Webinar DROP COLUMN check_in_token;
+ALTER TABLE Webinar DROP COLUMN check_in_code;
+
+DROP TABLE Webinar_Cohost;
+DROP TABLE Webinar_Speaker;
@@ -0,0 +1,33 @@
+-- Speakers are either linked to a user, whose name and picture are shown
+-- unless overridden, or external guests.
+CREATE TABLE Webinar_Speaker (
+    id SERIAL PRIMARY KEY,
+    webinar_id INT NOT NULL REFERENCES Webinar(id) ON DELETE CASCADE,
+    user_id INT REFERENCES App_User(id) ON DELETE SET NULL,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    bio TEXT NOT NULL DEFAULT '',
+    photo VARCHAR(512) NOT NULL DEFAULT '',
+    position INT NOT NULL
+);
+
+CREATE INDEX webinar_speaker_webinar_id_idx ON Webinar_Speaker(webinar_id, position);
+
+CREATE TABLE Webinar_Cohost (
+    webinar_id INT NOT NULL REFERENCES Webinar(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES App_User(id) ON DELETE CASCADE,
+    created_at INT NOT NULL,
+    PRIMARY KEY (webinar_id, user_id)
+);
+
+CREATE INDEX webinar_cohost_user_id_idx ON Webinar_Cohost(user_id);
+
+ALTER TABLE Webinar ADD COLUMN check_in_code VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE Webinar ADD COLUMN check_in_token VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE TABLE Webinar_Attendance (
+    webinar_id INT NOT NULL REFERENCES Webinar(id) ON DELETE CASCADE,
+    occurrence INT NOT NULL DEFAULT 0,
+    user_id INT NOT NULL REFERENCES App_User(id) ON DELETE CASCADE,
+    checked_in_at INT NOT NULL,
+    PRIMARY KEY (webinar_id, occurrence, user_id)
+);
@@ -0,0 +1,5 @@
+DROP TABLE Webinar_Poll_Vote;
+DROP TABLE Webinar_Poll_Option;
+DROP TABLE Webinar_Poll;
+DROP TABLE Webinar_Question_Upvote;
+DROP TABLE Webinar_Question;
@@ -0,0 +1,53 @@
+-- Questions and polls belong to one occurrence of a webinar, 0 for webinars
+-- that do not recur. Upvote and vote counts are kept on the rows so they can
+-- be broadcast without counting.
+CREATE TABLE Webinar_Question (
+    id SERIAL PRIMARY KEY,
+    webinar_id INT NOT NULL REFERENCES Webinar(id) ON DELETE CASCADE,
+    occurrence INT NOT NULL DEFAULT 0,
+    user_id INT NOT NULL REFERENCES App_User(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    upvotes INT NOT NULL DEFAULT 0,
+    answered_at INT NOT NULL DEFAULT 0,
+    created_at INT NOT NULL
+);
+
+CREATE INDEX webinar_question_webinar_id_idx ON Webinar_Question(webinar_id, occurrence);
+
+CREATE TABLE Webinar_Question_Upvote (
+    question_id INT NOT NULL REFERENCES Webinar_Question(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES App_User(id) ON DELETE CASCADE,
+    created_at INT NOT NULL,
+    PRIMARY KEY (question_id, user_id)
+);
+
+CREATE TABLE Webinar_Poll (
+    id SERIAL PRIMARY KEY,
+    webinar_id INT NOT NULL REFERENCES Webinar(id) ON DELETE CASCADE,
+    occurrence INT NOT NULL DEFAULT 0,
+    author_id INT NOT NULL REFERENCES App_User(id) ON DELETE CASCADE,
+    question VARCHAR(255) NOT NULL,
+    closed_at INT NOT NULL DEFAULT 0,
+    created_at INT NOT NULL
+);
+
+CREATE INDEX webinar_poll_webinar_id_idx ON Webinar_Poll(webinar_id, occurrence);
+
+CREATE TABLE Webinar_Poll_Option (
+    id SERIAL PRIMARY KEY,
+    poll_id INT NOT NULL REFERENCES Webinar_Poll(id) ON DELETE CASCADE,
+    body VARCHAR(255) NOT NULL,
+    position INT NOT NULL,
+    votes INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX webinar_poll_option_poll_id_idx ON Webinar_Poll_Option(poll_id, position);
+
+-- One vote per user and poll.
+CREATE TABLE Webinar_Poll_Vote (
+    poll_id INT NOT NULL REFERENCES Webinar_Poll(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES App_User(id) ON DELETE CASCADE,
+    option_id INT NOT NULL REFERENCES Webinar_Poll_Option(id) ON DELETE CASCADE,
+    created_at INT NOT NULL,
+    PRIMARY KEY (poll_id, user_id)
+);
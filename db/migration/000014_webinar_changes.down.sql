@@ -0,0 +1,4 @@
+DROP TABLE Notification;
+DROP TABLE Webinar_Change;
+
+ALTER TABLE Webinar DROP COLUMN link;
@@ -0,0 +1,27 @@
+ALTER TABLE Webinar ADD COLUMN link VARCHAR(512) NOT NULL DEFAULT '';
+
+-- Each edit of a webinar, with the old and new value of every field it
+-- changed, e.g. [{"field": "title", "from": "...", "to": "..."}].
+CREATE TABLE Webinar_Change (
+    id SERIAL PRIMARY KEY,
+    webinar_id INT NOT NULL REFERENCES Webinar(id) ON DELETE CASCADE,
+    user_id INT REFERENCES App_User(id) ON DELETE SET NULL,
+    changes JSONB NOT NULL,
+    created_at INT NOT NULL
+);
+
+CREATE INDEX webinar_change_webinar_id_idx ON Webinar_Change(webinar_id, created_at);
+
+-- In-app notifications. Reference names what the notification is about,
+-- e.g. webinar:12.
+CREATE TABLE Notification (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES App_User(id) ON DELETE CASCADE,
+    reference VARCHAR(255) NOT NULL DEFAULT '',
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    read_at INT NOT NULL DEFAULT 0,
+    created_at INT NOT NULL
+);
+
+CREATE INDEX notification_user_id_idx ON Notification(user_id, created_at);
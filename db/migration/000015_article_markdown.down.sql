@@ -0,0 +1,3 @@
+ALTER TABLE Article DROP COLUMN reading_time;
+ALTER TABLE Article DROP COLUMN excerpt;
+ALTER TABLE Article DROP COLUMN html;
@@ -0,0 +1,4 @@
+-- description holds the Markdown source, html its sanitized rendering.
+ALTER TABLE Article ADD COLUMN html TEXT NOT NULL DEFAULT '';
+ALTER TABLE Article ADD COLUMN excerpt TEXT NOT NULL DEFAULT '';
+ALTER TABLE Article ADD COLUMN reading_time INT NOT NULL DEFAULT 0;
@@ -0,0 +1,9 @@
+DROP TABLE Article_Review;
+
+DROP INDEX article_status_idx;
+
+ALTER TABLE Article DROP COLUMN published_at;
+ALTER TABLE Article DROP COLUMN publish_at;
+ALTER TABLE Article DROP COLUMN status;
+
+ALTER TABLE App_User DROP COLUMN role;
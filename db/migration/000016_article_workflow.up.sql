@@ -0,0 +1,23 @@
+-- Editors review the articles submitted for publication.
+ALTER TABLE App_User ADD COLUMN role VARCHAR(32) NOT NULL DEFAULT 'user';
+
+-- Existing articles were published when they were created; new ones start
+-- as drafts.
+ALTER TABLE Article ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'published';
+ALTER TABLE Article ALTER COLUMN status SET DEFAULT 'draft';
+ALTER TABLE Article ADD COLUMN publish_at INT NOT NULL DEFAULT 0;
+ALTER TABLE Article ADD COLUMN published_at INT NOT NULL DEFAULT 0;
+UPDATE Article SET published_at = created_at;
+
+CREATE INDEX article_status_idx ON Article(status);
+
+CREATE TABLE Article_Review (
+    id SERIAL PRIMARY KEY,
+    article_id INT NOT NULL REFERENCES Article(id) ON DELETE CASCADE,
+    reviewer_id INT REFERENCES App_User(id) ON DELETE SET NULL,
+    decision VARCHAR(16) NOT NULL,
+    comment TEXT NOT NULL DEFAULT '',
+    created_at INT NOT NULL
+);
+
+CREATE INDEX article_review_article_id_idx ON Article_Review(article_id, created_at);
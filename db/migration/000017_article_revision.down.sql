@@ -0,0 +1 @@
+DROP TABLE Article_Revision;
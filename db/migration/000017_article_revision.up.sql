@@ -0,0 +1,20 @@
+-- Every version of an article's title, body and category, oldest first.
+CREATE TABLE Article_Revision (
+    id SERIAL PRIMARY KEY,
+    article_id INT NOT NULL REFERENCES Article(id) ON DELETE CASCADE,
+    user_id INT REFERENCES App_User(id) ON DELETE SET NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL,
+    category VARCHAR(128) NOT NULL,
+    created_at INT NOT NULL
+);
+
+CREATE INDEX article_revision_article_id_idx ON Article_Revision(article_id, id);
+
+INSERT INTO
+    Article_Revision
+    (article_id, user_id, title, description, category, created_at)
+SELECT
+    id, author_id, title, description, category, updated_at
+FROM
+    Article;
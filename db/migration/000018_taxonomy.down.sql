@@ -0,0 +1,5 @@
+-- The category columns keep the slugs they were normalized to.
+DROP TABLE Webinar_Term;
+DROP TABLE Discussion_Term;
+DROP TABLE Article_Term;
+DROP TABLE Term;
@@ -0,0 +1,75 @@
+-- Categories and tags shared by articles, discussions and webinars.
+-- Translations maps a language code to the name in that language, e.g.
+-- {"id": "Kecemasan"}.
+CREATE TABLE Term (
+    id SERIAL PRIMARY KEY,
+    kind VARCHAR(16) NOT NULL,
+    slug VARCHAR(128) NOT NULL,
+    name VARCHAR(128) NOT NULL,
+    translations JSONB NOT NULL DEFAULT '{}',
+    created_at INT NOT NULL,
+    updated_at INT NOT NULL,
+    UNIQUE(kind, slug)
+);
+
+-- The terms of an item in order, its categories first.
+CREATE TABLE Article_Term (
+    article_id INT NOT NULL REFERENCES Article(id) ON DELETE CASCADE,
+    term_id INT NOT NULL REFERENCES Term(id) ON DELETE CASCADE,
+    position INT NOT NULL DEFAULT 0,
+    PRIMARY KEY(article_id, term_id)
+);
+
+CREATE TABLE Discussion_Term (
+    discussion_id INT NOT NULL REFERENCES Discussion(id) ON DELETE CASCADE,
+    term_id INT NOT NULL REFERENCES Term(id) ON DELETE CASCADE,
+    position INT NOT NULL DEFAULT 0,
+    PRIMARY KEY(discussion_id, term_id)
+);
+
+CREATE TABLE Webinar_Term (
+    webinar_id INT NOT NULL REFERENCES Webinar(id) ON DELETE CASCADE,
+    term_id INT NOT NULL REFERENCES Term(id) ON DELETE CASCADE,
+    position INT NOT NULL DEFAULT 0,
+    PRIMARY KEY(webinar_id, term_id)
+);
+
+CREATE INDEX article_term_term_id_idx ON Article_Term(term_id);
+CREATE INDEX discussion_term_term_id_idx ON Discussion_Term(term_id);
+CREATE INDEX webinar_term_term_id_idx ON Webinar_Term(term_id);
+
+-- Existing free-text categories become managed categories, merged by slug,
+-- and the category column of each item now holds the slug of its primary
+-- category.
+CREATE FUNCTION pg_temp.slugify(s TEXT) RETURNS TEXT AS $$
+    SELECT COALESCE(NULLIF(TRIM(BOTH '-' FROM REGEXP_REPLACE(LOWER(TRIM(s)), '[^a-z0-9]+', '-', 'g')), ''), 'uncategorized')
+$$ LANGUAGE SQL IMMUTABLE;
+
+INSERT INTO
+    Term
+    (kind, slug, name, created_at, updated_at)
+SELECT
+    'category', pg_temp.slugify(name), MIN(TRIM(name)), EXTRACT(EPOCH FROM NOW())::INT, EXTRACT(EPOCH FROM NOW())::INT
+FROM
+    (
+        SELECT category AS name FROM Article
+        UNION ALL
+        SELECT category FROM Discussion
+        UNION ALL
+        SELECT category FROM Webinar
+    ) c
+GROUP BY
+    pg_temp.slugify(name);
+
+UPDATE Article SET category = pg_temp.slugify(category);
+UPDATE Discussion SET category = pg_temp.slugify(category);
+UPDATE Webinar SET category = pg_temp.slugify(category);
+
+INSERT INTO Article_Term (article_id, term_id)
+SELECT a.id, t.id FROM Article a JOIN Term t ON t.kind = 'category' AND t.slug = a.category;
+
+INSERT INTO Discussion_Term (discussion_id, term_id)
+SELECT d.id, t.id FROM Discussion d JOIN Term t ON t.kind = 'category' AND t.slug = d.category;
+
+INSERT INTO Webinar_Term (webinar_id, term_id)
+SELECT w.id, t.id FROM Webinar w JOIN Term t ON t.kind = 'category' AND t.slug = w.category;
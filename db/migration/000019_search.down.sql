@@ -0,0 +1,8 @@
+ALTER TABLE Podcast DROP COLUMN search_id;
+ALTER TABLE Podcast DROP COLUMN search_en;
+ALTER TABLE Webinar DROP COLUMN search_id;
+ALTER TABLE Webinar DROP COLUMN search_en;
+ALTER TABLE Discussion DROP COLUMN search_id;
+ALTER TABLE Discussion DROP COLUMN search_en;
+ALTER TABLE Article DROP COLUMN search_id;
+ALTER TABLE Article DROP COLUMN search_en;
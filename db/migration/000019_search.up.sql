@@ -0,0 +1,38 @@
+-- Full-text search documents in English and Indonesian, weighting the title
+-- above the description.
+ALTER TABLE Article ADD COLUMN search_en TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', title), 'A') || setweight(to_tsvector('english', description), 'B')
+) STORED;
+ALTER TABLE Article ADD COLUMN search_id TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('indonesian', title), 'A') || setweight(to_tsvector('indonesian', description), 'B')
+) STORED;
+
+ALTER TABLE Discussion ADD COLUMN search_en TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', title), 'A') || setweight(to_tsvector('english', description), 'B')
+) STORED;
+ALTER TABLE Discussion ADD COLUMN search_id TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('indonesian', title), 'A') || setweight(to_tsvector('indonesian', description), 'B')
+) STORED;
+
+ALTER TABLE Webinar ADD COLUMN search_en TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', title), 'A') || setweight(to_tsvector('english', description), 'B')
+) STORED;
+ALTER TABLE Webinar ADD COLUMN search_id TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('indonesian', title), 'A') || setweight(to_tsvector('indonesian', description), 'B')
+) STORED;
+
+ALTER TABLE Podcast ADD COLUMN search_en TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', title), 'A') || setweight(to_tsvector('english', description), 'B')
+) STORED;
+ALTER TABLE Podcast ADD COLUMN search_id TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('indonesian', title), 'A') || setweight(to_tsvector('indonesian', description), 'B')
+) STORED;
+
+CREATE INDEX article_search_en_idx ON Article USING GIN(search_en);
+CREATE INDEX article_search_id_idx ON Article USING GIN(search_id);
+CREATE INDEX discussion_search_en_idx ON Discussion USING GIN(search_en);
+CREATE INDEX discussion_search_id_idx ON Discussion USING GIN(search_id);
+CREATE INDEX webinar_search_en_idx ON Webinar USING GIN(search_en);
+CREATE INDEX webinar_search_id_idx ON Webinar USING GIN(search_id);
+CREATE INDEX podcast_search_en_idx ON Podcast USING GIN(search_en);
+CREATE INDEX podcast_search_id_idx ON Podcast USING GIN(search_id);
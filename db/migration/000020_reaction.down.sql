@@ -0,0 +1,29 @@
+CREATE TABLE Starred_Podcast (
+    id SERIAL PRIMARY KEY,
+    podcast_id INT NOT NULL REFERENCES Podcast(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES App_User(id) ON DELETE CASCADE,
+    created_at INT NOT NULL,
+    UNIQUE(podcast_id, user_id)
+);
+
+CREATE INDEX starred_podcast_user_idx ON Starred_Podcast(user_id);
+
+CREATE TABLE Starred_Webinar (
+    id SERIAL PRIMARY KEY,
+    webinar_id INT NOT NULL REFERENCES Webinar(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES App_User(id) ON DELETE CASCADE,
+    created_at INT NOT NULL,
+    UNIQUE(webinar_id, user_id)
+);
+
+INSERT INTO Starred_Podcast (podcast_id, user_id, created_at)
+SELECT podcast_id, user_id, created_at FROM Podcast_Reaction WHERE kind = 'like';
+
+INSERT INTO Starred_Webinar (webinar_id, user_id, created_at)
+SELECT webinar_id, user_id, created_at FROM Webinar_Reaction WHERE kind = 'bookmark';
+
+DROP TABLE Comment_Reaction;
+DROP TABLE Podcast_Reaction;
+DROP TABLE Webinar_Reaction;
+DROP TABLE Discussion_Reaction;
+DROP TABLE Article_Reaction;
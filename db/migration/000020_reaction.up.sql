@@ -0,0 +1,64 @@
+-- Likes and bookmarks of users, one table per content type so they are
+-- deleted with the item. A user likes or bookmarks an item at most once.
+CREATE TABLE Article_Reaction (
+    id SERIAL PRIMARY KEY,
+    article_id INT NOT NULL REFERENCES Article(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES App_User(id) ON DELETE CASCADE,
+    kind VARCHAR(16) NOT NULL,
+    created_at INT NOT NULL,
+    UNIQUE(article_id, kind, user_id)
+);
+
+CREATE TABLE Discussion_Reaction (
+    id SERIAL PRIMARY KEY,
+    discussion_id INT NOT NULL REFERENCES Discussion(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES App_User(id) ON DELETE CASCADE,
+    kind VARCHAR(16) NOT NULL,
+    created_at INT NOT NULL,
+    UNIQUE(discussion_id, kind, user_id)
+);
+
+CREATE TABLE Webinar_Reaction (
+    id SERIAL PRIMARY KEY,
+    webinar_id INT NOT NULL REFERENCES Webinar(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES App_User(id) ON DELETE CASCADE,
+    kind VARCHAR(16) NOT NULL,
+    created_at INT NOT NULL,
+    UNIQUE(webinar_id, kind, user_id)
+);
+
+CREATE TABLE Podcast_Reaction (
+    id SERIAL PRIMARY KEY,
+    podcast_id INT NOT NULL REFERENCES Podcast(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES App_User(id) ON DELETE CASCADE,
+    kind VARCHAR(16) NOT NULL,
+    created_at INT NOT NULL,
+    UNIQUE(podcast_id, kind, user_id)
+);
+
+CREATE TABLE Comment_Reaction (
+    id SERIAL PRIMARY KEY,
+    comment_id INT NOT NULL REFERENCES Discussion_Comment(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES App_User(id) ON DELETE CASCADE,
+    kind VARCHAR(16) NOT NULL,
+    created_at INT NOT NULL,
+    UNIQUE(comment_id, kind, user_id)
+);
+
+CREATE INDEX article_reaction_user_idx ON Article_Reaction(user_id, kind, created_at);
+CREATE INDEX discussion_reaction_user_idx ON Discussion_Reaction(user_id, kind, created_at);
+CREATE INDEX webinar_reaction_user_idx ON Webinar_Reaction(user_id, kind, created_at);
+CREATE INDEX podcast_reaction_user_idx ON Podcast_Reaction(user_id, kind, created_at);
+CREATE INDEX comment_reaction_user_idx ON Comment_Reaction(user_id, kind, created_at);
+
+-- Stars predate reactions. A podcast star counted towards its popularity, so
+-- it becomes a like. A webinar star put the webinar in the calendar of the
+-- user and subscribed them to its changes, so it becomes a bookmark.
+INSERT INTO Podcast_Reaction (podcast_id, user_id, kind, created_at)
+SELECT podcast_id, user_id, 'like', created_at FROM Starred_Podcast;
+
+INSERT INTO Webinar_Reaction (webinar_id, user_id, kind, created_at)
+SELECT webinar_id, user_id, 'bookmark', created_at FROM Starred_Webinar;
+
+DROP TABLE Starred_Podcast;
+DROP TABLE Starred_Webinar;
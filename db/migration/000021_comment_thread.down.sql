@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS discussion_comment_discussion_idx;
+DROP INDEX IF EXISTS discussion_comment_parent_idx;
+
+-- Replies become flat comments, blanked comments go.
+ALTER TABLE Discussion_Comment DROP COLUMN parent_id;
+DELETE FROM Discussion_Comment WHERE deleted_at <> 0;
+
+ALTER TABLE Discussion_Comment DROP COLUMN deleted_at;
+ALTER TABLE Discussion_Comment DROP COLUMN depth;
@@ -0,0 +1,8 @@
+-- Replies point to the comment they answer. A comment with replies is blanked
+-- instead of deleted, so deleted_at is set and its replies stay in the thread.
+ALTER TABLE Discussion_Comment ADD COLUMN parent_id INT REFERENCES Discussion_Comment(id) ON DELETE CASCADE;
+ALTER TABLE Discussion_Comment ADD COLUMN depth INT NOT NULL DEFAULT 0;
+ALTER TABLE Discussion_Comment ADD COLUMN deleted_at INT NOT NULL DEFAULT 0;
+
+CREATE INDEX discussion_comment_parent_idx ON Discussion_Comment(parent_id, created_at, id);
+CREATE INDEX discussion_comment_discussion_idx ON Discussion_Comment(discussion_id, created_at, id) WHERE parent_id IS NULL;
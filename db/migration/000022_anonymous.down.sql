@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS Discussion_Pseudonym;
+
+ALTER TABLE Discussion_Comment DROP COLUMN anonymous;
+ALTER TABLE Discussion DROP COLUMN anonymous;
@@ -0,0 +1,13 @@
+ALTER TABLE Discussion ADD COLUMN anonymous BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE Discussion_Comment ADD COLUMN anonymous BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- The name a user posts under anonymously in a discussion and its comments,
+-- drawn on their first anonymous post there.
+CREATE TABLE Discussion_Pseudonym (
+    discussion_id INT NOT NULL REFERENCES Discussion(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES App_User(id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,
+    created_at INT NOT NULL,
+    PRIMARY KEY(discussion_id, user_id),
+    UNIQUE(discussion_id, name)
+);
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS Moderation_Action;
+DROP TABLE IF EXISTS Report;
+
+ALTER TABLE App_User DROP COLUMN suspended_until;
+
+ALTER TABLE Podcast DROP COLUMN hidden_at;
+ALTER TABLE Webinar DROP COLUMN hidden_at;
+ALTER TABLE Discussion_Comment DROP COLUMN hidden_at;
+ALTER TABLE Discussion DROP COLUMN hidden_at;
+ALTER TABLE Article DROP COLUMN hidden_at;
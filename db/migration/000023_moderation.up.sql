@@ -0,0 +1,42 @@
+-- Hidden content is left out of every public list and detail.
+ALTER TABLE Article ADD COLUMN hidden_at INT NOT NULL DEFAULT 0;
+ALTER TABLE Discussion ADD COLUMN hidden_at INT NOT NULL DEFAULT 0;
+ALTER TABLE Discussion_Comment ADD COLUMN hidden_at INT NOT NULL DEFAULT 0;
+ALTER TABLE Webinar ADD COLUMN hidden_at INT NOT NULL DEFAULT 0;
+ALTER TABLE Podcast ADD COLUMN hidden_at INT NOT NULL DEFAULT 0;
+
+-- A suspended user cannot post until then.
+ALTER TABLE App_User ADD COLUMN suspended_until INT NOT NULL DEFAULT 0;
+
+-- Reports of content by users. A user has at most one open report of an
+-- item; reports are resolved or dismissed by a moderation action.
+CREATE TABLE Report (
+    id SERIAL PRIMARY KEY,
+    content_type VARCHAR(32) NOT NULL,
+    content_id INT NOT NULL,
+    reporter_id INT NOT NULL REFERENCES App_User(id) ON DELETE CASCADE,
+    reason VARCHAR(32) NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL DEFAULT 'open',
+    created_at INT NOT NULL,
+    resolved_at INT NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX report_open_idx ON Report(content_type, content_id, reporter_id) WHERE status = 'open';
+CREATE INDEX report_status_idx ON Report(status, content_type, content_id);
+
+-- Every action taken by a moderator, on the content and its author.
+CREATE TABLE Moderation_Action (
+    id SERIAL PRIMARY KEY,
+    moderator_id INT REFERENCES App_User(id) ON DELETE SET NULL,
+    action VARCHAR(16) NOT NULL,
+    content_type VARCHAR(32) NOT NULL,
+    content_id INT NOT NULL,
+    target_id INT REFERENCES App_User(id) ON DELETE SET NULL,
+    note TEXT NOT NULL DEFAULT '',
+    until INT NOT NULL DEFAULT 0,
+    created_at INT NOT NULL
+);
+
+CREATE INDEX moderation_action_content_idx ON Moderation_Action(content_type, content_id, created_at);
+CREATE INDEX moderation_action_target_idx ON Moderation_Action(target_id, created_at);
@@ -0,0 +1,6 @@
+ALTER TABLE Report DROP COLUMN priority;
+DELETE FROM Report WHERE reporter_id IS NULL;
+ALTER TABLE Report ALTER COLUMN reporter_id SET NOT NULL;
+
+ALTER TABLE Discussion_Comment DROP COLUMN crisis;
+ALTER TABLE Discussion DROP COLUMN crisis;
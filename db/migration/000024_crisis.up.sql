@@ -0,0 +1,8 @@
+-- The language of the crisis terms found in a post, empty when none were.
+ALTER TABLE Discussion ADD COLUMN crisis VARCHAR(8) NOT NULL DEFAULT '';
+ALTER TABLE Discussion_Comment ADD COLUMN crisis VARCHAR(8) NOT NULL DEFAULT '';
+
+-- Posts in crisis are reported without a reporter, and come first in the
+-- moderation queue.
+ALTER TABLE Report ALTER COLUMN reporter_id DROP NOT NULL;
+ALTER TABLE Report ADD COLUMN priority BOOLEAN NOT NULL DEFAULT FALSE;
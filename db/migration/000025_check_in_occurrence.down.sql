@@ -0,0 +1 @@
+ALTER TABLE Webinar DROP COLUMN check_in_occurrence;
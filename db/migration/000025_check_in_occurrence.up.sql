@@ -0,0 +1,2 @@
+-- The occurrence the check-in code and token of a webinar are valid for.
+ALTER TABLE Webinar ADD COLUMN check_in_occurrence BIGINT NOT NULL DEFAULT 0;
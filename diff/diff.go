@@ -0,0 +1,176 @@
+// Package diff compares texts line by line or word by word.
+package diff
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	Equal  = "equal"
+	Insert = "insert"
+	Delete = "delete"
+)
+
+// maxEdits bounds the work spent on a diff. Texts that differ in more lines or
+// words than this are shown as replaced as a whole.
+const maxEdits = 2000
+
+// Op is a run of text that is kept, inserted or deleted. Joining the Equal
+// and Delete runs gives back the old text, the Equal and Insert runs the new
+// one.
+type Op struct {
+	Kind string `json:"kind"`
+	Text string `json:"text"`
+}
+
+// Lines diffs the texts line by line.
+func Lines(a, b string) []Op {
+	return compute(splitLines(a), splitLines(b))
+}
+
+// Words diffs the texts word by word. Runs of whitespace count as words of
+// their own.
+func Words(a, b string) []Op {
+	return compute(splitWords(a), splitWords(b))
+}
+
+// splitLines keeps the line endings so that the lines join back into the
+// text.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+func splitWords(s string) []string {
+	words := make([]string, 0)
+	start, space := 0, false
+	for i, r := range s {
+		if i > start && unicode.IsSpace(r) != space {
+			words = append(words, s[start:i])
+			start = i
+		}
+		space = unicode.IsSpace(r)
+	}
+	if start < len(s) {
+		words = append(words, s[start:])
+	}
+
+	return words
+}
+
+// compute finds a shortest edit script with Myers' algorithm. trace[d] holds
+// the furthest x reached on each diagonal k in [-d, d] after d edits, at
+// index k+d.
+func compute(a, b []string) []Op {
+	n, m := len(a), len(b)
+	trace := make([][]int, 0)
+
+	for d := 0; d <= n+m; d++ {
+		if d > maxEdits {
+			return replace(a, b)
+		}
+
+		v := make([]int, 2*d+1)
+		for k := -d; k <= d; k += 2 {
+			x := 0
+			if d > 0 {
+				prev := trace[d-1]
+				if k == -d || (k != d && prev[k-1+d-1] < prev[k+1+d-1]) {
+					x = prev[k+1+d-1]
+				} else {
+					x = prev[k-1+d-1] + 1
+				}
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[k+d] = x
+
+			if x >= n && y >= m {
+				trace = append(trace, v)
+				return backtrack(a, b, trace)
+			}
+		}
+		trace = append(trace, v)
+	}
+
+	return nil
+}
+
+func backtrack(a, b []string, trace [][]int) []Op {
+	ops := make([]Op, 0)
+	x, y := len(a), len(b)
+
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1]
+		k := x - y
+
+		prevK := k - 1
+		if k == -d || (k != d && prev[k-1+d-1] < prev[k+1+d-1]) {
+			prevK = k + 1
+		}
+		prevX := prev[prevK+d-1]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			ops = append(ops, Op{Kind: Equal, Text: a[x-1]})
+			x--
+			y--
+		}
+		if prevK == k+1 {
+			ops = append(ops, Op{Kind: Insert, Text: b[prevY]})
+		} else {
+			ops = append(ops, Op{Kind: Delete, Text: a[prevX]})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		ops = append(ops, Op{Kind: Equal, Text: a[x-1]})
+		x--
+		y--
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+
+	return merge(ops)
+}
+
+func replace(a, b []string) []Op {
+	ops := make([]Op, 0, 2)
+	if len(a) > 0 {
+		ops = append(ops, Op{Kind: Delete, Text: strings.Join(a, "")})
+	}
+	if len(b) > 0 {
+		ops = append(ops, Op{Kind: Insert, Text: strings.Join(b, "")})
+	}
+
+	return ops
+}
+
+// merge joins adjacent runs of the same kind.
+func merge(ops []Op) []Op {
+	merged := make([]Op, 0, len(ops))
+	for _, op := range ops {
+		if n := len(merged); n > 0 && merged[n-1].Kind == op.Kind {
+			merged[n-1].Text += op.Text
+			continue
+		}
+		merged = append(merged, op)
+	}
+
+	return merged
+}
@@ -0,0 +1,56 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLines(t *testing.T) {
+	ops := Lines("a\nb\nc\n", "a\nc\nd\n")
+
+	assert.Equal(t, []Op{
+		{Kind: Equal, Text: "a\n"},
+		{Kind: Delete, Text: "b\n"},
+		{Kind: Equal, Text: "c\n"},
+		{Kind: Insert, Text: "d\n"},
+	}, ops)
+}
+
+func TestWords(t *testing.T) {
+	ops := Words("the quick fox", "the slow  fox")
+
+	assert.Equal(t, []Op{
+		{Kind: Equal, Text: "the "},
+		{Kind: Delete, Text: "quick "},
+		{Kind: Insert, Text: "slow  "},
+		{Kind: Equal, Text: "fox"},
+	}, ops)
+}
+
+func TestRoundTrip(t *testing.T) {
+	a := "one two three\nfour five\nsix\n"
+	b := "zero one three\nfour six five\nseven"
+
+	for _, ops := range [][]Op{Lines(a, b), Words(a, b)} {
+		var old, new strings.Builder
+		for _, op := range ops {
+			if op.Kind != Insert {
+				old.WriteString(op.Text)
+			}
+			if op.Kind != Delete {
+				new.WriteString(op.Text)
+			}
+		}
+
+		assert.Equal(t, a, old.String())
+		assert.Equal(t, b, new.String())
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	assert.Empty(t, Lines("", ""))
+	assert.Equal(t, []Op{{Kind: Insert, Text: "new"}}, Words("", "new"))
+	assert.Equal(t, []Op{{Kind: Delete, Text: "old\n"}}, Lines("old\n", ""))
+}
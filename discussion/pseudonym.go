@@ -0,0 +1,103 @@
+package discussion
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+)
+
+var adjectives = []string{
+	"Brave", "Calm", "Bright", "Gentle", "Quiet", "Kind", "Steady", "Hopeful",
+	"Patient", "Warm", "Clever", "Curious", "Honest", "Humble", "Lively", "Mellow",
+	"Nimble", "Peaceful", "Proud", "Sunny", "Swift", "Tender", "Wise", "Bold",
+	"Cheerful", "Easy", "Golden", "Silver", "Soft", "Strong", "True", "Wandering",
+}
+
+var animals = []string{
+	"Otter", "Heron", "Fox", "Owl", "Panda", "Dolphin", "Sparrow", "Turtle",
+	"Badger", "Deer", "Falcon", "Hedgehog", "Koala", "Lynx", "Moose", "Penguin",
+	"Rabbit", "Robin", "Seal", "Swan", "Tiger", "Whale", "Wolf", "Wren",
+	"Beaver", "Crane", "Finch", "Gecko", "Lark", "Marten", "Puffin", "Raven",
+}
+
+// Pseudonym returns the name the user posts under anonymously in the
+// discussion, drawing one on their first anonymous post there. Names are not
+// shared by two users of a discussion.
+func Pseudonym(ctx context.Context, discussionRepo Repository, discussionID, userID int64) (string, error) {
+	for attempt := 0; attempt < 10; attempt++ {
+		name, err := newPseudonym(attempt)
+		if err != nil {
+			return "", err
+		}
+
+		pseudonym := models.Pseudonym{
+			DiscussionID: discussionID,
+			User:         models.User{ID: userID},
+			Name:         name,
+			CreatedAt:    time.Now().Unix(),
+		}
+
+		err = discussionRepo.CreatePseudonym(ctx, &pseudonym)
+		if app.ErrorCode(err) == app.Econflict {
+			continue
+		} else if app.ErrorCode(err) == app.ENotFound {
+			return "", app.NewError(err, app.ENotFound, "Discussion not found")
+		} else if err != nil {
+			return "", err
+		}
+
+		return pseudonym.Name, nil
+	}
+
+	return "", app.NewError(nil, app.Econflict, "Failed to find a free pseudonym, try again")
+}
+
+// newPseudonym draws a name such as "Gentle Heron". Once a few names were
+// taken in the discussion, a number is added to make room.
+func newPseudonym(attempt int) (string, error) {
+	b := make([]byte, 4)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	n := int(binary.BigEndian.Uint32(b))
+	name := adjectives[n%len(adjectives)] + " " + animals[n/len(adjectives)%len(animals)]
+	if attempt >= 3 {
+		name += fmt.Sprintf(" %d", n/len(adjectives)/len(animals)%1000)
+	}
+
+	return name, nil
+}
+
+// Avatar draws the avatar of a pseudonym, its initials on a color picked by
+// the name, as an SVG data URI.
+func Avatar(pseudonym string) string {
+	initials := ""
+	for _, word := range strings.Fields(pseudonym) {
+		if len(initials) < 2 && word[0] >= 'A' && word[0] <= 'Z' {
+			initials += word[:1]
+		}
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(pseudonym))
+
+	svg := fmt.Sprintf(
+		`<svg xmlns="http://www.w3.org/2000/svg" width="64" height="64" viewBox="0 0 64 64">`+
+			`<rect width="64" height="64" rx="32" fill="hsl(%d,55%%,50%%)"/>`+
+			`<text x="32" y="40" font-family="sans-serif" font-size="24" fill="#fff" text-anchor="middle">%s</text>`+
+			`</svg>`,
+		h.Sum32()%360, initials,
+	)
+
+	return "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString([]byte(svg))
+}
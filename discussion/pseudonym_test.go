@@ -0,0 +1,29 @@
+package discussion
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPseudonym(t *testing.T) {
+	name, err := newPseudonym(0)
+	assert.NoError(t, err)
+	assert.Len(t, strings.Fields(name), 2)
+
+	name, err = newPseudonym(3)
+	assert.NoError(t, err)
+	assert.Len(t, strings.Fields(name), 3)
+}
+
+func TestAvatar(t *testing.T) {
+	avatar := Avatar("Gentle Heron")
+	assert.Equal(t, avatar, Avatar("Gentle Heron"))
+	assert.NotEqual(t, avatar, Avatar("Gentle Heron 12"))
+
+	svg, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(avatar, "data:image/svg+xml;base64,"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(svg), ">GH</text>")
+}
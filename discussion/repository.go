@@ -3,17 +3,21 @@ package discussion
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 
 	"github.com/bagus2x/recovy/app"
 	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/pagination"
+	"github.com/lib/pq"
 )
 
 type Repository interface {
 	Create(ctx context.Context, discussion *models.Discussion) error
 	FindByID(ctx context.Context, discussionID int64) (models.Discussion, error)
-	Find(ctx context.Context) ([]models.Discussion, error)
-	FindByCategory(ctx context.Context, category string) ([]models.Discussion, error)
+	Find(ctx context.Context, params *Params) ([]models.Discussion, pagination.Cursor, error)
 	Delete(ctx context.Context, discussionID int64) error
+	CreatePseudonym(ctx context.Context, pseudonym *models.Pseudonym) error
 }
 
 type repository struct {
@@ -29,9 +33,9 @@ func NewRepository(db *sql.DB) Repository {
 var create = `
 	INSERT INTO
 		Discussion
-		(author_id, picture, title, description, category, created_at, updated_at)
+		(author_id, anonymous, picture, title, description, category, crisis, hidden_at, created_at, updated_at)
 	VALUES
-		($1, $2, $3, $4, $5, $6, $7)
+		($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	RETURNING
 		id
 `
@@ -41,10 +45,13 @@ func (r *repository) Create(ctx context.Context, discussion *models.Discussion)
 		ctx,
 		create,
 		discussion.Author.ID,
+		discussion.Anonymous,
 		discussion.Picture,
 		discussion.Title,
 		discussion.Description,
 		discussion.Category,
+		discussion.Crisis,
+		discussion.HiddenAt,
 		discussion.CreatedAt,
 		discussion.UpdatedAt,
 	).Scan(&discussion.ID)
@@ -54,15 +61,20 @@ func (r *repository) Create(ctx context.Context, discussion *models.Discussion)
 
 var findByID = `
 	SELECT
-		a.id, au.id, au.name, au.picture, a.picture, a.title, a.description, a.category, a.created_at, a.updated_at
+		a.id, au.id, au.name, au.picture, a.anonymous, COALESCE(dp.name, ''), a.picture, a.title, a.description, a.category, a.crisis,
+		a.created_at, a.updated_at
 	FROM
 		Discussion a
 	JOIN
 		App_User au
 	ON
 		a.author_id = au.id
+	LEFT JOIN
+		Discussion_Pseudonym dp
+	ON
+		dp.discussion_id = a.id AND dp.user_id = a.author_id
 	WHERE
-		a.id = $1
+		a.id = $1 AND a.hidden_at = 0
 `
 
 func (r *repository) FindByID(ctx context.Context, discussionID int64) (models.Discussion, error) {
@@ -73,10 +85,13 @@ func (r *repository) FindByID(ctx context.Context, discussionID int64) (models.D
 		&discussion.Author.ID,
 		&discussion.Author.Name,
 		&discussion.Author.Picture,
+		&discussion.Anonymous,
+		&discussion.Pseudonym,
 		&discussion.Picture,
 		&discussion.Title,
 		&discussion.Description,
 		&discussion.Category,
+		&discussion.Crisis,
 		&discussion.CreatedAt,
 		&discussion.UpdatedAt,
 	)
@@ -87,77 +102,69 @@ func (r *repository) FindByID(ctx context.Context, discussionID int64) (models.D
 	return discussion, nil
 }
 
-var find = `
-	SELECT
-		a.id, au.id, au.name, au.picture, a.picture, a.title, a.description, a.category, a.created_at, a.updated_at
-	FROM
-		Discussion a
-	JOIN
-		App_User au
-	ON
-		a.author_id = au.id
-	LIMIT
-		100
-`
-
-func (r *repository) Find(ctx context.Context) ([]models.Discussion, error) {
-	discussions := make([]models.Discussion, 0)
-
-	rows, err := r.db.QueryContext(ctx, find)
-	if err != nil {
-		return discussions, err
+// find builds a keyset query listing discussions newest first, with a.id as
+// the tie breaker.
+func find(params *Params, key *pagination.Key) (string, []interface{}) {
+	query := strings.Builder{}
+	args := make([]interface{}, 0)
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
 	}
 
-	for rows.Next() {
-		var discussion models.Discussion
-
-		err := rows.Scan(
-			&discussion.ID,
-			&discussion.Author.ID,
-			&discussion.Author.Name,
-			&discussion.Author.Picture,
-			&discussion.Picture,
-			&discussion.Title,
-			&discussion.Description,
-			&discussion.Category,
-			&discussion.CreatedAt,
-			&discussion.UpdatedAt,
-		)
-		if err != nil {
-			return discussions, err
-		}
+	query.WriteString(`
+		SELECT
+			a.id, au.id, au.name, au.picture, a.anonymous, COALESCE(dp.name, ''), a.picture, a.title, a.description, a.category, a.crisis,
+			a.created_at, a.updated_at
+		FROM
+			Discussion a
+		JOIN
+			App_User au
+		ON
+			a.author_id = au.id
+		LEFT JOIN
+			Discussion_Pseudonym dp
+		ON
+			dp.discussion_id = a.id AND dp.user_id = a.author_id
+		WHERE
+			a.hidden_at = 0`,
+	)
 
-		discussions = append(discussions, discussion)
+	// Dynamic query
+	if params.Category != "" {
+		fmt.Fprintf(&query, " AND EXISTS (SELECT 1 FROM Discussion_Term dt JOIN Term t ON t.id = dt.term_id WHERE dt.discussion_id = a.id AND t.kind = 'category' AND t.slug = %s) ", arg(params.Category))
 	}
-	if rows.Err() != nil {
-		return discussions, err
+
+	// Cursor
+	op, order := params.Order(true)
+	if key != nil {
+		fmt.Fprintf(&query, " AND (a.created_at, a.id) %s (%s, %s) ", op, arg(key.Value), arg(key.ID))
 	}
 
-	return discussions, nil
-}
+	// Limit
+	fmt.Fprintf(&query, " ORDER BY a.created_at %s, a.id %s LIMIT %s", order, order, arg(params.Limit))
 
-var findByCategory = `
-	SELECT
-		a.id, au.id, au.name, au.picture, a.picture, a.title, a.description, a.category, a.created_at, a.updated_at
-	FROM
-		Discussion a
-	JOIN
-		App_User au
-	ON
-		a.author_id = au.id
-	WHERE
-		a.category = $1
-	LIMIT
-		100
-`
+	return query.String(), args
+}
 
-func (r *repository) FindByCategory(ctx context.Context, category string) ([]models.Discussion, error) {
-	discussions := make([]models.Discussion, 0)
+// Find lists a page of discussions, newest first. Category selects the
+// discussions in the category with that slug, whether it is their primary
+// category or not.
+func (r *repository) Find(ctx context.Context, params *Params) ([]models.Discussion, pagination.Cursor, error) {
+	var key *pagination.Key
+	err := params.Normalize(&key)
+	if err != nil {
+		return nil, pagination.Cursor{}, err
+	}
 
-	rows, err := r.db.QueryContext(ctx, findByCategory, category)
+	query, args := find(params, key)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return discussions, err
+		return nil, pagination.Cursor{}, err
 	}
+	defer rows.Close()
+
+	discussions := make([]models.Discussion, 0)
 
 	for rows.Next() {
 		var discussion models.Discussion
@@ -167,24 +174,40 @@ func (r *repository) FindByCategory(ctx context.Context, category string) ([]mod
 			&discussion.Author.ID,
 			&discussion.Author.Name,
 			&discussion.Author.Picture,
+			&discussion.Anonymous,
+			&discussion.Pseudonym,
 			&discussion.Picture,
 			&discussion.Title,
 			&discussion.Description,
 			&discussion.Category,
+			&discussion.Crisis,
 			&discussion.CreatedAt,
 			&discussion.UpdatedAt,
 		)
 		if err != nil {
-			return discussions, err
+			return nil, pagination.Cursor{}, err
 		}
 
 		discussions = append(discussions, discussion)
 	}
-	if rows.Err() != nil {
-		return discussions, err
+	if err := rows.Err(); err != nil {
+		return nil, pagination.Cursor{}, err
+	}
+
+	if params.Previous() {
+		pagination.Reverse(discussions)
 	}
 
-	return discussions, nil
+	var cursor pagination.Cursor
+	if len(discussions) > 0 {
+		first, last := discussions[0], discussions[len(discussions)-1]
+		cursor = pagination.NewCursor(
+			pagination.Key{Value: first.CreatedAt, ID: first.ID},
+			pagination.Key{Value: last.CreatedAt, ID: last.ID},
+		)
+	}
+
+	return discussions, cursor, nil
 }
 
 var delete = `
@@ -199,3 +222,41 @@ func (r *repository) Delete(ctx context.Context, discussionID int64) error {
 
 	return err
 }
+
+var createPseudonym = `
+	INSERT INTO
+		Discussion_Pseudonym
+		(discussion_id, user_id, name, created_at)
+	VALUES
+		($1, $2, $3, $4)
+	ON CONFLICT
+		(discussion_id, user_id)
+	DO UPDATE SET
+		name = Discussion_Pseudonym.name
+	RETURNING
+		name
+`
+
+// CreatePseudonym gives the user the pseudonym in the discussion, unless they
+// have one already, which is returned instead. Econflict means the name is
+// taken by another user of the discussion.
+func (r *repository) CreatePseudonym(ctx context.Context, pseudonym *models.Pseudonym) error {
+	err := r.db.QueryRowContext(
+		ctx,
+		createPseudonym,
+		pseudonym.DiscussionID,
+		pseudonym.User.ID,
+		pseudonym.Name,
+		pseudonym.CreatedAt,
+	).Scan(&pseudonym.Name)
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code {
+		case "23505":
+			return app.NewError(err, app.Econflict)
+		case "23503":
+			return app.NewError(err, app.ENotFound)
+		}
+	}
+
+	return err
+}
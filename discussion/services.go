@@ -2,27 +2,55 @@ package discussion
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/auth"
+	"github.com/bagus2x/recovy/crisis"
+	"github.com/bagus2x/recovy/filter"
 	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/moderation"
+	"github.com/bagus2x/recovy/reaction"
+	"github.com/bagus2x/recovy/taxonomy"
+	"github.com/sirupsen/logrus"
 )
 
 type Service interface {
 	Create(ctx context.Context, req *CreateDiscussionReq) (CreateDiscussionResp, error)
-	GetByID(ctx context.Context, discussionID int64) (GetDiscussionResp, error)
-	GetByCategory(ctx context.Context, category string) ([]GetDiscussionResp, error)
-	Get(ctx context.Context) ([]GetDiscussionResp, error)
+	GetByID(ctx context.Context, discussionID, viewerID int64) (GetDiscussionResp, error)
+	GetByCategory(ctx context.Context, category string, params *Params) (GetDiscussionsResp, error)
+	Get(ctx context.Context, params *Params) (GetDiscussionsResp, error)
 	Delete(ctx context.Context, discussionID, authorID int64) error
 }
 
 type service struct {
-	discussionRepo Repository
+	discussionRepo    Repository
+	userRepo          auth.Repository
+	taxonomyService   taxonomy.Service
+	reactionService   reaction.Service
+	moderationService moderation.Service
+	detector          *crisis.Detector
+	contentFilter     *filter.Pipeline
 }
 
-func NewService(discussionRepo Repository) Service {
+func NewService(
+	discussionRepo Repository,
+	userRepo auth.Repository,
+	taxonomyService taxonomy.Service,
+	reactionService reaction.Service,
+	moderationService moderation.Service,
+	detector *crisis.Detector,
+	contentFilter *filter.Pipeline,
+) Service {
 	return &service{
-		discussionRepo: discussionRepo,
+		discussionRepo:    discussionRepo,
+		userRepo:          userRepo,
+		taxonomyService:   taxonomyService,
+		reactionService:   reactionService,
+		moderationService: moderationService,
+		detector:          detector,
+		contentFilter:     contentFilter,
 	}
 }
 
@@ -32,38 +60,108 @@ func (s *service) Create(ctx context.Context, req *CreateDiscussionReq) (CreateD
 		return CreateDiscussionResp{}, err
 	}
 
+	author, err := s.userRepo.FindByID(ctx, req.AuthorID)
+	if err != nil {
+		return CreateDiscussionResp{}, err
+	}
+	if author.IsSuspended(time.Now().Unix()) {
+		return CreateDiscussionResp{}, app.NewError(nil, app.EForbidden, "Your account is suspended")
+	}
+
+	terms, err := s.taxonomyService.Resolve(ctx, append([]string{req.Category}, req.Categories...), req.Tags)
+	if err != nil {
+		return CreateDiscussionResp{}, err
+	}
+
+	// Crisis terms are looked for in the text as written, before the filter
+	// can mask or reject it.
+	match := s.detector.Detect(req.Title + "\n" + req.Description)
+
+	post := filter.Post{
+		ContentType: models.ContentDiscussion,
+		AuthorID:    req.AuthorID,
+		Title:       req.Title,
+		Body:        req.Description,
+		Crisis:      match.Flagged(),
+	}
+	filtered, err := s.contentFilter.Run(ctx, &post)
+	if err != nil {
+		return CreateDiscussionResp{}, err
+	}
+
 	discussion := models.Discussion{
 		Author: models.User{
 			ID: req.AuthorID,
 		},
+		Anonymous:   req.Anonymous,
 		Picture:     req.Picture,
-		Title:       req.Title,
-		Description: req.Description,
-		Category:    req.Category,
+		Title:       post.Title,
+		Description: post.Body,
+		Category:    terms[0].Slug,
+		Crisis:      match.Language,
 		CreatedAt:   time.Now().Unix(),
 		UpdatedAt:   time.Now().Unix(),
 	}
+	if filtered.Held {
+		discussion.HiddenAt = discussion.CreatedAt
+	}
 
 	err = s.discussionRepo.Create(ctx, &discussion)
 	if err != nil {
 		return CreateDiscussionResp{}, err
 	}
 
+	err = s.taxonomyService.Link(ctx, models.ContentDiscussion, discussion.ID, terms)
+	if err != nil {
+		return CreateDiscussionResp{}, err
+	}
+	categories, tags := taxonomy.Split(terms)
+
+	if discussion.Anonymous {
+		discussion.Pseudonym, err = Pseudonym(ctx, s.discussionRepo, discussion.ID, discussion.Author.ID)
+		if err != nil {
+			return CreateDiscussionResp{}, err
+		}
+	}
+
+	if filtered.Held {
+		err = s.moderationService.Hold(ctx, models.ContentDiscussion, discussion.ID, strings.Join(filtered.Reasons, "; "))
+		if err != nil {
+			return CreateDiscussionResp{}, err
+		}
+	}
+
+	// The discussion is posted already, so a failed flag must not keep the
+	// author from seeing the crisis resources.
+	if match.Flagged() {
+		err = s.moderationService.Flag(ctx, models.ContentDiscussion, discussion.ID, "Crisis terms: "+strings.Join(match.Terms, ", "))
+		if err != nil {
+			logrus.Error(err)
+		}
+	}
+
 	res := CreateDiscussionResp{
-		ID:          discussion.ID,
-		AuthorID:    discussion.Author.ID,
-		Picture:     discussion.Picture,
-		Title:       discussion.Title,
-		Description: discussion.Description,
-		Category:    discussion.Category,
-		CreatedAt:   discussion.CreatedAt,
-		UpdatedAt:   discussion.UpdatedAt,
+		ID:              discussion.ID,
+		AuthorID:        discussion.Author.ID,
+		Anonymous:       discussion.Anonymous,
+		Pseudonym:       discussion.Pseudonym,
+		Picture:         discussion.Picture,
+		Title:           discussion.Title,
+		Description:     discussion.Description,
+		Category:        discussion.Category,
+		Categories:      categories,
+		Tags:            tags,
+		CrisisResources: s.detector.Resources(discussion.Crisis),
+		Held:            filtered.Held,
+		HeldReasons:     filtered.Reasons,
+		CreatedAt:       discussion.CreatedAt,
+		UpdatedAt:       discussion.UpdatedAt,
 	}
 
 	return res, nil
 }
 
-func (s *service) GetByID(ctx context.Context, discussionID int64) (GetDiscussionResp, error) {
+func (s *service) GetByID(ctx context.Context, discussionID, viewerID int64) (GetDiscussionResp, error) {
 	discussion, err := s.discussionRepo.FindByID(ctx, discussionID)
 	if app.ErrorCode(err) == app.ENotFound {
 		return GetDiscussionResp{}, app.NewError(err, app.ENotFound, "Discussion not found")
@@ -71,76 +169,32 @@ func (s *service) GetByID(ctx context.Context, discussionID int64) (GetDiscussio
 		return GetDiscussionResp{}, err
 	}
 
-	resp := GetDiscussionResp{
-		ID: discussion.ID,
-		Author: Author{
-			ID:      discussion.Author.ID,
-			Name:    discussion.Author.Name,
-			Picture: discussion.Author.Picture,
-		},
-		Picture:     discussion.Picture,
-		Title:       discussion.Title,
-		Description: discussion.Description,
-		Category:    discussion.Category,
-		CreatedAt:   discussion.CreatedAt,
-		UpdatedAt:   discussion.UpdatedAt,
+	resp, err := s.discussionsResp(ctx, []models.Discussion{discussion}, viewerID)
+	if err != nil {
+		return GetDiscussionResp{}, err
 	}
 
-	return resp, nil
+	return resp[0], nil
 }
 
-func (s *service) GetByCategory(ctx context.Context, category string) ([]GetDiscussionResp, error) {
-	discussions, err := s.discussionRepo.FindByCategory(ctx, category)
-	if err != nil {
-		return make([]GetDiscussionResp, 0), err
-	}
+func (s *service) GetByCategory(ctx context.Context, category string, params *Params) (GetDiscussionsResp, error) {
+	params.Category = category
 
-	resp := make([]GetDiscussionResp, 0)
-	for _, discussion := range discussions {
-		resp = append(resp, GetDiscussionResp{
-			ID: discussion.ID,
-			Author: Author{
-				ID:      discussion.Author.ID,
-				Name:    discussion.Author.Name,
-				Picture: discussion.Author.Picture,
-			},
-			Picture:     discussion.Picture,
-			Title:       discussion.Title,
-			Description: discussion.Description,
-			Category:    discussion.Category,
-			CreatedAt:   discussion.CreatedAt,
-			UpdatedAt:   discussion.UpdatedAt,
-		})
-	}
-
-	return resp, nil
+	return s.Get(ctx, params)
 }
 
-func (s *service) Get(ctx context.Context) ([]GetDiscussionResp, error) {
-	discussions, err := s.discussionRepo.Find(ctx)
+func (s *service) Get(ctx context.Context, params *Params) (GetDiscussionsResp, error) {
+	discussions, cursor, err := s.discussionRepo.Find(ctx, params)
 	if err != nil {
-		return make([]GetDiscussionResp, 0), err
+		return GetDiscussionsResp{}, err
 	}
 
-	resp := make([]GetDiscussionResp, 0)
-	for _, discussion := range discussions {
-		resp = append(resp, GetDiscussionResp{
-			ID: discussion.ID,
-			Author: Author{
-				ID:      discussion.Author.ID,
-				Name:    discussion.Author.Name,
-				Picture: discussion.Author.Picture,
-			},
-			Picture:     discussion.Picture,
-			Title:       discussion.Title,
-			Description: discussion.Description,
-			Category:    discussion.Category,
-			CreatedAt:   discussion.CreatedAt,
-			UpdatedAt:   discussion.UpdatedAt,
-		})
+	resp, err := s.discussionsResp(ctx, discussions, params.ViewerID)
+	if err != nil {
+		return GetDiscussionsResp{}, err
 	}
 
-	return resp, nil
+	return GetDiscussionsResp{Cursor: cursor, Discussions: resp}, nil
 }
 
 func (s *service) Delete(ctx context.Context, discussionID, authorID int64) error {
@@ -157,3 +211,90 @@ func (s *service) Delete(ctx context.Context, discussionID, authorID int64) erro
 
 	return s.discussionRepo.Delete(ctx, discussionID)
 }
+
+// discussionsResp adds the terms of the discussions and their reactions, with
+// those of the viewer, and the crisis resources of those in crisis.
+func (s *service) discussionsResp(ctx context.Context, discussions []models.Discussion, viewerID int64) ([]GetDiscussionResp, error) {
+	ids := make([]int64, 0, len(discussions))
+	for _, discussion := range discussions {
+		ids = append(ids, discussion.ID)
+	}
+
+	terms, err := s.taxonomyService.Terms(ctx, models.ContentDiscussion, ids...)
+	if err != nil {
+		return make([]GetDiscussionResp, 0), err
+	}
+
+	summaries, err := s.reactionService.Summaries(ctx, models.ContentDiscussion, viewerID, ids...)
+	if err != nil {
+		return make([]GetDiscussionResp, 0), err
+	}
+
+	moderator, err := s.isModerator(ctx, viewerID)
+	if err != nil {
+		return make([]GetDiscussionResp, 0), err
+	}
+
+	resp := make([]GetDiscussionResp, 0, len(discussions))
+	for _, discussion := range discussions {
+		res := toDiscussionResp(discussion, terms[discussion.ID], moderator)
+		res.CrisisResources = s.detector.Resources(discussion.Crisis)
+		res.Summary = summaries[discussion.ID]
+		resp = append(resp, res)
+	}
+
+	return resp, nil
+}
+
+func (s *service) isModerator(ctx context.Context, userID int64) (bool, error) {
+	if userID == 0 {
+		return false, nil
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return user.IsModerator(), nil
+}
+
+// toDiscussionResp hides the author of an anonymous discussion behind their
+// pseudonym, except from moderators.
+func toDiscussionResp(discussion models.Discussion, terms []models.Term, moderator bool) GetDiscussionResp {
+	categories, tags := taxonomy.Split(terms)
+
+	author := Author{
+		ID:      discussion.Author.ID,
+		Name:    discussion.Author.Name,
+		Picture: discussion.Author.Picture,
+	}
+	var realAuthor *Author
+	if discussion.Anonymous {
+		if moderator {
+			owner := author
+			realAuthor = &owner
+		}
+		author = Author{
+			Name:    discussion.Pseudonym,
+			Picture: Avatar(discussion.Pseudonym),
+		}
+	}
+
+	return GetDiscussionResp{
+		ID:          discussion.ID,
+		Author:      author,
+		Anonymous:   discussion.Anonymous,
+		RealAuthor:  realAuthor,
+		Picture:     discussion.Picture,
+		Title:       discussion.Title,
+		Description: discussion.Description,
+		Category:    discussion.Category,
+		Categories:  categories,
+		Tags:        tags,
+		CreatedAt:   discussion.CreatedAt,
+		UpdatedAt:   discussion.UpdatedAt,
+	}
+}
@@ -0,0 +1,30 @@
+package discussion
+
+import (
+	"testing"
+
+	"github.com/bagus2x/recovy/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToDiscussionRespAnonymous(t *testing.T) {
+	discussion := models.Discussion{
+		ID:        1,
+		Author:    models.User{ID: 7, Name: "Jane", Picture: "jane.png"},
+		Anonymous: true,
+		Pseudonym: "Calm Otter",
+	}
+
+	resp := toDiscussionResp(discussion, nil, false)
+	assert.Equal(t, Author{Name: "Calm Otter", Picture: Avatar("Calm Otter")}, resp.Author)
+	assert.Nil(t, resp.RealAuthor)
+
+	resp = toDiscussionResp(discussion, nil, true)
+	assert.Equal(t, "Calm Otter", resp.Author.Name)
+	assert.Equal(t, &Author{ID: 7, Name: "Jane", Picture: "jane.png"}, resp.RealAuthor)
+
+	discussion.Anonymous = false
+	resp = toDiscussionResp(discussion, nil, true)
+	assert.Equal(t, int64(7), resp.Author.ID)
+	assert.Nil(t, resp.RealAuthor)
+}
@@ -2,15 +2,26 @@ package discussion
 
 import (
 	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/crisis"
+	"github.com/bagus2x/recovy/pagination"
+	"github.com/bagus2x/recovy/reaction"
+	"github.com/bagus2x/recovy/taxonomy"
 	"github.com/go-playground/validator/v10"
 )
 
+// CreateDiscussionReq takes the primary category in Category and any others
+// in Categories, each as the slug, name or translated name of a managed
+// category. An Anonymous discussion is shown under the author's pseudonym in
+// the discussion.
 type CreateDiscussionReq struct {
-	AuthorID    int64  `json:"authorID" validate:"required,gt=0"`
-	Picture     string `json:"picture" validate:"lte=512"`
-	Title       string `json:"title" validate:"required,lte=128"`
-	Description string `json:"description" validate:"required"`
-	Category    string `json:"category"  validate:"required,lte=128"`
+	AuthorID    int64    `json:"authorID" validate:"required,gt=0"`
+	Anonymous   bool     `json:"anonymous"`
+	Picture     string   `json:"picture" validate:"lte=512"`
+	Title       string   `json:"title" validate:"required,lte=128"`
+	Description string   `json:"description" validate:"required"`
+	Category    string   `json:"category"  validate:"required,lte=128"`
+	Categories  []string `json:"categories" validate:"max=10,dive,lte=128"`
+	Tags        []string `json:"tags" validate:"max=10,dive,lte=128"`
 }
 
 func (r *CreateDiscussionReq) Validate() error {
@@ -20,15 +31,25 @@ func (r *CreateDiscussionReq) Validate() error {
 	return app.ValidateAndTranslate(validate, err)
 }
 
+// CreateDiscussionResp has CrisisResources when the discussion mentions
+// suicide or self-harm. A Held discussion is hidden until a moderator
+// reviews it, for HeldReasons.
 type CreateDiscussionResp struct {
-	ID          int64  `json:"id"`
-	AuthorID    int64  `json:"authorID"`
-	Picture     string `json:"picture"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Category    string `json:"category"`
-	CreatedAt   int64  `json:"createdAt"`
-	UpdatedAt   int64  `json:"updatedAt"`
+	ID              int64             `json:"id"`
+	AuthorID        int64             `json:"authorID"`
+	Anonymous       bool              `json:"anonymous"`
+	Pseudonym       string            `json:"pseudonym,omitempty"`
+	Picture         string            `json:"picture"`
+	Title           string            `json:"title"`
+	Description     string            `json:"description"`
+	Category        string            `json:"category"`
+	Categories      []taxonomy.Term   `json:"categories"`
+	Tags            []taxonomy.Term   `json:"tags"`
+	CrisisResources *crisis.Resources `json:"crisisResources,omitempty"`
+	Held            bool              `json:"held"`
+	HeldReasons     []string          `json:"heldReasons,omitempty"`
+	CreatedAt       int64             `json:"createdAt"`
+	UpdatedAt       int64             `json:"updatedAt"`
 }
 
 type Author struct {
@@ -37,13 +58,34 @@ type Author struct {
 	Picture string `json:"picture"`
 }
 
+// GetDiscussionResp shows an anonymous discussion with the pseudonym of the
+// author as Author. RealAuthor is shown to moderators only. CrisisResources
+// are shown with a discussion that mentions suicide or self-harm.
 type GetDiscussionResp struct {
-	ID          int64  `json:"id"`
-	Author      Author `json:"author"`
-	Picture     string `json:"picture"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Category    string `json:"category"`
-	CreatedAt   int64  `json:"createdAt"`
-	UpdatedAt   int64  `json:"updatedAt"`
+	ID              int64             `json:"id"`
+	Author          Author            `json:"author"`
+	Anonymous       bool              `json:"anonymous"`
+	RealAuthor      *Author           `json:"realAuthor,omitempty"`
+	Picture         string            `json:"picture"`
+	Title           string            `json:"title"`
+	Description     string            `json:"description"`
+	Category        string            `json:"category"`
+	Categories      []taxonomy.Term   `json:"categories"`
+	Tags            []taxonomy.Term   `json:"tags"`
+	CrisisResources *crisis.Resources `json:"crisisResources,omitempty"`
+	CreatedAt       int64             `json:"createdAt"`
+	UpdatedAt       int64             `json:"updatedAt"`
+	reaction.Summary
+}
+
+// Params filters the discussion list. Category is a slug.
+type Params struct {
+	pagination.Params
+	ViewerID int64
+	Category string
+}
+
+type GetDiscussionsResp struct {
+	Cursor      pagination.Cursor   `json:"cursor"`
+	Discussions []GetDiscussionResp `json:"discussions"`
 }
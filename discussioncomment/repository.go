@@ -3,17 +3,21 @@ package discussioncomment
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 
 	"github.com/bagus2x/recovy/app"
 	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/pagination"
+	"github.com/lib/pq"
 )
 
 type Repository interface {
 	Create(ctx context.Context, discussionComment *models.DiscussionComment) error
 	FindByID(ctx context.Context, discussionCommentID int64) (models.DiscussionComment, error)
-	Find(ctx context.Context) ([]models.DiscussionComment, error)
-	FindByDiscussionID(ctx context.Context, discussionID int64) ([]models.DiscussionComment, error)
-	Delete(ctx context.Context, discussionCommentID int64) error
+	Find(ctx context.Context, params *Params) ([]models.DiscussionComment, pagination.Cursor, error)
+	FindReplies(ctx context.Context, parentIDs []int64) ([]models.DiscussionComment, error)
+	Delete(ctx context.Context, discussionCommentID, deletedAt int64) error
 }
 
 type repository struct {
@@ -29,9 +33,9 @@ func NewRepository(db *sql.DB) Repository {
 var create = `
 	INSERT INTO
 		Discussion_Comment
-		(discussion_id, commentator_id,  description, created_at, updated_at)
+		(discussion_id, parent_id, depth, commentator_id, anonymous, description, crisis, hidden_at, created_at, updated_at)
 	VALUES
-		($1, $2, $3, $4, $5)
+		($1, NULLIF($2, 0), $3, $4, $5, $6, $7, $8, $9, $10)
 	RETURNING
 		id
 `
@@ -41,8 +45,13 @@ func (r *repository) Create(ctx context.Context, discussionComment *models.Discu
 		ctx,
 		create,
 		discussionComment.DiscussionID,
+		discussionComment.ParentID,
+		discussionComment.Depth,
 		discussionComment.Commentator.ID,
+		discussionComment.Anonymous,
 		discussionComment.Description,
+		discussionComment.Crisis,
+		discussionComment.HiddenAt,
 		discussionComment.CreatedAt,
 		discussionComment.UpdatedAt,
 	).Scan(&discussionComment.ID)
@@ -52,15 +61,24 @@ func (r *repository) Create(ctx context.Context, discussionComment *models.Discu
 
 var findByID = `
 	SELECT
-		dc.id, dc.discussion_id, au.id, au.name, au.picture,  dc.description, dc.created_at, dc.updated_at
+		dc.id, dc.discussion_id, COALESCE(dc.parent_id, 0), dc.depth, au.id, au.name, au.picture, dc.anonymous, COALESCE(dp.name, ''),
+		dc.description, dc.crisis, dc.created_at, dc.updated_at, dc.deleted_at, (SELECT COUNT(*) FROM Discussion_Comment r WHERE r.parent_id = dc.id AND r.hidden_at = 0)
 	FROM
 		Discussion_Comment dc
 	JOIN
 		App_User au
 	ON
 		dc.commentator_id = au.id
+	JOIN
+		Discussion d
+	ON
+		dc.discussion_id = d.id
+	LEFT JOIN
+		Discussion_Pseudonym dp
+	ON
+		dp.discussion_id = dc.discussion_id AND dp.user_id = dc.commentator_id
 	WHERE
-		dc.id = $1
+		dc.id = $1 AND dc.hidden_at = 0 AND d.hidden_at = 0
 `
 
 func (r *repository) FindByID(ctx context.Context, discussionCommentID int64) (models.DiscussionComment, error) {
@@ -69,12 +87,19 @@ func (r *repository) FindByID(ctx context.Context, discussionCommentID int64) (m
 	err := r.db.QueryRowContext(ctx, findByID, discussionCommentID).Scan(
 		&discussionComment.ID,
 		&discussionComment.DiscussionID,
+		&discussionComment.ParentID,
+		&discussionComment.Depth,
 		&discussionComment.Commentator.ID,
 		&discussionComment.Commentator.Name,
 		&discussionComment.Commentator.Picture,
+		&discussionComment.Anonymous,
+		&discussionComment.Pseudonym,
 		&discussionComment.Description,
+		&discussionComment.Crisis,
 		&discussionComment.CreatedAt,
 		&discussionComment.UpdatedAt,
+		&discussionComment.DeletedAt,
+		&discussionComment.ReplyCount,
 	)
 	if err == sql.ErrNoRows {
 		return models.DiscussionComment{}, app.NewError(err, app.ENotFound)
@@ -83,26 +108,78 @@ func (r *repository) FindByID(ctx context.Context, discussionCommentID int64) (m
 	return discussionComment, nil
 }
 
-var find = `
-	SELECT
-		dc.id, dc.discussion_id, au.id, au.name, au.picture,  dc.description, dc.created_at, dc.updated_at
-	FROM
-		Discussion_Comment dc
-	JOIN
-		App_User au
-	ON
-		dc.commentator_id = au.id
-	LIMIT
-		100
-`
+// find builds a keyset query listing comments oldest first, with dc.id as the
+// tie breaker.
+func find(params *Params, key *pagination.Key) (string, []interface{}) {
+	query := strings.Builder{}
+	args := make([]interface{}, 0)
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
 
-func (r *repository) Find(ctx context.Context) ([]models.DiscussionComment, error) {
-	discussionComments := make([]models.DiscussionComment, 0)
+	query.WriteString(`
+		SELECT
+			dc.id, dc.discussion_id, COALESCE(dc.parent_id, 0), dc.depth, au.id, au.name, au.picture, dc.anonymous, COALESCE(dp.name, ''),
+			dc.description, dc.crisis, dc.created_at, dc.updated_at, dc.deleted_at, (SELECT COUNT(*) FROM Discussion_Comment r WHERE r.parent_id = dc.id AND r.hidden_at = 0)
+		FROM
+			Discussion_Comment dc
+		JOIN
+			App_User au
+		ON
+			dc.commentator_id = au.id
+		JOIN
+			Discussion d
+		ON
+			dc.discussion_id = d.id
+		LEFT JOIN
+			Discussion_Pseudonym dp
+		ON
+			dp.discussion_id = dc.discussion_id AND dp.user_id = dc.commentator_id
+		WHERE
+			dc.hidden_at = 0 AND d.hidden_at = 0`,
+	)
+
+	// Dynamic query
+	if params.DiscussionID != 0 {
+		fmt.Fprintf(&query, " AND dc.discussion_id = %s ", arg(params.DiscussionID))
+	}
+	if params.ParentID != 0 {
+		fmt.Fprintf(&query, " AND dc.parent_id = %s ", arg(params.ParentID))
+	} else if params.View != "" {
+		query.WriteString(" AND dc.parent_id IS NULL ")
+	}
 
-	rows, err := r.db.QueryContext(ctx, find)
+	// Cursor
+	op, order := params.Order(false)
+	if key != nil {
+		fmt.Fprintf(&query, " AND (dc.created_at, dc.id) %s (%s, %s) ", op, arg(key.Value), arg(key.ID))
+	}
+
+	// Limit
+	fmt.Fprintf(&query, " ORDER BY dc.created_at %s, dc.id %s LIMIT %s", order, order, arg(params.Limit))
+
+	return query.String(), args
+}
+
+// Find lists a page of comments, oldest first, of every discussion or only of
+// the one with DiscussionID. With a View, only the top level comments or the
+// direct replies to ParentID are listed.
+func (r *repository) Find(ctx context.Context, params *Params) ([]models.DiscussionComment, pagination.Cursor, error) {
+	var key *pagination.Key
+	err := params.Normalize(&key)
+	if err != nil {
+		return nil, pagination.Cursor{}, err
+	}
+
+	query, args := find(params, key)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return discussionComments, err
+		return nil, pagination.Cursor{}, err
 	}
+	defer rows.Close()
+
+	discussionComments := make([]models.DiscussionComment, 0)
 
 	for rows.Next() {
 		var discussionComment models.DiscussionComment
@@ -110,48 +187,88 @@ func (r *repository) Find(ctx context.Context) ([]models.DiscussionComment, erro
 		err := rows.Scan(
 			&discussionComment.ID,
 			&discussionComment.DiscussionID,
+			&discussionComment.ParentID,
+			&discussionComment.Depth,
 			&discussionComment.Commentator.ID,
 			&discussionComment.Commentator.Name,
 			&discussionComment.Commentator.Picture,
+			&discussionComment.Anonymous,
+			&discussionComment.Pseudonym,
 			&discussionComment.Description,
+			&discussionComment.Crisis,
 			&discussionComment.CreatedAt,
 			&discussionComment.UpdatedAt,
+			&discussionComment.DeletedAt,
+			&discussionComment.ReplyCount,
 		)
 		if err != nil {
-			return discussionComments, err
+			return nil, pagination.Cursor{}, err
 		}
 
 		discussionComments = append(discussionComments, discussionComment)
 	}
-	if rows.Err() != nil {
-		return discussionComments, err
+	if err := rows.Err(); err != nil {
+		return nil, pagination.Cursor{}, err
+	}
+
+	if params.Previous() {
+		pagination.Reverse(discussionComments)
+	}
+
+	var cursor pagination.Cursor
+	if len(discussionComments) > 0 {
+		first, last := discussionComments[0], discussionComments[len(discussionComments)-1]
+		cursor = pagination.NewCursor(
+			pagination.Key{Value: first.CreatedAt, ID: first.ID},
+			pagination.Key{Value: last.CreatedAt, ID: last.ID},
+		)
 	}
 
-	return discussionComments, nil
+	return discussionComments, cursor, nil
 }
 
-var findByDiscussionID = `
+var findReplies = `
+	WITH RECURSIVE thread AS (
+		SELECT id FROM Discussion_Comment WHERE parent_id = ANY($1) AND hidden_at = 0
+		UNION ALL
+		SELECT dc.id FROM Discussion_Comment dc JOIN thread t ON dc.parent_id = t.id WHERE dc.hidden_at = 0
+	)
 	SELECT
-		dc.id, dc.discussion_id, au.id, au.name, au.picture,  dc.description, dc.created_at, dc.updated_at
+		dc.id, dc.discussion_id, COALESCE(dc.parent_id, 0), dc.depth, au.id, au.name, au.picture, dc.anonymous, COALESCE(dp.name, ''),
+		dc.description, dc.crisis, dc.created_at, dc.updated_at, dc.deleted_at, (SELECT COUNT(*) FROM Discussion_Comment r WHERE r.parent_id = dc.id AND r.hidden_at = 0)
 	FROM
 		Discussion_Comment dc
 	JOIN
 		App_User au
 	ON
 		dc.commentator_id = au.id
+	JOIN
+		Discussion d
+	ON
+		dc.discussion_id = d.id
+	LEFT JOIN
+		Discussion_Pseudonym dp
+	ON
+		dp.discussion_id = dc.discussion_id AND dp.user_id = dc.commentator_id
 	WHERE
-		dc.discussion_id = $1
-	LIMIT
-		100
+		dc.id IN (SELECT id FROM thread) AND d.hidden_at = 0
+	ORDER BY
+		dc.created_at, dc.id
 `
 
-func (r *repository) FindByDiscussionID(ctx context.Context, discussionCommentID int64) ([]models.DiscussionComment, error) {
+// FindReplies finds the replies to the comments, and the replies to those,
+// down to the deepest, oldest first.
+func (r *repository) FindReplies(ctx context.Context, parentIDs []int64) ([]models.DiscussionComment, error) {
 	discussionComments := make([]models.DiscussionComment, 0)
+	if len(parentIDs) == 0 {
+		return discussionComments, nil
+	}
 
-	rows, err := r.db.QueryContext(ctx, findByDiscussionID, discussionCommentID)
+	rows, err := r.db.QueryContext(ctx, findReplies, pq.Array(parentIDs))
 	if err != nil {
-		return discussionComments, err
+		return nil, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		var discussionComment models.DiscussionComment
@@ -159,35 +276,96 @@ func (r *repository) FindByDiscussionID(ctx context.Context, discussionCommentID
 		err := rows.Scan(
 			&discussionComment.ID,
 			&discussionComment.DiscussionID,
+			&discussionComment.ParentID,
+			&discussionComment.Depth,
 			&discussionComment.Commentator.ID,
 			&discussionComment.Commentator.Name,
 			&discussionComment.Commentator.Picture,
+			&discussionComment.Anonymous,
+			&discussionComment.Pseudonym,
 			&discussionComment.Description,
+			&discussionComment.Crisis,
 			&discussionComment.CreatedAt,
 			&discussionComment.UpdatedAt,
+			&discussionComment.DeletedAt,
+			&discussionComment.ReplyCount,
 		)
 		if err != nil {
-			return discussionComments, err
+			return nil, err
 		}
 
 		discussionComments = append(discussionComments, discussionComment)
 	}
-	if rows.Err() != nil {
-		return discussionComments, err
-	}
 
-	return discussionComments, nil
+	return discussionComments, rows.Err()
 }
 
+var blank = `
+	UPDATE
+		Discussion_Comment dc
+	SET
+		description = '', deleted_at = $2
+	WHERE
+		dc.id = $1 AND EXISTS (SELECT 1 FROM Discussion_Comment r WHERE r.parent_id = dc.id)
+`
+
 var delete = `
 	DELETE FROM
 		Discussion_Comment
 	WHERE
 		id = $1
+	RETURNING
+		COALESCE(parent_id, 0)
 `
 
-func (r *repository) Delete(ctx context.Context, discussionCommentID int64) error {
-	_, err := r.db.ExecContext(ctx, delete, discussionCommentID)
+var deleteBlank = `
+	DELETE FROM
+		Discussion_Comment dc
+	WHERE
+		dc.id = $1 AND dc.deleted_at <> 0 AND NOT EXISTS (SELECT 1 FROM Discussion_Comment r WHERE r.parent_id = dc.id)
+	RETURNING
+		COALESCE(dc.parent_id, 0)
+`
 
-	return err
+// Delete removes the comment. A comment with replies is blanked instead, so
+// the replies keep their place in the thread, and blanked comments left
+// without replies are removed with their last reply.
+func (r *repository) Delete(ctx context.Context, discussionCommentID, deletedAt int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, blank, discussionCommentID, deletedAt)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected != 0 {
+		return tx.Commit()
+	}
+
+	var parentID int64
+	err = tx.QueryRowContext(ctx, delete, discussionCommentID).Scan(&parentID)
+	if err == sql.ErrNoRows {
+		return app.NewError(err, app.ENotFound)
+	} else if err != nil {
+		return err
+	}
+
+	for parentID != 0 {
+		err = tx.QueryRowContext(ctx, deleteBlank, parentID).Scan(&parentID)
+		if err == sql.ErrNoRows {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
@@ -0,0 +1,38 @@
+package discussioncomment
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bagus2x/recovy/pagination"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindKeyset(t *testing.T) {
+	key := pagination.Key{Value: 100, ID: 7}
+	params := Params{Params: pagination.Params{Limit: 5}, DiscussionID: 2}
+
+	query, args := find(&params, &key)
+	assert.Contains(t, query, "dc.discussion_id = $1")
+	assert.Contains(t, query, "(dc.created_at, dc.id) > ($2, $3)")
+	assert.True(t, strings.HasSuffix(query, "ORDER BY dc.created_at ASC, dc.id ASC LIMIT $4"))
+	assert.Equal(t, []interface{}{int64(2), int64(100), int64(7), int64(5)}, args)
+
+	params.Direction = pagination.DirectionPrevious
+	query, _ = find(&params, &key)
+	assert.Contains(t, query, "(dc.created_at, dc.id) < ($2, $3)")
+	assert.Contains(t, query, "ORDER BY dc.created_at DESC, dc.id DESC")
+}
+
+func TestFindThreaded(t *testing.T) {
+	params := Params{Params: pagination.Params{Limit: 5}, DiscussionID: 2, View: ViewTree}
+
+	query, _ := find(&params, nil)
+	assert.Contains(t, query, "dc.parent_id IS NULL")
+
+	params.ParentID = 9
+	query, args := find(&params, nil)
+	assert.Contains(t, query, "dc.parent_id = $2")
+	assert.NotContains(t, query, "dc.parent_id IS NULL")
+	assert.Equal(t, []interface{}{int64(2), int64(9), int64(5)}, args)
+}
@@ -2,30 +2,62 @@ package discussioncomment
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/auth"
+	"github.com/bagus2x/recovy/crisis"
 	"github.com/bagus2x/recovy/discussion"
+	"github.com/bagus2x/recovy/filter"
 	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/moderation"
+	"github.com/bagus2x/recovy/reaction"
+	"github.com/sirupsen/logrus"
 )
 
 type Service interface {
 	Create(ctx context.Context, req *CreateDiscussionCommentReq) (CreateDiscussionCommentResp, error)
-	GetByID(ctx context.Context, discussionCommentID int64) (GetDiscussionCommentResp, error)
-	GetByDiscussionID(ctx context.Context, disussionCommentID int64) ([]GetDiscussionCommentResp, error)
-	Get(ctx context.Context) ([]GetDiscussionCommentResp, error)
+	GetByID(ctx context.Context, discussionCommentID, viewerID int64) (GetDiscussionCommentResp, error)
+	GetByDiscussionID(ctx context.Context, discussionID int64, params *Params) (GetDiscussionCommentsResp, error)
+	GetReplies(ctx context.Context, discussionCommentID int64, params *Params) (GetDiscussionCommentsResp, error)
+	Get(ctx context.Context, params *Params) (GetDiscussionCommentsResp, error)
 	Delete(ctx context.Context, discussionCommentID, commentatorID int64) error
 }
 
 type service struct {
 	discussionCommentRepo Repository
 	discussionRepo        discussion.Repository
+	userRepo              auth.Repository
+	reactionService       reaction.Service
+	moderationService     moderation.Service
+	detector              *crisis.Detector
+	contentFilter         *filter.Pipeline
+	maxDepth              int
 }
 
-func NewService(discussionCommentRepo Repository, discussionRepo discussion.Repository) Service {
+// NewService returns a Service that accepts replies nested at most maxDepth
+// deep, top level comments being at depth 0.
+func NewService(
+	discussionCommentRepo Repository,
+	discussionRepo discussion.Repository,
+	userRepo auth.Repository,
+	reactionService reaction.Service,
+	moderationService moderation.Service,
+	detector *crisis.Detector,
+	contentFilter *filter.Pipeline,
+	maxDepth int,
+) Service {
 	return &service{
 		discussionCommentRepo: discussionCommentRepo,
 		discussionRepo:        discussionRepo,
+		userRepo:              userRepo,
+		reactionService:       reactionService,
+		moderationService:     moderationService,
+		detector:              detector,
+		contentFilter:         contentFilter,
+		maxDepth:              maxDepth,
 	}
 }
 
@@ -35,41 +67,120 @@ func (s *service) Create(ctx context.Context, req *CreateDiscussionCommentReq) (
 		return CreateDiscussionCommentResp{}, err
 	}
 
-	discussion, err := s.discussionRepo.FindByID(ctx, req.DiscussionID)
-	if app.ErrorCode(err) == app.ENotFound || discussion.ID == 0 {
+	commentator, err := s.userRepo.FindByID(ctx, req.CommentatorID)
+	if err != nil {
+		return CreateDiscussionCommentResp{}, err
+	}
+	if commentator.IsSuspended(time.Now().Unix()) {
+		return CreateDiscussionCommentResp{}, app.NewError(nil, app.EForbidden, "Your account is suspended")
+	}
+
+	topic, err := s.discussionRepo.FindByID(ctx, req.DiscussionID)
+	if app.ErrorCode(err) == app.ENotFound || topic.ID == 0 {
 		return CreateDiscussionCommentResp{}, app.NewError(err, app.ENotFound, "Discussion not found")
 	} else if err != nil {
 		return CreateDiscussionCommentResp{}, err
 	}
 
+	depth := 0
+	if req.ParentID != 0 {
+		parent, err := s.discussionCommentRepo.FindByID(ctx, req.ParentID)
+		if app.ErrorCode(err) == app.ENotFound || parent.ID == 0 || parent.DeletedAt != 0 {
+			return CreateDiscussionCommentResp{}, app.NewError(err, app.ENotFound, "Parent comment not found")
+		} else if err != nil {
+			return CreateDiscussionCommentResp{}, err
+		}
+
+		if parent.DiscussionID != req.DiscussionID {
+			return CreateDiscussionCommentResp{}, app.NewError(nil, app.EBadRequest, "Parent comment is not in the discussion")
+		}
+		if parent.Depth >= s.maxDepth {
+			return CreateDiscussionCommentResp{}, app.NewError(nil, app.EBadRequest, fmt.Sprintf("Replies can be nested at most %d deep", s.maxDepth))
+		}
+
+		depth = parent.Depth + 1
+	}
+
+	// Crisis terms are looked for in the text as written, before the filter
+	// can mask or reject it.
+	match := s.detector.Detect(req.Description)
+
+	post := filter.Post{
+		ContentType: models.ContentComment,
+		AuthorID:    req.CommentatorID,
+		Body:        req.Description,
+		Crisis:      match.Flagged(),
+	}
+	filtered, err := s.contentFilter.Run(ctx, &post)
+	if err != nil {
+		return CreateDiscussionCommentResp{}, err
+	}
+
 	discussionComment := models.DiscussionComment{
 		DiscussionID: req.DiscussionID,
+		ParentID:     req.ParentID,
+		Depth:        depth,
 		Commentator: models.User{
 			ID: req.CommentatorID,
 		},
-		Description: req.Description,
+		Anonymous:   req.Anonymous,
+		Description: post.Body,
+		Crisis:      match.Language,
 		CreatedAt:   time.Now().Unix(),
 		UpdatedAt:   time.Now().Unix(),
 	}
+	if filtered.Held {
+		discussionComment.HiddenAt = discussionComment.CreatedAt
+	}
+
+	if discussionComment.Anonymous {
+		discussionComment.Pseudonym, err = discussion.Pseudonym(ctx, s.discussionRepo, req.DiscussionID, req.CommentatorID)
+		if err != nil {
+			return CreateDiscussionCommentResp{}, err
+		}
+	}
 
 	err = s.discussionCommentRepo.Create(ctx, &discussionComment)
 	if err != nil {
 		return CreateDiscussionCommentResp{}, err
 	}
 
+	if filtered.Held {
+		err = s.moderationService.Hold(ctx, models.ContentComment, discussionComment.ID, strings.Join(filtered.Reasons, "; "))
+		if err != nil {
+			return CreateDiscussionCommentResp{}, err
+		}
+	}
+
+	// The comment is posted already, so a failed flag must not keep the
+	// commentator from seeing the crisis resources.
+	if match.Flagged() {
+		err = s.moderationService.Flag(ctx, models.ContentComment, discussionComment.ID, "Crisis terms: "+strings.Join(match.Terms, ", "))
+		if err != nil {
+			logrus.Error(err)
+		}
+	}
+
 	res := CreateDiscussionCommentResp{
-		ID:            discussionComment.ID,
-		DiscussionID:  discussionComment.DiscussionID,
-		CommentatorID: discussionComment.Commentator.ID,
-		Description:   discussionComment.Description,
-		CreatedAt:     discussionComment.CreatedAt,
-		UpdatedAt:     discussionComment.UpdatedAt,
+		ID:              discussionComment.ID,
+		DiscussionID:    discussionComment.DiscussionID,
+		ParentID:        discussionComment.ParentID,
+		Depth:           discussionComment.Depth,
+		CommentatorID:   discussionComment.Commentator.ID,
+		Anonymous:       discussionComment.Anonymous,
+		Pseudonym:       discussionComment.Pseudonym,
+		Description:     discussionComment.Description,
+		CrisisResources: s.detector.Resources(discussionComment.Crisis),
+		Held:            filtered.Held,
+		HeldReasons:     filtered.Reasons,
+		CreatedAt:       discussionComment.CreatedAt,
+		UpdatedAt:       discussionComment.UpdatedAt,
 	}
 
 	return res, nil
 }
 
-func (s *service) GetByID(ctx context.Context, discussionCommentID int64) (GetDiscussionCommentResp, error) {
+func (s *service) GetByID(ctx context.Context, discussionCommentID, viewerID int64) (GetDiscussionCommentResp, error) {
 	discussionComment, err := s.discussionCommentRepo.FindByID(ctx, discussionCommentID)
 	if app.ErrorCode(err) == app.ENotFound {
 		return GetDiscussionCommentResp{}, app.NewError(err, app.ENotFound, "DiscussionComment not found")
@@ -77,83 +188,202 @@ func (s *service) GetByID(ctx context.Context, discussionCommentID int64) (GetDi
 		return GetDiscussionCommentResp{}, err
 	}
 
-	resp := GetDiscussionCommentResp{
-		ID:           discussionComment.ID,
-		DiscussionID: discussionComment.DiscussionID,
-		Commentator: Commentator{
-			ID:      discussionComment.Commentator.ID,
-			Name:    discussionComment.Commentator.Name,
-			Picture: discussionComment.Commentator.Picture,
-		},
-		Description: discussionComment.Description,
-		CreatedAt:   discussionComment.CreatedAt,
-		UpdatedAt:   discussionComment.UpdatedAt,
+	resp, err := s.discussionCommentsResp(ctx, []models.DiscussionComment{discussionComment}, viewerID)
+	if err != nil {
+		return GetDiscussionCommentResp{}, err
 	}
 
-	return resp, nil
+	return resp[0], nil
+}
+
+func (s *service) GetByDiscussionID(ctx context.Context, discussionID int64, params *Params) (GetDiscussionCommentsResp, error) {
+	params.DiscussionID = discussionID
+
+	return s.Get(ctx, params)
 }
 
-func (s *service) GetByDiscussionID(ctx context.Context, disussionCommentID int64) ([]GetDiscussionCommentResp, error) {
-	discussionComments, err := s.discussionCommentRepo.FindByDiscussionID(ctx, disussionCommentID)
+// GetReplies lists the replies to the comment, flat unless params has a View.
+func (s *service) GetReplies(ctx context.Context, discussionCommentID int64, params *Params) (GetDiscussionCommentsResp, error) {
+	discussionComment, err := s.discussionCommentRepo.FindByID(ctx, discussionCommentID)
+	if app.ErrorCode(err) == app.ENotFound || discussionComment.ID == 0 {
+		return GetDiscussionCommentsResp{}, app.NewError(err, app.ENotFound, "Discussion Comment not found")
+	} else if err != nil {
+		return GetDiscussionCommentsResp{}, err
+	}
+
+	params.DiscussionID = discussionComment.DiscussionID
+	params.ParentID = discussionComment.ID
+	if params.View == "" {
+		params.View = ViewFlat
+	}
+
+	return s.Get(ctx, params)
+}
+
+func (s *service) Get(ctx context.Context, params *Params) (GetDiscussionCommentsResp, error) {
+	if params.View != "" && params.View != ViewFlat && params.View != ViewTree {
+		return GetDiscussionCommentsResp{}, app.NewError(nil, app.EBadRequest, "View must be one of [flat tree]")
+	}
+
+	discussionComments, cursor, err := s.discussionCommentRepo.Find(ctx, params)
 	if err != nil {
-		return make([]GetDiscussionCommentResp, 0), err
+		return GetDiscussionCommentsResp{}, err
+	}
+
+	if params.View == "" {
+		resp, err := s.discussionCommentsResp(ctx, discussionComments, params.ViewerID)
+		if err != nil {
+			return GetDiscussionCommentsResp{}, err
+		}
+
+		return GetDiscussionCommentsResp{Cursor: cursor, DiscussionComments: resp}, nil
 	}
 
-	resp := make([]GetDiscussionCommentResp, 0)
+	ids := make([]int64, 0, len(discussionComments))
 	for _, discussionComment := range discussionComments {
-		resp = append(resp, GetDiscussionCommentResp{
-			ID:           discussionComment.ID,
-			DiscussionID: discussionComment.DiscussionID,
-			Commentator: Commentator{
-				ID:      discussionComment.Commentator.ID,
-				Name:    discussionComment.Commentator.Name,
-				Picture: discussionComment.Commentator.Picture,
-			},
-			Description: discussionComment.Description,
-			CreatedAt:   discussionComment.CreatedAt,
-			UpdatedAt:   discussionComment.UpdatedAt,
-		})
+		ids = append(ids, discussionComment.ID)
 	}
 
-	return resp, nil
+	replies, err := s.discussionCommentRepo.FindReplies(ctx, ids)
+	if err != nil {
+		return GetDiscussionCommentsResp{}, err
+	}
+
+	resp, err := s.discussionCommentsResp(ctx, append(discussionComments, replies...), params.ViewerID)
+	if err != nil {
+		return GetDiscussionCommentsResp{}, err
+	}
+
+	return GetDiscussionCommentsResp{
+		Cursor:             cursor,
+		DiscussionComments: thread(resp[:len(discussionComments)], resp[len(discussionComments):], params.View),
+	}, nil
 }
 
-func (s *service) Get(ctx context.Context) ([]GetDiscussionCommentResp, error) {
-	discussionComments, err := s.discussionCommentRepo.Find(ctx)
+func (s *service) Delete(ctx context.Context, discussionCommentID, commentatorID int64) error {
+	discussionComment, err := s.discussionCommentRepo.FindByID(ctx, discussionCommentID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return app.NewError(err, app.ENotFound, "Discussion Comment not found")
+	} else if err != nil {
+		return err
+	}
+
+	if discussionComment.DeletedAt != 0 {
+		return app.NewError(nil, app.ENotFound, "Discussion Comment not found")
+	}
+
+	if discussionComment.Commentator.ID != commentatorID {
+		return app.NewError(nil, app.EForbidden, "Forbidden access, comment not found")
+	}
+
+	err = s.discussionCommentRepo.Delete(ctx, discussionCommentID, time.Now().Unix())
+	if app.ErrorCode(err) == app.ENotFound {
+		return app.NewError(err, app.ENotFound, "Discussion Comment not found")
+	}
+
+	return err
+}
+
+// discussionCommentsResp adds the reactions to the comments, with those of
+// the viewer, and the crisis resources of those in crisis.
+func (s *service) discussionCommentsResp(ctx context.Context, discussionComments []models.DiscussionComment, viewerID int64) ([]GetDiscussionCommentResp, error) {
+	ids := make([]int64, 0, len(discussionComments))
+	for _, discussionComment := range discussionComments {
+		ids = append(ids, discussionComment.ID)
+	}
+
+	summaries, err := s.reactionService.Summaries(ctx, models.ContentComment, viewerID, ids...)
+	if err != nil {
+		return make([]GetDiscussionCommentResp, 0), err
+	}
+
+	moderator, err := s.isModerator(ctx, viewerID)
 	if err != nil {
 		return make([]GetDiscussionCommentResp, 0), err
 	}
 
-	resp := make([]GetDiscussionCommentResp, 0)
+	resp := make([]GetDiscussionCommentResp, 0, len(discussionComments))
 	for _, discussionComment := range discussionComments {
-		resp = append(resp, GetDiscussionCommentResp{
+		comment := GetDiscussionCommentResp{
 			ID:           discussionComment.ID,
 			DiscussionID: discussionComment.DiscussionID,
+			ParentID:     discussionComment.ParentID,
+			Depth:        discussionComment.Depth,
+			ReplyCount:   discussionComment.ReplyCount,
 			Commentator: Commentator{
 				ID:      discussionComment.Commentator.ID,
 				Name:    discussionComment.Commentator.Name,
 				Picture: discussionComment.Commentator.Picture,
 			},
-			Description: discussionComment.Description,
-			CreatedAt:   discussionComment.CreatedAt,
-			UpdatedAt:   discussionComment.UpdatedAt,
-		})
+			Anonymous:       discussionComment.Anonymous,
+			Description:     discussionComment.Description,
+			CrisisResources: s.detector.Resources(discussionComment.Crisis),
+			CreatedAt:       discussionComment.CreatedAt,
+			UpdatedAt:       discussionComment.UpdatedAt,
+			Summary:         summaries[discussionComment.ID],
+		}
+		if discussionComment.Anonymous {
+			if moderator {
+				commentator := comment.Commentator
+				comment.RealCommentator = &commentator
+			}
+			comment.Commentator = Commentator{
+				Name:    discussionComment.Pseudonym,
+				Picture: discussion.Avatar(discussionComment.Pseudonym),
+			}
+		}
+		if discussionComment.DeletedAt != 0 {
+			comment.Commentator = Commentator{}
+			comment.Description = Deleted
+			comment.Deleted = true
+			comment.CrisisResources = nil
+		}
+
+		resp = append(resp, comment)
 	}
 
 	return resp, nil
 }
 
-func (s *service) Delete(ctx context.Context, discussionCommentID, commentatorID int64) error {
-	discussionComment, err := s.discussionCommentRepo.FindByID(ctx, discussionCommentID)
+func (s *service) isModerator(ctx context.Context, userID int64) (bool, error) {
+	if userID == 0 {
+		return false, nil
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
 	if app.ErrorCode(err) == app.ENotFound {
-		return app.NewError(err, app.ENotFound, "Discussion Comment not found")
+		return false, nil
 	} else if err != nil {
-		return err
+		return false, err
 	}
 
-	if discussionComment.Commentator.ID != commentatorID {
-		return app.NewError(nil, app.EForbidden, "Forbidden access, comment not found")
+	return user.IsModerator(), nil
+}
+
+// thread puts the replies, oldest first, under the comments they answer:
+// nested in Replies for the tree view, or right after them for the flat view.
+func thread(discussionComments, replies []GetDiscussionCommentResp, view string) []GetDiscussionCommentResp {
+	children := make(map[int64][]GetDiscussionCommentResp)
+	for _, reply := range replies {
+		children[reply.ParentID] = append(children[reply.ParentID], reply)
+	}
+
+	var walk func(discussionComments []GetDiscussionCommentResp) []GetDiscussionCommentResp
+	walk = func(discussionComments []GetDiscussionCommentResp) []GetDiscussionCommentResp {
+		res := make([]GetDiscussionCommentResp, 0, len(discussionComments))
+		for _, discussionComment := range discussionComments {
+			if view == ViewTree {
+				discussionComment.Replies = walk(children[discussionComment.ID])
+				res = append(res, discussionComment)
+				continue
+			}
+
+			res = append(res, discussionComment)
+			res = append(res, walk(children[discussionComment.ID])...)
+		}
+
+		return res
 	}
 
-	return s.discussionCommentRepo.Delete(ctx, discussionCommentID)
+	return walk(discussionComments)
 }
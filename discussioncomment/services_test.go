@@ -0,0 +1,32 @@
+package discussioncomment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThread(t *testing.T) {
+	comments := []GetDiscussionCommentResp{{ID: 1}, {ID: 2}}
+	replies := []GetDiscussionCommentResp{
+		{ID: 3, ParentID: 1, Depth: 1},
+		{ID: 4, ParentID: 2, Depth: 1},
+		{ID: 5, ParentID: 3, Depth: 2},
+		{ID: 6, ParentID: 1, Depth: 1},
+	}
+
+	flat := thread(comments, replies, ViewFlat)
+	ids := make([]int64, 0, len(flat))
+	for _, comment := range flat {
+		ids = append(ids, comment.ID)
+		assert.Empty(t, comment.Replies)
+	}
+	assert.Equal(t, []int64{1, 3, 5, 6, 2, 4}, ids)
+
+	tree := thread(comments, replies, ViewTree)
+	assert.Len(t, tree, 2)
+	assert.Len(t, tree[0].Replies, 2)
+	assert.Equal(t, int64(5), tree[0].Replies[0].Replies[0].ID)
+	assert.Equal(t, int64(6), tree[0].Replies[1].ID)
+	assert.Equal(t, int64(4), tree[1].Replies[0].ID)
+}
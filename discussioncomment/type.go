@@ -2,12 +2,28 @@ package discussioncomment
 
 import (
 	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/crisis"
+	"github.com/bagus2x/recovy/pagination"
+	"github.com/bagus2x/recovy/reaction"
 	"github.com/go-playground/validator/v10"
 )
 
+const (
+	ViewFlat = "flat"
+	ViewTree = "tree"
+)
+
+// Deleted replaces the text of a deleted comment that still has replies.
+const Deleted = "[deleted]"
+
+// CreateDiscussionCommentReq replies to the comment with ParentID, or to the
+// discussion when it is 0. An Anonymous comment is shown under the
+// commentator's pseudonym in the discussion.
 type CreateDiscussionCommentReq struct {
 	DiscussionID  int64  `json:"discussionID" validate:"required,gt=0"`
+	ParentID      int64  `json:"parentID" validate:"gte=0"`
 	CommentatorID int64  `json:"commentatorID" validate:"required,gt=0" `
+	Anonymous     bool   `json:"anonymous"`
 	Description   string `json:"description" validate:"required"`
 }
 
@@ -18,13 +34,23 @@ func (r *CreateDiscussionCommentReq) Validate() error {
 	return app.ValidateAndTranslate(validate, err)
 }
 
+// CreateDiscussionCommentResp has CrisisResources when the comment mentions
+// suicide or self-harm. A Held comment is hidden until a moderator reviews
+// it, for HeldReasons.
 type CreateDiscussionCommentResp struct {
-	ID            int64  `json:"id"`
-	DiscussionID  int64  `json:"discussion_id"`
-	CommentatorID int64  `json:"commentator_id"`
-	Description   string `json:"description"`
-	CreatedAt     int64  `json:"createdAt"`
-	UpdatedAt     int64  `json:"updatedAt"`
+	ID              int64             `json:"id"`
+	DiscussionID    int64             `json:"discussion_id"`
+	ParentID        int64             `json:"parentID"`
+	Depth           int               `json:"depth"`
+	CommentatorID   int64             `json:"commentator_id"`
+	Anonymous       bool              `json:"anonymous"`
+	Pseudonym       string            `json:"pseudonym,omitempty"`
+	Description     string            `json:"description"`
+	CrisisResources *crisis.Resources `json:"crisisResources,omitempty"`
+	Held            bool              `json:"held"`
+	HeldReasons     []string          `json:"heldReasons,omitempty"`
+	CreatedAt       int64             `json:"createdAt"`
+	UpdatedAt       int64             `json:"updatedAt"`
 }
 
 type Commentator struct {
@@ -33,11 +59,43 @@ type Commentator struct {
 	Picture string `json:"picture"`
 }
 
+// GetDiscussionCommentResp is a comment and, in the tree view, its replies.
+// A deleted comment keeps its place as long as it has replies, with Deleted
+// as its description and no commentator. An anonymous comment shows the
+// pseudonym of the commentator as Commentator, and RealCommentator to
+// moderators only. CrisisResources are shown with a comment that mentions
+// suicide or self-harm.
 type GetDiscussionCommentResp struct {
-	ID           int64       `json:"id"`
-	DiscussionID int64       `json:"discussionID"`
-	Commentator  Commentator `json:"commentator"`
-	Description  string      `json:"description"`
-	CreatedAt    int64       `json:"createdAt"`
-	UpdatedAt    int64       `json:"updatedAt"`
+	ID              int64                      `json:"id"`
+	DiscussionID    int64                      `json:"discussionID"`
+	ParentID        int64                      `json:"parentID"`
+	Depth           int                        `json:"depth"`
+	ReplyCount      int64                      `json:"replyCount"`
+	Commentator     Commentator                `json:"commentator"`
+	Anonymous       bool                       `json:"anonymous"`
+	RealCommentator *Commentator               `json:"realCommentator,omitempty"`
+	Description     string                     `json:"description"`
+	Deleted         bool                       `json:"deleted"`
+	CrisisResources *crisis.Resources          `json:"crisisResources,omitempty"`
+	CreatedAt       int64                      `json:"createdAt"`
+	UpdatedAt       int64                      `json:"updatedAt"`
+	Replies         []GetDiscussionCommentResp `json:"replies,omitempty"`
+	reaction.Summary
+}
+
+// Params filters the comment list. DiscussionID 0 lists the comments of every
+// discussion. Without a View the comments are listed as they were posted;
+// with one, a page holds the top level comments, or the direct replies to
+// ParentID, each followed by all of its replies.
+type Params struct {
+	pagination.Params
+	ViewerID     int64
+	DiscussionID int64
+	ParentID     int64
+	View         string
+}
+
+type GetDiscussionCommentsResp struct {
+	Cursor             pagination.Cursor          `json:"cursor"`
+	DiscussionComments []GetDiscussionCommentResp `json:"discussionComments"`
 }
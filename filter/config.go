@@ -0,0 +1,123 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Config sets the action of each filter; an empty action turns it off.
+// Profanity words are listed by language. Links is the most links a post can
+// have. Repeats is how many times the same text can be posted within Window
+// seconds.
+type Config struct {
+	Profanity struct {
+		Action string              `json:"action"`
+		Words  map[string][]string `json:"words"`
+	} `json:"profanity"`
+	Links struct {
+		Action string `json:"action"`
+		Max    int    `json:"max"`
+	} `json:"links"`
+	Repeats struct {
+		Action string `json:"action"`
+		Max    int64  `json:"max"`
+		Window int64  `json:"window"`
+	} `json:"repeats"`
+}
+
+// DefaultConfig masks English and Indonesian profanity, holds posts of more
+// than 3 links for review and rejects the same text posted twice in 10
+// minutes.
+var DefaultConfig = func() Config {
+	var cfg Config
+
+	cfg.Profanity.Action = ActionMask
+	cfg.Profanity.Words = map[string][]string{
+		"en": {
+			"asshole", "bastard", "bitch", "bullshit", "cunt", "dick", "fuck", "fucked", "fucker",
+			"fucking", "motherfucker", "shit", "shitty", "slut", "whore", "wanker",
+		},
+		"id": {
+			"bajingan", "bangsat", "brengsek", "goblok", "jancok", "jancuk", "kampret", "kontol",
+			"memek", "ngentot", "pantek", "tolol",
+		},
+	}
+	cfg.Links.Action = ActionHold
+	cfg.Links.Max = 3
+	cfg.Repeats.Action = ActionReject
+	cfg.Repeats.Max = 1
+	cfg.Repeats.Window = 10 * 60
+
+	return cfg
+}()
+
+// Load returns the config in the JSON file at path.
+func Load(path string) (Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var cfg Config
+	err = json.Unmarshal(b, &cfg)
+	if err != nil {
+		return Config{}, fmt.Errorf("filter: %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// New returns the pipeline of the filters the config turns on: profanity,
+// then links, then repeats.
+func New(cfg Config, repo Repository) (*Pipeline, error) {
+	filters := make([]Filter, 0)
+
+	if cfg.Profanity.Action != "" {
+		err := checkAction("profanity", cfg.Profanity.Action, true)
+		if err != nil {
+			return nil, err
+		}
+
+		words := make([]string, 0)
+		for _, list := range cfg.Profanity.Words {
+			words = append(words, list...)
+		}
+		filters = append(filters, NewProfanity(cfg.Profanity.Action, words))
+	}
+
+	if cfg.Links.Action != "" {
+		err := checkAction("links", cfg.Links.Action, true)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, NewLinks(cfg.Links.Action, cfg.Links.Max))
+	}
+
+	if cfg.Repeats.Action != "" {
+		err := checkAction("repeats", cfg.Repeats.Action, false)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Repeats.Max < 1 || cfg.Repeats.Window < 1 {
+			return nil, fmt.Errorf("filter: repeats needs a max and a window of at least 1")
+		}
+		filters = append(filters, NewRepeats(repo, cfg.Repeats.Action, cfg.Repeats.Max, time.Duration(cfg.Repeats.Window)*time.Second))
+	}
+
+	return NewPipeline(filters...), nil
+}
+
+func checkAction(filter, action string, maskable bool) error {
+	switch action {
+	case ActionReject, ActionHold:
+		return nil
+	case ActionMask:
+		if maskable {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("filter: %s cannot %q", filter, action)
+}
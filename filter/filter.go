@@ -0,0 +1,92 @@
+// Package filter checks the text users post for profanity and spam before it
+// is stored. Each filter of a pipeline rejects, masks or holds a post for
+// review, as configured.
+package filter
+
+import (
+	"context"
+
+	"github.com/bagus2x/recovy/app"
+)
+
+const (
+	ActionReject = "reject"
+	ActionMask   = "mask"
+	ActionHold   = "hold"
+)
+
+// Post is the text of a discussion, comment or article by AuthorID. Title is
+// empty for comments. ID is 0 for a post that is not stored yet. Crisis is
+// set for posts with crisis terms, which are held rather than rejected so
+// they still reach the moderators.
+type Post struct {
+	ContentType string
+	ID          int64
+	AuthorID    int64
+	Title       string
+	Body        string
+	Crisis      bool
+}
+
+// Verdict is what a filter decided on a post, and why. An empty Action lets
+// the post through.
+type Verdict struct {
+	Action string
+	Reason string
+}
+
+// Filter checks a post. A filter that masks rewrites the post itself.
+type Filter interface {
+	Check(ctx context.Context, post *Post) (Verdict, error)
+}
+
+// Result tells whether the post is held for review, and why. Masked holds
+// why parts of the post were masked.
+type Result struct {
+	Held    bool
+	Reasons []string
+	Masked  []string
+}
+
+type Pipeline struct {
+	filters []Filter
+}
+
+func NewPipeline(filters ...Filter) *Pipeline {
+	return &Pipeline{
+		filters: filters,
+	}
+}
+
+// Run passes the post through the filters in order, each seeing the post as
+// masked by those before it. The first filter that rejects the post stops it
+// with its reason as an EBadRequest error, unless the post is in crisis.
+func (p *Pipeline) Run(ctx context.Context, post *Post) (Result, error) {
+	res := Result{
+		Reasons: make([]string, 0),
+		Masked:  make([]string, 0),
+	}
+
+	for _, filter := range p.filters {
+		verdict, err := filter.Check(ctx, post)
+		if err != nil {
+			return Result{}, err
+		}
+
+		if verdict.Action == ActionReject && post.Crisis {
+			verdict.Action = ActionHold
+		}
+
+		switch verdict.Action {
+		case ActionReject:
+			return Result{}, app.NewError(nil, app.EBadRequest, verdict.Reason)
+		case ActionMask:
+			res.Masked = append(res.Masked, verdict.Reason)
+		case ActionHold:
+			res.Held = true
+			res.Reasons = append(res.Reasons, verdict.Reason)
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,98 @@
+package filter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubRepository struct {
+	repeats int64
+}
+
+func (r stubRepository) CountRepeats(ctx context.Context, authorID int64, text string, since int64) (int64, error) {
+	return r.repeats, nil
+}
+
+func TestProfanityMask(t *testing.T) {
+	post := Post{Title: "What the SH1T", Body: "Dasar goblok, shitake mushrooms are fine"}
+	verdict, err := NewProfanity(ActionMask, []string{"shit", "goblok"}).Check(context.Background(), &post)
+	require.NoError(t, err)
+	assert.Equal(t, ActionMask, verdict.Action)
+	assert.Equal(t, "What the ****", post.Title)
+	assert.Equal(t, "Dasar ******, shitake mushrooms are fine", post.Body)
+
+	post = Post{Body: "All good here"}
+	verdict, err = NewProfanity(ActionReject, []string{"shit"}).Check(context.Background(), &post)
+	require.NoError(t, err)
+	assert.Equal(t, Verdict{}, verdict)
+}
+
+func TestLinks(t *testing.T) {
+	post := Post{Body: "see https://a.example and www.b.example"}
+	verdict, err := NewLinks(ActionHold, 2).Check(context.Background(), &post)
+	require.NoError(t, err)
+	assert.Equal(t, Verdict{}, verdict)
+
+	verdict, err = NewLinks(ActionMask, 1).Check(context.Background(), &post)
+	require.NoError(t, err)
+	assert.Equal(t, ActionMask, verdict.Action)
+	assert.Equal(t, "see "+LinkRemoved+" and "+LinkRemoved, post.Body)
+}
+
+func TestRepeats(t *testing.T) {
+	filter := NewRepeats(stubRepository{repeats: 1}, ActionReject, 1, time.Minute)
+
+	verdict, err := filter.Check(context.Background(), &Post{Body: "buy now"})
+	require.NoError(t, err)
+	assert.Equal(t, ActionReject, verdict.Action)
+
+	verdict, err = filter.Check(context.Background(), &Post{ID: 3, Body: "buy now"})
+	require.NoError(t, err)
+	assert.Equal(t, Verdict{}, verdict)
+}
+
+func TestPipeline(t *testing.T) {
+	pipeline := NewPipeline(
+		NewProfanity(ActionMask, []string{"fuck"}),
+		NewLinks(ActionHold, 0),
+	)
+
+	post := Post{Body: "fuck this, read http://spam.example"}
+	res, err := pipeline.Run(context.Background(), &post)
+	require.NoError(t, err)
+	assert.True(t, res.Held)
+	assert.Equal(t, []string{"Text can have at most 0 links"}, res.Reasons)
+	assert.Equal(t, []string{"Profanity was masked"}, res.Masked)
+	assert.Equal(t, "**** this, read http://spam.example", post.Body)
+
+	pipeline = NewPipeline(NewRepeats(stubRepository{repeats: 2}, ActionReject, 2, time.Minute))
+	_, err = pipeline.Run(context.Background(), &Post{Body: "hello"})
+	assert.Equal(t, app.EBadRequest, app.ErrorCode(err))
+	assert.Equal(t, []string{"You posted the same text recently"}, app.ErrorMessage(err))
+
+	// A post in crisis is held instead, so it reaches the moderators.
+	res, err = pipeline.Run(context.Background(), &Post{Body: "hello", Crisis: true})
+	require.NoError(t, err)
+	assert.True(t, res.Held)
+	assert.Equal(t, []string{"You posted the same text recently"}, res.Reasons)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(DefaultConfig, stubRepository{})
+	assert.NoError(t, err)
+
+	cfg := DefaultConfig
+	cfg.Repeats.Action = ActionMask
+	_, err = New(cfg, stubRepository{})
+	assert.Error(t, err)
+
+	cfg = DefaultConfig
+	cfg.Links.Action = "delete"
+	_, err = New(cfg, stubRepository{})
+	assert.Error(t, err)
+}
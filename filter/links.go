@@ -0,0 +1,43 @@
+package filter
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+var link = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+
+// LinkRemoved replaces the links of a post with too many of them when the
+// links filter masks.
+const LinkRemoved = "[link removed]"
+
+type links struct {
+	action string
+	max    int
+}
+
+// NewLinks returns a Filter of posts with more than max links, as spam often
+// is. Masking removes all the links of such a post.
+func NewLinks(action string, max int) Filter {
+	return &links{
+		action: action,
+		max:    max,
+	}
+}
+
+func (l *links) Check(ctx context.Context, post *Post) (Verdict, error) {
+	count := len(link.FindAllStringIndex(post.Title, -1)) + len(link.FindAllStringIndex(post.Body, -1))
+	if count <= l.max {
+		return Verdict{}, nil
+	}
+
+	if l.action != ActionMask {
+		return Verdict{Action: l.action, Reason: fmt.Sprintf("Text can have at most %d links", l.max)}, nil
+	}
+
+	post.Title = link.ReplaceAllString(post.Title, LinkRemoved)
+	post.Body = link.ReplaceAllString(post.Body, LinkRemoved)
+
+	return Verdict{Action: ActionMask, Reason: "Links were removed"}, nil
+}
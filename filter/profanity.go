@@ -0,0 +1,97 @@
+package filter
+
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+// leet undoes the usual letter swaps, so that "sh1t" reads as "shit".
+var leet = map[rune]rune{
+	'0': 'o',
+	'1': 'i',
+	'3': 'e',
+	'4': 'a',
+	'5': 's',
+	'7': 't',
+	'@': 'a',
+	'$': 's',
+}
+
+type profanity struct {
+	action string
+	words  map[string]bool
+}
+
+// NewProfanity returns a Filter of the words, matched whole and regardless of
+// case or letter swaps. Masking replaces every letter of the word with "*".
+func NewProfanity(action string, words []string) Filter {
+	p := &profanity{
+		action: action,
+		words:  make(map[string]bool),
+	}
+	for _, word := range words {
+		p.words[unleet(strings.ToLower(word))] = true
+	}
+
+	return p
+}
+
+func (p *profanity) Check(ctx context.Context, post *Post) (Verdict, error) {
+	title, titleFound := p.mask(post.Title)
+	body, bodyFound := p.mask(post.Body)
+	if !titleFound && !bodyFound {
+		return Verdict{}, nil
+	}
+
+	if p.action != ActionMask {
+		return Verdict{Action: p.action, Reason: "Text must not contain profanity"}, nil
+	}
+
+	post.Title, post.Body = title, body
+
+	return Verdict{Action: ActionMask, Reason: "Profanity was masked"}, nil
+}
+
+// mask returns the text with its profane words masked, and whether there
+// were any.
+func (p *profanity) mask(text string) (string, bool) {
+	runes := []rune(text)
+	found := false
+
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			i++
+			continue
+		}
+
+		j := i
+		for j < len(runes) && isWordRune(runes[j]) {
+			j++
+		}
+
+		if p.words[unleet(strings.ToLower(string(runes[i:j])))] {
+			found = true
+			for k := i; k < j; k++ {
+				runes[k] = '*'
+			}
+		}
+		i = j
+	}
+
+	return string(runes), found
+}
+
+func isWordRune(r rune) bool {
+	_, ok := leet[r]
+	return ok || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func unleet(word string) string {
+	return strings.Map(func(r rune) rune {
+		if l, ok := leet[r]; ok {
+			return l
+		}
+		return r
+	}, word)
+}
@@ -0,0 +1,40 @@
+package filter
+
+import (
+	"context"
+	"time"
+)
+
+type repeats struct {
+	repo   Repository
+	action string
+	max    int64
+	window time.Duration
+}
+
+// NewRepeats returns a Filter of new posts whose author posted the same text
+// max times already within the window. Edits are not checked.
+func NewRepeats(repo Repository, action string, max int64, window time.Duration) Filter {
+	return &repeats{
+		repo:   repo,
+		action: action,
+		max:    max,
+		window: window,
+	}
+}
+
+func (r *repeats) Check(ctx context.Context, post *Post) (Verdict, error) {
+	if post.ID != 0 {
+		return Verdict{}, nil
+	}
+
+	count, err := r.repo.CountRepeats(ctx, post.AuthorID, post.Body, time.Now().Add(-r.window).Unix())
+	if err != nil {
+		return Verdict{}, err
+	}
+	if count < r.max {
+		return Verdict{}, nil
+	}
+
+	return Verdict{Action: r.action, Reason: "You posted the same text recently"}, nil
+}
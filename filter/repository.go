@@ -0,0 +1,42 @@
+package filter
+
+import (
+	"context"
+	"database/sql"
+)
+
+type Repository interface {
+	CountRepeats(ctx context.Context, authorID int64, text string, since int64) (int64, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+var countRepeats = `
+	SELECT
+		COUNT(*)
+	FROM
+		(
+			SELECT id FROM Discussion WHERE author_id = $1 AND created_at >= $3 AND LOWER(TRIM(description)) = LOWER(TRIM($2))
+			UNION ALL
+			SELECT id FROM Discussion_Comment WHERE commentator_id = $1 AND created_at >= $3 AND LOWER(TRIM(description)) = LOWER(TRIM($2))
+			UNION ALL
+			SELECT id FROM Article WHERE author_id = $1 AND created_at >= $3 AND LOWER(TRIM(description)) = LOWER(TRIM($2))
+		) r
+`
+
+// CountRepeats counts the discussions, comments and articles the author
+// posted since then with the same text, hidden or not.
+func (r *repository) CountRepeats(ctx context.Context, authorID int64, text string, since int64) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx, countRepeats, authorID, text, since).Scan(&count)
+
+	return count, err
+}
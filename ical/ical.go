@@ -0,0 +1,276 @@
+package ical
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	dateTimeLocal = "20060102T150405"
+	dateTimeUTC   = "20060102T150405Z"
+
+	recurrenceYears = 2
+)
+
+type Event struct {
+	// Location is the zone the event's times are written in, defaulting to
+	// the calendar's location.
+	Location    *time.Location
+	UID         string
+	Sequence    int64
+	Stamp       time.Time
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	URL         string
+	Status      string
+	// RRule and ExDates make the event recurring. An event with a
+	// RecurrenceID overrides that occurrence of the event with the same UID.
+	RRule        string
+	ExDates      []time.Time
+	RecurrenceID time.Time
+}
+
+type Calendar struct {
+	Name     string
+	Location *time.Location
+	Events   []Event
+}
+
+// Encode writes the calendar as an RFC 5545 document. Event times are
+// written in their location, together with a VTIMEZONE for every location
+// that covers all events using it.
+func (c *Calendar) Encode() string {
+	var b builder
+	b.line("BEGIN:VCALENDAR")
+	b.line("VERSION:2.0")
+	b.line("PRODID:-//Recovy//Webinar//EN")
+	b.line("CALSCALE:GREGORIAN")
+	b.line("METHOD:PUBLISH")
+	if c.Name != "" {
+		b.line("X-WR-CALNAME:" + escape(c.Name))
+	}
+	if c.Location != nil && c.Location != time.UTC {
+		b.line("X-WR-TIMEZONE:" + c.Location.String())
+	}
+
+	type span struct {
+		loc      *time.Location
+		from, to time.Time
+	}
+	spans := make(map[string]*span)
+	names := make([]string, 0)
+	for _, e := range c.Events {
+		loc := c.location(e)
+		if loc == time.UTC {
+			continue
+		}
+
+		end := e.End
+		if e.RRule != "" {
+			// Observances are only listed explicitly, so a recurring event
+			// gets those of the next years.
+			end = end.AddDate(recurrenceYears, 0, 0)
+		}
+
+		sp, ok := spans[loc.String()]
+		if !ok {
+			sp = &span{loc: loc, from: e.Start, to: end}
+			spans[loc.String()] = sp
+			names = append(names, loc.String())
+		}
+		if e.Start.Before(sp.from) {
+			sp.from = e.Start
+		}
+		if end.After(sp.to) {
+			sp.to = end
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writeTimezone(&b, spans[name].loc, spans[name].from, spans[name].to)
+	}
+
+	for _, e := range c.Events {
+		loc := c.location(e)
+
+		b.line("BEGIN:VEVENT")
+		b.line("UID:" + e.UID)
+		b.line(fmt.Sprintf("SEQUENCE:%d", e.Sequence))
+		b.line("DTSTAMP:" + e.Stamp.UTC().Format(dateTimeUTC))
+		b.line(dateTime("DTSTART", e.Start, loc))
+		b.line(dateTime("DTEND", e.End, loc))
+		if !e.RecurrenceID.IsZero() {
+			b.line(dateTime("RECURRENCE-ID", e.RecurrenceID, loc))
+		}
+		if e.RRule != "" {
+			b.line("RRULE:" + e.RRule)
+		}
+		if len(e.ExDates) > 0 {
+			b.line(dateTime("EXDATE", e.ExDates[0], loc, e.ExDates[1:]...))
+		}
+		b.line("SUMMARY:" + escape(e.Summary))
+		if e.Description != "" {
+			b.line("DESCRIPTION:" + escape(e.Description))
+		}
+		if e.URL != "" {
+			b.line("URL:" + e.URL)
+		}
+		if e.Status != "" {
+			b.line("STATUS:" + e.Status)
+		}
+		b.line("END:VEVENT")
+	}
+
+	b.line("END:VCALENDAR")
+
+	return b.String()
+}
+
+func (c *Calendar) location(e Event) *time.Location {
+	if e.Location != nil {
+		return e.Location
+	}
+	if c.Location != nil {
+		return c.Location
+	}
+
+	return time.UTC
+}
+
+// dateTime formats a property holding one or more date-times in loc.
+func dateTime(name string, t time.Time, loc *time.Location, more ...time.Time) string {
+	values := make([]string, 0, 1+len(more))
+	for _, t := range append([]time.Time{t}, more...) {
+		if loc == time.UTC {
+			values = append(values, t.UTC().Format(dateTimeUTC))
+		} else {
+			values = append(values, t.In(loc).Format(dateTimeLocal))
+		}
+	}
+
+	if loc == time.UTC {
+		return name + ":" + strings.Join(values, ",")
+	}
+
+	return fmt.Sprintf("%s;TZID=%s:%s", name, loc.String(), strings.Join(values, ","))
+}
+
+type transition struct {
+	at         time.Time
+	name       string
+	offsetFrom int
+	offsetTo   int
+	dst        bool
+}
+
+// writeTimezone describes the location's offsets with one observance per
+// transition, found by probing the zone between the first and last event.
+// Go does not expose the tz rules themselves, but explicit observances are
+// equally valid and cover any zone the tz database knows about.
+func writeTimezone(b *builder, loc *time.Location, from, to time.Time) {
+	from = from.AddDate(0, 0, -1)
+	to = to.AddDate(0, 0, 1)
+
+	name, offset := from.In(loc).Zone()
+	transitions := []transition{{
+		at:         from,
+		name:       name,
+		offsetFrom: offset,
+		offsetTo:   offset,
+		dst:        from.In(loc).IsDST(),
+	}}
+
+	for day := from; day.Before(to); day = day.Add(24 * time.Hour) {
+		next := day.Add(24 * time.Hour)
+		_, o1 := day.In(loc).Zone()
+		_, o2 := next.In(loc).Zone()
+		if o1 == o2 {
+			continue
+		}
+
+		lo, hi := day, next
+		for hi.Sub(lo) > time.Minute {
+			mid := lo.Add(hi.Sub(lo) / 2)
+			if _, o := mid.In(loc).Zone(); o == o1 {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+
+		name, o := hi.In(loc).Zone()
+		transitions = append(transitions, transition{
+			at:         hi.Truncate(time.Minute),
+			name:       name,
+			offsetFrom: o1,
+			offsetTo:   o,
+			dst:        hi.In(loc).IsDST(),
+		})
+	}
+
+	sort.SliceStable(transitions, func(i, j int) bool {
+		return transitions[i].at.Before(transitions[j].at)
+	})
+
+	b.line("BEGIN:VTIMEZONE")
+	b.line("TZID:" + loc.String())
+	for _, t := range transitions {
+		kind := "STANDARD"
+		if t.dst {
+			kind = "DAYLIGHT"
+		}
+
+		b.line("BEGIN:" + kind)
+		// DTSTART of an observance is the local time before the transition.
+		b.line("DTSTART:" + t.at.In(time.FixedZone("", t.offsetFrom)).Format(dateTimeLocal))
+		b.line("TZOFFSETFROM:" + formatOffset(t.offsetFrom))
+		b.line("TZOFFSETTO:" + formatOffset(t.offsetTo))
+		if t.name != "" {
+			b.line("TZNAME:" + escape(t.name))
+		}
+		b.line("END:" + kind)
+	}
+	b.line("END:VTIMEZONE")
+}
+
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds/60%60)
+}
+
+func escape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+	return r.Replace(s)
+}
+
+type builder struct {
+	strings.Builder
+}
+
+// line writes a content line terminated by CRLF, folding it so that no line
+// is longer than 75 octets without splitting a UTF-8 sequence.
+func (b *builder) line(s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+}
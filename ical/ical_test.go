@@ -0,0 +1,100 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncode(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	assert.NoError(t, err)
+
+	start := time.Date(2021, 9, 1, 19, 0, 0, 0, loc)
+	cal := Calendar{
+		Name:     "Webinar",
+		Location: loc,
+		Events: []Event{{
+			UID:         "webinar-1@recovy",
+			Sequence:    2,
+			Stamp:       time.Date(2021, 8, 20, 0, 0, 0, 0, time.UTC),
+			Start:       start,
+			End:         start.Add(90 * time.Minute),
+			Summary:     "Coping, together; session 1",
+			Description: strings.Repeat("long line ", 20),
+		}},
+	}
+
+	out := cal.Encode()
+	assert.Contains(t, out, "DTSTART;TZID=Asia/Jakarta:20210901T190000\r\n")
+	assert.Contains(t, out, "DTEND;TZID=Asia/Jakarta:20210901T203000\r\n")
+	assert.Contains(t, out, "DTSTAMP:20210820T000000Z\r\n")
+	assert.Contains(t, out, "SEQUENCE:2\r\n")
+	assert.Contains(t, out, `SUMMARY:Coping\, together\; session 1`)
+	assert.Contains(t, out, "TZOFFSETTO:+0700\r\n")
+
+	for _, line := range strings.Split(out, "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, line)
+	}
+	assert.True(t, strings.HasSuffix(out, "END:VCALENDAR\r\n"))
+}
+
+func TestTimezoneTransitions(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	cal := Calendar{
+		Location: loc,
+		Events: []Event{
+			{UID: "a", Start: time.Date(2021, 3, 1, 10, 0, 0, 0, loc), End: time.Date(2021, 3, 1, 11, 0, 0, 0, loc)},
+			{UID: "b", Start: time.Date(2021, 11, 20, 10, 0, 0, 0, loc), End: time.Date(2021, 11, 20, 11, 0, 0, 0, loc)},
+		},
+	}
+
+	out := cal.Encode()
+	assert.Contains(t, out, "BEGIN:DAYLIGHT\r\nDTSTART:20210314T020000\r\nTZOFFSETFROM:-0500\r\nTZOFFSETTO:-0400\r\nTZNAME:EDT\r\nEND:DAYLIGHT")
+	assert.Contains(t, out, "BEGIN:STANDARD\r\nDTSTART:20211107T020000\r\nTZOFFSETFROM:-0400\r\nTZOFFSETTO:-0500\r\nTZNAME:EST\r\nEND:STANDARD")
+}
+
+func TestFoldMultibyte(t *testing.T) {
+	var b builder
+	b.line("SUMMARY:" + strings.Repeat("é", 60))
+
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+		assert.True(t, strings.ToValidUTF8(line, "?") == line)
+	}
+}
+
+func TestRecurrence(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	assert.NoError(t, err)
+
+	start := time.Date(2021, 9, 6, 19, 0, 0, 0, loc)
+	cal := Calendar{
+		Location: loc,
+		Events: []Event{
+			{
+				UID:     "webinar-1-1@recovy",
+				Start:   start,
+				End:     start.Add(time.Hour),
+				RRule:   "FREQ=WEEKLY;BYDAY=MO;COUNT=4",
+				ExDates: []time.Time{start.AddDate(0, 0, 7), start.AddDate(0, 0, 14)},
+			},
+			{
+				UID:          "webinar-1-1@recovy",
+				Start:        start.AddDate(0, 0, 22),
+				End:          start.AddDate(0, 0, 22).Add(time.Hour),
+				RecurrenceID: start.AddDate(0, 0, 21),
+			},
+		},
+	}
+
+	out := cal.Encode()
+	assert.Contains(t, out, "RRULE:FREQ=WEEKLY;BYDAY=MO;COUNT=4\r\n")
+	assert.Contains(t, out, "EXDATE;TZID=Asia/Jakarta:20210913T190000,20210920T190000\r\n")
+	assert.Contains(t, out, "RECURRENCE-ID;TZID=Asia/Jakarta:20210927T190000\r\n")
+	assert.Equal(t, 1, strings.Count(out, "BEGIN:VTIMEZONE"))
+}
@@ -0,0 +1,84 @@
+// Package mail sends plain text emails.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+type logSender struct{}
+
+// NewLogSender returns a Sender that only logs, for running without a mail
+// server.
+func NewLogSender() Sender {
+	return logSender{}
+}
+
+func (logSender) Send(ctx context.Context, msg Message) error {
+	logrus.Infof("mail to %s: %s", msg.To, msg.Subject)
+
+	return nil
+}
+
+type smtpSender struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPSender sends through an SMTP server, authenticating with PLAIN when
+// a username is given. net/smtp upgrades to TLS when the server offers it.
+func NewSMTPSender(host, port, username, password, from string) Sender {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &smtpSender{
+		addr: host + ":" + port,
+		auth: auth,
+		from: from,
+	}
+}
+
+func (s *smtpSender) Send(ctx context.Context, msg Message) error {
+	return smtp.SendMail(s.addr, s.auth, s.from, []string{msg.To}, encode(s.from, msg, time.Now()))
+}
+
+// encode builds the message with the headers mail clients need. The subject
+// is Q-encoded so it may hold any UTF-8 text.
+func encode(from string, msg Message, date time.Time) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+
+	body := strings.ReplaceAll(msg.Body, "\r\n", "\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+
+	return b.Bytes()
+}
@@ -0,0 +1,23 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncode(t *testing.T) {
+	date := time.Date(2021, 9, 6, 12, 0, 0, 0, time.UTC)
+	out := string(encode("Recovy <no-reply@recovy.app>", Message{
+		To:      "ayu@example.com",
+		Subject: "Jadwal webinar berubah — Senin",
+		Body:    "Line one\nLine two",
+	}, date))
+
+	assert.True(t, strings.HasPrefix(out, "From: Recovy <no-reply@recovy.app>\r\nTo: ayu@example.com\r\n"))
+	assert.Contains(t, out, "Subject: =?utf-8?q?")
+	assert.Contains(t, out, "Date: Mon, 06 Sep 2021 12:00:00 +0000\r\n")
+	assert.True(t, strings.HasSuffix(out, "\r\n\r\nLine one\r\nLine two\r\n"))
+}
@@ -1,19 +1,39 @@
 package main
 
 import (
+	"context"
 	"log"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/bagus2x/recovy/app/middleware"
 	"github.com/bagus2x/recovy/app/routes"
 	"github.com/bagus2x/recovy/article"
 	"github.com/bagus2x/recovy/auth"
+	"github.com/bagus2x/recovy/calendar"
 	"github.com/bagus2x/recovy/config"
+	"github.com/bagus2x/recovy/crisis"
 	"github.com/bagus2x/recovy/db"
 	"github.com/bagus2x/recovy/discussion"
 	"github.com/bagus2x/recovy/discussioncomment"
+	"github.com/bagus2x/recovy/filter"
+	"github.com/bagus2x/recovy/mail"
+	"github.com/bagus2x/recovy/moderation"
+	"github.com/bagus2x/recovy/notification"
+	"github.com/bagus2x/recovy/playlist"
 	"github.com/bagus2x/recovy/podcast"
-	"github.com/bagus2x/recovy/starredpodcast"
+	"github.com/bagus2x/recovy/pubsub"
+	"github.com/bagus2x/recovy/queue"
+	"github.com/bagus2x/recovy/reaction"
+	"github.com/bagus2x/recovy/recommendation"
+	"github.com/bagus2x/recovy/search"
+	"github.com/bagus2x/recovy/taxonomy"
+	"github.com/bagus2x/recovy/transcript"
 	"github.com/bagus2x/recovy/webinar"
+	"github.com/bagus2x/recovy/webinarattendance"
+	"github.com/bagus2x/recovy/webinarlive"
+	"github.com/bagus2x/recovy/webinarregistration"
+	"github.com/bagus2x/recovy/webinarreminder"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
@@ -40,27 +60,125 @@ func main() {
 	authRepo := auth.NewRepository(db)
 	authCacheRepo := auth.NewCacheRepository(cache, cfg)
 	podcastRepo := podcast.NewRepository(db)
-	starredPodcastRepo := starredpodcast.NewRepository(db)
 	webinarRepo := webinar.NewRepository(db)
+	webinarRegistrationRepo := webinarregistration.NewRepository(db)
+	webinarReminderRepo := webinarreminder.NewRepository(db)
+	webinarAttendanceRepo := webinarattendance.NewRepository(db)
+	webinarLiveRepo := webinarlive.NewRepository(db)
+	queueRepo := queue.NewRepository(db)
 	articleRepo := article.NewRepository(db)
 	discussionRepo := discussion.NewRepository(db)
 	discussionCommentRepo := discussioncomment.NewRepository(db)
+	playlistRepo := playlist.NewRepository(db)
+	transcriptRepo := transcript.NewRepository(db)
+	recommendationRepo := recommendation.NewRepository(db)
+	calendarRepo := calendar.NewRepository(db)
+	notificationRepo := notification.NewRepository(db)
+	taxonomyRepo := taxonomy.NewRepository(db)
+	searchRepo := search.NewRepository(db)
+	reactionRepo := reaction.NewRepository(db)
+	moderationRepo := moderation.NewRepository(db)
+	filterRepo := filter.NewRepository(db)
+
+	mailSender := mail.NewLogSender()
+	if cfg.SMTPHost() != "" {
+		mailSender = mail.NewSMTPSender(cfg.SMTPHost(), cfg.SMTPPort(), cfg.SMTPUsername(), cfg.SMTPPassword(), cfg.MailFrom())
+	}
+
+	crisisDetector := crisis.Default()
+	if cfg.CrisisTermsFile() != "" {
+		crisisDetector, err = crisis.Load(cfg.CrisisTermsFile())
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	filterConfig := filter.DefaultConfig
+	if cfg.FilterConfigFile() != "" {
+		filterConfig, err = filter.Load(cfg.FilterConfigFile())
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	contentFilter, err := filter.New(filterConfig, filterRepo)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	livePubSub := pubsub.NewMemory()
+	if cfg.PubSub() == "postgres" {
+		livePubSub = pubsub.NewPostgres(db, cfg.DatabaseConnection())
+	}
 
 	authService := auth.NewService(authRepo, authCacheRepo, cfg)
-	podcastService := podcast.NewService(podcastRepo, starredPodcastRepo)
-	webinarService := webinar.NewService(webinarRepo)
-	articleService := article.NewService(articleRepo)
-	discussionService := discussion.NewService(discussionRepo)
-	discussionCommentService := discussioncomment.NewService(discussionCommentRepo, discussionRepo)
+	taxonomyService := taxonomy.NewService(taxonomyRepo, authRepo)
+	reactionService := reaction.NewService(reactionRepo)
+	podcastService := podcast.NewService(podcastRepo, reactionService)
+	webinarService := webinar.NewService(
+		webinarRepo,
+		webinarRegistrationRepo,
+		webinarReminderRepo,
+		webinarAttendanceRepo,
+		queueRepo,
+		webinar.NewNotifier(notificationRepo, mailSender),
+		taxonomyService,
+		reactionService,
+	)
+	webinarLiveService := webinarlive.NewService(webinarLiveRepo, webinarRepo, livePubSub)
+	moderationService := moderation.NewService(moderationRepo, authRepo, notificationRepo)
+	articleService := article.NewService(articleRepo, authRepo, queueRepo, taxonomyService, reactionService, moderationService, contentFilter)
+	discussionService := discussion.NewService(
+		discussionRepo,
+		authRepo,
+		taxonomyService,
+		reactionService,
+		moderationService,
+		crisisDetector,
+		contentFilter,
+	)
+	discussionCommentService := discussioncomment.NewService(
+		discussionCommentRepo,
+		discussionRepo,
+		authRepo,
+		reactionService,
+		moderationService,
+		crisisDetector,
+		contentFilter,
+		cfg.CommentMaxDepth(),
+	)
+	playlistService := playlist.NewService(playlistRepo, podcastRepo)
+	transcriptService := transcript.NewService(transcriptRepo, podcastRepo)
+	recommendationService := recommendation.NewService(recommendationRepo, podcastRepo)
+	calendarService := calendar.NewService(calendarRepo, webinarRepo)
+	notificationService := notification.NewService(notificationRepo)
+	searchService := search.NewService(searchRepo)
+
+	go recommendation.NewJob(recommendationService, 2).Run(context.Background())
+
+	worker := queue.NewWorker(queueRepo, 5*time.Second, 2)
+	worker.Handle(webinar.KindReminder, webinarService.HandleReminder)
+	worker.Handle(webinar.KindChangeNotice, webinarService.HandleChange)
+	worker.Handle(article.KindPublish, articleService.HandlePublish)
+	go worker.Run(context.Background())
 
 	mw := middleware.NewMiddleware(authService)
 
 	routes.AuthRoutes(app, mw, authService)
+	routes.RecommendationRoutes(app, mw, recommendationService)
 	routes.PodcastRoutes(app, mw, podcastService)
 	routes.WebinarRoutes(app, mw, webinarService)
+	routes.WebinarLiveRoutes(app, mw, webinarLiveService)
+	routes.CalendarRoutes(app, mw, calendarService)
+	routes.NotificationRoutes(app, mw, notificationService)
+	routes.TaxonomyRoutes(app, mw, taxonomyService)
+	routes.SearchRoutes(app, searchService)
 	routes.ArticleRoutes(app, mw, articleService)
 	routes.DiscussionRoutes(app, mw, discussionService)
 	routes.DiscussionCommentRoutes(app, mw, discussionCommentService)
+	routes.PlaylistRoutes(app, mw, playlistService)
+	routes.TranscriptRoutes(app, mw, transcriptService)
+	routes.ReactionRoutes(app, mw, reactionService)
+	routes.ModerationRoutes(app, mw, moderationService)
 
 	app.Listen(cfg.AppPort())
 }
@@ -0,0 +1,797 @@
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type blockKind int
+
+const (
+	blockParagraph blockKind = iota
+	blockHeading
+	blockThematicBreak
+	blockCode
+	blockHTML
+	blockQuote
+	blockList
+	blockItem
+	blockTable
+)
+
+type block struct {
+	kind blockKind
+	// text is the raw inline content of paragraphs and headings, and the
+	// literal content of code and HTML blocks.
+	text     string
+	level    int
+	info     string
+	ordered  bool
+	start    int
+	tight    bool
+	align    []string
+	rows     [][]string
+	children []*block
+}
+
+type reference struct {
+	dest  string
+	title string
+}
+
+type parser struct {
+	refs map[string]reference
+}
+
+// parseBlocks parses the lines of a container. Quotes and list items are
+// parsed by stripping their markers and parsing their lines recursively.
+func (p *parser) parseBlocks(lines []string) []*block {
+	blocks := make([]*block, 0)
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if isBlank(line) {
+			i++
+			continue
+		}
+
+		var b *block
+		var n int
+
+		switch {
+		case indent(line) >= 4:
+			b, n = indentedCode(lines[i:])
+		case fenceStart(line) != nil:
+			b, n = fencedCode(lines[i:])
+		case isATXHeading(line):
+			level, text, _ := atxHeading(line)
+			b, n = &block{kind: blockHeading, level: level, text: text}, 1
+		case isThematicBreak(line):
+			b, n = &block{kind: blockThematicBreak}, 1
+		case isQuote(line):
+			b, n = p.quote(lines[i:])
+		case isListItem(line):
+			b, n = p.list(lines[i:])
+		case htmlBlockStart(line, false) > 0:
+			b, n = htmlBlock(lines[i:])
+		case i+1 < len(lines) && isTableStart(line, lines[i+1]):
+			b, n = p.table(lines[i:])
+		default:
+			b, n = p.paragraph(lines[i:])
+		}
+
+		if b != nil {
+			blocks = append(blocks, b)
+		}
+		i += n
+	}
+
+	return blocks
+}
+
+// interrupts reports whether the line starts a block that ends a paragraph.
+func interrupts(line string) bool {
+	if indent(line) >= 4 {
+		return false
+	}
+	if fenceStart(line) != nil || isATXHeading(line) || isThematicBreak(line) || isQuote(line) {
+		return true
+	}
+	if htmlBlockStart(line, true) > 0 {
+		return true
+	}
+
+	m, ok := listMarker(line)
+
+	return ok && !m.empty && (!m.ordered || m.start == 1)
+}
+
+func (p *parser) paragraph(lines []string) (*block, int) {
+	text := make([]string, 0)
+
+	i := 0
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isBlank(line) {
+			break
+		}
+
+		if i > 0 {
+			if level := setextLevel(line); level > 0 {
+				content := strings.Join(text, "\n")
+				content = p.parseReferences(content)
+				if strings.TrimSpace(content) != "" {
+					return &block{kind: blockHeading, level: level, text: strings.TrimSpace(content)}, i + 1
+				}
+			}
+			if interrupts(line) || (i+1 < len(lines) && isTableStart(line, lines[i+1])) {
+				break
+			}
+		}
+
+		text = append(text, strings.TrimLeft(line, " \t"))
+	}
+
+	content := p.parseReferences(strings.Join(text, "\n"))
+	content = strings.TrimRight(content, " \t\n")
+	if content == "" {
+		return nil, i
+	}
+
+	return &block{kind: blockParagraph, text: content}, i
+}
+
+// parseReferences records the link reference definitions at the start of a
+// paragraph and returns the rest of it.
+func (p *parser) parseReferences(s string) string {
+	for {
+		n := p.parseReference(s)
+		if n == 0 {
+			return s
+		}
+		s = s[n:]
+	}
+}
+
+func (p *parser) parseReference(s string) int {
+	label, pos := linkLabel(s, 0)
+	if pos == 0 || pos >= len(s) || s[pos] != ':' {
+		return 0
+	}
+
+	pos = skipSpaceNewline(s, pos+1)
+	dest, pos, ok := linkDestination(s, pos)
+	if !ok {
+		return 0
+	}
+
+	beforeTitle := pos
+	title := ""
+	if next := skipSpaceNewline(s, pos); next > pos {
+		if t, end, ok := linkTitle(s, next); ok {
+			title, pos = t, end
+		}
+	}
+
+	end, ok := lineEnd(s, pos)
+	if !ok && title != "" {
+		title = ""
+		end, ok = lineEnd(s, beforeTitle)
+	}
+	if !ok {
+		return 0
+	}
+
+	key := normalizeLabel(label)
+	if key == "" {
+		return 0
+	}
+	if _, ok := p.refs[key]; !ok {
+		p.refs[key] = reference{dest: dest, title: title}
+	}
+
+	return end
+}
+
+// lineEnd skips trailing spaces and reports whether the line ends there,
+// returning the start of the next line.
+func lineEnd(s string, pos int) (int, bool) {
+	for pos < len(s) && (s[pos] == ' ' || s[pos] == '\t') {
+		pos++
+	}
+	if pos == len(s) {
+		return pos, true
+	}
+	if s[pos] == '\n' {
+		return pos + 1, true
+	}
+
+	return pos, false
+}
+
+func indentedCode(lines []string) (*block, int) {
+	content := make([]string, 0)
+
+	i := 0
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isBlank(line) {
+			content = append(content, strings.TrimLeft(line, " "))
+			continue
+		}
+		if indent(line) < 4 {
+			break
+		}
+		content = append(content, line[4:])
+	}
+
+	for len(content) > 0 && strings.TrimSpace(content[len(content)-1]) == "" {
+		content = content[:len(content)-1]
+	}
+
+	return &block{kind: blockCode, text: strings.Join(content, "\n") + "\n"}, i
+}
+
+type fence struct {
+	indent int
+	char   byte
+	length int
+	info   string
+}
+
+func fenceStart(line string) *fence {
+	ind := indent(line)
+	if ind > 3 {
+		return nil
+	}
+
+	l := line[ind:]
+	if len(l) < 3 || (l[0] != '`' && l[0] != '~') {
+		return nil
+	}
+
+	n := 0
+	for n < len(l) && l[n] == l[0] {
+		n++
+	}
+	if n < 3 {
+		return nil
+	}
+
+	info := strings.TrimSpace(l[n:])
+	if l[0] == '`' && strings.Contains(info, "`") {
+		return nil
+	}
+
+	return &fence{indent: ind, char: l[0], length: n, info: info}
+}
+
+func (f *fence) closes(line string) bool {
+	ind := indent(line)
+	if ind > 3 {
+		return false
+	}
+
+	l := strings.TrimRight(line[ind:], " \t")
+	if len(l) < f.length {
+		return false
+	}
+
+	return strings.Trim(l, string(f.char)) == ""
+}
+
+// fencedCode runs to the closing fence or to the end of its container.
+func fencedCode(lines []string) (*block, int) {
+	f := fenceStart(lines[0])
+	content := make([]string, 0)
+
+	i := 1
+	for ; i < len(lines); i++ {
+		if f.closes(lines[i]) {
+			i++
+			break
+		}
+
+		line := lines[i]
+		strip := indent(line)
+		if strip > f.indent {
+			strip = f.indent
+		}
+		content = append(content, line[strip:])
+	}
+
+	text := ""
+	if len(content) > 0 {
+		text = strings.Join(content, "\n") + "\n"
+	}
+
+	info := ""
+	if fields := strings.Fields(f.info); len(fields) > 0 {
+		info = unescape(fields[0])
+	}
+
+	return &block{kind: blockCode, text: text, info: info}, i
+}
+
+func isATXHeading(line string) bool {
+	_, _, ok := atxHeading(line)
+
+	return ok
+}
+
+func atxHeading(line string) (int, string, bool) {
+	ind := indent(line)
+	if ind > 3 {
+		return 0, "", false
+	}
+
+	l := line[ind:]
+	n := 0
+	for n < len(l) && l[n] == '#' {
+		n++
+	}
+	if n == 0 || n > 6 {
+		return 0, "", false
+	}
+
+	rest := l[n:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return 0, "", false
+	}
+
+	// A closing sequence of #s must be preceded by a space.
+	rest = strings.Trim(rest, " \t")
+	trimmed := strings.TrimRight(rest, "#")
+	if trimmed == "" {
+		rest = ""
+	} else if last := trimmed[len(trimmed)-1]; last == ' ' || last == '\t' {
+		rest = strings.TrimRight(trimmed, " \t")
+	}
+
+	return n, rest, true
+}
+
+func isThematicBreak(line string) bool {
+	if indent(line) > 3 {
+		return false
+	}
+
+	var char byte
+	count := 0
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; c {
+		case ' ', '\t':
+		case '-', '*', '_':
+			if char != 0 && c != char {
+				return false
+			}
+			char = c
+			count++
+		default:
+			return false
+		}
+	}
+
+	return count >= 3
+}
+
+func setextLevel(line string) int {
+	if indent(line) > 3 {
+		return 0
+	}
+
+	l := strings.TrimRight(strings.TrimLeft(line, " "), " \t")
+	switch {
+	case l == "":
+		return 0
+	case strings.Trim(l, "=") == "":
+		return 1
+	case strings.Trim(l, "-") == "":
+		return 2
+	}
+
+	return 0
+}
+
+func isQuote(line string) bool {
+	ind := indent(line)
+
+	return ind <= 3 && ind < len(line) && line[ind] == '>'
+}
+
+// quote collects the lines of a block quote, including lazy continuation
+// lines of its paragraphs.
+func (p *parser) quote(lines []string) (*block, int) {
+	content := make([]string, 0)
+
+	i := 0
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isQuote(line) {
+			l := line[indent(line)+1:]
+			if strings.HasPrefix(l, " ") {
+				l = l[1:]
+			}
+			content = append(content, l)
+			continue
+		}
+
+		last := ""
+		if len(content) > 0 {
+			last = content[len(content)-1]
+		}
+		if isBlank(line) || isBlank(last) || interrupts(line) || fenceStart(last) != nil {
+			break
+		}
+		content = append(content, line)
+	}
+
+	return &block{kind: blockQuote, children: p.parseBlocks(content)}, i
+}
+
+type marker struct {
+	ordered bool
+	// char is the bullet of a bullet list and the delimiter of an ordered
+	// list.
+	char  byte
+	start int
+	// width is where the content of the item starts.
+	width int
+	empty bool
+}
+
+var orderedMarker = regexp.MustCompile(`^ {0,3}([0-9]{1,9})([.)])`)
+
+func listMarker(line string) (marker, bool) {
+	ind := indent(line)
+	if ind > 3 || ind >= len(line) {
+		return marker{}, false
+	}
+
+	var m marker
+	var width int
+	switch c := line[ind]; c {
+	case '-', '+', '*':
+		m.char = c
+		width = ind + 1
+	default:
+		match := orderedMarker.FindStringSubmatch(line)
+		if match == nil {
+			return marker{}, false
+		}
+		m.ordered = true
+		m.start, _ = strconv.Atoi(match[1])
+		m.char = match[2][0]
+		width = len(match[0])
+	}
+
+	rest := line[width:]
+	if strings.TrimSpace(rest) == "" {
+		m.empty = true
+		m.width = width + 1
+
+		return m, true
+	}
+	if rest[0] != ' ' && rest[0] != '\t' {
+		return marker{}, false
+	}
+
+	spaces := indent(rest)
+	if spaces > 4 {
+		// The content is an indented code block.
+		spaces = 1
+	}
+	m.width = width + spaces
+
+	return m, true
+}
+
+func isListItem(line string) bool {
+	_, ok := listMarker(line)
+
+	return ok
+}
+
+func (p *parser) list(lines []string) (*block, int) {
+	m, _ := listMarker(lines[0])
+	list := &block{kind: blockList, ordered: m.ordered, start: m.start, tight: true}
+
+	i := 0
+	for {
+		item, n, loose := p.listItem(lines[i:], m)
+		list.children = append(list.children, item)
+		if loose {
+			list.tight = false
+		}
+		i += n
+
+		next := i
+		for next < len(lines) && isBlank(lines[next]) {
+			next++
+		}
+		if next == len(lines) || isThematicBreak(lines[next]) {
+			return list, next
+		}
+
+		nm, ok := listMarker(lines[next])
+		if !ok || nm.ordered != m.ordered || nm.char != m.char {
+			return list, next
+		}
+		if next > i {
+			list.tight = false
+		}
+		i, m = next, nm
+	}
+}
+
+// listItem collects the lines of an item. It reports the item as loose when
+// a blank line separates its blocks.
+func (p *parser) listItem(lines []string, m marker) (*block, int, bool) {
+	first := ""
+	if !m.empty {
+		first = lines[0][m.width:]
+	}
+	content := []string{first}
+
+	i := 1
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isBlank(line) {
+			// An item can start with at most one blank line.
+			if m.empty && i == 1 {
+				break
+			}
+			content = append(content, "")
+			continue
+		}
+		if indent(line) >= m.width {
+			content = append(content, line[m.width:])
+			continue
+		}
+
+		last := content[len(content)-1]
+		if isBlank(last) || interrupts(line) || isListItem(line) || fenceStart(strings.TrimLeft(last, " ")) != nil {
+			break
+		}
+		content = append(content, line)
+	}
+
+	// Trailing blank lines belong to the list, not the item.
+	for i > 1 && len(content) > 1 && isBlank(content[len(content)-1]) {
+		content = content[:len(content)-1]
+		i--
+	}
+
+	item := &block{kind: blockItem, children: p.parseBlocks(content)}
+
+	loose := false
+	if len(item.children) > 1 {
+		for _, line := range content[1:] {
+			if isBlank(line) {
+				loose = true
+				break
+			}
+		}
+	}
+
+	return item, i, loose
+}
+
+var (
+	htmlBlockRaw     = regexp.MustCompile(`(?i)^<(script|pre|style|textarea)(\s|>|$)`)
+	htmlBlockRawEnd  = regexp.MustCompile(`(?i)</(script|pre|style|textarea)>`)
+	htmlBlockTag     = regexp.MustCompile(`^</?([A-Za-z][A-Za-z0-9]*)(\s|/?>|$)`)
+	htmlBlockOpen    = regexp.MustCompile(`^(?:` + openTag + `|` + closeTag + `)\s*$`)
+	htmlBlockEndings = []string{"", "", "-->", "?>", ">", "]]>"}
+)
+
+var htmlBlockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "base": true, "basefont": true, "blockquote": true,
+	"body": true, "caption": true, "center": true, "col": true, "colgroup": true, "dd": true,
+	"details": true, "dialog": true, "dir": true, "div": true, "dl": true, "dt": true,
+	"fieldset": true, "figcaption": true, "figure": true, "footer": true, "form": true, "frame": true,
+	"frameset": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true,
+	"h6": true, "head": true, "header": true, "hr": true, "html": true, "iframe": true,
+	"legend": true, "li": true, "link": true, "main": true, "menu": true, "menuitem": true,
+	"nav": true, "noframes": true, "ol": true, "optgroup": true, "option": true, "p": true,
+	"param": true, "section": true, "source": true, "summary": true, "table": true, "tbody": true,
+	"td": true, "tfoot": true, "th": true, "thead": true, "title": true, "tr": true,
+	"track": true, "ul": true,
+}
+
+// htmlBlockStart returns the kind of HTML block the line starts, numbered as
+// in the CommonMark spec, or 0. Kind 7 cannot interrupt a paragraph.
+func htmlBlockStart(line string, interrupting bool) int {
+	ind := indent(line)
+	if ind > 3 {
+		return 0
+	}
+
+	l := line[ind:]
+	switch {
+	case htmlBlockRaw.MatchString(l):
+		return 1
+	case strings.HasPrefix(l, "<!--"):
+		return 2
+	case strings.HasPrefix(l, "<?"):
+		return 3
+	case strings.HasPrefix(l, "<![CDATA["):
+		return 5
+	case len(l) > 2 && strings.HasPrefix(l, "<!") && isASCIILetter(l[2]):
+		return 4
+	}
+
+	if m := htmlBlockTag.FindStringSubmatch(l); m != nil && htmlBlockTags[strings.ToLower(m[1])] {
+		return 6
+	}
+	if !interrupting && htmlBlockOpen.MatchString(l) {
+		return 7
+	}
+
+	return 0
+}
+
+func htmlBlock(lines []string) (*block, int) {
+	kind := htmlBlockStart(lines[0], false)
+	content := make([]string, 0)
+
+	i := 0
+	for ; i < len(lines); i++ {
+		line := lines[i]
+
+		if kind >= 6 {
+			if isBlank(line) {
+				break
+			}
+			content = append(content, line)
+			continue
+		}
+
+		content = append(content, line)
+		if kind == 1 && htmlBlockRawEnd.MatchString(line) || kind > 1 && strings.Contains(line, htmlBlockEndings[kind]) {
+			i++
+			break
+		}
+	}
+
+	return &block{kind: blockHTML, text: strings.Join(content, "\n") + "\n"}, i
+}
+
+func isTableStart(line, next string) bool {
+	if indent(line) > 3 || !strings.Contains(line, "|") {
+		return false
+	}
+
+	align, ok := delimiterRow(next)
+
+	return ok && len(splitRow(line)) == len(align)
+}
+
+// delimiterRow parses the row under a table's header into the alignment of
+// each column.
+func delimiterRow(line string) ([]string, bool) {
+	if indent(line) > 3 {
+		return nil, false
+	}
+
+	cells := splitRow(line)
+	if len(cells) == 1 && !strings.Contains(line, "|") {
+		return nil, false
+	}
+
+	align := make([]string, 0, len(cells))
+	for _, cell := range cells {
+		left, right := strings.HasPrefix(cell, ":"), strings.HasSuffix(cell, ":")
+		dashes := strings.TrimSuffix(strings.TrimPrefix(cell, ":"), ":")
+		if dashes == "" || strings.Trim(dashes, "-") != "" {
+			return nil, false
+		}
+
+		switch {
+		case left && right:
+			align = append(align, "center")
+		case left:
+			align = append(align, "left")
+		case right:
+			align = append(align, "right")
+		default:
+			align = append(align, "")
+		}
+	}
+
+	return align, true
+}
+
+// splitRow splits a table row on its unescaped pipes.
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	cells := make([]string, 0)
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// table runs to the first blank line or the start of another block.
+func (p *parser) table(lines []string) (*block, int) {
+	header := splitRow(lines[0])
+	align, _ := delimiterRow(lines[1])
+	table := &block{kind: blockTable, align: align, rows: [][]string{header}}
+
+	i := 2
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isBlank(line) || interrupts(line) {
+			break
+		}
+
+		row := splitRow(line)
+		for len(row) < len(header) {
+			row = append(row, "")
+		}
+		table.rows = append(table.rows, row[:len(header)])
+	}
+
+	return table, i
+}
+
+func indent(line string) int {
+	n := 0
+	for n < len(line) && line[n] == ' ' {
+		n++
+	}
+
+	return n
+}
+
+func isBlank(line string) bool {
+	return strings.TrimLeft(line, " \t") == ""
+}
+
+// expandTabs replaces the tabs that indent a line with spaces up to the next
+// tab stop.
+func expandTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+
+	var b strings.Builder
+	col := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\t':
+			n := 4 - col%4
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+		case ' ', '>':
+			b.WriteByte(line[i])
+			col++
+		default:
+			b.WriteString(line[i:])
+			return b.String()
+		}
+	}
+
+	return b.String()
+}
+
+func isASCIILetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
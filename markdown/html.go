@@ -0,0 +1,223 @@
+package markdown
+
+import (
+	"strconv"
+	"strings"
+)
+
+type renderer struct {
+	b    strings.Builder
+	refs map[string]reference
+}
+
+func (r *renderer) blocks(blocks []*block, tight bool) {
+	for _, b := range blocks {
+		r.block(b, tight)
+	}
+}
+
+func (r *renderer) block(b *block, tight bool) {
+	switch b.kind {
+	case blockParagraph:
+		if tight {
+			r.inlines(b.text)
+			return
+		}
+		r.b.WriteString("<p>")
+		r.inlines(b.text)
+		r.b.WriteString("</p>\n")
+	case blockHeading:
+		tag := "h" + strconv.Itoa(b.level)
+		r.b.WriteString("<" + tag + ">")
+		r.inlines(b.text)
+		r.b.WriteString("</" + tag + ">\n")
+	case blockThematicBreak:
+		r.b.WriteString("<hr />\n")
+	case blockCode:
+		r.b.WriteString("<pre><code")
+		if b.info != "" {
+			r.b.WriteString(` class="language-` + escape(b.info) + `"`)
+		}
+		r.b.WriteString(">" + escape(b.text) + "</code></pre>\n")
+	case blockHTML:
+		r.b.WriteString(b.text)
+	case blockQuote:
+		r.b.WriteString("<blockquote>\n")
+		r.blocks(b.children, false)
+		r.b.WriteString("</blockquote>\n")
+	case blockList:
+		r.list(b)
+	case blockTable:
+		r.table(b)
+	}
+}
+
+func (r *renderer) list(list *block) {
+	tag := "ul"
+	if list.ordered {
+		tag = "ol"
+	}
+
+	r.b.WriteString("<" + tag)
+	if list.ordered && list.start != 1 {
+		r.b.WriteString(` start="` + strconv.Itoa(list.start) + `"`)
+	}
+	r.b.WriteString(">\n")
+
+	for _, item := range list.children {
+		r.b.WriteString("<li>")
+		for _, child := range item.children {
+			// A tight item's paragraphs are written without tags, so only
+			// its other blocks start on their own line.
+			if !list.tight || child.kind != blockParagraph {
+				r.newline()
+			}
+			r.block(child, list.tight)
+		}
+		r.b.WriteString("</li>\n")
+	}
+
+	r.b.WriteString("</" + tag + ">\n")
+}
+
+// newline starts a new line unless the output is at the start of one.
+func (r *renderer) newline() {
+	s := r.b.String()
+	if s != "" && s[len(s)-1] != '\n' {
+		r.b.WriteString("\n")
+	}
+}
+
+func (r *renderer) table(table *block) {
+	r.b.WriteString("<table>\n<thead>\n")
+	for i, row := range table.rows {
+		if i == 1 {
+			r.b.WriteString("<tbody>\n")
+		}
+
+		cell := "td"
+		if i == 0 {
+			cell = "th"
+		}
+
+		r.b.WriteString("<tr>\n")
+		for j, content := range row {
+			r.b.WriteString("<" + cell)
+			if table.align[j] != "" {
+				r.b.WriteString(` align="` + table.align[j] + `"`)
+			}
+			r.b.WriteString(">")
+			r.inlines(content)
+			r.b.WriteString("</" + cell + ">\n")
+		}
+		r.b.WriteString("</tr>\n")
+
+		if i == 0 {
+			r.b.WriteString("</thead>\n")
+		}
+	}
+	if len(table.rows) > 1 {
+		r.b.WriteString("</tbody>\n")
+	}
+	r.b.WriteString("</table>\n")
+}
+
+func (r *renderer) inlines(s string) {
+	root := parseInlines(s, r.refs)
+	for n := root.first; n != nil; n = n.next {
+		r.inline(n)
+	}
+}
+
+func (r *renderer) inline(n *node) {
+	switch n.kind {
+	case nodeText:
+		r.b.WriteString(escape(n.literal))
+	case nodeSoftBreak:
+		r.b.WriteString("\n")
+	case nodeHardBreak:
+		r.b.WriteString("<br />\n")
+	case nodeCode:
+		r.b.WriteString("<code>" + escape(n.literal) + "</code>")
+	case nodeHTML:
+		r.b.WriteString(n.literal)
+	case nodeEmph, nodeStrong:
+		tag := "em"
+		if n.kind == nodeStrong {
+			tag = "strong"
+		}
+		r.b.WriteString("<" + tag + ">")
+		r.children(n)
+		r.b.WriteString("</" + tag + ">")
+	case nodeLink:
+		r.b.WriteString(`<a href="` + escape(normalizeURL(n.dest)) + `"`)
+		if n.title != "" {
+			r.b.WriteString(` title="` + escape(n.title) + `"`)
+		}
+		r.b.WriteString(">")
+		r.children(n)
+		r.b.WriteString("</a>")
+	case nodeImage:
+		r.b.WriteString(`<img src="` + escape(normalizeURL(n.dest)) + `" alt="` + escape(plainText(n)) + `"`)
+		if n.title != "" {
+			r.b.WriteString(` title="` + escape(n.title) + `"`)
+		}
+		r.b.WriteString(" />")
+	}
+}
+
+func (r *renderer) children(n *node) {
+	for child := n.first; child != nil; child = child.next {
+		r.inline(child)
+	}
+}
+
+// plainText is the text of an image's description, used as its alt text.
+func plainText(n *node) string {
+	var b strings.Builder
+	for child := n.first; child != nil; child = child.next {
+		switch child.kind {
+		case nodeText, nodeCode:
+			b.WriteString(child.literal)
+		case nodeSoftBreak, nodeHardBreak:
+			b.WriteString(" ")
+		default:
+			b.WriteString(plainText(child))
+		}
+	}
+
+	return b.String()
+}
+
+var escaper = strings.NewReplacer(`&`, "&amp;", `<`, "&lt;", `>`, "&gt;", `"`, "&quot;")
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// normalizeURL percent-encodes the characters that are not allowed in a URL,
+// keeping existing escapes.
+func normalizeURL(s string) string {
+	const hex = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			b.WriteByte(c)
+		case c < 0x80 && (isASCIILetter(c) || c >= '0' && c <= '9' || strings.IndexByte("-_.!~*'();/?:@&=+$,#", c) >= 0):
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&15])
+		}
+	}
+
+	return b.String()
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
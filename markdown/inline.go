@@ -0,0 +1,729 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type nodeKind int
+
+const (
+	nodeContainer nodeKind = iota
+	nodeText
+	nodeSoftBreak
+	nodeHardBreak
+	nodeCode
+	nodeHTML
+	nodeEmph
+	nodeStrong
+	nodeLink
+	nodeImage
+)
+
+// node is an inline element. Inlines are kept in a linked tree so that
+// emphasis and links can wrap runs of their siblings.
+type node struct {
+	kind    nodeKind
+	literal string
+	dest    string
+	title   string
+
+	parent, first, last, prev, next *node
+}
+
+func (n *node) appendChild(child *node) {
+	child.unlink()
+	child.parent = n
+	if n.last != nil {
+		n.last.next = child
+		child.prev = n.last
+		n.last = child
+	} else {
+		n.first = child
+		n.last = child
+	}
+}
+
+func (n *node) insertAfter(sibling *node) {
+	sibling.unlink()
+	sibling.next = n.next
+	if sibling.next != nil {
+		sibling.next.prev = sibling
+	}
+	sibling.prev = n
+	n.next = sibling
+	sibling.parent = n.parent
+	if sibling.next == nil && sibling.parent != nil {
+		sibling.parent.last = sibling
+	}
+}
+
+func (n *node) unlink() {
+	if n.prev != nil {
+		n.prev.next = n.next
+	} else if n.parent != nil {
+		n.parent.first = n.next
+	}
+	if n.next != nil {
+		n.next.prev = n.prev
+	} else if n.parent != nil {
+		n.parent.last = n.prev
+	}
+	n.parent, n.prev, n.next = nil, nil, nil
+}
+
+// delimiter is a run of * or _ that may open or close emphasis.
+type delimiter struct {
+	char       byte
+	count      int
+	origCount  int
+	node       *node
+	canOpen    bool
+	canClose   bool
+	prev, next *delimiter
+}
+
+// bracket is a [ or ![ that may open a link or image.
+type bracket struct {
+	node      *node
+	prev      *bracket
+	prevDelim *delimiter
+	// index is where the link text starts.
+	index    int
+	image    bool
+	active   bool
+	hasAfter bool
+}
+
+type inlineParser struct {
+	refs       map[string]reference
+	s          string
+	pos        int
+	root       *node
+	delimiters *delimiter
+	brackets   *bracket
+}
+
+const (
+	tagName   = `[A-Za-z][A-Za-z0-9-]*`
+	attribute = `(?:\s+[A-Za-z_:][A-Za-z0-9_.:-]*(?:\s*=\s*(?:[^\s"'=<>` + "`" + `]+|'[^']*'|"[^"]*"))?)`
+	openTag   = `<` + tagName + attribute + `*\s*/?>`
+	closeTag  = `</` + tagName + `\s*>`
+)
+
+var (
+	rawHTML   = regexp.MustCompile(`^(?:` + openTag + `|` + closeTag + `|<!---->|<!--(?:-?[^>-])(?:-?[^-])*-->|(?s:<\?.*?\?>)|<![A-Z]+\s+[^>]*>|(?s:<!\[CDATA\[.*?\]\]>))`)
+	autolink  = regexp.MustCompile(`^<[A-Za-z][A-Za-z0-9.+-]{1,31}:[^<>\x00-\x20]*>`)
+	emailLink = regexp.MustCompile(`^<[a-zA-Z0-9.!#$%&'*+/=?^_` + "`" + `{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*>`)
+	entity    = regexp.MustCompile(`^&(?:#[xX][0-9a-fA-F]{1,6}|#[0-9]{1,7}|[A-Za-z][A-Za-z0-9]{1,31});`)
+)
+
+const specialChars = "\n\\`*_[]!<&"
+
+// parseInlines parses the raw content of a paragraph, heading or table cell.
+func parseInlines(s string, refs map[string]reference) *node {
+	p := &inlineParser{
+		refs: refs,
+		s:    s,
+		root: &node{kind: nodeContainer},
+	}
+
+	for p.pos < len(p.s) {
+		p.parseInline()
+	}
+	p.processEmphasis(nil)
+
+	return p.root
+}
+
+func (p *inlineParser) text(s string) *node {
+	n := &node{kind: nodeText, literal: s}
+	p.root.appendChild(n)
+
+	return n
+}
+
+func (p *inlineParser) parseInline() {
+	c := p.s[p.pos]
+
+	switch c {
+	case '\n':
+		p.newline()
+	case '\\':
+		p.backslash()
+	case '`':
+		p.codeSpan()
+	case '*', '_':
+		p.delimiterRun(c)
+	case '[':
+		p.pos++
+		p.pushBracket(p.text("["), false)
+	case '!':
+		if p.pos+1 < len(p.s) && p.s[p.pos+1] == '[' {
+			p.pos += 2
+			p.pushBracket(p.text("!["), true)
+		} else {
+			p.pos++
+			p.text("!")
+		}
+	case ']':
+		p.closeBracket()
+	case '<':
+		p.angle()
+	case '&':
+		p.entity()
+	default:
+		end := strings.IndexAny(p.s[p.pos:], specialChars)
+		if end < 0 {
+			end = len(p.s) - p.pos
+		} else if end == 0 {
+			end = 1
+		}
+		p.text(p.s[p.pos : p.pos+end])
+		p.pos += end
+	}
+}
+
+// newline ends a line with a hard break when it ends with two spaces.
+func (p *inlineParser) newline() {
+	p.pos++
+
+	kind := nodeSoftBreak
+	if last := p.root.last; last != nil && last.kind == nodeText {
+		if strings.HasSuffix(last.literal, "  ") {
+			kind = nodeHardBreak
+		}
+		last.literal = strings.TrimRight(last.literal, " ")
+	}
+	p.root.appendChild(&node{kind: kind})
+
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *inlineParser) backslash() {
+	p.pos++
+	if p.pos < len(p.s) && p.s[p.pos] == '\n' {
+		p.pos++
+		p.root.appendChild(&node{kind: nodeHardBreak})
+		for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+			p.pos++
+		}
+		return
+	}
+	if p.pos < len(p.s) && isASCIIPunct(p.s[p.pos]) {
+		p.text(p.s[p.pos : p.pos+1])
+		p.pos++
+		return
+	}
+	p.text(`\`)
+}
+
+func (p *inlineParser) codeSpan() {
+	start := p.pos
+	for p.pos < len(p.s) && p.s[p.pos] == '`' {
+		p.pos++
+	}
+	ticks := p.s[start:p.pos]
+
+	for i := p.pos; i < len(p.s); {
+		j := strings.Index(p.s[i:], ticks)
+		if j < 0 {
+			break
+		}
+		j += i
+
+		end := j + len(ticks)
+		if end < len(p.s) && p.s[end] == '`' {
+			for end < len(p.s) && p.s[end] == '`' {
+				end++
+			}
+			i = end
+			continue
+		}
+
+		content := strings.ReplaceAll(p.s[p.pos:j], "\n", " ")
+		if len(content) > 2 && content[0] == ' ' && content[len(content)-1] == ' ' && strings.Trim(content, " ") != "" {
+			content = content[1 : len(content)-1]
+		}
+		p.root.appendChild(&node{kind: nodeCode, literal: content})
+		p.pos = end
+		return
+	}
+
+	p.text(ticks)
+}
+
+func (p *inlineParser) delimiterRun(c byte) {
+	start := p.pos
+	for p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+	}
+
+	before, after := '\n', '\n'
+	if start > 0 {
+		before, _ = utf8.DecodeLastRuneInString(p.s[:start])
+	}
+	if p.pos < len(p.s) {
+		after, _ = utf8.DecodeRuneInString(p.s[p.pos:])
+	}
+
+	beforeSpace, afterSpace := unicode.IsSpace(before), unicode.IsSpace(after)
+	beforePunct, afterPunct := isPunct(before), isPunct(after)
+
+	leftFlanking := !afterSpace && (!afterPunct || beforeSpace || beforePunct)
+	rightFlanking := !beforeSpace && (!beforePunct || afterSpace || afterPunct)
+
+	canOpen, canClose := leftFlanking, rightFlanking
+	if c == '_' {
+		canOpen = leftFlanking && (!rightFlanking || beforePunct)
+		canClose = rightFlanking && (!leftFlanking || afterPunct)
+	}
+
+	n := p.text(p.s[start:p.pos])
+	if !canOpen && !canClose {
+		return
+	}
+
+	d := &delimiter{
+		char:      c,
+		count:     p.pos - start,
+		origCount: p.pos - start,
+		node:      n,
+		canOpen:   canOpen,
+		canClose:  canClose,
+		prev:      p.delimiters,
+	}
+	if d.prev != nil {
+		d.prev.next = d
+	}
+	p.delimiters = d
+}
+
+func (p *inlineParser) removeDelimiter(d *delimiter) {
+	if d.prev != nil {
+		d.prev.next = d.next
+	}
+	if d.next != nil {
+		d.next.prev = d.prev
+	} else {
+		p.delimiters = d.prev
+	}
+}
+
+// processEmphasis matches the delimiters above bottom into emphasis, as in
+// the CommonMark spec.
+func (p *inlineParser) processEmphasis(bottom *delimiter) {
+	type key struct {
+		char    byte
+		canOpen bool
+		mod     int
+	}
+	openersBottom := make(map[key]*delimiter)
+
+	closer := p.delimiters
+	for closer != nil && closer.prev != bottom {
+		closer = closer.prev
+	}
+
+	for closer != nil {
+		if !closer.canClose {
+			closer = closer.next
+			continue
+		}
+
+		k := key{closer.char, closer.canOpen, closer.origCount % 3}
+		limit, ok := openersBottom[k]
+		if !ok {
+			limit = bottom
+		}
+
+		opener := closer.prev
+		found := false
+		for opener != nil && opener != bottom && opener != limit {
+			oddMatch := (closer.canOpen || opener.canClose) && closer.origCount%3 != 0 && (opener.origCount+closer.origCount)%3 == 0
+			if opener.char == closer.char && opener.canOpen && !oddMatch {
+				found = true
+				break
+			}
+			opener = opener.prev
+		}
+
+		if !found {
+			openersBottom[k] = closer.prev
+			next := closer.next
+			if !closer.canOpen {
+				p.removeDelimiter(closer)
+			}
+			closer = next
+			continue
+		}
+
+		use := 1
+		if opener.count >= 2 && closer.count >= 2 {
+			use = 2
+		}
+		opener.count -= use
+		closer.count -= use
+		opener.node.literal = opener.node.literal[:opener.count]
+		closer.node.literal = closer.node.literal[:closer.count]
+
+		emph := &node{kind: nodeEmph}
+		if use == 2 {
+			emph.kind = nodeStrong
+		}
+		for n := opener.node.next; n != nil && n != closer.node; {
+			next := n.next
+			emph.appendChild(n)
+			n = next
+		}
+		opener.node.insertAfter(emph)
+
+		// Delimiters between the opener and closer can no longer match.
+		opener.next = closer
+		closer.prev = opener
+
+		if opener.count == 0 {
+			opener.node.unlink()
+			p.removeDelimiter(opener)
+		}
+		if closer.count == 0 {
+			closer.node.unlink()
+			next := closer.next
+			p.removeDelimiter(closer)
+			closer = next
+		}
+	}
+
+	for p.delimiters != nil && p.delimiters != bottom {
+		p.removeDelimiter(p.delimiters)
+	}
+}
+
+func (p *inlineParser) pushBracket(n *node, image bool) {
+	if p.brackets != nil {
+		p.brackets.hasAfter = true
+	}
+	p.brackets = &bracket{
+		node:      n,
+		prev:      p.brackets,
+		prevDelim: p.delimiters,
+		index:     p.pos,
+		image:     image,
+		active:    true,
+	}
+}
+
+func (p *inlineParser) closeBracket() {
+	p.pos++
+	start := p.pos
+
+	opener := p.brackets
+	if opener == nil {
+		p.text("]")
+		return
+	}
+	if !opener.active {
+		p.brackets = opener.prev
+		p.text("]")
+		return
+	}
+
+	dest, title, matched := "", "", false
+
+	// An inline link: [text](dest "title").
+	if p.pos < len(p.s) && p.s[p.pos] == '(' {
+		pos := skipSpaceNewline(p.s, p.pos+1)
+		if pos < len(p.s) && p.s[pos] == ')' {
+			matched = true
+			p.pos = pos + 1
+		} else if d, end, ok := linkDestination(p.s, pos); ok {
+			pos = end
+			next := skipSpaceNewline(p.s, pos)
+			if next > pos {
+				if t, end, ok := linkTitle(p.s, next); ok {
+					title, next = t, skipSpaceNewline(p.s, end)
+				}
+			}
+			if next < len(p.s) && p.s[next] == ')' {
+				dest, matched = d, true
+				p.pos = next + 1
+			}
+		}
+	}
+
+	// A full, collapsed or shortcut reference link.
+	if !matched {
+		label, end := linkLabel(p.s, p.pos)
+		var ref string
+		switch {
+		case end > p.pos+2:
+			ref = label
+		case !opener.hasAfter:
+			ref = p.s[opener.index : start-1]
+		}
+		if ref != "" {
+			if r, ok := p.refs[normalizeLabel(ref)]; ok {
+				dest, title, matched = r.dest, r.title, true
+				if end > 0 {
+					p.pos = end
+				}
+			}
+		}
+	}
+
+	if !matched {
+		p.brackets = opener.prev
+		p.pos = start
+		p.text("]")
+		return
+	}
+
+	link := &node{kind: nodeLink, dest: dest, title: title}
+	if opener.image {
+		link.kind = nodeImage
+	}
+	for n := opener.node.next; n != nil; {
+		next := n.next
+		link.appendChild(n)
+		n = next
+	}
+	p.root.appendChild(link)
+
+	p.processEmphasis(opener.prevDelim)
+	p.brackets = opener.prev
+	opener.node.unlink()
+
+	// Links may not contain other links.
+	if !opener.image {
+		for b := p.brackets; b != nil; b = b.prev {
+			if !b.image {
+				b.active = false
+			}
+		}
+	}
+}
+
+func (p *inlineParser) angle() {
+	rest := p.s[p.pos:]
+
+	if m := autolink.FindString(rest); m != "" {
+		dest := m[1 : len(m)-1]
+		link := &node{kind: nodeLink, dest: dest}
+		link.appendChild(&node{kind: nodeText, literal: dest})
+		p.root.appendChild(link)
+		p.pos += len(m)
+		return
+	}
+	if m := emailLink.FindString(rest); m != "" {
+		email := m[1 : len(m)-1]
+		link := &node{kind: nodeLink, dest: "mailto:" + email}
+		link.appendChild(&node{kind: nodeText, literal: email})
+		p.root.appendChild(link)
+		p.pos += len(m)
+		return
+	}
+	if m := rawHTML.FindString(rest); m != "" {
+		p.root.appendChild(&node{kind: nodeHTML, literal: m})
+		p.pos += len(m)
+		return
+	}
+
+	p.pos++
+	p.text("<")
+}
+
+func (p *inlineParser) entity() {
+	if m := entity.FindString(p.s[p.pos:]); m != "" {
+		p.pos += len(m)
+		p.text(decodeEntity(m))
+		return
+	}
+
+	p.pos++
+	p.text("&")
+}
+
+// decodeEntity decodes a valid entity and leaves anything else as it is.
+func decodeEntity(s string) string {
+	decoded := html.UnescapeString(s)
+	if decoded == "\x00" || decoded == "" {
+		return "�"
+	}
+
+	return decoded
+}
+
+// linkLabel parses [label] at pos, returning the label and the position
+// after it, or 0 when there is none.
+func linkLabel(s string, pos int) (string, int) {
+	if pos >= len(s) || s[pos] != '[' {
+		return "", 0
+	}
+
+	for i := pos + 1; i < len(s) && i-pos <= 1000; i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			return "", 0
+		case ']':
+			label := s[pos+1 : i]
+			if strings.TrimSpace(label) == "" && i > pos+1 {
+				return "", 0
+			}
+			return label, i + 1
+		}
+	}
+
+	return "", 0
+}
+
+// linkDestination parses <dest> or a destination without spaces and with
+// balanced parentheses.
+func linkDestination(s string, pos int) (string, int, bool) {
+	if pos < len(s) && s[pos] == '<' {
+		for i := pos + 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '\n', '<':
+				return "", pos, false
+			case '>':
+				return unescape(s[pos+1 : i]), i + 1, true
+			}
+		}
+
+		return "", pos, false
+	}
+
+	depth := 0
+	i := pos
+loop:
+	for ; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			i++
+		case c == '(':
+			depth++
+			if depth > 32 {
+				return "", pos, false
+			}
+		case c == ')':
+			if depth == 0 {
+				break loop
+			}
+			depth--
+		case c <= ' ':
+			break loop
+		}
+	}
+	if i == pos || depth != 0 {
+		return "", pos, false
+	}
+
+	return unescape(s[pos:i]), i, true
+}
+
+func linkTitle(s string, pos int) (string, int, bool) {
+	if pos >= len(s) {
+		return "", pos, false
+	}
+
+	var closing byte
+	switch s[pos] {
+	case '"':
+		closing = '"'
+	case '\'':
+		closing = '\''
+	case '(':
+		closing = ')'
+	default:
+		return "", pos, false
+	}
+
+	for i := pos + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '(':
+			if closing == ')' {
+				return "", pos, false
+			}
+		case closing:
+			return unescape(s[pos+1 : i]), i + 1, true
+		}
+	}
+
+	return "", pos, false
+}
+
+// skipSpaceNewline skips spaces and tabs with at most one line ending.
+func skipSpaceNewline(s string, pos int) int {
+	newline := false
+	for pos < len(s) {
+		switch s[pos] {
+		case ' ', '\t':
+		case '\n':
+			if newline {
+				return pos
+			}
+			newline = true
+		default:
+			return pos
+		}
+		pos++
+	}
+
+	return pos
+}
+
+// normalizeLabel matches reference labels case-insensitively, with runs of
+// whitespace collapsed.
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.ToUpper(strings.Join(strings.Fields(label), " ")))
+}
+
+// unescape processes backslash escapes and entities in destinations, titles
+// and info strings.
+func unescape(s string) string {
+	if !strings.ContainsAny(s, `\&`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			b.WriteByte(s[i+1])
+			i++
+		case s[i] == '&':
+			if m := entity.FindString(s[i:]); m != "" {
+				b.WriteString(decodeEntity(m))
+				i += len(m) - 1
+				continue
+			}
+			b.WriteByte('&')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String()
+}
+
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func isPunct(r rune) bool {
+	if r < utf8.RuneSelf {
+		return isASCIIPunct(byte(r))
+	}
+
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
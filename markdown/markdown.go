@@ -0,0 +1,29 @@
+// Package markdown renders CommonMark, with GitHub's table extension, to
+// HTML.
+package markdown
+
+import "strings"
+
+// HTML renders the Markdown source. Raw HTML in the source is passed
+// through, so the output must be sanitized before it is served.
+func HTML(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\r", "\n")
+	src = strings.ReplaceAll(src, "\x00", "�")
+
+	lines := strings.Split(src, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for i, line := range lines {
+		lines[i] = expandTabs(line)
+	}
+
+	p := &parser{refs: make(map[string]reference)}
+	blocks := p.parseBlocks(lines)
+
+	r := &renderer{refs: p.refs}
+	r.blocks(blocks, false)
+
+	return r.b.String()
+}
@@ -0,0 +1,52 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		in, out string
+	}{
+		{"# Hello *world*\n", "<h1>Hello <em>world</em></h1>\n"},
+		{"Foo\n===\n\nBar\n---\n", "<h1>Foo</h1>\n<h2>Bar</h2>\n"},
+		{"### foo ###\n####### no\n", "<h3>foo</h3>\n<p>####### no</p>\n"},
+		{"a **b** _c_ `d`\n", "<p>a <strong>b</strong> <em>c</em> <code>d</code></p>\n"},
+		{"*foo**bar**baz*\n", "<p><em>foo<strong>bar</strong>baz</em></p>\n"},
+		{"***x***\n", "<p><em><strong>x</strong></em></p>\n"},
+		{"_foo_bar\n", "<p>_foo_bar</p>\n"},
+		{"**foo*\n", "<p>*<em>foo</em></p>\n"},
+		{"\\*not\\*\n", "<p>*not*</p>\n"},
+		{"line  \nbreak\nsoft\n", "<p>line<br />\nbreak\nsoft</p>\n"},
+		{"&amp; &copy; &foo; <b>raw</b>\n", "<p>&amp; © &amp;foo; <b>raw</b></p>\n"},
+		{"[a](/u \"t\") ![i](/i.png) <http://x.com>\n", "<p><a href=\"/u\" title=\"t\">a</a> <img src=\"/i.png\" alt=\"i\" /> <a href=\"http://x.com\">http://x.com</a></p>\n"},
+		{"[foo [bar](/u)](/v)\n", "<p>[foo <a href=\"/u\">bar</a>](/v)</p>\n"},
+		{"*foo [bar*](/url)\n", "<p>*foo <a href=\"/url\">bar*</a></p>\n"},
+		{"[x]\n\n[x]: /url \"title\"\n", "<p><a href=\"/url\" title=\"title\">x</a></p>\n"},
+		{"[link](/a b)\n", "<p>[link](/a b)</p>\n"},
+		{"[ä](/ä)\n", "<p><a href=\"/%C3%A4\">ä</a></p>\n"},
+		{"> quote\nlazy\n", "<blockquote>\n<p>quote\nlazy</p>\n</blockquote>\n"},
+		{"- a\n- b\n  - c\n", "<ul>\n<li>a</li>\n<li>b\n<ul>\n<li>c</li>\n</ul>\n</li>\n</ul>\n"},
+		{"- a\n\n- b\n", "<ul>\n<li>\n<p>a</p>\n</li>\n<li>\n<p>b</p>\n</li>\n</ul>\n"},
+		{"3. a\n4. b\n", "<ol start=\"3\">\n<li>a</li>\n<li>b</li>\n</ol>\n"},
+		{"para\n2. not a list\n", "<p>para\n2. not a list</p>\n"},
+		{"* * *\n", "<hr />\n"},
+		{"    code\n      more\n", "<pre><code>code\n  more\n</code></pre>\n"},
+		{"```go\nfunc main() {}\n```\n", "<pre><code class=\"language-go\">func main() {}\n</code></pre>\n"},
+		{"~~~\n<b>\n", "<pre><code>&lt;b&gt;\n</code></pre>\n"},
+		{"<div>\n*hi*\n</div>\n", "<div>\n*hi*\n</div>\n"},
+		{
+			"| a | b |\n|:-|:-:|\n| 1 | \\| |\n| 2 |\n",
+			"<table>\n<thead>\n<tr>\n<th align=\"left\">a</th>\n<th align=\"center\">b</th>\n</tr>\n</thead>\n<tbody>\n" +
+				"<tr>\n<td align=\"left\">1</td>\n<td align=\"center\">|</td>\n</tr>\n" +
+				"<tr>\n<td align=\"left\">2</td>\n<td align=\"center\"></td>\n</tr>\n</tbody>\n</table>\n",
+		},
+		{"a | b\n--|--\n", "<table>\n<thead>\n<tr>\n<th>a</th>\n<th>b</th>\n</tr>\n</thead>\n</table>\n"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.out, HTML(test.in), test.in)
+	}
+}
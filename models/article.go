@@ -1,12 +1,56 @@
 package models
 
+const (
+	ArticleDraft     = "draft"
+	ArticleSubmitted = "submitted"
+	ArticleRejected  = "rejected"
+	ArticleScheduled = "scheduled"
+	ArticlePublished = "published"
+
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+)
+
+// Article keeps its Markdown source in Description and the sanitized HTML
+// rendered from it in HTML. ReadingTime is in minutes. An approved article
+// is published at PublishAt, or right away when it is 0 or has passed. A
+// hidden article is left out until a moderator restores it.
 type Article struct {
 	ID          int64
 	Author      User
 	Picture     string
 	Title       string
 	Description string
+	HTML        string
+	Excerpt     string
+	ReadingTime int64
 	Category    string
+	Status      string
+	PublishAt   int64
+	PublishedAt int64
+	HiddenAt    int64
 	CreatedAt   int64
 	UpdatedAt   int64
 }
+
+// ArticleReview is an editor's decision on a submitted article.
+type ArticleReview struct {
+	ID        int64
+	ArticleID int64
+	Reviewer  User
+	Decision  string
+	Comment   string
+	CreatedAt int64
+}
+
+// ArticleRevision is a version of an article's title, body and category, and
+// the user who wrote it.
+type ArticleRevision struct {
+	ID          int64
+	ArticleID   int64
+	User        User
+	Title       string
+	Description string
+	Category    string
+	CreatedAt   int64
+}
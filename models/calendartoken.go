@@ -0,0 +1,7 @@
+package models
+
+type CalendarToken struct {
+	User      User
+	Token     string
+	CreatedAt int64
+}
@@ -0,0 +1,10 @@
+package models
+
+// The types of content that terms and reactions refer to.
+const (
+	ContentArticle    = "article"
+	ContentDiscussion = "discussion"
+	ContentWebinar    = "webinar"
+	ContentPodcast    = "podcast"
+	ContentComment    = "comment"
+)
@@ -1,12 +1,27 @@
 package models
 
+// Discussion is posted by Author. When Anonymous, others see it posted under
+// Pseudonym instead. Crisis is the language of the crisis terms found in it,
+// if any. A hidden discussion is left out until a moderator restores it.
 type Discussion struct {
 	ID          int64
 	Author      User
+	Anonymous   bool
+	Pseudonym   string
 	Picture     string
 	Title       string
 	Description string
 	Category    string
+	Crisis      string
+	HiddenAt    int64
 	CreatedAt   int64
 	UpdatedAt   int64
 }
+
+// Pseudonym is the name User posts under anonymously in a discussion.
+type Pseudonym struct {
+	DiscussionID int64
+	User         User
+	Name         string
+	CreatedAt    int64
+}
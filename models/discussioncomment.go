@@ -1,10 +1,22 @@
 package models
 
+// DiscussionComment is posted by Commentator. When Anonymous, others see it
+// posted under Pseudonym instead. Crisis is the language of the crisis terms
+// found in it, if any. A hidden comment is left out until a moderator
+// restores it.
 type DiscussionComment struct {
 	ID           int64
 	DiscussionID int64
+	ParentID     int64
+	Depth        int
+	ReplyCount   int64
 	Commentator  User
+	Anonymous    bool
+	Pseudonym    string
 	Description  string
+	Crisis       string
+	HiddenAt     int64
 	CreatedAt    int64
 	UpdatedAt    int64
+	DeletedAt    int64
 }
@@ -0,0 +1,15 @@
+package models
+
+type Job struct {
+	ID          int64
+	Kind        string
+	Key         string
+	Reference   string
+	Payload     []byte
+	RunAt       int64
+	Attempts    int64
+	MaxAttempts int64
+	LastError   string
+	CreatedAt   int64
+	UpdatedAt   int64
+}
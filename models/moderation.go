@@ -0,0 +1,60 @@
+package models
+
+const (
+	ReportOpen      = "open"
+	ReportResolved  = "resolved"
+	ReportDismissed = "dismissed"
+)
+
+const (
+	ModerationHide    = "hide"
+	ModerationRestore = "restore"
+	ModerationDelete  = "delete"
+	ModerationWarn    = "warn"
+	ModerationSuspend = "suspend"
+	ModerationDismiss = "dismiss"
+)
+
+// Report is a user's report of an item for Reason. Reports without a
+// Reporter are filed by the app itself, and Priority ones are urgent.
+type Report struct {
+	ID          int64
+	ContentType string
+	ContentID   int64
+	Reporter    User
+	Reason      string
+	Details     string
+	Status      string
+	Priority    bool
+	CreatedAt   int64
+	ResolvedAt  int64
+}
+
+// ReportedContent is an item with open reports, as queued for the moderators.
+// Reasons holds the reason of each report. It is Priority when any of them is.
+type ReportedContent struct {
+	ContentType     string
+	ContentID       int64
+	Title           string
+	Author          User
+	HiddenAt        int64
+	Priority        bool
+	Reports         int64
+	Reasons         []string
+	FirstReportedAt int64
+	LastReportedAt  int64
+}
+
+// ModerationAction is taken by Moderator on an item and its author, Target.
+// Until is the end of a suspension.
+type ModerationAction struct {
+	ID          int64
+	Moderator   User
+	Action      string
+	ContentType string
+	ContentID   int64
+	Target      User
+	Note        string
+	Until       int64
+	CreatedAt   int64
+}
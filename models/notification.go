@@ -0,0 +1,11 @@
+package models
+
+type Notification struct {
+	ID        int64
+	User      User
+	Reference string
+	Title     string
+	Body      string
+	ReadAt    int64
+	CreatedAt int64
+}
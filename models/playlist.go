@@ -0,0 +1,20 @@
+package models
+
+type Playlist struct {
+	ID          int64
+	Owner       User
+	Title       string
+	Description string
+	Public      bool
+	ShareToken  string
+	CreatedAt   int64
+	UpdatedAt   int64
+}
+
+type PlaylistItem struct {
+	ID         int64
+	PlaylistID int64
+	Podcast    Podcast
+	Position   int64
+	CreatedAt  int64
+}
@@ -7,6 +7,8 @@ type Podcast struct {
 	Title       string `db:"title"`
 	Description string `db:"description"`
 	File        string `db:"file"`
+	Duration    int64  `db:"duration"`
+	Likes       int64  `db:"likes"`
 	CreatedAt   int64  `db:"created_at"`
 	UpdatedAt   int64  `db:"updated_at"`
 }
@@ -0,0 +1,8 @@
+package models
+
+type PodcastListen struct {
+	Podcast        Podcast
+	User           User
+	Plays          int64
+	LastListenedAt int64
+}
@@ -0,0 +1,35 @@
+package models
+
+const (
+	ReactionLike     = "like"
+	ReactionBookmark = "bookmark"
+)
+
+// Reaction is a like or a bookmark of an item by a user.
+type Reaction struct {
+	ID          int64
+	Kind        string
+	ContentType string
+	ContentID   int64
+	User        User
+	CreatedAt   int64
+}
+
+// ReactionSummary is the number of likes of an item and whether a user liked
+// or bookmarked it.
+type ReactionSummary struct {
+	Likes      int64
+	Liked      bool
+	Bookmarked bool
+}
+
+// Bookmark is an item bookmarked by a user, with what is needed to list it.
+// The title of a comment is its text. CreatedAt is when it was bookmarked.
+type Bookmark struct {
+	ID          int64
+	ContentType string
+	ContentID   int64
+	Title       string
+	Picture     string
+	CreatedAt   int64
+}
@@ -0,0 +1,16 @@
+package models
+
+// SearchResult is an item matching a search. Title and Snippet have the
+// matched words wrapped in the selection markers the search was given.
+// Config is the text search configuration the item matched in.
+type SearchResult struct {
+	Type      string
+	ID        int64
+	Title     string
+	Snippet   string
+	Picture   string
+	Category  string
+	Rank      float64
+	Config    string
+	CreatedAt int64
+}
@@ -0,0 +1,27 @@
+package models
+
+const (
+	TermCategory = "category"
+	TermTag      = "tag"
+)
+
+// Term is a category or a tag. Translations maps a language code to the name
+// of the term in that language.
+type Term struct {
+	ID           int64
+	Kind         string
+	Slug         string
+	Name         string
+	Translations map[string]string
+	CreatedAt    int64
+	UpdatedAt    int64
+}
+
+// TermCount is a term with the number of items of each type that have it.
+// Only published articles are counted.
+type TermCount struct {
+	Term        Term
+	Articles    int64
+	Discussions int64
+	Webinars    int64
+}
@@ -0,0 +1,22 @@
+package models
+
+type Transcript struct {
+	PodcastID int64
+	VTT       string
+	PlainText string
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+type Chapter struct {
+	Start float64 `json:"start"`
+	Title string  `json:"title"`
+	URL   string  `json:"url,omitempty"`
+}
+
+type PodcastChapters struct {
+	PodcastID int64
+	Chapters  []Chapter
+	CreatedAt int64
+	UpdatedAt int64
+}
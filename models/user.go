@@ -4,14 +4,37 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	RoleUser      = "user"
+	RoleEditor    = "editor"
+	RoleModerator = "moderator"
+)
+
 type User struct {
-	ID        int64  `db:"id"`
-	Name      string `db:"name"`
-	Email     string `db:"email"`
-	Picture   string `db:"picture"`
-	Password  string `db:"password"`
-	CreatedAt int64  `db:"created_at"`
-	UpdatedAt int64  `db:"updated_at"`
+	ID             int64  `db:"id"`
+	Name           string `db:"name"`
+	Email          string `db:"email"`
+	Picture        string `db:"picture"`
+	Password       string `db:"password"`
+	Role           string `db:"role"`
+	CreatedAt      int64  `db:"created_at"`
+	UpdatedAt      int64  `db:"updated_at"`
+	SuspendedUntil int64  `db:"suspended_until"`
+}
+
+// IsEditor reports whether the user reviews articles.
+func (p *User) IsEditor() bool {
+	return p.Role == RoleEditor
+}
+
+// IsModerator reports whether the user moderates discussions.
+func (p *User) IsModerator() bool {
+	return p.Role == RoleModerator
+}
+
+// IsSuspended reports whether the user is barred from posting at now.
+func (p *User) IsSuspended(now int64) bool {
+	return p.SuspendedUntil > now
 }
 
 func (p *User) HashPassword() error {
@@ -1,15 +1,94 @@
 package models
 
+// Webinar carries a CheckInCode typed in by attendees and a CheckInToken
+// encoded in the QR code shown to them, both generated on first use and
+// only valid for the occurrence CheckInOccurrence.
 type Webinar struct {
-	ID          int64
-	Author      User
-	Picture     string
-	Title       string
-	Description string
-	Category    string
-	StartDate   int64
-	LastDate    int64
-	Time        string
-	CreatedAt   int64
-	UpdatedAt   int64
+	ID                int64
+	Author            User
+	Picture           string
+	Title             string
+	Description       string
+	Category          string
+	Link              string
+	StartAt           int64
+	EndAt             int64
+	Timezone          string
+	Sessions          []WebinarSession
+	Recurrence        string
+	Exceptions        []WebinarOccurrence
+	Speakers          []WebinarSpeaker
+	Cohosts           []User
+	Capacity          int64
+	Registered        int64
+	Sequence          int64
+	CancelledAt       int64
+	CheckInCode       string
+	CheckInToken      string
+	CheckInOccurrence int64
+	CreatedAt         int64
+	UpdatedAt         int64
+}
+
+// IsHost reports whether the user is the webinar's author or one of its
+// co-hosts, who may all edit the webinar.
+func (w *Webinar) IsHost(userID int64) bool {
+	if w.Author.ID == userID {
+		return true
+	}
+
+	for _, cohost := range w.Cohosts {
+		if cohost.ID == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WebinarChange records an edit of a webinar.
+type WebinarChange struct {
+	ID        int64
+	WebinarID int64
+	User      User
+	Changes   []FieldChange
+	CreatedAt int64
+}
+
+// FieldChange holds the old and new value of a field as shown to users. Long
+// fields such as the description are recorded without their values.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
+}
+
+type WebinarSession struct {
+	ID        int64
+	WebinarID int64
+	StartAt   int64
+	EndAt     int64
+}
+
+// WebinarOccurrence overrides a single occurrence of a recurring webinar.
+// RecurrenceID is the occurrence's start as generated by the rule.
+type WebinarOccurrence struct {
+	WebinarID    int64
+	RecurrenceID int64
+	StartAt      int64
+	EndAt        int64
+	Cancelled    bool
+	UpdatedAt    int64
+}
+
+// WebinarSpeaker is either a user of the app, linked by User.ID, or an
+// external guest. Name and Photo of a linked speaker fall back to the user's.
+type WebinarSpeaker struct {
+	ID        int64
+	WebinarID int64
+	User      User
+	Name      string
+	Bio       string
+	Photo     string
+	Position  int64
 }
@@ -0,0 +1,10 @@
+package models
+
+// WebinarAttendance records that a user checked in to a webinar, or to one
+// occurrence of a recurring webinar.
+type WebinarAttendance struct {
+	WebinarID   int64
+	Occurrence  int64
+	User        User
+	CheckedInAt int64
+}
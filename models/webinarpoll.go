@@ -0,0 +1,20 @@
+package models
+
+type WebinarPoll struct {
+	ID         int64
+	WebinarID  int64
+	Occurrence int64
+	AuthorID   int64
+	Question   string
+	Options    []WebinarPollOption
+	ClosedAt   int64
+	CreatedAt  int64
+}
+
+type WebinarPollOption struct {
+	ID       int64
+	PollID   int64
+	Body     string
+	Position int64
+	Votes    int64
+}
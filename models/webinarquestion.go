@@ -0,0 +1,12 @@
+package models
+
+type WebinarQuestion struct {
+	ID         int64
+	WebinarID  int64
+	Occurrence int64
+	User       User
+	Body       string
+	Upvotes    int64
+	AnsweredAt int64
+	CreatedAt  int64
+}
@@ -0,0 +1,16 @@
+package models
+
+const (
+	RegistrationRegistered = "registered"
+	RegistrationWaitlisted = "waitlisted"
+)
+
+type WebinarRegistration struct {
+	ID         int64
+	WebinarID  int64
+	Occurrence int64
+	User       User
+	Status     string
+	CreatedAt  int64
+	UpdatedAt  int64
+}
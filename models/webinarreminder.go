@@ -0,0 +1,7 @@
+package models
+
+type WebinarReminder struct {
+	WebinarID int64
+	User      User
+	CreatedAt int64
+}
@@ -0,0 +1,18 @@
+package moderation
+
+import (
+	"github.com/bagus2x/recovy/models"
+)
+
+// cursorToken is the position of an item in the queue. Items of each content
+// type are numbered apart, so the type breaks ties between ids.
+type cursorToken struct {
+	Priority   bool   `json:"p"`
+	ReportedAt int64  `json:"r"`
+	Type       string `json:"t"`
+	ID         int64  `json:"id"`
+}
+
+func newCursorToken(content models.ReportedContent) cursorToken {
+	return cursorToken{Priority: content.Priority, ReportedAt: content.FirstReportedAt, Type: content.ContentType, ID: content.ContentID}
+}
@@ -0,0 +1,453 @@
+package moderation
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/pagination"
+	"github.com/lib/pq"
+)
+
+type Repository interface {
+	CreateReport(ctx context.Context, report *models.Report) error
+	FindContent(ctx context.Context, contentType string, contentID int64) (models.ReportedContent, error)
+	FindQueue(ctx context.Context, params *QueueParams, cursor *cursorToken) ([]models.ReportedContent, error)
+	Moderate(ctx context.Context, action *models.ModerationAction) error
+	FindActions(ctx context.Context, params *ActionParams, key *pagination.Key) ([]models.ModerationAction, error)
+}
+
+// content is the table of the items of a content type. Comments are blanked
+// when deleted, so that their replies stay in the thread.
+type content struct {
+	table  string
+	author string
+	title  string
+	delete string
+}
+
+var contents = map[string]content{
+	models.ContentArticle: {
+		table:  "Article",
+		author: "author_id",
+		title:  "c.title",
+		delete: "DELETE FROM Article WHERE id = $1",
+	},
+	models.ContentDiscussion: {
+		table:  "Discussion",
+		author: "author_id",
+		title:  "c.title",
+		delete: "DELETE FROM Discussion WHERE id = $1",
+	},
+	models.ContentWebinar: {
+		table:  "Webinar",
+		author: "author_id",
+		title:  "c.title",
+		delete: "DELETE FROM Webinar WHERE id = $1",
+	},
+	models.ContentPodcast: {
+		table:  "Podcast",
+		author: "author_id",
+		title:  "c.title",
+		delete: "DELETE FROM Podcast WHERE id = $1",
+	},
+	models.ContentComment: {
+		table:  "Discussion_Comment",
+		author: "commentator_id",
+		title:  "LEFT(c.description, 255)",
+		delete: "UPDATE Discussion_Comment SET description = '', deleted_at = $2 WHERE id = $1",
+	},
+}
+
+func contentOf(contentType string) (content, error) {
+	c, ok := contents[contentType]
+	if !ok {
+		return content{}, fmt.Errorf("unknown content type %q", contentType)
+	}
+
+	return c, nil
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+var createReport = `
+	INSERT INTO
+		Report
+		(content_type, content_id, reporter_id, reason, details, status, priority, created_at)
+	SELECT
+		$1, $2, NULLIF($3, 0), $4, $5, $6, $7, $8
+	WHERE
+		EXISTS (SELECT 1 FROM %s WHERE id = $2 AND (hidden_at = 0 OR $3 = 0))
+	RETURNING
+		id
+`
+
+// CreateReport returns ENotFound when the item does not exist or is hidden,
+// and Econflict when the user reported it already. A report without a
+// reporter is filed by the app, of hidden items too.
+func (r *repository) CreateReport(ctx context.Context, report *models.Report) error {
+	c, err := contentOf(report.ContentType)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRowContext(
+		ctx,
+		fmt.Sprintf(createReport, c.table),
+		report.ContentType,
+		report.ContentID,
+		report.Reporter.ID,
+		report.Reason,
+		report.Details,
+		report.Status,
+		report.Priority,
+		report.CreatedAt,
+	).Scan(&report.ID)
+	if err == sql.ErrNoRows {
+		return app.NewError(err, app.ENotFound)
+	}
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return app.NewError(err, app.Econflict)
+	}
+
+	return err
+}
+
+var findContent = `
+	SELECT
+		%[2]s, au.id, au.name, c.hidden_at
+	FROM
+		%[1]s c
+	JOIN
+		App_User au
+	ON
+		c.%[3]s = au.id
+	WHERE
+		c.id = $1
+`
+
+// FindContent finds the item, hidden or not, without its reports.
+func (r *repository) FindContent(ctx context.Context, contentType string, contentID int64) (models.ReportedContent, error) {
+	c, err := contentOf(contentType)
+	if err != nil {
+		return models.ReportedContent{}, err
+	}
+
+	reported := models.ReportedContent{
+		ContentType: contentType,
+		ContentID:   contentID,
+	}
+
+	err = r.db.QueryRowContext(ctx, fmt.Sprintf(findContent, c.table, c.title, c.author), contentID).Scan(
+		&reported.Title,
+		&reported.Author.ID,
+		&reported.Author.Name,
+		&reported.HiddenAt,
+	)
+	if err == sql.ErrNoRows {
+		return models.ReportedContent{}, app.NewError(err, app.ENotFound)
+	} else if err != nil {
+		return models.ReportedContent{}, err
+	}
+
+	return reported, nil
+}
+
+var queue = `
+	SELECT
+		'%[1]s' AS type, c.id, %[3]s AS title, au.id AS author_id, au.name AS author_name, c.hidden_at,
+		q.priority, q.reports, q.reasons, q.first_reported_at, q.last_reported_at
+	FROM
+		(
+			SELECT content_id, BOOL_OR(priority) AS priority, COUNT(*) AS reports, ARRAY_AGG(reason) AS reasons, MIN(created_at) AS first_reported_at, MAX(created_at) AS last_reported_at
+			FROM Report WHERE content_type = '%[1]s' AND status = 'open' GROUP BY content_id
+		) q
+	JOIN
+		%[2]s c ON c.id = q.content_id
+	JOIN
+		App_User au ON au.id = c.%[4]s`
+
+// findQueue builds a keyset query over the items of the types with open
+// reports, the priority ones first, then the longest waiting.
+func findQueue(params *QueueParams, cursor *cursorToken) (string, []interface{}) {
+	query := strings.Builder{}
+	args := make([]interface{}, 0)
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	selects := make([]string, 0, len(params.Types))
+	for _, contentType := range params.Types {
+		c := contents[contentType]
+		selects = append(selects, fmt.Sprintf(queue, contentType, c.table, c.title, c.author))
+	}
+
+	fmt.Fprintf(&query, `
+		SELECT
+			q.type, q.id, q.title, q.author_id, q.author_name, q.hidden_at, q.priority, q.reports, q.reasons, q.first_reported_at, q.last_reported_at
+		FROM
+			(%s) q
+		WHERE
+			TRUE`,
+		strings.Join(selects, " UNION ALL "),
+	)
+
+	// Dynamic query
+	if params.Priority {
+		query.WriteString(" AND q.priority ")
+	}
+
+	// Cursor
+	op, order := params.Order(false)
+	if cursor != nil {
+		fmt.Fprintf(&query, " AND (NOT q.priority, q.first_reported_at, q.type, q.id) %s (%s, %s, %s, %s) ", op, arg(!cursor.Priority), arg(cursor.ReportedAt), arg(cursor.Type), arg(cursor.ID))
+	}
+
+	// Limit
+	fmt.Fprintf(&query, " ORDER BY NOT q.priority %[1]s, q.first_reported_at %[1]s, q.type %[1]s, q.id %[1]s LIMIT %[2]s", order, arg(params.Limit))
+
+	return query.String(), args
+}
+
+func (r *repository) FindQueue(ctx context.Context, params *QueueParams, cursor *cursorToken) ([]models.ReportedContent, error) {
+	query, args := findQueue(params, cursor)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]models.ReportedContent, 0)
+
+	for rows.Next() {
+		var reported models.ReportedContent
+
+		err := rows.Scan(
+			&reported.ContentType,
+			&reported.ContentID,
+			&reported.Title,
+			&reported.Author.ID,
+			&reported.Author.Name,
+			&reported.HiddenAt,
+			&reported.Priority,
+			&reported.Reports,
+			pq.Array(&reported.Reasons),
+			&reported.FirstReportedAt,
+			&reported.LastReportedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, reported)
+	}
+
+	return res, rows.Err()
+}
+
+var hide = `
+	UPDATE %s SET hidden_at = $2 WHERE id = $1
+`
+
+var restore = `
+	UPDATE %s SET hidden_at = 0 WHERE id = $1
+`
+
+var suspend = `
+	UPDATE App_User SET suspended_until = $2 WHERE id = $1
+`
+
+var resolveReports = `
+	UPDATE
+		Report
+	SET
+		status = $3, resolved_at = $4
+	WHERE
+		content_type = $1 AND content_id = $2 AND status = 'open'
+`
+
+var resolveHeld = `
+	UPDATE
+		Report
+	SET
+		status = 'resolved', resolved_at = $3
+	WHERE
+		content_type = $1 AND content_id = $2 AND status = 'open' AND reason = 'filter'
+`
+
+var createAction = `
+	INSERT INTO
+		Moderation_Action
+		(moderator_id, action, content_type, content_id, target_id, note, until, created_at)
+	VALUES
+		($1, $2, $3, $4, NULLIF($5, 0), $6, $7, $8)
+	RETURNING
+		id
+`
+
+// Moderate takes the action on the item or its author, closes the open
+// reports of the item, and records the action, all or none. Restoring an item
+// only closes the reports that held it for review.
+func (r *repository) Moderate(ctx context.Context, action *models.ModerationAction) error {
+	c, err := contentOf(action.ContentType)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	switch action.Action {
+	case models.ModerationHide:
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(hide, c.table), action.ContentID, action.CreatedAt)
+	case models.ModerationRestore:
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(restore, c.table), action.ContentID)
+	case models.ModerationDelete:
+		if action.ContentType == models.ContentComment {
+			_, err = tx.ExecContext(ctx, c.delete, action.ContentID, action.CreatedAt)
+		} else {
+			_, err = tx.ExecContext(ctx, c.delete, action.ContentID)
+		}
+	case models.ModerationSuspend:
+		_, err = tx.ExecContext(ctx, suspend, action.Target.ID, action.Until)
+	}
+	if err != nil {
+		return err
+	}
+
+	if action.Action != models.ModerationRestore {
+		status := models.ReportResolved
+		if action.Action == models.ModerationDismiss {
+			status = models.ReportDismissed
+		}
+
+		_, err = tx.ExecContext(ctx, resolveReports, action.ContentType, action.ContentID, status, action.CreatedAt)
+	} else {
+		_, err = tx.ExecContext(ctx, resolveHeld, action.ContentType, action.ContentID, action.CreatedAt)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(
+		ctx,
+		createAction,
+		action.Moderator.ID,
+		action.Action,
+		action.ContentType,
+		action.ContentID,
+		action.Target.ID,
+		action.Note,
+		action.Until,
+		action.CreatedAt,
+	).Scan(&action.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// findActions builds a keyset query listing moderation actions newest first,
+// with ma.id as the tie breaker.
+func findActions(params *ActionParams, key *pagination.Key) (string, []interface{}) {
+	query := strings.Builder{}
+	args := make([]interface{}, 0)
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	query.WriteString(`
+		SELECT
+			ma.id, COALESCE(mu.id, 0), COALESCE(mu.name, ''), ma.action, ma.content_type, ma.content_id,
+			COALESCE(tu.id, 0), COALESCE(tu.name, ''), ma.note, ma.until, ma.created_at
+		FROM
+			Moderation_Action ma
+		LEFT JOIN
+			App_User mu
+		ON
+			ma.moderator_id = mu.id
+		LEFT JOIN
+			App_User tu
+		ON
+			ma.target_id = tu.id
+		WHERE
+			TRUE`,
+	)
+
+	// Dynamic query
+	if params.ContentType != "" {
+		fmt.Fprintf(&query, " AND ma.content_type = %s ", arg(params.ContentType))
+	}
+	if params.ContentID != 0 {
+		fmt.Fprintf(&query, " AND ma.content_id = %s ", arg(params.ContentID))
+	}
+	if params.TargetID != 0 {
+		fmt.Fprintf(&query, " AND ma.target_id = %s ", arg(params.TargetID))
+	}
+
+	// Cursor
+	op, order := params.Order(true)
+	if key != nil {
+		fmt.Fprintf(&query, " AND (ma.created_at, ma.id) %s (%s, %s) ", op, arg(key.Value), arg(key.ID))
+	}
+
+	// Limit
+	fmt.Fprintf(&query, " ORDER BY ma.created_at %s, ma.id %s LIMIT %s", order, order, arg(params.Limit))
+
+	return query.String(), args
+}
+
+func (r *repository) FindActions(ctx context.Context, params *ActionParams, key *pagination.Key) ([]models.ModerationAction, error) {
+	query, args := findActions(params, key)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]models.ModerationAction, 0)
+
+	for rows.Next() {
+		var action models.ModerationAction
+
+		err := rows.Scan(
+			&action.ID,
+			&action.Moderator.ID,
+			&action.Moderator.Name,
+			&action.Action,
+			&action.ContentType,
+			&action.ContentID,
+			&action.Target.ID,
+			&action.Target.Name,
+			&action.Note,
+			&action.Until,
+			&action.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, action)
+	}
+
+	return res, rows.Err()
+}
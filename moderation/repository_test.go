@@ -0,0 +1,35 @@
+package moderation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/pagination"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindQueueKeyset(t *testing.T) {
+	cursor := cursorToken{Priority: true, ReportedAt: 100, Type: models.ContentComment, ID: 7}
+	params := QueueParams{
+		Params: pagination.Params{Limit: 5},
+		Types:  []string{models.ContentDiscussion, models.ContentComment},
+	}
+
+	query, args := findQueue(&params, &cursor)
+	assert.Equal(t, 1, strings.Count(query, "UNION ALL"))
+	assert.Contains(t, query, "content_type = 'comment' AND status = 'open'")
+	assert.Contains(t, query, "JOIN\n\t\tDiscussion_Comment c ON c.id = q.content_id")
+	assert.Contains(t, query, "App_User au ON au.id = c.commentator_id")
+	assert.Contains(t, query, "(NOT q.priority, q.first_reported_at, q.type, q.id) > ($1, $2, $3, $4)")
+	assert.True(t, strings.HasSuffix(query, "ORDER BY NOT q.priority ASC, q.first_reported_at ASC, q.type ASC, q.id ASC LIMIT $5"))
+	assert.Equal(t, []interface{}{false, int64(100), models.ContentComment, int64(7), int64(5)}, args)
+
+	assert.NotContains(t, query, "AND q.priority")
+
+	params.Direction = pagination.DirectionPrevious
+	params.Priority = true
+	query, _ = findQueue(&params, &cursor)
+	assert.Contains(t, query, "AND q.priority")
+	assert.Contains(t, query, "(NOT q.priority, q.first_reported_at, q.type, q.id) < ($1, $2, $3, $4)")
+}
@@ -0,0 +1,337 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/auth"
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/notification"
+	"github.com/bagus2x/recovy/pagination"
+)
+
+type Service interface {
+	Report(ctx context.Context, req *CreateReportReq) (CreateReportResp, error)
+	Flag(ctx context.Context, contentType string, contentID int64, details string) error
+	Hold(ctx context.Context, contentType string, contentID int64, details string) error
+	GetQueue(ctx context.Context, params *QueueParams) (GetQueueResp, error)
+	Moderate(ctx context.Context, req *ModerateReq) (Action, error)
+	GetActions(ctx context.Context, params *ActionParams) (GetActionsResp, error)
+}
+
+type service struct {
+	moderationRepo   Repository
+	userRepo         auth.Repository
+	notificationRepo notification.Repository
+}
+
+func NewService(moderationRepo Repository, userRepo auth.Repository, notificationRepo notification.Repository) Service {
+	return &service{
+		moderationRepo:   moderationRepo,
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+	}
+}
+
+// Report lets a user report an item to the moderators.
+func (s *service) Report(ctx context.Context, req *CreateReportReq) (CreateReportResp, error) {
+	err := req.Validate()
+	if err != nil {
+		return CreateReportResp{}, err
+	}
+
+	report := models.Report{
+		ContentType: req.ContentType,
+		ContentID:   req.ContentID,
+		Reporter:    models.User{ID: req.ReporterID},
+		Reason:      req.Reason,
+		Details:     req.Details,
+		Status:      models.ReportOpen,
+		CreatedAt:   time.Now().Unix(),
+	}
+
+	err = s.moderationRepo.CreateReport(ctx, &report)
+	switch app.ErrorCode(err) {
+	case app.Econflict:
+		return CreateReportResp{}, app.NewError(err, app.Econflict, "Already reported")
+	case app.ENotFound:
+		return CreateReportResp{}, app.NewError(err, app.ENotFound, title(req.ContentType)+" not found")
+	}
+	if err != nil {
+		return CreateReportResp{}, err
+	}
+
+	return CreateReportResp{
+		ID:          report.ID,
+		ContentType: report.ContentType,
+		ContentID:   report.ContentID,
+		Reason:      report.Reason,
+		Details:     report.Details,
+		CreatedAt:   report.CreatedAt,
+	}, nil
+}
+
+// Flag reports an item on behalf of the app, at the top of the queue, as its
+// author may be at risk of self-harm.
+func (s *service) Flag(ctx context.Context, contentType string, contentID int64, details string) error {
+	report := models.Report{
+		ContentType: contentType,
+		ContentID:   contentID,
+		Reason:      "self_harm",
+		Details:     details,
+		Status:      models.ReportOpen,
+		Priority:    true,
+		CreatedAt:   time.Now().Unix(),
+	}
+
+	return s.moderationRepo.CreateReport(ctx, &report)
+}
+
+// Hold reports an item the content filter held for review, hidden until a
+// moderator restores it.
+func (s *service) Hold(ctx context.Context, contentType string, contentID int64, details string) error {
+	report := models.Report{
+		ContentType: contentType,
+		ContentID:   contentID,
+		Reason:      "filter",
+		Details:     details,
+		Status:      models.ReportOpen,
+		CreatedAt:   time.Now().Unix(),
+	}
+
+	return s.moderationRepo.CreateReport(ctx, &report)
+}
+
+// GetQueue lists the reported items to moderators, the priority ones first,
+// then the longest waiting.
+func (s *service) GetQueue(ctx context.Context, params *QueueParams) (GetQueueResp, error) {
+	err := s.mustBeModerator(ctx, params.ModeratorID)
+	if err != nil {
+		return GetQueueResp{}, err
+	}
+
+	if len(params.Types) == 0 {
+		params.Types = ContentTypes
+	}
+	for _, contentType := range params.Types {
+		if _, ok := contents[contentType]; !ok {
+			return GetQueueResp{}, app.NewError(nil, app.EBadRequest, "Type must be one of ["+strings.Join(ContentTypes, " ")+"]")
+		}
+	}
+
+	var cursor *cursorToken
+	err = params.Normalize(&cursor)
+	if err != nil {
+		return GetQueueResp{}, err
+	}
+
+	queue, err := s.moderationRepo.FindQueue(ctx, params, cursor)
+	if err != nil {
+		return GetQueueResp{}, err
+	}
+
+	if params.Previous() {
+		pagination.Reverse(queue)
+	}
+
+	resp := GetQueueResp{
+		Items: make([]QueueItem, 0, len(queue)),
+	}
+	for _, reported := range queue {
+		reasons := make(map[string]int64)
+		for _, reason := range reported.Reasons {
+			reasons[reason]++
+		}
+
+		resp.Items = append(resp.Items, QueueItem{
+			ContentType:     reported.ContentType,
+			ContentID:       reported.ContentID,
+			Title:           reported.Title,
+			Author:          User{ID: reported.Author.ID, Name: reported.Author.Name},
+			Hidden:          reported.HiddenAt != 0,
+			Priority:        reported.Priority,
+			Reports:         reported.Reports,
+			Reasons:         reasons,
+			FirstReportedAt: reported.FirstReportedAt,
+			LastReportedAt:  reported.LastReportedAt,
+		})
+	}
+	if len(queue) > 0 {
+		resp.Cursor = pagination.NewCursor(newCursorToken(queue[0]), newCursorToken(queue[len(queue)-1]))
+	}
+
+	return resp, nil
+}
+
+// Moderate lets a moderator take an action on an item or its author. The
+// author is notified of every action but dismiss and restore.
+func (s *service) Moderate(ctx context.Context, req *ModerateReq) (Action, error) {
+	err := req.Validate()
+	if err != nil {
+		return Action{}, err
+	}
+
+	err = s.mustBeModerator(ctx, req.ModeratorID)
+	if err != nil {
+		return Action{}, err
+	}
+
+	reported, err := s.moderationRepo.FindContent(ctx, req.ContentType, req.ContentID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return Action{}, app.NewError(err, app.ENotFound, title(req.ContentType)+" not found")
+	} else if err != nil {
+		return Action{}, err
+	}
+
+	now := time.Now().Unix()
+	action := models.ModerationAction{
+		Moderator:   models.User{ID: req.ModeratorID},
+		Action:      req.Action,
+		ContentType: req.ContentType,
+		ContentID:   req.ContentID,
+		Target:      reported.Author,
+		Note:        req.Note,
+		CreatedAt:   now,
+	}
+
+	switch req.Action {
+	case models.ModerationHide:
+		if reported.HiddenAt != 0 {
+			return Action{}, app.NewError(nil, app.Econflict, "Already hidden")
+		}
+	case models.ModerationRestore:
+		if reported.HiddenAt == 0 {
+			return Action{}, app.NewError(nil, app.EBadRequest, "Not hidden")
+		}
+	case models.ModerationSuspend:
+		if req.Days == 0 {
+			return Action{}, app.NewError(nil, app.EBadRequest, "Days is required to suspend")
+		}
+		action.Until = now + req.Days*24*60*60
+	}
+
+	err = s.moderationRepo.Moderate(ctx, &action)
+	if err != nil {
+		return Action{}, err
+	}
+
+	if notice, ok := notice(&action, reported.Title); ok {
+		err = s.notificationRepo.Create(ctx, []models.Notification{notice})
+		if err != nil {
+			return Action{}, err
+		}
+	}
+
+	return Action{
+		ID:          action.ID,
+		Moderator:   User{ID: action.Moderator.ID},
+		Action:      action.Action,
+		ContentType: action.ContentType,
+		ContentID:   action.ContentID,
+		Target:      User{ID: action.Target.ID, Name: action.Target.Name},
+		Note:        action.Note,
+		Until:       action.Until,
+		CreatedAt:   action.CreatedAt,
+	}, nil
+}
+
+// GetActions lists the moderation log to moderators, newest first.
+func (s *service) GetActions(ctx context.Context, params *ActionParams) (GetActionsResp, error) {
+	err := s.mustBeModerator(ctx, params.ModeratorID)
+	if err != nil {
+		return GetActionsResp{}, err
+	}
+
+	var key *pagination.Key
+	err = params.Normalize(&key)
+	if err != nil {
+		return GetActionsResp{}, err
+	}
+
+	actions, err := s.moderationRepo.FindActions(ctx, params, key)
+	if err != nil {
+		return GetActionsResp{}, err
+	}
+
+	if params.Previous() {
+		pagination.Reverse(actions)
+	}
+
+	resp := GetActionsResp{
+		Actions: make([]Action, 0, len(actions)),
+	}
+	for _, action := range actions {
+		resp.Actions = append(resp.Actions, Action{
+			ID:          action.ID,
+			Moderator:   User{ID: action.Moderator.ID, Name: action.Moderator.Name},
+			Action:      action.Action,
+			ContentType: action.ContentType,
+			ContentID:   action.ContentID,
+			Target:      User{ID: action.Target.ID, Name: action.Target.Name},
+			Note:        action.Note,
+			Until:       action.Until,
+			CreatedAt:   action.CreatedAt,
+		})
+	}
+	if len(actions) > 0 {
+		first, last := actions[0], actions[len(actions)-1]
+		resp.Cursor = pagination.NewCursor(
+			pagination.Key{Value: first.CreatedAt, ID: first.ID},
+			pagination.Key{Value: last.CreatedAt, ID: last.ID},
+		)
+	}
+
+	return resp, nil
+}
+
+func (s *service) mustBeModerator(ctx context.Context, userID int64) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return app.NewError(err, app.EForbidden, "Only moderators can moderate content")
+	} else if err != nil {
+		return err
+	}
+
+	if !user.IsModerator() {
+		return app.NewError(nil, app.EForbidden, "Only moderators can moderate content")
+	}
+
+	return nil
+}
+
+// notice tells the author what the moderators did to their item, or to them.
+func notice(action *models.ModerationAction, contentTitle string) (models.Notification, bool) {
+	var head string
+	switch action.Action {
+	case models.ModerationHide:
+		head = fmt.Sprintf("Your %s was hidden by the moderators", action.ContentType)
+	case models.ModerationDelete:
+		head = fmt.Sprintf("Your %s was removed by the moderators", action.ContentType)
+	case models.ModerationWarn:
+		head = "You received a warning from the moderators"
+	case models.ModerationSuspend:
+		head = "Your account is suspended until " + time.Unix(action.Until, 0).UTC().Format("2 January 2006 15:04 MST")
+	default:
+		return models.Notification{}, false
+	}
+
+	body := fmt.Sprintf("About your %s %q.", action.ContentType, contentTitle)
+	if action.Note != "" {
+		body += " " + action.Note
+	}
+
+	return models.Notification{
+		User:      action.Target,
+		Reference: fmt.Sprintf("moderation:%d", action.ID),
+		Title:     head,
+		Body:      body,
+		CreatedAt: action.CreatedAt,
+	}, true
+}
+
+func title(contentType string) string {
+	return strings.ToUpper(contentType[:1]) + contentType[1:]
+}
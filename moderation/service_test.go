@@ -0,0 +1,36 @@
+package moderation
+
+import (
+	"testing"
+
+	"github.com/bagus2x/recovy/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotice(t *testing.T) {
+	action := models.ModerationAction{
+		ID:          3,
+		Action:      models.ModerationHide,
+		ContentType: models.ContentDiscussion,
+		Target:      models.User{ID: 9},
+		Note:        "Please keep it kind.",
+		CreatedAt:   100,
+	}
+
+	notification, ok := notice(&action, "Hello")
+	assert.True(t, ok)
+	assert.Equal(t, int64(9), notification.User.ID)
+	assert.Equal(t, "moderation:3", notification.Reference)
+	assert.Equal(t, "Your discussion was hidden by the moderators", notification.Title)
+	assert.Equal(t, `About your discussion "Hello". Please keep it kind.`, notification.Body)
+
+	action.Action = models.ModerationSuspend
+	action.Until = 86400
+	notification, ok = notice(&action, "Hello")
+	assert.True(t, ok)
+	assert.Equal(t, "Your account is suspended until 2 January 1970 00:00 UTC", notification.Title)
+
+	action.Action = models.ModerationDismiss
+	_, ok = notice(&action, "Hello")
+	assert.False(t, ok)
+}
@@ -0,0 +1,121 @@
+package moderation
+
+import (
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/pagination"
+	"github.com/go-playground/validator/v10"
+)
+
+// ContentTypes are the types of content that can be reported and moderated.
+var ContentTypes = []string{
+	models.ContentArticle,
+	models.ContentDiscussion,
+	models.ContentWebinar,
+	models.ContentPodcast,
+	models.ContentComment,
+}
+
+type CreateReportReq struct {
+	ReporterID  int64  `json:"reporterID" validate:"required,gt=0"`
+	ContentType string `json:"contentType" validate:"required,oneof=article discussion webinar podcast comment"`
+	ContentID   int64  `json:"contentID" validate:"required,gt=0"`
+	Reason      string `json:"reason" validate:"required,oneof=spam harassment hate self_harm misinformation other"`
+	Details     string `json:"details" validate:"lte=1000"`
+}
+
+func (r *CreateReportReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+
+	return app.ValidateAndTranslate(validate, err)
+}
+
+type CreateReportResp struct {
+	ID          int64  `json:"id"`
+	ContentType string `json:"contentType"`
+	ContentID   int64  `json:"contentID"`
+	Reason      string `json:"reason"`
+	Details     string `json:"details"`
+	CreatedAt   int64  `json:"createdAt"`
+}
+
+// ModerateReq takes Action on an item. Warn and suspend are aimed at the
+// author of the item, who is suspended for Days. Every action but restore
+// closes the open reports of the item; restore closes those of the content
+// filter, approving a held item.
+type ModerateReq struct {
+	ModeratorID int64  `json:"moderatorID" validate:"required,gt=0"`
+	Action      string `json:"action" validate:"required,oneof=hide restore delete warn suspend dismiss"`
+	ContentType string `json:"contentType" validate:"required,oneof=article discussion webinar podcast comment"`
+	ContentID   int64  `json:"contentID" validate:"required,gt=0"`
+	Note        string `json:"note" validate:"lte=1000"`
+	Days        int64  `json:"days" validate:"gte=0,lte=3650"`
+}
+
+func (r *ModerateReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+
+	return app.ValidateAndTranslate(validate, err)
+}
+
+type User struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type Action struct {
+	ID          int64  `json:"id"`
+	Moderator   User   `json:"moderator"`
+	Action      string `json:"action"`
+	ContentType string `json:"contentType"`
+	ContentID   int64  `json:"contentID"`
+	Target      User   `json:"target"`
+	Note        string `json:"note"`
+	Until       int64  `json:"until"`
+	CreatedAt   int64  `json:"createdAt"`
+}
+
+// QueueParams lists the reported items of Types, all of them when empty.
+// Priority lists only the priority items.
+type QueueParams struct {
+	pagination.Params
+	ModeratorID int64
+	Types       []string
+	Priority    bool
+}
+
+// QueueItem is a reported item with its open reports counted by reason.
+type QueueItem struct {
+	ContentType     string           `json:"contentType"`
+	ContentID       int64            `json:"contentID"`
+	Title           string           `json:"title"`
+	Author          User             `json:"author"`
+	Hidden          bool             `json:"hidden"`
+	Priority        bool             `json:"priority"`
+	Reports         int64            `json:"reports"`
+	Reasons         map[string]int64 `json:"reasons"`
+	FirstReportedAt int64            `json:"firstReportedAt"`
+	LastReportedAt  int64            `json:"lastReportedAt"`
+}
+
+type GetQueueResp struct {
+	Cursor pagination.Cursor `json:"cursor"`
+	Items  []QueueItem       `json:"items"`
+}
+
+// ActionParams filters the log of moderation actions. ContentID selects the
+// actions on one item of ContentType, TargetID those aimed at a user.
+type ActionParams struct {
+	pagination.Params
+	ModeratorID int64
+	ContentType string
+	ContentID   int64
+	TargetID    int64
+}
+
+type GetActionsResp struct {
+	Cursor  pagination.Cursor `json:"cursor"`
+	Actions []Action          `json:"actions"`
+}
@@ -0,0 +1,184 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+)
+
+type Repository interface {
+	Create(ctx context.Context, notifications []models.Notification) error
+	FindByUserID(ctx context.Context, userID, limit int64) ([]models.Notification, error)
+	CountUnread(ctx context.Context, userID int64) (int64, error)
+	MarkRead(ctx context.Context, notificationID, userID, readAt int64) error
+	MarkAllRead(ctx context.Context, userID, readAt int64) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+var create = `
+	INSERT INTO
+		Notification
+		(user_id, reference, title, body, created_at)
+	VALUES
+		($1, $2, $3, $4, $5)
+	RETURNING
+		id
+`
+
+// Create stores the notifications all or none.
+func (r *repository) Create(ctx context.Context, notifications []models.Notification) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i := range notifications {
+		notification := &notifications[i]
+
+		err := tx.QueryRowContext(
+			ctx,
+			create,
+			notification.User.ID,
+			notification.Reference,
+			notification.Title,
+			notification.Body,
+			notification.CreatedAt,
+		).Scan(&notification.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+var findByUserID = `
+	SELECT
+		id, user_id, reference, title, body, read_at, created_at
+	FROM
+		Notification
+	WHERE
+		user_id = $1
+	ORDER BY
+		created_at DESC, id DESC
+	LIMIT
+		$2
+`
+
+// FindByUserID returns the newest notifications of the user first.
+func (r *repository) FindByUserID(ctx context.Context, userID, limit int64) ([]models.Notification, error) {
+	notifications := make([]models.Notification, 0)
+
+	rows, err := r.db.QueryContext(ctx, findByUserID, userID, limit)
+	if err != nil {
+		return notifications, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var notification models.Notification
+
+		err := rows.Scan(
+			&notification.ID,
+			&notification.User.ID,
+			&notification.Reference,
+			&notification.Title,
+			&notification.Body,
+			&notification.ReadAt,
+			&notification.CreatedAt,
+		)
+		if err != nil {
+			return notifications, err
+		}
+
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, rows.Err()
+}
+
+var countUnread = `
+	SELECT
+		COUNT(*)
+	FROM
+		Notification
+	WHERE
+		user_id = $1 AND read_at = 0
+`
+
+func (r *repository) CountUnread(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx, countUnread, userID).Scan(&count)
+
+	return count, err
+}
+
+var markRead = `
+	UPDATE
+		Notification
+	SET
+		read_at = $3
+	WHERE
+		id = $1 AND user_id = $2 AND read_at = 0
+`
+
+var exists = `
+	SELECT EXISTS (
+		SELECT 1 FROM Notification WHERE id = $1 AND user_id = $2
+	)
+`
+
+// MarkRead marks a notification of the user as read. Marking it again is
+// not an error.
+func (r *repository) MarkRead(ctx context.Context, notificationID, userID, readAt int64) error {
+	res, err := r.db.ExecContext(ctx, markRead, notificationID, userID, readAt)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	var found bool
+	err = r.db.QueryRowContext(ctx, exists, notificationID, userID).Scan(&found)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return app.NewError(sql.ErrNoRows, app.ENotFound, "Notification not found")
+	}
+
+	return nil
+}
+
+var markAllRead = `
+	UPDATE
+		Notification
+	SET
+		read_at = $2
+	WHERE
+		user_id = $1 AND read_at = 0
+`
+
+func (r *repository) MarkAllRead(ctx context.Context, userID, readAt int64) error {
+	_, err := r.db.ExecContext(ctx, markAllRead, userID, readAt)
+
+	return err
+}
@@ -0,0 +1,63 @@
+package notification
+
+import (
+	"context"
+	"time"
+)
+
+// maxNotifications is how many of the newest notifications are listed.
+const maxNotifications = 50
+
+type Service interface {
+	GetByUserID(ctx context.Context, userID int64) (GetNotificationsResp, error)
+	MarkRead(ctx context.Context, notificationID, userID int64) error
+	MarkAllRead(ctx context.Context, userID int64) error
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{
+		repo: repo,
+	}
+}
+
+func (s *service) GetByUserID(ctx context.Context, userID int64) (GetNotificationsResp, error) {
+	notifications, err := s.repo.FindByUserID(ctx, userID, maxNotifications)
+	if err != nil {
+		return GetNotificationsResp{}, err
+	}
+
+	unread, err := s.repo.CountUnread(ctx, userID)
+	if err != nil {
+		return GetNotificationsResp{}, err
+	}
+
+	resp := GetNotificationsResp{
+		Unread:        unread,
+		Notifications: make([]Notification, 0, len(notifications)),
+	}
+	for _, notification := range notifications {
+		resp.Notifications = append(resp.Notifications, Notification{
+			ID:        notification.ID,
+			Reference: notification.Reference,
+			Title:     notification.Title,
+			Body:      notification.Body,
+			Read:      notification.ReadAt > 0,
+			ReadAt:    notification.ReadAt,
+			CreatedAt: notification.CreatedAt,
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *service) MarkRead(ctx context.Context, notificationID, userID int64) error {
+	return s.repo.MarkRead(ctx, notificationID, userID, time.Now().Unix())
+}
+
+func (s *service) MarkAllRead(ctx context.Context, userID int64) error {
+	return s.repo.MarkAllRead(ctx, userID, time.Now().Unix())
+}
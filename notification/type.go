@@ -0,0 +1,16 @@
+package notification
+
+type Notification struct {
+	ID        int64  `json:"id"`
+	Reference string `json:"reference"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	Read      bool   `json:"read"`
+	ReadAt    int64  `json:"readAt,omitempty"`
+	CreatedAt int64  `json:"createdAt"`
+}
+
+type GetNotificationsResp struct {
+	Unread        int64          `json:"unread"`
+	Notifications []Notification `json:"notifications"`
+}
@@ -0,0 +1,129 @@
+// Package pagination implements the keyset pagination shared by the lists of
+// the API. A page is asked for with an opaque cursor, a limit and a direction,
+// and every list answers with the cursors of the pages around it.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+
+	"github.com/bagus2x/recovy/app"
+)
+
+const (
+	DirectionNext     = "next"
+	DirectionPrevious = "previous"
+
+	DefaultLimit = 10
+	MaxLimit     = 100
+)
+
+// Params is the page of a list asked for. Without a cursor the list starts
+// from its first row.
+type Params struct {
+	Cursor    string
+	Limit     int64
+	Direction string
+}
+
+// Cursor holds the cursors of the pages after and before the returned one.
+// Both are empty when the page has no rows.
+type Cursor struct {
+	Next     string `json:"next"`
+	Previous string `json:"previous"`
+}
+
+// Key is the position of a row in a list ordered by an integer column, such
+// as a timestamp, with the id as the tie breaker.
+type Key struct {
+	Value int64 `json:"v"`
+	ID    int64 `json:"id"`
+}
+
+// Normalize clamps the limit, checks the direction and decodes the cursor
+// into key. Key is left untouched without a cursor.
+func (p *Params) Normalize(key interface{}) error {
+	p.Limit = Limit(p.Limit)
+
+	if p.Direction == "" {
+		p.Direction = DirectionNext
+	}
+	if p.Direction != DirectionNext && p.Direction != DirectionPrevious {
+		return app.NewError(nil, app.EBadRequest, "Direction must be one of [next previous]")
+	}
+
+	if p.Cursor != "" {
+		err := Decode(p.Cursor, key)
+		if err != nil {
+			return app.NewError(err, app.EBadRequest, "Invalid cursor")
+		}
+	}
+
+	return nil
+}
+
+// Previous reports whether the page before the cursor is asked for.
+func (p *Params) Previous() bool {
+	return p.Direction == DirectionPrevious
+}
+
+// Order returns the comparison that keeps the rows after the cursor and the
+// SQL order to fetch them in. Fetching the previous page flips both, the rows
+// are then put back in display order with Reverse.
+func (p *Params) Order(desc bool) (op, order string) {
+	if p.Previous() {
+		desc = !desc
+	}
+	if desc {
+		return "<", "DESC"
+	}
+
+	return ">", "ASC"
+}
+
+// Limit clamps the number of rows of a page, 0 meaning the default.
+func Limit(n int64) int64 {
+	if n <= 0 {
+		return DefaultLimit
+	}
+	if n > MaxLimit {
+		return MaxLimit
+	}
+
+	return n
+}
+
+// Encode turns the position of a row into an opaque cursor.
+func Encode(v interface{}) string {
+	data, _ := json.Marshal(v)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode reads the position of a row back from a cursor made by Encode.
+func Decode(s string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// NewCursor makes the cursors of a page from the positions of its first and
+// last rows.
+func NewCursor(first, last interface{}) Cursor {
+	return Cursor{
+		Next:     Encode(last),
+		Previous: Encode(first),
+	}
+}
+
+// Reverse reverses a slice in place.
+func Reverse(slice interface{}) {
+	swap := reflect.Swapper(slice)
+	for i, j := 0, reflect.ValueOf(slice).Len()-1; i < j; i, j = i+1, j-1 {
+		swap(i, j)
+	}
+}
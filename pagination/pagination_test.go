@@ -0,0 +1,51 @@
+package pagination
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	var key *Key
+	params := Params{Limit: 500}
+	assert.NoError(t, params.Normalize(&key))
+	assert.Equal(t, int64(MaxLimit), params.Limit)
+	assert.Equal(t, DirectionNext, params.Direction)
+	assert.Nil(t, key)
+
+	params = Params{Cursor: Encode(Key{Value: 1630497600, ID: 3}), Direction: DirectionPrevious}
+	assert.NoError(t, params.Normalize(&key))
+	assert.Equal(t, int64(DefaultLimit), params.Limit)
+	assert.Equal(t, &Key{Value: 1630497600, ID: 3}, key)
+
+	params = Params{Cursor: "not a cursor!"}
+	assert.Error(t, params.Normalize(&key))
+
+	params = Params{Direction: "sideways"}
+	assert.Error(t, params.Normalize(&key))
+}
+
+func TestOrder(t *testing.T) {
+	params := Params{Direction: DirectionNext}
+	op, order := params.Order(true)
+	assert.Equal(t, "<", op)
+	assert.Equal(t, "DESC", order)
+
+	params.Direction = DirectionPrevious
+	op, order = params.Order(true)
+	assert.Equal(t, ">", op)
+	assert.Equal(t, "ASC", order)
+
+	op, order = params.Order(false)
+	assert.Equal(t, "<", op)
+	assert.Equal(t, "DESC", order)
+}
+
+func TestReverse(t *testing.T) {
+	s := []int{1, 2, 3, 4}
+	Reverse(s)
+	assert.Equal(t, []int{4, 3, 2, 1}, s)
+
+	Reverse([]int{})
+}
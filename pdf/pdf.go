@@ -0,0 +1,153 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Font is one of the standard PDF fonts, which viewers provide themselves so
+// nothing has to be embedded.
+type Font string
+
+const (
+	Helvetica     Font = "F1"
+	HelveticaBold Font = "F2"
+)
+
+// A4 landscape in points.
+const (
+	A4Width  = 842
+	A4Height = 595
+)
+
+// Document is a single page PDF built from text, lines and rectangles.
+type Document struct {
+	Width   float64
+	Height  float64
+	content bytes.Buffer
+}
+
+func New(width, height float64) *Document {
+	return &Document{
+		Width:  width,
+		Height: height,
+	}
+}
+
+// Text draws s with its baseline starting at x, y, measured from the bottom
+// left corner.
+func (d *Document) Text(x, y float64, font Font, size float64, s string) {
+	fmt.Fprintf(&d.content, "BT /%s %s Tf %s %s Td (%s) Tj ET\n", font, num(size), num(x), num(y), escape(s))
+}
+
+// CenteredText draws s horizontally centered on the page.
+func (d *Document) CenteredText(y float64, font Font, size float64, s string) {
+	d.Text((d.Width-TextWidth(s, size))/2, y, font, size, s)
+}
+
+// Line strokes a line from x1, y1 to x2, y2.
+func (d *Document) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&d.content, "%s w %s %s m %s %s l S\n", num(width), num(x1), num(y1), num(x2), num(y2))
+}
+
+// Rect strokes a rectangle with its bottom left corner at x, y.
+func (d *Document) Rect(x, y, w, h, width float64) {
+	fmt.Fprintf(&d.content, "%s w %s %s %s %s re S\n", num(width), num(x), num(y), num(w), num(h))
+}
+
+// Color sets the color of the following text and strokes, with components
+// between 0 and 1.
+func (d *Document) Color(r, g, b float64) {
+	fmt.Fprintf(&d.content, "%s %s %s rg %s %s %s RG\n", num(r), num(g), num(b), num(r), num(g), num(b))
+}
+
+// Bytes encodes the document.
+func (d *Document) Bytes() []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>",
+			num(d.Width), num(d.Height),
+		),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", d.content.Len(), d.content.String()),
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, 0, len(objects))
+	for i, object := range objects {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return b.Bytes()
+}
+
+// TextWidth measures s in Helvetica. Bold text is slightly wider, which is
+// close enough for centering.
+func TextWidth(s string, size float64) float64 {
+	var width int
+	for _, r := range s {
+		if r >= 32 && r <= 126 {
+			width += helveticaWidths[r-32]
+		} else {
+			width += 556
+		}
+	}
+
+	return float64(width) * size / 1000
+}
+
+// escape encodes s as the contents of a PDF string in WinAnsiEncoding.
+// Characters the encoding lacks are replaced with a question mark.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 32 && r <= 126:
+			b.WriteRune(r)
+		case r >= 160 && r <= 255:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+
+	return b.String()
+}
+
+func num(f float64) string {
+	s := fmt.Sprintf("%.2f", f)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "" || s == "-" {
+		return "0"
+	}
+
+	return s
+}
+
+// helveticaWidths are the advance widths of the printable ASCII characters
+// in Helvetica, in thousandths of the font size.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
@@ -0,0 +1,43 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBytes(t *testing.T) {
+	doc := New(A4Width, A4Height)
+	doc.Color(0.2, 0.4, 0.6)
+	doc.Rect(20, 20, 802, 555, 2)
+	doc.CenteredText(400, HelveticaBold, 32, "Certificate (of) Participation")
+	doc.Text(100, 100, Helvetica, 12, "Café 中")
+
+	out := doc.Bytes()
+	assert.True(t, bytes.HasPrefix(out, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(out, []byte("%%EOF\n")))
+	assert.Contains(t, string(out), `(Certificate \(of\) Participation) Tj`)
+	assert.Contains(t, string(out), `(Caf\351 ?) Tj`)
+
+	// Every xref entry must point at its object.
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out, -1)
+	assert.Len(t, entries, 6)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		assert.True(t, bytes.HasPrefix(out[offset:], []byte(fmt.Sprintf("%d 0 obj", i+1))))
+	}
+
+	start := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(out)
+	offset, _ := strconv.Atoi(string(start[1]))
+	assert.True(t, bytes.HasPrefix(out[offset:], []byte("xref")))
+}
+
+func TestTextWidth(t *testing.T) {
+	assert.Equal(t, 5.56*2, TextWidth("ab", 10))
+	assert.Equal(t, "12.5", num(12.5))
+	assert.Equal(t, "3", num(3))
+}
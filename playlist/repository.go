@@ -0,0 +1,456 @@
+package playlist
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+	"github.com/lib/pq"
+)
+
+type Repository interface {
+	Create(ctx context.Context, playlist *models.Playlist) error
+	FindByID(ctx context.Context, playlistID int64) (models.Playlist, error)
+	FindByShareToken(ctx context.Context, token string) (models.Playlist, error)
+	FindByOwnerID(ctx context.Context, ownerID int64) ([]models.Playlist, error)
+	Update(ctx context.Context, playlist *models.Playlist) error
+	Delete(ctx context.Context, playlistID int64) error
+	AddItem(ctx context.Context, item *models.PlaylistItem) error
+	FindItems(ctx context.Context, playlistID int64) ([]models.PlaylistItem, error)
+	DeleteItem(ctx context.Context, playlistID, podcastID int64) error
+	MoveItem(ctx context.Context, playlistID, podcastID, position int64) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+var create = `
+	INSERT INTO
+		Playlist
+		(owner_id, title, description, public, share_token, created_at, updated_at)
+	VALUES
+		($1, $2, $3, $4, $5, $6, $7)
+	RETURNING
+		id
+`
+
+func (r *repository) Create(ctx context.Context, playlist *models.Playlist) error {
+	err := r.db.QueryRowContext(
+		ctx,
+		create,
+		playlist.Owner.ID,
+		playlist.Title,
+		playlist.Description,
+		playlist.Public,
+		playlist.ShareToken,
+		playlist.CreatedAt,
+		playlist.UpdatedAt,
+	).Scan(&playlist.ID)
+
+	return err
+}
+
+var findByID = `
+	SELECT
+		pl.id, au.id, au.name, au.picture, pl.title, pl.description, pl.public, pl.share_token, pl.created_at, pl.updated_at
+	FROM
+		Playlist pl
+	JOIN
+		App_User au
+	ON
+		pl.owner_id = au.id
+	WHERE
+		pl.id = $1
+`
+
+func (r *repository) FindByID(ctx context.Context, playlistID int64) (models.Playlist, error) {
+	return r.findOne(ctx, findByID, playlistID)
+}
+
+var findByShareToken = `
+	SELECT
+		pl.id, au.id, au.name, au.picture, pl.title, pl.description, pl.public, pl.share_token, pl.created_at, pl.updated_at
+	FROM
+		Playlist pl
+	JOIN
+		App_User au
+	ON
+		pl.owner_id = au.id
+	WHERE
+		pl.share_token = $1
+`
+
+func (r *repository) FindByShareToken(ctx context.Context, token string) (models.Playlist, error) {
+	return r.findOne(ctx, findByShareToken, token)
+}
+
+func (r *repository) findOne(ctx context.Context, query string, arg interface{}) (models.Playlist, error) {
+	var playlist models.Playlist
+
+	err := r.db.QueryRowContext(ctx, query, arg).Scan(
+		&playlist.ID,
+		&playlist.Owner.ID,
+		&playlist.Owner.Name,
+		&playlist.Owner.Picture,
+		&playlist.Title,
+		&playlist.Description,
+		&playlist.Public,
+		&playlist.ShareToken,
+		&playlist.CreatedAt,
+		&playlist.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return models.Playlist{}, app.NewError(err, app.ENotFound)
+	} else if err != nil {
+		return models.Playlist{}, err
+	}
+
+	return playlist, nil
+}
+
+var findByOwnerID = `
+	SELECT
+		pl.id, au.id, au.name, au.picture, pl.title, pl.description, pl.public, pl.share_token, pl.created_at, pl.updated_at
+	FROM
+		Playlist pl
+	JOIN
+		App_User au
+	ON
+		pl.owner_id = au.id
+	WHERE
+		pl.owner_id = $1
+	ORDER BY
+		pl.id DESC
+`
+
+func (r *repository) FindByOwnerID(ctx context.Context, ownerID int64) ([]models.Playlist, error) {
+	playlists := make([]models.Playlist, 0)
+
+	rows, err := r.db.QueryContext(ctx, findByOwnerID, ownerID)
+	if err != nil {
+		return playlists, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var playlist models.Playlist
+
+		err := rows.Scan(
+			&playlist.ID,
+			&playlist.Owner.ID,
+			&playlist.Owner.Name,
+			&playlist.Owner.Picture,
+			&playlist.Title,
+			&playlist.Description,
+			&playlist.Public,
+			&playlist.ShareToken,
+			&playlist.CreatedAt,
+			&playlist.UpdatedAt,
+		)
+		if err != nil {
+			return playlists, err
+		}
+
+		playlists = append(playlists, playlist)
+	}
+	if err := rows.Err(); err != nil {
+		return playlists, err
+	}
+
+	return playlists, nil
+}
+
+var update = `
+	UPDATE
+		Playlist
+	SET
+		title = $1, description = $2, public = $3, share_token = $4, updated_at = $5
+	WHERE
+		id = $6
+`
+
+func (r *repository) Update(ctx context.Context, playlist *models.Playlist) error {
+	res, err := r.db.ExecContext(
+		ctx,
+		update,
+		playlist.Title,
+		playlist.Description,
+		playlist.Public,
+		playlist.ShareToken,
+		playlist.UpdatedAt,
+		playlist.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return mustAffect(res)
+}
+
+var delete = `
+	DELETE FROM
+		Playlist
+	WHERE
+		id = $1
+`
+
+func (r *repository) Delete(ctx context.Context, playlistID int64) error {
+	res, err := r.db.ExecContext(ctx, delete, playlistID)
+	if err != nil {
+		return err
+	}
+
+	return mustAffect(res)
+}
+
+var addItem = `
+	INSERT INTO
+		Playlist_Item
+		(playlist_id, podcast_id, position, created_at)
+	SELECT
+		$1, $2, COALESCE(MAX(position), 0) + 1, $3
+	FROM
+		Playlist_Item
+	WHERE
+		playlist_id = $1
+	RETURNING
+		id, position
+`
+
+var lockPlaylist = `
+	SELECT
+		id
+	FROM
+		Playlist
+	WHERE
+		id = $1
+	FOR UPDATE
+`
+
+// AddItem appends the podcast to the playlist. The playlist is locked first,
+// so podcasts added at the same time get their own positions.
+func (r *repository) AddItem(ctx context.Context, item *models.PlaylistItem) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = lock(ctx, tx, item.PlaylistID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(
+		ctx,
+		addItem,
+		item.PlaylistID,
+		item.Podcast.ID,
+		item.CreatedAt,
+	).Scan(&item.ID, &item.Position)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return app.NewError(err, app.Econflict, "Podcast is already in the playlist")
+	} else if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func lock(ctx context.Context, tx *sql.Tx, playlistID int64) error {
+	var id int64
+	err := tx.QueryRowContext(ctx, lockPlaylist, playlistID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return app.NewError(err, app.ENotFound)
+	}
+
+	return err
+}
+
+var findItems = `
+	SELECT
+		pi.id, pi.playlist_id, pi.position, pi.created_at,
+		pt.id, au.id, au.name, au.picture, pt.picture, pt.title, pt.description, pt.file, pt.created_at, pt.updated_at
+	FROM
+		Playlist_Item pi
+	JOIN
+		Podcast pt
+	ON
+		pi.podcast_id = pt.id
+	JOIN
+		App_User au
+	ON
+		pt.author_id = au.id
+	WHERE
+		pi.playlist_id = $1 AND pt.hidden_at = 0
+	ORDER BY
+		pi.position ASC, pi.id ASC
+`
+
+func (r *repository) FindItems(ctx context.Context, playlistID int64) ([]models.PlaylistItem, error) {
+	items := make([]models.PlaylistItem, 0)
+
+	rows, err := r.db.QueryContext(ctx, findItems, playlistID)
+	if err != nil {
+		return items, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.PlaylistItem
+
+		err := rows.Scan(
+			&item.ID,
+			&item.PlaylistID,
+			&item.Position,
+			&item.CreatedAt,
+			&item.Podcast.ID,
+			&item.Podcast.Author.ID,
+			&item.Podcast.Author.Name,
+			&item.Podcast.Author.Picture,
+			&item.Podcast.Picture,
+			&item.Podcast.Title,
+			&item.Podcast.Description,
+			&item.Podcast.File,
+			&item.Podcast.CreatedAt,
+			&item.Podcast.UpdatedAt,
+		)
+		if err != nil {
+			return items, err
+		}
+
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return items, err
+	}
+
+	return items, nil
+}
+
+var deleteItem = `
+	DELETE FROM
+		Playlist_Item
+	WHERE
+		playlist_id = $1 AND podcast_id = $2
+`
+
+func (r *repository) DeleteItem(ctx context.Context, playlistID, podcastID int64) error {
+	res, err := r.db.ExecContext(ctx, deleteItem, playlistID, podcastID)
+	if err != nil {
+		return err
+	}
+
+	return mustAffect(res)
+}
+
+var lockItems = `
+	SELECT
+		podcast_id
+	FROM
+		Playlist_Item
+	WHERE
+		playlist_id = $1
+	ORDER BY
+		position ASC, id ASC
+	FOR UPDATE
+`
+
+var updatePosition = `
+	UPDATE
+		Playlist_Item
+	SET
+		position = $1
+	WHERE
+		playlist_id = $2 AND podcast_id = $3
+`
+
+// MoveItem places the podcast at the given 1-based position and renumbers the
+// rest of the playlist, closing any gaps left by deleted podcasts.
+func (r *repository) MoveItem(ctx context.Context, playlistID, podcastID, position int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = lock(ctx, tx, playlistID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, lockItems, playlistID)
+	if err != nil {
+		return err
+	}
+
+	order := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		order = append(order, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	order, found := reorder(order, podcastID, position)
+	if !found {
+		return app.NewError(nil, app.ENotFound)
+	}
+
+	for i, id := range order {
+		_, err := tx.ExecContext(ctx, updatePosition, i+1, playlistID, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// reorder moves the podcast to the 1-based position in the order, or to the
+// end when the position is past it. It reports false when the podcast is not
+// in the order.
+func reorder(order []int64, podcastID, position int64) ([]int64, bool) {
+	rest := make([]int64, 0, len(order))
+	for _, id := range order {
+		if id != podcastID {
+			rest = append(rest, id)
+		}
+	}
+	if len(rest) == len(order) {
+		return order, false
+	}
+
+	index := position - 1
+	if index > int64(len(rest)) {
+		index = int64(len(rest))
+	}
+
+	return append(rest[:index], append([]int64{podcastID}, rest[index:]...)...), true
+}
+
+func mustAffect(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return app.NewError(nil, app.ENotFound)
+	}
+
+	return nil
+}
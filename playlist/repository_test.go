@@ -0,0 +1,25 @@
+package playlist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReorder(t *testing.T) {
+	order, found := reorder([]int64{1, 2, 3, 4}, 4, 1)
+	assert.True(t, found)
+	assert.Equal(t, []int64{4, 1, 2, 3}, order)
+
+	order, found = reorder([]int64{1, 2, 3, 4}, 1, 3)
+	assert.True(t, found)
+	assert.Equal(t, []int64{2, 3, 1, 4}, order)
+
+	// A position past the end moves the podcast last.
+	order, found = reorder([]int64{1, 2, 3}, 2, 10)
+	assert.True(t, found)
+	assert.Equal(t, []int64{1, 3, 2}, order)
+
+	_, found = reorder([]int64{1, 2, 3}, 5, 1)
+	assert.False(t, found)
+}
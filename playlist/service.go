@@ -0,0 +1,317 @@
+package playlist
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/podcast"
+)
+
+type Service interface {
+	Create(ctx context.Context, req *CreatePlaylistReq) (CreatePlaylistResp, error)
+	GetByID(ctx context.Context, playlistID int64) (GetPlaylistResp, error)
+	GetByShareToken(ctx context.Context, token string) (GetPlaylistResp, error)
+	GetByOwnerID(ctx context.Context, ownerID int64) ([]Playlist, error)
+	Update(ctx context.Context, req *UpdatePlaylistReq) (UpdatePlaylistResp, error)
+	Delete(ctx context.Context, playlistID, ownerID int64) error
+	AddItem(ctx context.Context, req *AddItemReq) (AddItemResp, error)
+	MoveItem(ctx context.Context, req *MoveItemReq) error
+	RemoveItem(ctx context.Context, req *RemoveItemReq) error
+}
+
+type service struct {
+	playlistRepo Repository
+	podcastRepo  podcast.Repository
+}
+
+func NewService(playlistRepo Repository, podcastRepo podcast.Repository) Service {
+	return &service{
+		playlistRepo: playlistRepo,
+		podcastRepo:  podcastRepo,
+	}
+}
+
+func (s *service) Create(ctx context.Context, req *CreatePlaylistReq) (CreatePlaylistResp, error) {
+	err := req.Validate()
+	if err != nil {
+		return CreatePlaylistResp{}, err
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return CreatePlaylistResp{}, err
+	}
+
+	playlist := models.Playlist{
+		Owner:       models.User{ID: req.OwnerID},
+		Title:       req.Title,
+		Description: req.Description,
+		Public:      req.Public,
+		ShareToken:  token,
+		CreatedAt:   time.Now().Unix(),
+		UpdatedAt:   time.Now().Unix(),
+	}
+
+	err = s.playlistRepo.Create(ctx, &playlist)
+	if err != nil {
+		return CreatePlaylistResp{}, err
+	}
+
+	return CreatePlaylistResp(toPlaylist(playlist, true)), nil
+}
+
+// GetByID returns a public playlist to anyone, and a private one only to its owner.
+func (s *service) GetByID(ctx context.Context, playlistID int64) (GetPlaylistResp, error) {
+	playlist, err := s.playlistRepo.FindByID(ctx, playlistID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return GetPlaylistResp{}, app.NewError(err, app.ENotFound, "Playlist not found")
+	} else if err != nil {
+		return GetPlaylistResp{}, err
+	}
+
+	userID, _ := ctx.Value("userID").(int64)
+	isOwner := userID == playlist.Owner.ID
+	if !playlist.Public && !isOwner {
+		return GetPlaylistResp{}, app.NewError(nil, app.ENotFound, "Playlist not found")
+	}
+
+	return s.withItems(ctx, playlist, isOwner)
+}
+
+// GetByShareToken resolves a share link. Anyone holding the link can view the
+// playlist, even if it is private.
+func (s *service) GetByShareToken(ctx context.Context, token string) (GetPlaylistResp, error) {
+	playlist, err := s.playlistRepo.FindByShareToken(ctx, token)
+	if app.ErrorCode(err) == app.ENotFound {
+		return GetPlaylistResp{}, app.NewError(err, app.ENotFound, "Playlist not found")
+	} else if err != nil {
+		return GetPlaylistResp{}, err
+	}
+
+	userID, _ := ctx.Value("userID").(int64)
+
+	return s.withItems(ctx, playlist, userID == playlist.Owner.ID)
+}
+
+func (s *service) GetByOwnerID(ctx context.Context, ownerID int64) ([]Playlist, error) {
+	playlists, err := s.playlistRepo.FindByOwnerID(ctx, ownerID)
+	if err != nil {
+		return make([]Playlist, 0), err
+	}
+
+	res := make([]Playlist, 0)
+	for _, playlist := range playlists {
+		res = append(res, toPlaylist(playlist, true))
+	}
+
+	return res, nil
+}
+
+func (s *service) Update(ctx context.Context, req *UpdatePlaylistReq) (UpdatePlaylistResp, error) {
+	err := req.Validate()
+	if err != nil {
+		return UpdatePlaylistResp{}, err
+	}
+
+	playlist, err := s.findOwned(ctx, req.PlaylistID, req.OwnerID)
+	if err != nil {
+		return UpdatePlaylistResp{}, err
+	}
+
+	if req.Title != nil {
+		playlist.Title = *req.Title
+	}
+	if req.Description != nil {
+		playlist.Description = *req.Description
+	}
+	if req.Public != nil {
+		playlist.Public = *req.Public
+	}
+	if req.RegenerateShareToken {
+		playlist.ShareToken, err = newShareToken()
+		if err != nil {
+			return UpdatePlaylistResp{}, err
+		}
+	}
+	playlist.UpdatedAt = time.Now().Unix()
+
+	err = s.playlistRepo.Update(ctx, &playlist)
+	if err != nil {
+		return UpdatePlaylistResp{}, err
+	}
+
+	return UpdatePlaylistResp(toPlaylist(playlist, true)), nil
+}
+
+func (s *service) Delete(ctx context.Context, playlistID, ownerID int64) error {
+	_, err := s.findOwned(ctx, playlistID, ownerID)
+	if err != nil {
+		return err
+	}
+
+	return s.playlistRepo.Delete(ctx, playlistID)
+}
+
+func (s *service) AddItem(ctx context.Context, req *AddItemReq) (AddItemResp, error) {
+	err := req.Validate()
+	if err != nil {
+		return AddItemResp{}, err
+	}
+
+	_, err = s.findOwned(ctx, req.PlaylistID, req.OwnerID)
+	if err != nil {
+		return AddItemResp{}, err
+	}
+
+	_, err = s.podcastRepo.FindByID(ctx, req.PodcastID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return AddItemResp{}, app.NewError(err, app.ENotFound, "Podcast not found")
+	} else if err != nil {
+		return AddItemResp{}, err
+	}
+
+	item := models.PlaylistItem{
+		PlaylistID: req.PlaylistID,
+		Podcast:    models.Podcast{ID: req.PodcastID},
+		CreatedAt:  time.Now().Unix(),
+	}
+
+	err = s.playlistRepo.AddItem(ctx, &item)
+	if err != nil {
+		return AddItemResp{}, err
+	}
+
+	res := AddItemResp{
+		PlaylistID: item.PlaylistID,
+		PodcastID:  item.Podcast.ID,
+		Position:   item.Position,
+		AddedAt:    item.CreatedAt,
+	}
+
+	return res, nil
+}
+
+func (s *service) MoveItem(ctx context.Context, req *MoveItemReq) error {
+	err := req.Validate()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.findOwned(ctx, req.PlaylistID, req.OwnerID)
+	if err != nil {
+		return err
+	}
+
+	err = s.playlistRepo.MoveItem(ctx, req.PlaylistID, req.PodcastID, req.Position)
+	if app.ErrorCode(err) == app.ENotFound {
+		return app.NewError(err, app.ENotFound, "Podcast is not in the playlist")
+	}
+
+	return err
+}
+
+func (s *service) RemoveItem(ctx context.Context, req *RemoveItemReq) error {
+	err := req.Validate()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.findOwned(ctx, req.PlaylistID, req.OwnerID)
+	if err != nil {
+		return err
+	}
+
+	err = s.playlistRepo.DeleteItem(ctx, req.PlaylistID, req.PodcastID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return app.NewError(err, app.ENotFound, "Podcast is not in the playlist")
+	}
+
+	return err
+}
+
+func (s *service) findOwned(ctx context.Context, playlistID, ownerID int64) (models.Playlist, error) {
+	playlist, err := s.playlistRepo.FindByID(ctx, playlistID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return models.Playlist{}, app.NewError(err, app.ENotFound, "Playlist not found")
+	} else if err != nil {
+		return models.Playlist{}, err
+	}
+
+	if playlist.Owner.ID != ownerID {
+		return models.Playlist{}, app.NewError(nil, app.EForbidden, "Forbidden access, playlist not found")
+	}
+
+	return playlist, nil
+}
+
+func (s *service) withItems(ctx context.Context, playlist models.Playlist, isOwner bool) (GetPlaylistResp, error) {
+	items, err := s.playlistRepo.FindItems(ctx, playlist.ID)
+	if err != nil {
+		return GetPlaylistResp{}, err
+	}
+
+	res := GetPlaylistResp{
+		Playlist: toPlaylist(playlist, isOwner),
+		Items:    make([]Item, 0),
+	}
+
+	for _, item := range items {
+		res.Items = append(res.Items, Item{
+			Position: item.Position,
+			Podcast: Podcast{
+				ID: item.Podcast.ID,
+				Author: Author{
+					ID:      item.Podcast.Author.ID,
+					Name:    item.Podcast.Author.Name,
+					Picture: item.Podcast.Author.Picture,
+				},
+				Picture:     item.Podcast.Picture,
+				Title:       item.Podcast.Title,
+				Description: item.Podcast.Description,
+				File:        item.Podcast.File,
+				CreatedAt:   item.Podcast.CreatedAt,
+				UpdatedAt:   item.Podcast.UpdatedAt,
+			},
+			AddedAt: item.CreatedAt,
+		})
+	}
+
+	return res, nil
+}
+
+// toPlaylist hides the share token from everyone but the owner, so a public
+// listing cannot be used to reach the owner's private share link.
+func toPlaylist(playlist models.Playlist, isOwner bool) Playlist {
+	res := Playlist{
+		ID: playlist.ID,
+		Owner: Owner{
+			ID:      playlist.Owner.ID,
+			Name:    playlist.Owner.Name,
+			Picture: playlist.Owner.Picture,
+		},
+		Title:       playlist.Title,
+		Description: playlist.Description,
+		Public:      playlist.Public,
+		CreatedAt:   playlist.CreatedAt,
+		UpdatedAt:   playlist.UpdatedAt,
+	}
+	if isOwner {
+		res.ShareToken = playlist.ShareToken
+	}
+
+	return res
+}
+
+func newShareToken() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,26 @@
+package playlist
+
+import (
+	"testing"
+
+	"github.com/bagus2x/recovy/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewShareToken(t *testing.T) {
+	token, err := newShareToken()
+	require.NoError(t, err)
+	assert.Len(t, token, 32)
+
+	other, err := newShareToken()
+	require.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func TestToPlaylist(t *testing.T) {
+	playlist := models.Playlist{ID: 1, Title: "Sleep", ShareToken: "abc"}
+
+	assert.Equal(t, "abc", toPlaylist(playlist, true).ShareToken)
+	assert.Empty(t, toPlaylist(playlist, false).ShareToken)
+}
@@ -0,0 +1,132 @@
+package playlist
+
+import (
+	"github.com/bagus2x/recovy/app"
+	"github.com/go-playground/validator/v10"
+)
+
+type Owner struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Picture string `json:"picture"`
+}
+
+type Author struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Picture string `json:"picture"`
+}
+
+type Podcast struct {
+	ID          int64  `json:"id"`
+	Author      Author `json:"author"`
+	Picture     string `json:"picture"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	File        string `json:"file"`
+	CreatedAt   int64  `json:"createdAt"`
+	UpdatedAt   int64  `json:"updatedAt"`
+}
+
+type Item struct {
+	Position int64   `json:"position"`
+	Podcast  Podcast `json:"podcast"`
+	AddedAt  int64   `json:"addedAt"`
+}
+
+type Playlist struct {
+	ID          int64  `json:"id"`
+	Owner       Owner  `json:"owner"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Public      bool   `json:"public"`
+	ShareToken  string `json:"shareToken,omitempty"`
+	CreatedAt   int64  `json:"createdAt"`
+	UpdatedAt   int64  `json:"updatedAt"`
+}
+
+type CreatePlaylistReq struct {
+	OwnerID     int64  `json:"ownerID" validate:"required,gt=0"`
+	Title       string `json:"title" validate:"required,lte=255"`
+	Description string `json:"description" validate:"lte=512"`
+	Public      bool   `json:"public"`
+}
+
+func (r *CreatePlaylistReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+
+	return app.ValidateAndTranslate(validate, err)
+}
+
+type CreatePlaylistResp Playlist
+
+type UpdatePlaylistReq struct {
+	PlaylistID           int64   `json:"playlistID" validate:"required,gt=0"`
+	OwnerID              int64   `json:"ownerID" validate:"required,gt=0"`
+	Title                *string `json:"title" validate:"omitempty,gt=0,lte=255"`
+	Description          *string `json:"description" validate:"omitempty,lte=512"`
+	Public               *bool   `json:"public"`
+	RegenerateShareToken bool    `json:"regenerateShareToken"`
+}
+
+func (r *UpdatePlaylistReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+
+	return app.ValidateAndTranslate(validate, err)
+}
+
+type UpdatePlaylistResp Playlist
+
+type GetPlaylistResp struct {
+	Playlist
+	Items []Item `json:"items"`
+}
+
+type AddItemReq struct {
+	PlaylistID int64 `json:"playlistID" validate:"required,gt=0"`
+	PodcastID  int64 `json:"podcastID" validate:"required,gt=0"`
+	OwnerID    int64 `json:"ownerID" validate:"required,gt=0"`
+}
+
+func (r *AddItemReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+
+	return app.ValidateAndTranslate(validate, err)
+}
+
+type AddItemResp struct {
+	PlaylistID int64 `json:"playlistID"`
+	PodcastID  int64 `json:"podcastID"`
+	Position   int64 `json:"position"`
+	AddedAt    int64 `json:"addedAt"`
+}
+
+type MoveItemReq struct {
+	PlaylistID int64 `json:"playlistID" validate:"required,gt=0"`
+	PodcastID  int64 `json:"podcastID" validate:"required,gt=0"`
+	OwnerID    int64 `json:"ownerID" validate:"required,gt=0"`
+	Position   int64 `json:"position" validate:"required,gt=0"`
+}
+
+func (r *MoveItemReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+
+	return app.ValidateAndTranslate(validate, err)
+}
+
+type RemoveItemReq struct {
+	PlaylistID int64 `json:"playlistID" validate:"required,gt=0"`
+	PodcastID  int64 `json:"podcastID" validate:"required,gt=0"`
+	OwnerID    int64 `json:"ownerID" validate:"required,gt=0"`
+}
+
+func (r *RemoveItemReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+
+	return app.ValidateAndTranslate(validate, err)
+}
@@ -0,0 +1,43 @@
+package podcast
+
+import (
+	"strings"
+
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/pagination"
+)
+
+// cursorToken is the position of a row within a sort order. Clients only see
+// it as an opaque string, so the key can change with the sort.
+type cursorToken struct {
+	Sort string `json:"s"`
+	Int  int64  `json:"i,omitempty"`
+	Text string `json:"t,omitempty"`
+	ID   int64  `json:"id"`
+}
+
+func newCursorToken(sort string, podcast models.Podcast) cursorToken {
+	token := cursorToken{Sort: sort, ID: podcast.ID}
+
+	switch sort {
+	case SortMostLiked:
+		token.Int = podcast.Likes
+	case SortTitle:
+		token.Text = strings.ToLower(podcast.Title)
+	default:
+		token.Int = podcast.CreatedAt
+	}
+
+	return token
+}
+
+func encodeCursor(token cursorToken) string {
+	return pagination.Encode(token)
+}
+
+func decodeCursor(s string) (cursorToken, error) {
+	var token cursorToken
+	err := pagination.Decode(s, &token)
+
+	return token, err
+}
@@ -0,0 +1,34 @@
+package podcast
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bagus2x/recovy/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	token := newCursorToken(SortTitle, models.Podcast{ID: 7, Title: "Mindful Mornings"})
+
+	decoded, err := decodeCursor(encodeCursor(token))
+	assert.NoError(t, err)
+	assert.Equal(t, token, decoded)
+	assert.Equal(t, "mindful mornings", decoded.Text)
+
+	_, err = decodeCursor("not a cursor!")
+	assert.Error(t, err)
+}
+
+func TestFindKeyset(t *testing.T) {
+	cursor := cursorToken{Sort: SortMostLiked, Int: 3, ID: 10}
+
+	query, args := find(&Params{Limit: 5}, SortMostLiked, &cursor)
+	assert.Contains(t, query, "(COALESCE(lc.likes, 0), pt.id) < ($1, $2)")
+	assert.True(t, strings.HasSuffix(query, "ORDER BY COALESCE(lc.likes, 0) DESC, pt.id DESC LIMIT $3"))
+	assert.Equal(t, []interface{}{int64(3), int64(10), int64(5)}, args)
+
+	query, _ = find(&Params{Limit: 5, Direction: DirectionPrevious}, SortMostLiked, &cursor)
+	assert.Contains(t, query, "(COALESCE(lc.likes, 0), pt.id) > ($1, $2)")
+	assert.Contains(t, query, "ORDER BY COALESCE(lc.likes, 0) ASC, pt.id ASC")
+}
@@ -4,11 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"math"
 	"strings"
 
 	"github.com/bagus2x/recovy/app"
 	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/pagination"
 )
 
 type Repository interface {
@@ -31,9 +31,9 @@ func NewRepository(db *sql.DB) Repository {
 var create = `
 			INSERT INTO
 				Podcast
-				(author_id, picture, title, description, file, created_at, updated_at)
+				(author_id, picture, title, description, file, duration, created_at, updated_at)
 			VALUES
-				($1, $2, $3, $4, $5, $6, $7)
+				($1, $2, $3, $4, $5, $6, $7, $8)
 			RETURNING
 				id
 `
@@ -47,6 +47,7 @@ func (r *repository) Create(ctx context.Context, podcast *models.Podcast) error
 		podcast.Title,
 		podcast.Description,
 		podcast.File,
+		podcast.Duration,
 		podcast.CreatedAt,
 		podcast.UpdatedAt,
 	).Scan(&podcast.ID)
@@ -56,7 +57,8 @@ func (r *repository) Create(ctx context.Context, podcast *models.Podcast) error
 
 var findByID = `
 			SELECT
-				pt.id, au.id, au.name, au.picture, pt.picture, pt.title, pt.description, pt.file, pt.created_at, pt.updated_at
+				pt.id, au.id, au.name, au.picture, pt.picture, pt.title, pt.description, pt.file, pt.duration,
+				(SELECT COUNT(*) FROM Podcast_Reaction pr WHERE pr.podcast_id = pt.id AND pr.kind = 'like'), pt.created_at, pt.updated_at
 			FROM
 				Podcast pt
 			JOIN
@@ -64,7 +66,7 @@ var findByID = `
 			ON
 				pt.author_id = au.id
 			WHERE
-				pt.id = $1
+				pt.id = $1 AND pt.hidden_at = 0
 `
 
 func (r *repository) FindByID(ctx context.Context, podcastID int64) (models.Podcast, error) {
@@ -79,6 +81,8 @@ func (r *repository) FindByID(ctx context.Context, podcastID int64) (models.Podc
 		&podcast.Title,
 		&podcast.Description,
 		&podcast.File,
+		&podcast.Duration,
+		&podcast.Likes,
 		&podcast.CreatedAt,
 		&podcast.UpdatedAt,
 	)
@@ -91,108 +95,127 @@ func (r *repository) FindByID(ctx context.Context, podcastID int64) (models.Podc
 	return podcast, nil
 }
 
-func findNext(params *Params) (string, []interface{}) {
-	podcasts := strings.Builder{}
-	podcasts.WriteString(`
+type sortKey struct {
+	column string
+	desc   bool
+	text   bool
+}
+
+var sortKeys = map[string]sortKey{
+	SortNewest:    {column: "pt.created_at", desc: true},
+	SortOldest:    {column: "pt.created_at"},
+	SortMostLiked: {column: "COALESCE(lc.likes, 0)", desc: true},
+	SortTitle:     {column: "LOWER(pt.title)", text: true},
+}
+
+// find builds a keyset query ordered by the sort key with pt.id as the tie
+// breaker. Fetching the previous page flips the comparison and the order, the
+// rows are put back in display order by Find.
+func find(params *Params, sort string, cursor *cursorToken) (string, []interface{}) {
+	key := sortKeys[sort]
+	query := strings.Builder{}
+	args := make([]interface{}, 0)
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	query.WriteString(`
 		SELECT
-			pt.id, au.id, au.name, au.picture, pt.picture, pt.title, pt.description, pt.file, pt.created_at, pt.updated_at
+			pt.id, au.id, au.name, au.picture, pt.picture, pt.title, pt.description, pt.file, pt.duration,
+			COALESCE(lc.likes, 0), pt.created_at, pt.updated_at
 		FROM
 			Podcast pt
 		JOIN
 			App_User au
 		ON
 			pt.author_id = au.id
-		WHERE`,
+		LEFT JOIN
+			(SELECT podcast_id, COUNT(*) AS likes FROM Podcast_Reaction WHERE kind = 'like' GROUP BY podcast_id) lc
+		ON
+			lc.podcast_id = pt.id
+		WHERE
+			pt.hidden_at = 0`,
 	)
 
-	dollar := 1
-	nonIntValues := make([]interface{}, 0)
-
-	// Cursor
-	cursor := params.Cursor
-	if cursor == 0 {
-		cursor = math.MaxInt32
-	}
-
-	limit := params.Limit
-	if limit == 0 {
-		limit = 10
-	}
-
-	fmt.Fprintf(&podcasts, " pt.id < %d ", cursor)
-
 	// Dynamic query
 	if params.Title != "" {
-		fmt.Fprintf(&podcasts, " AND pt.title ILIKE $%d ", dollar)
-		nonIntValues = append(nonIntValues, "%"+params.Title+"%")
-		dollar++
+		title := arg(params.Title)
+		fmt.Fprintf(
+			&query,
+			" AND (pt.search_en @@ websearch_to_tsquery('english', %[1]s) OR pt.search_id @@ websearch_to_tsquery('indonesian', %[1]s) OR EXISTS (SELECT 1 FROM Podcast_Transcript tr WHERE tr.podcast_id = pt.id AND tr.search @@ plainto_tsquery('simple', %[1]s))) ",
+			title,
+		)
 	}
-
 	if params.AuthorID != 0 {
-		fmt.Fprintf(&podcasts, " AND pt.author_id = %d ", params.AuthorID)
+		fmt.Fprintf(&query, " AND pt.author_id = %s ", arg(params.AuthorID))
+	}
+	if params.CreatedFrom != 0 {
+		fmt.Fprintf(&query, " AND pt.created_at >= %s ", arg(params.CreatedFrom))
+	}
+	if params.CreatedTo != 0 {
+		fmt.Fprintf(&query, " AND pt.created_at <= %s ", arg(params.CreatedTo))
+	}
+	if params.MinDuration != 0 {
+		fmt.Fprintf(&query, " AND pt.duration >= %s ", arg(params.MinDuration))
+	}
+	if params.MaxDuration != 0 {
+		fmt.Fprintf(&query, " AND pt.duration <= %s ", arg(params.MaxDuration))
+	}
+	if params.LikedBy != 0 {
+		fmt.Fprintf(&query, " AND EXISTS (SELECT 1 FROM Podcast_Reaction pr WHERE pr.podcast_id = pt.id AND pr.user_id = %s AND pr.kind = 'like') ", arg(params.LikedBy))
 	}
-
-	// Limit
-	fmt.Fprintf(&podcasts, " ORDER BY pt.id DESC LIMIT %d", limit)
-
-	return podcasts.String(), nonIntValues
-}
-
-func findPrev(params *Params) (string, []interface{}) {
-	podcasts := strings.Builder{}
-	podcasts.WriteString(`
-		WITH prev_mode AS (
-			SELECT
-				pt.id as podcast_id, au.id, au.name, au.picture, pt.picture, pt.title, pt.description, pt.file, pt.created_at, pt.updated_at
-			FROM
-				Podcast pt
-			JOIN
-				App_User au
-			ON
-				pt.author_id = au.id
-			WHERE`,
-	)
-
-	dollar := 1
-	nonIntValues := make([]interface{}, 0)
 
 	// Cursor
-	limit := params.Limit
-	if limit == 0 {
-		limit = 10
+	desc := key.desc
+	if params.Direction == DirectionPrevious {
+		desc = !desc
 	}
-
-	fmt.Fprintf(&podcasts, " pt.id > %d ", params.Cursor)
-
-	// Dynamic query
-	if params.Title != "" {
-		fmt.Fprintf(&podcasts, " AND pt.title ILIKE $%d ", dollar)
-		nonIntValues = append(nonIntValues, "%"+params.Title+"%")
-		dollar++
+	op, order := ">", "ASC"
+	if desc {
+		op, order = "<", "DESC"
 	}
 
-	if params.AuthorID != 0 {
-		fmt.Fprintf(&podcasts, " AND pt.author_id = %d ", params.AuthorID)
+	if cursor != nil {
+		var value interface{} = cursor.Int
+		if key.text {
+			value = cursor.Text
+		}
+		fmt.Fprintf(&query, " AND (%s, pt.id) %s (%s, %s) ", key.column, op, arg(value), arg(cursor.ID))
 	}
 
 	// Limit
-	fmt.Fprintf(&podcasts, "  LIMIT %d ", limit)
-	podcasts.WriteString(") SELECT * FROM prev_mode ORDER BY podcast_id DESC")
+	fmt.Fprintf(&query, " ORDER BY %s %s, pt.id %s LIMIT %s", key.column, order, order, arg(params.Limit))
 
-	return podcasts.String(), nonIntValues
+	return query.String(), args
 }
 
-func find(params *Params) (string, []interface{}) {
-	if params.Direction == "previous" {
-		return findPrev(params)
+func (r *repository) Find(ctx context.Context, params *Params) ([]models.Podcast, Cursor, error) {
+	sort := params.Sort
+	switch sort {
+	case "":
+		sort = SortNewest
+	case "most_starred":
+		// Stars became likes.
+		sort = SortMostLiked
+	}
+	if _, ok := sortKeys[sort]; !ok {
+		return nil, Cursor{}, app.NewError(nil, app.EBadRequest, "Sort must be one of [newest oldest most_liked title]")
 	}
 
-	return findNext(params)
-}
+	params.Limit = pagination.Limit(params.Limit)
 
-func (r *repository) Find(ctx context.Context, params *Params) ([]models.Podcast, Cursor, error) {
-	query, nonIntValues := find(params)
-	rows, err := r.db.QueryContext(ctx, query, nonIntValues...)
+	var cursor *cursorToken
+	if params.Cursor != "" {
+		token, err := decodeCursor(params.Cursor)
+		if err != nil || token.Sort != sort {
+			return nil, Cursor{}, app.NewError(err, app.EBadRequest, "Invalid cursor")
+		}
+		cursor = &token
+	}
+
+	query, args := find(params, sort, cursor)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, Cursor{}, err
 	}
@@ -212,6 +235,8 @@ func (r *repository) Find(ctx context.Context, params *Params) ([]models.Podcast
 			&podcast.Title,
 			&podcast.Description,
 			&podcast.File,
+			&podcast.Duration,
+			&podcast.Likes,
 			&podcast.CreatedAt,
 			&podcast.UpdatedAt,
 		)
@@ -221,17 +246,21 @@ func (r *repository) Find(ctx context.Context, params *Params) ([]models.Podcast
 
 		podcasts = append(podcasts, podcast)
 	}
-	if rows.Err() != nil {
+	if err := rows.Err(); err != nil {
 		return nil, Cursor{}, err
 	}
 
-	var cursor Cursor
+	if params.Direction == DirectionPrevious {
+		pagination.Reverse(podcasts)
+	}
+
+	var res Cursor
 	if len(podcasts) > 0 {
-		cursor.Next = podcasts[len(podcasts)-1].ID
-		cursor.Previous = podcasts[0].ID
+		res.Next = encodeCursor(newCursorToken(sort, podcasts[len(podcasts)-1]))
+		res.Previous = encodeCursor(newCursorToken(sort, podcasts[0]))
 	}
 
-	return podcasts, cursor, nil
+	return podcasts, res, nil
 }
 
 var delete = `
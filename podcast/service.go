@@ -6,27 +6,25 @@ import (
 
 	"github.com/bagus2x/recovy/app"
 	"github.com/bagus2x/recovy/models"
-	"github.com/bagus2x/recovy/starredpodcast"
+	"github.com/bagus2x/recovy/reaction"
 )
 
 type Service interface {
 	Create(ctx context.Context, req *CreatePodcastReq) (CreatePodcastResp, error)
 	GetByID(ctx context.Context, podcastID int64) (GetPodcastByIDResp, error)
 	GetByParams(ctx context.Context, params *Params) (GetPodcastsResp, error)
-	StarPodcast(ctx context.Context, req *StarPodcastReq) error
-	UnstarPodcast(ctx context.Context, req *StarPodcastReq) error
 	DeleteByPodcastIDAndAthorID(ctx context.Context, podcastID, authorID int64) error
 }
 
 type service struct {
-	podcastRepo        Repository
-	starredPodcastRepo starredpodcast.Repository
+	podcastRepo     Repository
+	reactionService reaction.Service
 }
 
-func NewService(podcastRepo Repository, starredPodcastRepo starredpodcast.Repository) Service {
+func NewService(podcastRepo Repository, reactionService reaction.Service) Service {
 	return &service{
-		podcastRepo:        podcastRepo,
-		starredPodcastRepo: starredPodcastRepo,
+		podcastRepo:     podcastRepo,
+		reactionService: reactionService,
 	}
 }
 
@@ -42,6 +40,7 @@ func (s *service) Create(ctx context.Context, req *CreatePodcastReq) (CreatePodc
 		Title:       req.Title,
 		Description: req.Description,
 		File:        req.File,
+		Duration:    req.Duration,
 		CreatedAt:   time.Now().Unix(),
 		UpdatedAt:   time.Now().Unix(),
 	}
@@ -57,6 +56,7 @@ func (s *service) Create(ctx context.Context, req *CreatePodcastReq) (CreatePodc
 		Title:       podcast.Title,
 		Description: podcast.Description,
 		File:        podcast.File,
+		Duration:    podcast.Duration,
 		CreatedAt:   podcast.CreatedAt,
 		UpdatedAt:   podcast.UpdatedAt,
 	}
@@ -64,52 +64,6 @@ func (s *service) Create(ctx context.Context, req *CreatePodcastReq) (CreatePodc
 	return res, nil
 }
 
-func (s *service) StarPodcast(ctx context.Context, req *StarPodcastReq) error {
-	err := req.Validate()
-	if err != nil {
-		return err
-	}
-
-	star, err := s.starredPodcastRepo.FindByPodcastIDAndUserID(ctx, req.PodcastID, req.UserID)
-	if err != nil && app.ErrorCode(err) != app.ENotFound {
-		return err
-	}
-
-	if (star != models.StarredPodcast{}) {
-		return app.NewError(nil, app.Econflict, "User has starred")
-	}
-
-	err = s.starredPodcastRepo.Create(ctx, &models.StarredPodcast{
-		Podcast: models.Podcast{
-			ID: req.PodcastID,
-		},
-		User: models.User{
-			ID: req.UserID,
-		},
-	})
-	if err != nil {
-		return app.NewError(err, app.ENotFound, "Podcast not found")
-	}
-
-	return nil
-}
-
-func (s *service) UnstarPodcast(ctx context.Context, req *StarPodcastReq) error {
-	err := req.Validate()
-	if err != nil {
-		return err
-	}
-
-	return s.starredPodcastRepo.DeleteByPodcastIDAndUserID(ctx, &models.StarredPodcast{
-		Podcast: models.Podcast{
-			ID: req.PodcastID,
-		},
-		User: models.User{
-			ID: req.UserID,
-		},
-	})
-}
-
 func (s *service) GetByID(ctx context.Context, podcastID int64) (GetPodcastByIDResp, error) {
 	podcast, err := s.podcastRepo.FindByID(ctx, podcastID)
 	if app.ErrorCode(err) == app.ENotFound {
@@ -118,32 +72,13 @@ func (s *service) GetByID(ctx context.Context, podcastID int64) (GetPodcastByIDR
 		return GetPodcastByIDResp{}, nil
 	}
 
-	res := GetPodcastByIDResp{
-		ID: podcastID,
-		Author: Author{
-			ID:      podcast.Author.ID,
-			Name:    podcast.Author.Name,
-			Picture: podcast.Author.Picture,
-		},
-		Picture:     podcast.Picture,
-		Title:       podcast.Title,
-		Description: podcast.Description,
-		File:        podcast.File,
-		CreatedAt:   podcast.CreatedAt,
-		UpdatedAt:   podcast.UpdatedAt,
-	}
-
-	userID, ok := ctx.Value("userID").(int64)
-	if ok {
-		star, err := s.starredPodcastRepo.FindByPodcastIDAndUserID(ctx, podcastID, userID)
-		if err != nil && app.ErrorCode(err) != app.ENotFound {
-			return GetPodcastByIDResp{}, nil
-		}
-
-		res.Starred = star != models.StarredPodcast{}
+	userID, _ := ctx.Value("userID").(int64)
+	summaries, err := s.reactionService.Summaries(ctx, models.ContentPodcast, userID, podcast.ID)
+	if err != nil {
+		return GetPodcastByIDResp{}, err
 	}
 
-	return res, nil
+	return GetPodcastByIDResp(toPodcast(podcast, summaries[podcast.ID])), nil
 }
 
 func (s *service) GetByParams(ctx context.Context, params *Params) (GetPodcastsResp, error) {
@@ -152,39 +87,23 @@ func (s *service) GetByParams(ctx context.Context, params *Params) (GetPodcastsR
 		return GetPodcastsResp{}, err
 	}
 
+	ids := make([]int64, 0, len(podcasts))
+	for _, podcast := range podcasts {
+		ids = append(ids, podcast.ID)
+	}
+
+	userID, _ := ctx.Value("userID").(int64)
+	summaries, err := s.reactionService.Summaries(ctx, models.ContentPodcast, userID, ids...)
+	if err != nil {
+		return GetPodcastsResp{}, err
+	}
+
 	res := GetPodcastsResp{
 		Cursor:   cursor,
 		Podcasts: make([]Podcast, 0),
 	}
-
-	userID, ok := ctx.Value("userID").(int64)
-
 	for _, podcast := range podcasts {
-		var starred bool
-		if ok {
-			star, err := s.starredPodcastRepo.FindByPodcastIDAndUserID(ctx, podcast.ID, userID)
-			if err != nil && app.ErrorCode(err) != app.ENotFound {
-				return GetPodcastsResp{}, err
-			}
-			starred = star != models.StarredPodcast{}
-		}
-
-		res.Podcasts = append(res.Podcasts, Podcast{
-			ID: podcast.ID,
-			Author: Author{
-				ID:      podcast.Author.ID,
-				Name:    podcast.Author.Name,
-				Picture: podcast.Author.Picture,
-			},
-			Picture:     podcast.Picture,
-			Title:       podcast.Description,
-			Description: podcast.Description,
-			File:        podcast.File,
-			Starred:     starred,
-			CreatedAt:   podcast.CreatedAt,
-			UpdatedAt:   podcast.UpdatedAt,
-		})
-
+		res.Podcasts = append(res.Podcasts, toPodcast(podcast, summaries[podcast.ID]))
 	}
 
 	return res, nil
@@ -209,3 +128,24 @@ func (s *service) DeleteByPodcastIDAndAthorID(ctx context.Context, podcastID, au
 
 	return nil
 }
+
+func toPodcast(podcast models.Podcast, summary reaction.Summary) Podcast {
+	return Podcast{
+		ID: podcast.ID,
+		Author: Author{
+			ID:      podcast.Author.ID,
+			Name:    podcast.Author.Name,
+			Picture: podcast.Author.Picture,
+		},
+		Picture:     podcast.Picture,
+		Title:       podcast.Title,
+		Description: podcast.Description,
+		File:        podcast.File,
+		Duration:    podcast.Duration,
+		Stars:       summary.Likes,
+		Starred:     summary.Liked,
+		CreatedAt:   podcast.CreatedAt,
+		UpdatedAt:   podcast.UpdatedAt,
+		Summary:     summary,
+	}
+}
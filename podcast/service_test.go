@@ -0,0 +1,20 @@
+package podcast
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/reaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToPodcastStars(t *testing.T) {
+	podcast := toPodcast(models.Podcast{ID: 1}, reaction.Summary{Likes: 4, Liked: true})
+
+	b, err := json.Marshal(podcast)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"stars":4,"starred":true`)
+	assert.Contains(t, string(b), `"likes":4,"liked":true`)
+}
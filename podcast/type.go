@@ -2,9 +2,13 @@ package podcast
 
 import (
 	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/pagination"
+	"github.com/bagus2x/recovy/reaction"
 	"github.com/go-playground/validator/v10"
 )
 
+// Podcast keeps Stars and Starred, what Likes and Liked were called before
+// reactions, for the clients that still read them.
 type Podcast struct {
 	ID          int64  `json:"id"`
 	Author      Author `json:"author"`
@@ -12,9 +16,12 @@ type Podcast struct {
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	File        string `json:"file"`
+	Duration    int64  `json:"duration"`
+	Stars       int64  `json:"stars"`
 	Starred     bool   `json:"starred"`
 	CreatedAt   int64  `json:"createdAt"`
 	UpdatedAt   int64  `json:"updatedAt"`
+	reaction.Summary
 }
 
 type Author struct {
@@ -23,18 +30,33 @@ type Author struct {
 	Picture string `json:"picture"`
 }
 
+const (
+	SortNewest    = "newest"
+	SortOldest    = "oldest"
+	SortMostLiked = "most_liked"
+	SortTitle     = "title"
+
+	DirectionNext     = pagination.DirectionNext
+	DirectionPrevious = pagination.DirectionPrevious
+)
+
+// Params filters the podcast list. Title is matched with full-text search
+// against the title, description and transcript.
 type Params struct {
-	Cursor    int64
-	Limit     int64
-	Direction string
-	Title     string
-	AuthorID  int64
+	Cursor      string
+	Limit       int64
+	Direction   string
+	Sort        string
+	Title       string
+	AuthorID    int64
+	CreatedFrom int64
+	CreatedTo   int64
+	MinDuration int64
+	MaxDuration int64
+	LikedBy     int64
 }
 
-type Cursor struct {
-	Next     int64 `json:"next"`
-	Previous int64 `json:"previous"`
-}
+type Cursor = pagination.Cursor
 
 type CreatePodcastReq struct {
 	AuthorID    int64  `json:"authorID" validate:"required"`
@@ -42,6 +64,7 @@ type CreatePodcastReq struct {
 	Title       string `json:"title" validate:"required,gte=5,lte=255"`
 	Description string `json:"description" validate:"lte=512"`
 	File        string `json:"file" validate:"required,gte=5,lte=255"`
+	Duration    int64  `json:"duration" validate:"gte=0"`
 }
 
 func (r *CreatePodcastReq) Validate() error {
@@ -58,6 +81,7 @@ type CreatePodcastResp struct {
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	File        string `json:"file"`
+	Duration    int64  `json:"duration"`
 	CreatedAt   int64  `json:"createdAt"`
 	UpdatedAt   int64  `json:"updatedAt"`
 }
@@ -68,17 +92,3 @@ type GetPodcastsResp struct {
 	Cursor   Cursor    `json:"cursor"`
 	Podcasts []Podcast `json:"podcasts"`
 }
-
-type StarPodcastReq struct {
-	PodcastID int64 `json:"podcast_id" validate:"required"`
-	UserID    int64 `json:"user_id" validate:"required"`
-}
-
-func (r *StarPodcastReq) Validate() error {
-	validate := validator.New()
-	err := validate.Struct(r)
-
-	return app.ValidateAndTranslate(validate, err)
-}
-
-type UnstarPodcastReq StarPodcastReq
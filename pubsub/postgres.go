@@ -0,0 +1,98 @@
+package pubsub
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// pingInterval keeps the listener connection alive and detects when it was
+// lost without a notification.
+const pingInterval = 90 * time.Second
+
+// postgres publishes with pg_notify and listens on a dedicated connection,
+// delivering notifications to the local subscribers. Topics are used as
+// channel names, so they must be shorter than 64 bytes; payloads must be
+// shorter than 8000 bytes.
+type postgres struct {
+	db       *sql.DB
+	listener *pq.Listener
+	local    *memory
+
+	mu sync.Mutex
+}
+
+func NewPostgres(db *sql.DB, dsn string) PubSub {
+	listener := pq.NewListener(dsn, 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			logrus.Error(err)
+		}
+	})
+
+	p := &postgres{
+		db:       db,
+		listener: listener,
+		local:    newMemory(),
+	}
+	go p.run()
+
+	return p
+}
+
+func (p *postgres) run() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case n, ok := <-p.listener.Notify:
+			if !ok {
+				return
+			}
+			// A nil notification means the connection was re-established
+			// and notifications may have been lost in between.
+			if n == nil {
+				continue
+			}
+			p.local.Publish(context.Background(), n.Channel, []byte(n.Extra))
+		case <-ticker.C:
+			go p.listener.Ping()
+		}
+	}
+}
+
+func (p *postgres) Publish(ctx context.Context, topic string, message []byte) error {
+	_, err := p.db.ExecContext(ctx, "SELECT pg_notify($1, $2)", topic, string(message))
+
+	return err
+}
+
+func (p *postgres) Subscribe(topic string) (<-chan []byte, func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ch, cancel := p.local.Subscribe(topic)
+	if p.local.count(topic) == 1 {
+		err := p.listener.Listen(topic)
+		if err != nil && err != pq.ErrChannelAlreadyOpen {
+			logrus.Error(err)
+		}
+	}
+
+	return ch, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		cancel()
+		if p.local.count(topic) == 0 {
+			err := p.listener.Unlisten(topic)
+			if err != nil && err != pq.ErrChannelNotOpen {
+				logrus.Error(err)
+			}
+		}
+	}
+}
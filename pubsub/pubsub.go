@@ -0,0 +1,85 @@
+// Package pubsub fans messages out to subscribers of a topic. The memory
+// implementation only reaches subscribers of the same process; the Postgres
+// one goes through LISTEN/NOTIFY and reaches every instance of the app.
+package pubsub
+
+import (
+	"context"
+	"sync"
+)
+
+// bufferSize is how many messages a subscriber may fall behind before
+// messages to it are dropped.
+const bufferSize = 64
+
+type PubSub interface {
+	Publish(ctx context.Context, topic string, message []byte) error
+	// Subscribe returns the messages published to the topic from now on. The
+	// channel is closed by the returned cancel function.
+	Subscribe(topic string) (<-chan []byte, func())
+}
+
+type memory struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan []byte]struct{}
+}
+
+func NewMemory() PubSub {
+	return newMemory()
+}
+
+func newMemory() *memory {
+	return &memory{
+		subscribers: make(map[string]map[chan []byte]struct{}),
+	}
+}
+
+// Publish never blocks: a subscriber that does not keep up misses messages.
+func (m *memory) Publish(ctx context.Context, topic string, message []byte) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for ch := range m.subscribers[topic] {
+		select {
+		case ch <- message:
+		default:
+		}
+	}
+
+	return nil
+}
+
+func (m *memory) Subscribe(topic string) (<-chan []byte, func()) {
+	ch := make(chan []byte, bufferSize)
+
+	m.mu.Lock()
+	if m.subscribers[topic] == nil {
+		m.subscribers[topic] = make(map[chan []byte]struct{})
+	}
+	m.subscribers[topic][ch] = struct{}{}
+	m.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			m.mu.Lock()
+			delete(m.subscribers[topic], ch)
+			if len(m.subscribers[topic]) == 0 {
+				delete(m.subscribers, topic)
+			}
+			m.mu.Unlock()
+
+			close(ch)
+		})
+	}
+
+	return ch, cancel
+}
+
+// count returns the number of subscribers of the topic.
+func (m *memory) count(topic string) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.subscribers[topic])
+}
@@ -0,0 +1,38 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemory(t *testing.T) {
+	ps := newMemory()
+
+	a, cancelA := ps.Subscribe("webinar:1")
+	b, cancelB := ps.Subscribe("webinar:1")
+	other, cancelOther := ps.Subscribe("webinar:2")
+	defer cancelOther()
+
+	assert.NoError(t, ps.Publish(context.Background(), "webinar:1", []byte("hi")))
+	assert.Equal(t, "hi", string(<-a))
+	assert.Equal(t, "hi", string(<-b))
+	assert.Len(t, other, 0)
+
+	cancelA()
+	cancelA()
+	_, ok := <-a
+	assert.False(t, ok)
+	assert.Equal(t, 1, ps.count("webinar:1"))
+
+	// A subscriber that falls behind misses messages instead of blocking
+	// the publisher.
+	for i := 0; i < bufferSize+10; i++ {
+		ps.Publish(context.Background(), "webinar:1", []byte("x"))
+	}
+	assert.Len(t, b, bufferSize)
+
+	cancelB()
+	assert.Equal(t, 0, ps.count("webinar:1"))
+}
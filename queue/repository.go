@@ -0,0 +1,216 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/bagus2x/recovy/models"
+)
+
+type Repository interface {
+	Enqueue(ctx context.Context, job *models.Job) error
+	CancelByKey(ctx context.Context, key string) error
+	CancelByReference(ctx context.Context, reference string) error
+	Process(ctx context.Context, fn func(ctx context.Context, job models.Job) error) (bool, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+var enqueue = `
+	INSERT INTO
+		Job
+		(kind, key, reference, payload, run_at, max_attempts, created_at, updated_at)
+	VALUES
+		($1, NULLIF($2, ''), $3, $4, $5, $6, $7, $8)
+	ON CONFLICT (key) DO UPDATE SET
+		kind = EXCLUDED.kind, reference = EXCLUDED.reference, payload = EXCLUDED.payload, run_at = EXCLUDED.run_at,
+		max_attempts = EXCLUDED.max_attempts, attempts = 0, last_error = '', updated_at = EXCLUDED.updated_at
+	RETURNING
+		id
+`
+
+// Enqueue adds a job. A job with the key of a pending job replaces it, so
+// keyed jobs can be rescheduled by enqueueing them again.
+func (r *repository) Enqueue(ctx context.Context, job *models.Job) error {
+	return EnqueueTx(ctx, r.db, job)
+}
+
+// Tx is a database or a transaction, so that other repositories can enqueue
+// and cancel jobs along with their own changes.
+type Tx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// EnqueueTx is Enqueue in the transaction.
+func EnqueueTx(ctx context.Context, tx Tx, job *models.Job) error {
+	if job.MaxAttempts == 0 {
+		job.MaxAttempts = DefaultMaxAttempts
+	}
+	if len(job.Payload) == 0 {
+		job.Payload = []byte("{}")
+	}
+
+	err := tx.QueryRowContext(
+		ctx,
+		enqueue,
+		job.Kind,
+		job.Key,
+		job.Reference,
+		job.Payload,
+		job.RunAt,
+		job.MaxAttempts,
+		job.CreatedAt,
+		job.UpdatedAt,
+	).Scan(&job.ID)
+
+	return err
+}
+
+var cancelByKey = `
+	DELETE FROM
+		Job
+	WHERE
+		key = $1
+`
+
+func (r *repository) CancelByKey(ctx context.Context, key string) error {
+	_, err := r.db.ExecContext(ctx, cancelByKey, key)
+
+	return err
+}
+
+var cancelByReference = `
+	DELETE FROM
+		Job
+	WHERE
+		reference = $1
+`
+
+// CancelByReference removes the pending jobs about a record, e.g. when the
+// record is deleted.
+func (r *repository) CancelByReference(ctx context.Context, reference string) error {
+	return CancelByReferenceTx(ctx, r.db, reference)
+}
+
+// CancelByReferenceTx is CancelByReference in the transaction.
+func CancelByReferenceTx(ctx context.Context, tx Tx, reference string) error {
+	_, err := tx.ExecContext(ctx, cancelByReference, reference)
+
+	return err
+}
+
+var dequeue = `
+	SELECT
+		id, kind, COALESCE(key, ''), reference, payload, run_at, attempts, max_attempts, last_error, created_at, updated_at
+	FROM
+		Job
+	WHERE
+		run_at <= $1
+	ORDER BY
+		run_at ASC, id ASC
+	LIMIT
+		1
+	FOR UPDATE SKIP LOCKED
+`
+
+var done = `
+	DELETE FROM
+		Job
+	WHERE
+		id = $1
+`
+
+var retry = `
+	UPDATE
+		Job
+	SET
+		attempts = $2, run_at = $3, last_error = $4, updated_at = $5
+	WHERE
+		id = $1
+`
+
+var bury = `
+	INSERT INTO
+		Dead_Job
+		(id, kind, key, reference, payload, attempts, last_error, created_at, failed_at)
+	VALUES
+		($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, $9)
+`
+
+// Process runs fn on the next due job and reports whether there was one. The
+// job stays locked in a transaction while fn runs, so other workers skip it,
+// and a worker that dies midway releases it to be picked up again. A failed
+// job is retried with backoff and moved to the dead-letter table once it has
+// run out of attempts.
+func (r *repository) Process(ctx context.Context, fn func(ctx context.Context, job models.Job) error) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	var job models.Job
+	err = tx.QueryRowContext(ctx, dequeue, now.Unix()).Scan(
+		&job.ID,
+		&job.Kind,
+		&job.Key,
+		&job.Reference,
+		&job.Payload,
+		&job.RunAt,
+		&job.Attempts,
+		&job.MaxAttempts,
+		&job.LastError,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	failure := fn(ctx, job)
+	job.Attempts++
+
+	switch {
+	case failure == nil:
+		_, err = tx.ExecContext(ctx, done, job.ID)
+	case job.Attempts >= job.MaxAttempts:
+		_, err = tx.ExecContext(
+			ctx,
+			bury,
+			job.ID,
+			job.Kind,
+			job.Key,
+			job.Reference,
+			job.Payload,
+			job.Attempts,
+			failure.Error(),
+			job.CreatedAt,
+			now.Unix(),
+		)
+		if err == nil {
+			_, err = tx.ExecContext(ctx, done, job.ID)
+		}
+	default:
+		runAt := now.Add(Backoff(job.Attempts)).Unix()
+		_, err = tx.ExecContext(ctx, retry, job.ID, job.Attempts, runAt, failure.Error(), now.Unix())
+	}
+	if err != nil {
+		return true, err
+	}
+
+	return true, tx.Commit()
+}
@@ -0,0 +1,107 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bagus2x/recovy/models"
+	"github.com/sirupsen/logrus"
+)
+
+const DefaultMaxAttempts = 5
+
+// Handler runs a job. Returning an error schedules a retry.
+type Handler func(ctx context.Context, job models.Job) error
+
+// Worker polls the queue for due jobs and dispatches them to the handler of
+// their kind. Any number of workers, in any number of instances, can share a
+// queue.
+type Worker struct {
+	repo        Repository
+	handlers    map[string]Handler
+	interval    time.Duration
+	concurrency int
+}
+
+func NewWorker(repo Repository, interval time.Duration, concurrency int) *Worker {
+	return &Worker{
+		repo:        repo,
+		handlers:    make(map[string]Handler),
+		interval:    interval,
+		concurrency: concurrency,
+	}
+}
+
+// Handle registers the handler for a kind of job. It must be called before
+// Run.
+func (w *Worker) Handle(kind string, handler Handler) {
+	w.handlers[kind] = handler
+}
+
+// Run processes jobs until ctx is done. Each goroutine drains the due jobs
+// and then sleeps for the poll interval.
+func (w *Worker) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < w.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (w *Worker) loop(ctx context.Context) {
+	for {
+		processed, err := w.repo.Process(ctx, w.dispatch)
+		if err != nil {
+			logrus.Error("process job: ", err)
+		}
+		if processed && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.interval):
+		}
+	}
+}
+
+func (w *Worker) dispatch(ctx context.Context, job models.Job) (err error) {
+	handler, ok := w.handlers[job.Kind]
+	if !ok {
+		return fmt.Errorf("no handler for job kind %q", job.Kind)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	err = handler(ctx, job)
+	if err != nil {
+		logrus.Errorf("job %d (%s) attempt %d: %v", job.ID, job.Kind, job.Attempts+1, err)
+	}
+
+	return err
+}
+
+// Backoff is the delay before the next attempt of a job that failed the
+// given number of times: 30 seconds, doubling up to an hour.
+func Backoff(attempts int64) time.Duration {
+	delay := 30 * time.Second
+	for i := int64(1); i < attempts && delay < time.Hour; i++ {
+		delay *= 2
+	}
+	if delay > time.Hour {
+		delay = time.Hour
+	}
+
+	return delay
+}
@@ -0,0 +1,30 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bagus2x/recovy/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, Backoff(1))
+	assert.Equal(t, time.Minute, Backoff(2))
+	assert.Equal(t, 8*time.Minute, Backoff(5))
+	assert.Equal(t, time.Hour, Backoff(20))
+}
+
+func TestDispatch(t *testing.T) {
+	w := NewWorker(nil, time.Second, 1)
+	w.Handle("ok", func(ctx context.Context, job models.Job) error { return nil })
+	w.Handle("fail", func(ctx context.Context, job models.Job) error { return errors.New("boom") })
+	w.Handle("panic", func(ctx context.Context, job models.Job) error { panic("boom") })
+
+	assert.NoError(t, w.dispatch(context.Background(), models.Job{Kind: "ok"}))
+	assert.EqualError(t, w.dispatch(context.Background(), models.Job{Kind: "fail"}), "boom")
+	assert.EqualError(t, w.dispatch(context.Background(), models.Job{Kind: "panic"}), "job panicked: boom")
+	assert.Error(t, w.dispatch(context.Background(), models.Job{Kind: "unknown"}))
+}
@@ -0,0 +1,17 @@
+package reaction
+
+import (
+	"github.com/bagus2x/recovy/models"
+)
+
+// cursorToken is the position of a bookmark in the list. Reactions to each
+// content type are numbered apart, so the type breaks ties between ids.
+type cursorToken struct {
+	CreatedAt int64  `json:"c"`
+	Type      string `json:"t"`
+	ID        int64  `json:"id"`
+}
+
+func newCursorToken(bookmark models.Bookmark) cursorToken {
+	return cursorToken{CreatedAt: bookmark.CreatedAt, Type: bookmark.ContentType, ID: bookmark.ID}
+}
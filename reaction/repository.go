@@ -0,0 +1,275 @@
+package reaction
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+	"github.com/lib/pq"
+)
+
+type Repository interface {
+	Create(ctx context.Context, reaction *models.Reaction) error
+	Delete(ctx context.Context, reaction *models.Reaction) error
+	FindSummaries(ctx context.Context, contentType string, userID int64, contentIDs []int64) (map[int64]models.ReactionSummary, error)
+	FindBookmarks(ctx context.Context, params *Params, cursor *cursorToken) ([]models.Bookmark, error)
+}
+
+// table holds the reactions to the items of a content type. Bookmarks are
+// listed with the title and picture selected from the item.
+type table struct {
+	name     string
+	column   string
+	bookmark string
+}
+
+var tables = map[string]table{
+	models.ContentArticle: {
+		name:   "Article_Reaction",
+		column: "article_id",
+		bookmark: `
+			SELECT 'article' AS type, r.id, r.article_id AS content_id, c.title, c.picture, r.created_at
+			FROM Article_Reaction r JOIN Article c ON c.id = r.article_id
+			WHERE r.user_id = %[1]s AND r.kind = 'bookmark' AND c.hidden_at = 0 AND (c.status = 'published' OR c.author_id = %[1]s)`,
+	},
+	models.ContentDiscussion: {
+		name:   "Discussion_Reaction",
+		column: "discussion_id",
+		bookmark: `
+			SELECT 'discussion' AS type, r.id, r.discussion_id, c.title, c.picture, r.created_at
+			FROM Discussion_Reaction r JOIN Discussion c ON c.id = r.discussion_id
+			WHERE r.user_id = %[1]s AND r.kind = 'bookmark' AND c.hidden_at = 0`,
+	},
+	models.ContentWebinar: {
+		name:   "Webinar_Reaction",
+		column: "webinar_id",
+		bookmark: `
+			SELECT 'webinar' AS type, r.id, r.webinar_id, c.title, c.picture, r.created_at
+			FROM Webinar_Reaction r JOIN Webinar c ON c.id = r.webinar_id
+			WHERE r.user_id = %[1]s AND r.kind = 'bookmark' AND c.hidden_at = 0`,
+	},
+	models.ContentPodcast: {
+		name:   "Podcast_Reaction",
+		column: "podcast_id",
+		bookmark: `
+			SELECT 'podcast' AS type, r.id, r.podcast_id, c.title, c.picture, r.created_at
+			FROM Podcast_Reaction r JOIN Podcast c ON c.id = r.podcast_id
+			WHERE r.user_id = %[1]s AND r.kind = 'bookmark' AND c.hidden_at = 0`,
+	},
+	models.ContentComment: {
+		name:   "Comment_Reaction",
+		column: "comment_id",
+		bookmark: `
+			SELECT 'comment' AS type, r.id, r.comment_id, LEFT(c.description, 255), '', r.created_at
+			FROM Comment_Reaction r JOIN Discussion_Comment c ON c.id = r.comment_id JOIN Discussion d ON d.id = c.discussion_id
+			WHERE r.user_id = %[1]s AND r.kind = 'bookmark' AND c.hidden_at = 0 AND c.deleted_at = 0 AND d.hidden_at = 0`,
+	},
+}
+
+func tableOf(contentType string) (table, error) {
+	t, ok := tables[contentType]
+	if !ok {
+		return table{}, fmt.Errorf("unknown content type %q", contentType)
+	}
+
+	return t, nil
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+var create = `
+	INSERT INTO
+		%s
+		(%s, user_id, kind, created_at)
+	VALUES
+		($1, $2, $3, $4)
+	RETURNING
+		id
+`
+
+// Create returns Econflict when the user already reacted so, and ENotFound
+// when the item does not exist.
+func (r *repository) Create(ctx context.Context, reaction *models.Reaction) error {
+	t, err := tableOf(reaction.ContentType)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRowContext(
+		ctx,
+		fmt.Sprintf(create, t.name, t.column),
+		reaction.ContentID,
+		reaction.User.ID,
+		reaction.Kind,
+		reaction.CreatedAt,
+	).Scan(&reaction.ID)
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code {
+		case "23505":
+			return app.NewError(err, app.Econflict)
+		case "23503":
+			return app.NewError(err, app.ENotFound)
+		}
+	}
+
+	return err
+}
+
+var delete = `
+	DELETE FROM
+		%s
+	WHERE
+		%s = $1 AND user_id = $2 AND kind = $3
+`
+
+func (r *repository) Delete(ctx context.Context, reaction *models.Reaction) error {
+	t, err := tableOf(reaction.ContentType)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.db.ExecContext(ctx, fmt.Sprintf(delete, t.name, t.column), reaction.ContentID, reaction.User.ID, reaction.Kind)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return app.NewError(nil, app.ENotFound)
+	}
+
+	return nil
+}
+
+var findSummaries = `
+	SELECT
+		%[2]s,
+		COUNT(*) FILTER (WHERE kind = 'like'),
+		BOOL_OR(kind = 'like' AND user_id = $2),
+		BOOL_OR(kind = 'bookmark' AND user_id = $2)
+	FROM
+		%[1]s
+	WHERE
+		%[2]s = ANY($1)
+	GROUP BY
+		%[2]s
+`
+
+// FindSummaries returns the summary of each item that has reactions. A
+// userID of 0 finds the counts only.
+func (r *repository) FindSummaries(ctx context.Context, contentType string, userID int64, contentIDs []int64) (map[int64]models.ReactionSummary, error) {
+	summaries := make(map[int64]models.ReactionSummary)
+
+	t, err := tableOf(contentType)
+	if err != nil {
+		return summaries, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(findSummaries, t.name, t.column), pq.Array(contentIDs), userID)
+	if err != nil {
+		return summaries, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			contentID int64
+			summary   models.ReactionSummary
+		)
+
+		err := rows.Scan(&contentID, &summary.Likes, &summary.Liked, &summary.Bookmarked)
+		if err != nil {
+			return summaries, err
+		}
+
+		summaries[contentID] = summary
+	}
+
+	return summaries, rows.Err()
+}
+
+// bookmarks builds a keyset query over the bookmarks of the user in each of
+// the types, newest first. Bookmarks of articles that are no longer
+// published are hidden unless the user wrote them, and so are those of items
+// hidden by the moderators.
+func bookmarks(params *Params, cursor *cursorToken) (string, []interface{}) {
+	query := strings.Builder{}
+	args := make([]interface{}, 0)
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	user := arg(params.UserID)
+	selects := make([]string, 0, len(params.Types))
+	for _, contentType := range params.Types {
+		selects = append(selects, fmt.Sprintf(tables[contentType].bookmark, user))
+	}
+
+	fmt.Fprintf(&query, `
+		SELECT
+			b.type, b.id, b.content_id, b.title, b.picture, b.created_at
+		FROM
+			(%s) b
+		WHERE
+			TRUE`,
+		strings.Join(selects, " UNION ALL "),
+	)
+
+	// Cursor
+	op, order := params.Order(true)
+	if cursor != nil {
+		fmt.Fprintf(&query, " AND (b.created_at, b.type, b.id) %s (%s, %s, %s) ", op, arg(cursor.CreatedAt), arg(cursor.Type), arg(cursor.ID))
+	}
+
+	// Limit
+	fmt.Fprintf(&query, " ORDER BY b.created_at %[1]s, b.type %[1]s, b.id %[1]s LIMIT %[2]s", order, arg(params.Limit))
+
+	return query.String(), args
+}
+
+func (r *repository) FindBookmarks(ctx context.Context, params *Params, cursor *cursorToken) ([]models.Bookmark, error) {
+	query, args := bookmarks(params, cursor)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]models.Bookmark, 0)
+
+	for rows.Next() {
+		var bookmark models.Bookmark
+
+		err := rows.Scan(
+			&bookmark.ContentType,
+			&bookmark.ID,
+			&bookmark.ContentID,
+			&bookmark.Title,
+			&bookmark.Picture,
+			&bookmark.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, bookmark)
+	}
+
+	return res, rows.Err()
+}
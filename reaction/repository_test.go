@@ -0,0 +1,31 @@
+package reaction
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/pagination"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBookmarksKeyset(t *testing.T) {
+	cursor := cursorToken{CreatedAt: 100, Type: models.ContentPodcast, ID: 7}
+	params := Params{
+		Params: pagination.Params{Limit: 5},
+		UserID: 3,
+		Types:  []string{models.ContentArticle, models.ContentPodcast},
+	}
+
+	query, args := bookmarks(&params, &cursor)
+	assert.Equal(t, 1, strings.Count(query, "UNION ALL"))
+	assert.Contains(t, query, "c.author_id = $1")
+	assert.Contains(t, query, "(b.created_at, b.type, b.id) < ($2, $3, $4)")
+	assert.True(t, strings.HasSuffix(query, "ORDER BY b.created_at DESC, b.type DESC, b.id DESC LIMIT $5"))
+	assert.Equal(t, []interface{}{int64(3), int64(100), models.ContentPodcast, int64(7), int64(5)}, args)
+
+	params.Direction = pagination.DirectionPrevious
+	query, _ = bookmarks(&params, &cursor)
+	assert.Contains(t, query, "(b.created_at, b.type, b.id) > ($2, $3, $4)")
+	assert.Contains(t, query, "ORDER BY b.created_at ASC, b.type ASC, b.id ASC")
+}
@@ -0,0 +1,152 @@
+package reaction
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/pagination"
+)
+
+type Service interface {
+	React(ctx context.Context, req *ReactReq) error
+	Unreact(ctx context.Context, req *ReactReq) error
+	Summaries(ctx context.Context, contentType string, userID int64, contentIDs ...int64) (map[int64]Summary, error)
+	GetBookmarks(ctx context.Context, params *Params) (GetBookmarksResp, error)
+}
+
+type service struct {
+	reactionRepo Repository
+}
+
+func NewService(reactionRepo Repository) Service {
+	return &service{
+		reactionRepo: reactionRepo,
+	}
+}
+
+// React likes or bookmarks the item for the user.
+func (s *service) React(ctx context.Context, req *ReactReq) error {
+	err := req.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = s.reactionRepo.Create(ctx, &models.Reaction{
+		Kind:        req.Kind,
+		ContentType: req.ContentType,
+		ContentID:   req.ContentID,
+		User:        models.User{ID: req.UserID},
+		CreatedAt:   time.Now().Unix(),
+	})
+	switch app.ErrorCode(err) {
+	case app.Econflict:
+		return app.NewError(err, app.Econflict, "Already "+past(req.Kind))
+	case app.ENotFound:
+		return app.NewError(err, app.ENotFound, title(req.ContentType)+" not found")
+	}
+
+	return err
+}
+
+func (s *service) Unreact(ctx context.Context, req *ReactReq) error {
+	err := req.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = s.reactionRepo.Delete(ctx, &models.Reaction{
+		Kind:        req.Kind,
+		ContentType: req.ContentType,
+		ContentID:   req.ContentID,
+		User:        models.User{ID: req.UserID},
+	})
+	if app.ErrorCode(err) == app.ENotFound {
+		return app.NewError(err, app.ENotFound, "Not "+past(req.Kind))
+	}
+
+	return err
+}
+
+// Summaries returns the summary of each item, for the viewer userID. Items
+// without reactions are missing from the map and read as the zero Summary.
+func (s *service) Summaries(ctx context.Context, contentType string, userID int64, contentIDs ...int64) (map[int64]Summary, error) {
+	res := make(map[int64]Summary)
+	if len(contentIDs) == 0 {
+		return res, nil
+	}
+
+	summaries, err := s.reactionRepo.FindSummaries(ctx, contentType, userID, contentIDs)
+	if err != nil {
+		return res, err
+	}
+
+	for contentID, summary := range summaries {
+		res[contentID] = Summary{
+			Likes:      summary.Likes,
+			Liked:      summary.Liked,
+			Bookmarked: summary.Bookmarked,
+		}
+	}
+
+	return res, nil
+}
+
+// GetBookmarks lists the bookmarks of the user, newest first.
+func (s *service) GetBookmarks(ctx context.Context, params *Params) (GetBookmarksResp, error) {
+	if len(params.Types) == 0 {
+		params.Types = ContentTypes
+	}
+	for _, contentType := range params.Types {
+		if _, ok := tables[contentType]; !ok {
+			return GetBookmarksResp{}, app.NewError(nil, app.EBadRequest, "Type must be one of ["+strings.Join(ContentTypes, " ")+"]")
+		}
+	}
+
+	var cursor *cursorToken
+	err := params.Normalize(&cursor)
+	if err != nil {
+		return GetBookmarksResp{}, err
+	}
+
+	bookmarks, err := s.reactionRepo.FindBookmarks(ctx, params, cursor)
+	if err != nil {
+		return GetBookmarksResp{}, err
+	}
+
+	if params.Previous() {
+		pagination.Reverse(bookmarks)
+	}
+
+	resp := GetBookmarksResp{
+		Bookmarks: make([]Bookmark, 0, len(bookmarks)),
+	}
+	for _, bookmark := range bookmarks {
+		resp.Bookmarks = append(resp.Bookmarks, Bookmark{
+			Type:         bookmark.ContentType,
+			ID:           bookmark.ContentID,
+			Title:        bookmark.Title,
+			Picture:      bookmark.Picture,
+			BookmarkedAt: bookmark.CreatedAt,
+		})
+	}
+	if len(bookmarks) > 0 {
+		resp.Cursor = pagination.NewCursor(newCursorToken(bookmarks[0]), newCursorToken(bookmarks[len(bookmarks)-1]))
+	}
+
+	return resp, nil
+}
+
+func past(kind string) string {
+	if kind == models.ReactionLike {
+		return "liked"
+	}
+
+	return "bookmarked"
+}
+
+func title(contentType string) string {
+	return strings.ToUpper(contentType[:1]) + contentType[1:]
+}
@@ -0,0 +1,62 @@
+package reaction
+
+import (
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/pagination"
+	"github.com/go-playground/validator/v10"
+)
+
+// ContentTypes are the types of content that can be liked and bookmarked.
+var ContentTypes = []string{
+	models.ContentArticle,
+	models.ContentDiscussion,
+	models.ContentWebinar,
+	models.ContentPodcast,
+	models.ContentComment,
+}
+
+type ReactReq struct {
+	Kind        string `json:"kind" validate:"required,oneof=like bookmark"`
+	ContentType string `json:"contentType" validate:"required,oneof=article discussion webinar podcast comment"`
+	ContentID   int64  `json:"contentID" validate:"required,gt=0"`
+	UserID      int64  `json:"userID" validate:"required,gt=0"`
+}
+
+func (r *ReactReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+
+	return app.ValidateAndTranslate(validate, err)
+}
+
+// Summary is embedded in the response of every item that can be liked and
+// bookmarked. Liked and Bookmarked are those of the viewer.
+type Summary struct {
+	Likes      int64 `json:"likes"`
+	Liked      bool  `json:"liked"`
+	Bookmarked bool  `json:"bookmarked"`
+}
+
+// Params lists the bookmarks of a user. Types selects the content types,
+// all of them when empty.
+type Params struct {
+	pagination.Params
+	UserID int64
+	Types  []string
+}
+
+// Bookmark is a bookmarked item. ID is that of the item, Title the text of a
+// comment.
+type Bookmark struct {
+	Type         string `json:"type"`
+	ID           int64  `json:"id"`
+	Title        string `json:"title"`
+	Picture      string `json:"picture"`
+	BookmarkedAt int64  `json:"bookmarkedAt"`
+}
+
+type GetBookmarksResp struct {
+	Cursor    pagination.Cursor `json:"cursor"`
+	Bookmarks []Bookmark        `json:"bookmarks"`
+}
@@ -0,0 +1,46 @@
+package recommendation
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Job recomputes podcast similarities once at startup and then every night at
+// the given hour, so reads only ever touch the precomputed table.
+type Job struct {
+	service Service
+	hour    int
+}
+
+func NewJob(service Service, hour int) *Job {
+	return &Job{
+		service: service,
+		hour:    hour,
+	}
+}
+
+func (j *Job) Run(ctx context.Context) {
+	for {
+		err := j.service.Recompute(ctx)
+		if err != nil {
+			logrus.Error("recompute podcast similarity: ", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(nextRun(time.Now(), j.hour))):
+		}
+	}
+}
+
+func nextRun(now time.Time, hour int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+
+	return next
+}
@@ -0,0 +1,21 @@
+package recommendation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextRun(t *testing.T) {
+	loc := time.FixedZone("WIB", 7*60*60)
+
+	before := time.Date(2021, 8, 20, 1, 30, 0, 0, loc)
+	assert.Equal(t, time.Date(2021, 8, 20, 2, 0, 0, 0, loc), nextRun(before, 2))
+
+	after := time.Date(2021, 8, 20, 2, 0, 0, 0, loc)
+	assert.Equal(t, time.Date(2021, 8, 21, 2, 0, 0, 0, loc), nextRun(after, 2))
+
+	endOfMonth := time.Date(2021, 8, 31, 23, 0, 0, 0, loc)
+	assert.Equal(t, time.Date(2021, 9, 1, 2, 0, 0, 0, loc), nextRun(endOfMonth, 2))
+}
@@ -0,0 +1,279 @@
+package recommendation
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bagus2x/recovy/models"
+)
+
+type Repository interface {
+	RecordListen(ctx context.Context, listen *models.PodcastListen) error
+	RecomputeSimilarity(ctx context.Context, computedAt int64) error
+	FindRecentlyLiked(ctx context.Context, userID, limit int64) ([]models.Podcast, error)
+	FindSimilar(ctx context.Context, podcastID, userID, limit int64) ([]models.Podcast, error)
+	FindForUser(ctx context.Context, userID, limit int64) ([]models.Podcast, error)
+	FindPopular(ctx context.Context, since, userID, limit int64) ([]models.Podcast, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+var recordListen = `
+	INSERT INTO
+		Podcast_Listen
+		(podcast_id, user_id, plays, last_listened_at)
+	VALUES
+		($1, $2, 1, $3)
+	ON CONFLICT (podcast_id, user_id) DO UPDATE SET
+		plays = Podcast_Listen.plays + 1, last_listened_at = EXCLUDED.last_listened_at
+	RETURNING
+		plays
+`
+
+func (r *repository) RecordListen(ctx context.Context, listen *models.PodcastListen) error {
+	err := r.db.QueryRowContext(
+		ctx,
+		recordListen,
+		listen.Podcast.ID,
+		listen.User.ID,
+		listen.LastListenedAt,
+	).Scan(&listen.Plays)
+
+	return err
+}
+
+// Likes weigh more than listens, and repeated plays saturate so a single
+// looped episode cannot dominate a user's profile. Scores are the cosine
+// similarity of the two podcasts' user vectors, keeping the top 20 per podcast.
+var recomputeSimilarity = `
+	WITH signals AS (
+		SELECT user_id, podcast_id, 2.0 AS weight FROM Podcast_Reaction WHERE kind = 'like'
+		UNION ALL
+		SELECT user_id, podcast_id, LEAST(plays, 5) * 0.2 FROM Podcast_Listen
+	), weights AS (
+		SELECT user_id, podcast_id, SUM(weight) AS weight FROM signals GROUP BY user_id, podcast_id
+	), norms AS (
+		SELECT podcast_id, SQRT(SUM(weight * weight)) AS norm FROM weights GROUP BY podcast_id
+	), pairs AS (
+		SELECT
+			a.podcast_id, b.podcast_id AS similar_podcast_id, SUM(a.weight * b.weight) AS dot
+		FROM
+			weights a
+		JOIN
+			weights b
+		ON
+			a.user_id = b.user_id AND a.podcast_id <> b.podcast_id
+		GROUP BY
+			a.podcast_id, b.podcast_id
+	), ranked AS (
+		SELECT
+			p.podcast_id, p.similar_podcast_id, p.dot / (na.norm * nb.norm) AS score,
+			ROW_NUMBER() OVER (PARTITION BY p.podcast_id ORDER BY p.dot / (na.norm * nb.norm) DESC) AS rank
+		FROM
+			pairs p
+		JOIN
+			norms na ON na.podcast_id = p.podcast_id
+		JOIN
+			norms nb ON nb.podcast_id = p.similar_podcast_id
+	)
+	INSERT INTO
+		Podcast_Similarity
+		(podcast_id, similar_podcast_id, score, computed_at)
+	SELECT
+		podcast_id, similar_podcast_id, score, $1
+	FROM
+		ranked
+	WHERE
+		rank <= 20
+`
+
+func (r *repository) RecomputeSimilarity(ctx context.Context, computedAt int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM Podcast_Similarity`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, recomputeSimilarity, computedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+var findRecentlyLiked = `
+	SELECT
+		pt.id, au.id, au.name, au.picture, pt.picture, pt.title, pt.description, pt.file, pt.duration, pt.created_at, pt.updated_at
+	FROM
+		Podcast_Reaction sp
+	JOIN
+		Podcast pt
+	ON
+		sp.podcast_id = pt.id
+	JOIN
+		App_User au
+	ON
+		pt.author_id = au.id
+	WHERE
+		sp.user_id = $1 AND sp.kind = 'like' AND pt.hidden_at = 0
+	ORDER BY
+		sp.id DESC
+	LIMIT
+		$2
+`
+
+func (r *repository) FindRecentlyLiked(ctx context.Context, userID, limit int64) ([]models.Podcast, error) {
+	return r.findPodcasts(ctx, findRecentlyLiked, userID, limit)
+}
+
+var findSimilar = `
+	SELECT
+		pt.id, au.id, au.name, au.picture, pt.picture, pt.title, pt.description, pt.file, pt.duration, pt.created_at, pt.updated_at
+	FROM
+		Podcast_Similarity ps
+	JOIN
+		Podcast pt
+	ON
+		ps.similar_podcast_id = pt.id
+	JOIN
+		App_User au
+	ON
+		pt.author_id = au.id
+	WHERE
+		ps.podcast_id = $1 AND pt.hidden_at = 0
+		AND NOT EXISTS (SELECT 1 FROM Podcast_Reaction sp WHERE sp.podcast_id = pt.id AND sp.user_id = $2 AND sp.kind = 'like')
+	ORDER BY
+		ps.score DESC
+	LIMIT
+		$3
+`
+
+func (r *repository) FindSimilar(ctx context.Context, podcastID, userID, limit int64) ([]models.Podcast, error) {
+	return r.findPodcasts(ctx, findSimilar, podcastID, userID, limit)
+}
+
+var findForUser = `
+	WITH profile AS (
+		SELECT podcast_id, 2.0 AS weight FROM Podcast_Reaction WHERE user_id = $1 AND kind = 'like'
+		UNION ALL
+		SELECT podcast_id, LEAST(plays, 5) * 0.2 FROM Podcast_Listen WHERE user_id = $1
+	), scores AS (
+		SELECT
+			ps.similar_podcast_id AS podcast_id, SUM(ps.score * pr.weight) AS score
+		FROM
+			profile pr
+		JOIN
+			Podcast_Similarity ps
+		ON
+			ps.podcast_id = pr.podcast_id
+		WHERE
+			ps.similar_podcast_id NOT IN (SELECT podcast_id FROM profile)
+		GROUP BY
+			ps.similar_podcast_id
+	)
+	SELECT
+		pt.id, au.id, au.name, au.picture, pt.picture, pt.title, pt.description, pt.file, pt.duration, pt.created_at, pt.updated_at
+	FROM
+		scores sc
+	JOIN
+		Podcast pt
+	ON
+		sc.podcast_id = pt.id
+	JOIN
+		App_User au
+	ON
+		pt.author_id = au.id
+	WHERE
+		pt.hidden_at = 0
+	ORDER BY
+		sc.score DESC, pt.id DESC
+	LIMIT
+		$2
+`
+
+func (r *repository) FindForUser(ctx context.Context, userID, limit int64) ([]models.Podcast, error) {
+	return r.findPodcasts(ctx, findForUser, userID, limit)
+}
+
+var findPopular = `
+	WITH popularity AS (
+		SELECT podcast_id, 2.0 AS weight FROM Podcast_Reaction WHERE created_at >= $1 AND kind = 'like'
+		UNION ALL
+		SELECT podcast_id, LEAST(plays, 5) * 0.2 FROM Podcast_Listen WHERE last_listened_at >= $1
+	)
+	SELECT
+		pt.id, au.id, au.name, au.picture, pt.picture, pt.title, pt.description, pt.file, pt.duration, pt.created_at, pt.updated_at
+	FROM
+		Podcast pt
+	JOIN
+		App_User au
+	ON
+		pt.author_id = au.id
+	LEFT JOIN
+		(SELECT podcast_id, SUM(weight) AS score FROM popularity GROUP BY podcast_id) pop
+	ON
+		pop.podcast_id = pt.id
+	WHERE
+		pt.hidden_at = 0
+		AND NOT EXISTS (SELECT 1 FROM Podcast_Reaction sp WHERE sp.podcast_id = pt.id AND sp.user_id = $2 AND sp.kind = 'like')
+	ORDER BY
+		COALESCE(pop.score, 0) DESC, pt.id DESC
+	LIMIT
+		$3
+`
+
+func (r *repository) FindPopular(ctx context.Context, since, userID, limit int64) ([]models.Podcast, error) {
+	return r.findPodcasts(ctx, findPopular, since, userID, limit)
+}
+
+func (r *repository) findPodcasts(ctx context.Context, query string, args ...interface{}) ([]models.Podcast, error) {
+	podcasts := make([]models.Podcast, 0)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return podcasts, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var podcast models.Podcast
+
+		err := rows.Scan(
+			&podcast.ID,
+			&podcast.Author.ID,
+			&podcast.Author.Name,
+			&podcast.Author.Picture,
+			&podcast.Picture,
+			&podcast.Title,
+			&podcast.Description,
+			&podcast.File,
+			&podcast.Duration,
+			&podcast.CreatedAt,
+			&podcast.UpdatedAt,
+		)
+		if err != nil {
+			return podcasts, err
+		}
+
+		podcasts = append(podcasts, podcast)
+	}
+	if err := rows.Err(); err != nil {
+		return podcasts, err
+	}
+
+	return podcasts, nil
+}
@@ -0,0 +1,135 @@
+package recommendation
+
+import (
+	"context"
+	"time"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/podcast"
+)
+
+const (
+	seedLimit       = 3
+	similarLimit    = 10
+	forYouLimit     = 20
+	popularityRange = 30 * 24 * time.Hour
+)
+
+type Service interface {
+	GetRecommendations(ctx context.Context, userID int64) (GetRecommendationsResp, error)
+	RecordListen(ctx context.Context, req *RecordListenReq) error
+	Recompute(ctx context.Context) error
+}
+
+type service struct {
+	recommendationRepo Repository
+	podcastRepo        podcast.Repository
+}
+
+func NewService(recommendationRepo Repository, podcastRepo podcast.Repository) Service {
+	return &service{
+		recommendationRepo: recommendationRepo,
+		podcastRepo:        podcastRepo,
+	}
+}
+
+// GetRecommendations builds "because you liked" rows from the user's latest
+// likes and a personal list from all of their signals. Anonymous users and
+// users without any signal yet get the currently popular podcasts instead.
+func (s *service) GetRecommendations(ctx context.Context, userID int64) (GetRecommendationsResp, error) {
+	res := GetRecommendationsResp{
+		BecauseYouLiked:   make([]BecauseYouLiked, 0),
+		RecommendedForYou: make([]podcast.Podcast, 0),
+	}
+
+	if userID != 0 {
+		seeds, err := s.recommendationRepo.FindRecentlyLiked(ctx, userID, seedLimit)
+		if err != nil {
+			return GetRecommendationsResp{}, err
+		}
+
+		for _, seed := range seeds {
+			similar, err := s.recommendationRepo.FindSimilar(ctx, seed.ID, userID, similarLimit)
+			if err != nil {
+				return GetRecommendationsResp{}, err
+			}
+			if len(similar) == 0 {
+				continue
+			}
+
+			res.BecauseYouLiked = append(res.BecauseYouLiked, BecauseYouLiked{
+				Podcast:  toPodcast(seed),
+				Podcasts: toPodcasts(similar),
+			})
+		}
+
+		forYou, err := s.recommendationRepo.FindForUser(ctx, userID, forYouLimit)
+		if err != nil {
+			return GetRecommendationsResp{}, err
+		}
+		res.RecommendedForYou = toPodcasts(forYou)
+	}
+
+	if len(res.RecommendedForYou) == 0 {
+		since := time.Now().Add(-popularityRange).Unix()
+		popular, err := s.recommendationRepo.FindPopular(ctx, since, userID, forYouLimit)
+		if err != nil {
+			return GetRecommendationsResp{}, err
+		}
+		res.RecommendedForYou = toPodcasts(popular)
+	}
+
+	return res, nil
+}
+
+func (s *service) RecordListen(ctx context.Context, req *RecordListenReq) error {
+	err := req.Validate()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.podcastRepo.FindByID(ctx, req.PodcastID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return app.NewError(err, app.ENotFound, "Podcast not found")
+	} else if err != nil {
+		return err
+	}
+
+	return s.recommendationRepo.RecordListen(ctx, &models.PodcastListen{
+		Podcast:        models.Podcast{ID: req.PodcastID},
+		User:           models.User{ID: req.UserID},
+		LastListenedAt: time.Now().Unix(),
+	})
+}
+
+func (s *service) Recompute(ctx context.Context) error {
+	return s.recommendationRepo.RecomputeSimilarity(ctx, time.Now().Unix())
+}
+
+func toPodcasts(podcasts []models.Podcast) []podcast.Podcast {
+	res := make([]podcast.Podcast, 0, len(podcasts))
+	for _, p := range podcasts {
+		res = append(res, toPodcast(p))
+	}
+
+	return res
+}
+
+func toPodcast(p models.Podcast) podcast.Podcast {
+	return podcast.Podcast{
+		ID: p.ID,
+		Author: podcast.Author{
+			ID:      p.Author.ID,
+			Name:    p.Author.Name,
+			Picture: p.Author.Picture,
+		},
+		Picture:     p.Picture,
+		Title:       p.Title,
+		Description: p.Description,
+		File:        p.File,
+		Duration:    p.Duration,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
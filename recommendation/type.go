@@ -0,0 +1,29 @@
+package recommendation
+
+import (
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/podcast"
+	"github.com/go-playground/validator/v10"
+)
+
+type BecauseYouLiked struct {
+	Podcast  podcast.Podcast   `json:"podcast"`
+	Podcasts []podcast.Podcast `json:"podcasts"`
+}
+
+type GetRecommendationsResp struct {
+	BecauseYouLiked   []BecauseYouLiked `json:"becauseYouLiked"`
+	RecommendedForYou []podcast.Podcast `json:"recommendedForYou"`
+}
+
+type RecordListenReq struct {
+	PodcastID int64 `json:"podcastID" validate:"required,gt=0"`
+	UserID    int64 `json:"userID" validate:"required,gt=0"`
+}
+
+func (r *RecordListenReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+
+	return app.ValidateAndTranslate(validate, err)
+}
@@ -0,0 +1,368 @@
+package rrule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+const untilLayout = "20060102T150405Z"
+
+// maxEmptyPeriods stops the expansion of rules that can never produce another
+// occurrence, e.g. the fifth Monday of every twelfth month in a short run.
+const maxEmptyPeriods = 1000
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// Weekday is a BYDAY entry. N selects the nth such weekday of the month,
+// counting from the end when negative; zero means every such weekday.
+type Weekday struct {
+	Day time.Weekday
+	N   int
+}
+
+func (w Weekday) String() string {
+	s := strings.ToUpper(w.Day.String()[:2])
+	if w.N != 0 {
+		s = strconv.Itoa(w.N) + s
+	}
+
+	return s
+}
+
+// Rule is the subset of an RFC 5545 RRULE the app supports: DAILY, WEEKLY
+// and MONTHLY frequencies with INTERVAL, BYDAY, COUNT and UNTIL. Weeks start
+// on Monday.
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	ByDay    []Weekday
+	Count    int
+	Until    time.Time
+}
+
+// Parse reads a rule such as "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10". A leading
+// "RRULE:" is accepted.
+func Parse(s string) (Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	rule := Rule{Interval: 1}
+
+	for _, part := range strings.Split(s, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return Rule{}, fmt.Errorf("invalid rule part %q", part)
+		}
+		key, value := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+
+		switch key {
+		case "FREQ":
+			switch f := Frequency(value); f {
+			case Daily, Weekly, Monthly:
+				rule.Freq = f
+			default:
+				return Rule{}, fmt.Errorf("unsupported frequency %q", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("invalid interval %q", value)
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("invalid count %q", value)
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return Rule{}, err
+			}
+			rule.Until = until
+		case "BYDAY":
+			for _, v := range strings.Split(value, ",") {
+				day, err := parseWeekday(v)
+				if err != nil {
+					return Rule{}, err
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		case "WKST":
+			if value != "MO" {
+				return Rule{}, fmt.Errorf("unsupported week start %q", value)
+			}
+		default:
+			return Rule{}, fmt.Errorf("unsupported rule part %q", key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return Rule{}, fmt.Errorf("missing FREQ")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return Rule{}, fmt.Errorf("COUNT and UNTIL must not both be set")
+	}
+	for _, day := range rule.ByDay {
+		if day.N != 0 && rule.Freq != Monthly {
+			return Rule{}, fmt.Errorf("BYDAY %s is only valid in a monthly rule", day)
+		}
+	}
+
+	return rule, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	if t, err := time.Parse(untilLayout, value); err == nil {
+		return t, nil
+	}
+
+	// A date-only UNTIL includes the whole day.
+	t, err := time.Parse("20060102", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid until %q", value)
+	}
+
+	return t.Add(24*time.Hour - time.Second), nil
+}
+
+func parseWeekday(v string) (Weekday, error) {
+	if len(v) < 2 {
+		return Weekday{}, fmt.Errorf("invalid weekday %q", v)
+	}
+
+	day, ok := weekdays[v[len(v)-2:]]
+	if !ok {
+		return Weekday{}, fmt.Errorf("invalid weekday %q", v)
+	}
+
+	var n int
+	if prefix := v[:len(v)-2]; prefix != "" {
+		var err error
+		n, err = strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return Weekday{}, fmt.Errorf("invalid weekday %q", v)
+		}
+	}
+
+	return Weekday{Day: day, N: n}, nil
+}
+
+// String formats the rule without the "RRULE:" prefix.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, day := range r.ByDay {
+			days = append(days, day.String())
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayout))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Bounded reports whether the rule ends by COUNT or UNTIL.
+func (r Rule) Bounded() bool {
+	return r.Count > 0 || !r.Until.IsZero()
+}
+
+// Each calls fn with every occurrence in order, starting at dtstart, until fn
+// returns false or the rule ends. Occurrences keep the wall clock time of
+// dtstart in its location, so they follow daylight saving changes. Callers of
+// an unbounded rule must stop the expansion themselves.
+func (r Rule) Each(dtstart time.Time, fn func(time.Time) bool) {
+	count := 0
+	empty := 0
+	for period := 0; empty < maxEmptyPeriods; period++ {
+		candidates := r.candidates(dtstart, period)
+		if len(candidates) == 0 {
+			empty++
+			continue
+		}
+		empty = 0
+
+		for _, t := range candidates {
+			if t.Before(dtstart) {
+				continue
+			}
+			if !r.Until.IsZero() && t.After(r.Until) {
+				return
+			}
+			count++
+			if r.Count > 0 && count > r.Count {
+				return
+			}
+			if !fn(t) {
+				return
+			}
+		}
+	}
+}
+
+// Between returns the occurrences starting in [from, to).
+func (r Rule) Between(dtstart, from, to time.Time) []time.Time {
+	occurrences := make([]time.Time, 0)
+	r.Each(dtstart, func(t time.Time) bool {
+		if !t.Before(to) {
+			return false
+		}
+		if !t.Before(from) {
+			occurrences = append(occurrences, t)
+		}
+
+		return true
+	})
+
+	return occurrences
+}
+
+// Includes reports whether t is an occurrence of the rule.
+func (r Rule) Includes(dtstart, t time.Time) bool {
+	found := false
+	r.Each(dtstart, func(o time.Time) bool {
+		found = o.Equal(t)
+
+		return o.Before(t)
+	})
+
+	return found
+}
+
+// Last returns the final occurrence of a bounded rule.
+func (r Rule) Last(dtstart time.Time) (time.Time, bool) {
+	if !r.Bounded() {
+		return time.Time{}, false
+	}
+
+	var last time.Time
+	r.Each(dtstart, func(t time.Time) bool {
+		last = t
+
+		return true
+	})
+
+	return last, !last.IsZero()
+}
+
+// candidates returns the sorted occurrences of the nth period after the one
+// containing dtstart, before COUNT and UNTIL are applied.
+func (r Rule) candidates(dtstart time.Time, n int) []time.Time {
+	y, m, d := dtstart.Date()
+	hh, mm, ss := dtstart.Clock()
+	loc := dtstart.Location()
+	step := n * r.Interval
+
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, hh, mm, ss, 0, loc)
+	}
+
+	switch r.Freq {
+	case Daily:
+		t := at(y, m, d+step)
+		if len(r.ByDay) > 0 && !r.hasWeekday(t.Weekday()) {
+			return nil
+		}
+
+		return []time.Time{t}
+	case Weekly:
+		monday := d - (int(dtstart.Weekday())+6)%7 + 7*step
+		if len(r.ByDay) == 0 {
+			return []time.Time{at(y, m, d+7*step)}
+		}
+
+		days := make([]time.Time, 0, len(r.ByDay))
+		for _, day := range r.ByDay {
+			days = append(days, at(y, m, monday+(int(day.Day)+6)%7))
+		}
+
+		return sortUnique(days)
+	case Monthly:
+		first := time.Date(y, m+time.Month(step), 1, 0, 0, 0, 0, loc)
+		y, m = first.Year(), first.Month()
+		length := time.Date(y, m+1, 0, 0, 0, 0, 0, loc).Day()
+		if len(r.ByDay) == 0 {
+			if d > length {
+				return nil
+			}
+
+			return []time.Time{at(y, m, d)}
+		}
+
+		days := make([]time.Time, 0)
+		for _, day := range r.ByDay {
+			firstDay := 1 + (int(day.Day)-int(first.Weekday())+7)%7
+			switch {
+			case day.N == 0:
+				for dd := firstDay; dd <= length; dd += 7 {
+					days = append(days, at(y, m, dd))
+				}
+			case day.N > 0:
+				if dd := firstDay + 7*(day.N-1); dd <= length {
+					days = append(days, at(y, m, dd))
+				}
+			default:
+				lastDay := firstDay + 7*((length-firstDay)/7)
+				if dd := lastDay + 7*(day.N+1); dd >= 1 {
+					days = append(days, at(y, m, dd))
+				}
+			}
+		}
+
+		return sortUnique(days)
+	}
+
+	return nil
+}
+
+func (r Rule) hasWeekday(weekday time.Weekday) bool {
+	for _, day := range r.ByDay {
+		if day.Day == weekday {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortUnique(times []time.Time) []time.Time {
+	sort.Slice(times, func(i, j int) bool {
+		return times[i].Before(times[j])
+	})
+
+	unique := times[:0]
+	for _, t := range times {
+		if len(unique) == 0 || !t.Equal(unique[len(unique)-1]) {
+			unique = append(unique, t)
+		}
+	}
+
+	return unique
+}
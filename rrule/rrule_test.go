@@ -0,0 +1,97 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	rule, err := Parse("RRULE:FREQ=MONTHLY;INTERVAL=2;BYDAY=1MO,-1FR;UNTIL=20211231T000000Z")
+	assert.NoError(t, err)
+	assert.Equal(t, Monthly, rule.Freq)
+	assert.Equal(t, 2, rule.Interval)
+	assert.Equal(t, []Weekday{{Day: time.Monday, N: 1}, {Day: time.Friday, N: -1}}, rule.ByDay)
+	assert.Equal(t, "FREQ=MONTHLY;INTERVAL=2;BYDAY=1MO,-1FR;UNTIL=20211231T000000Z", rule.String())
+
+	for _, s := range []string{
+		"",
+		"FREQ=YEARLY",
+		"FREQ=WEEKLY;INTERVAL=0",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=DAILY;COUNT=2;UNTIL=20211231",
+		"FREQ=DAILY;BYSETPOS=1",
+	} {
+		_, err := Parse(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestWeekly(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	rule, err := Parse("FREQ=WEEKLY;BYDAY=MO,TH;COUNT=5")
+	assert.NoError(t, err)
+
+	dtstart := time.Date(2021, 9, 2, 19, 0, 0, 0, loc) // Thursday
+	occurrences := rule.Between(dtstart, dtstart, dtstart.AddDate(1, 0, 0))
+	assert.Equal(t, []time.Time{
+		time.Date(2021, 9, 2, 19, 0, 0, 0, loc),
+		time.Date(2021, 9, 6, 19, 0, 0, 0, loc),
+		time.Date(2021, 9, 9, 19, 0, 0, 0, loc),
+		time.Date(2021, 9, 13, 19, 0, 0, 0, loc),
+		time.Date(2021, 9, 16, 19, 0, 0, 0, loc),
+	}, occurrences)
+
+	last, ok := rule.Last(dtstart)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2021, 9, 16, 19, 0, 0, 0, loc), last)
+	assert.True(t, rule.Includes(dtstart, time.Date(2021, 9, 13, 19, 0, 0, 0, loc)))
+	assert.False(t, rule.Includes(dtstart, time.Date(2021, 9, 14, 19, 0, 0, 0, loc)))
+}
+
+func TestDailyAcrossDST(t *testing.T) {
+	loc, _ := time.LoadLocation("America/New_York")
+	rule, _ := Parse("FREQ=DAILY;INTERVAL=2;UNTIL=20210316")
+
+	dtstart := time.Date(2021, 3, 10, 9, 0, 0, 0, loc)
+	occurrences := rule.Between(dtstart, dtstart, dtstart.AddDate(0, 1, 0))
+	assert.Len(t, occurrences, 4)
+	for _, o := range occurrences {
+		assert.Equal(t, 9, o.Hour())
+	}
+
+	_, ok := Rule{Freq: Daily, Interval: 1}.Last(dtstart)
+	assert.False(t, ok)
+}
+
+func TestMonthly(t *testing.T) {
+	rule, _ := Parse("FREQ=MONTHLY;COUNT=3")
+	dtstart := time.Date(2021, 1, 31, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, []time.Time{
+		time.Date(2021, 1, 31, 10, 0, 0, 0, time.UTC),
+		time.Date(2021, 3, 31, 10, 0, 0, 0, time.UTC),
+		time.Date(2021, 5, 31, 10, 0, 0, 0, time.UTC),
+	}, rule.Between(dtstart, dtstart, dtstart.AddDate(1, 0, 0)))
+
+	rule, _ = Parse("FREQ=MONTHLY;BYDAY=-1FR,2TU;COUNT=4")
+	dtstart = time.Date(2021, 9, 14, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, []time.Time{
+		time.Date(2021, 9, 14, 10, 0, 0, 0, time.UTC),
+		time.Date(2021, 9, 24, 10, 0, 0, 0, time.UTC),
+		time.Date(2021, 10, 12, 10, 0, 0, 0, time.UTC),
+		time.Date(2021, 10, 29, 10, 0, 0, 0, time.UTC),
+	}, rule.Between(dtstart, dtstart, dtstart.AddDate(1, 0, 0)))
+}
+
+func TestBetweenWindow(t *testing.T) {
+	rule, _ := Parse("FREQ=WEEKLY")
+	dtstart := time.Date(2021, 9, 1, 10, 0, 0, 0, time.UTC)
+
+	occurrences := rule.Between(dtstart, time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 10, 15, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, []time.Time{
+		time.Date(2021, 10, 6, 10, 0, 0, 0, time.UTC),
+		time.Date(2021, 10, 13, 10, 0, 0, 0, time.UTC),
+	}, occurrences)
+}
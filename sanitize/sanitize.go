@@ -0,0 +1,296 @@
+// Package sanitize cleans user-supplied HTML with an allow-list of elements
+// and attributes.
+package sanitize
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// allowed maps the elements that are kept to the attributes they may carry.
+var allowed = map[string]map[string]bool{
+	"a":          {"href": true, "title": true},
+	"abbr":       {"title": true},
+	"b":          {},
+	"blockquote": {},
+	"br":         {},
+	"code":       {"class": true},
+	"del":        {},
+	"em":         {},
+	"h1":         {},
+	"h2":         {},
+	"h3":         {},
+	"h4":         {},
+	"h5":         {},
+	"h6":         {},
+	"hr":         {},
+	"i":          {},
+	"img":        {"src": true, "alt": true, "title": true},
+	"li":         {},
+	"ol":         {"start": true},
+	"p":          {},
+	"pre":        {},
+	"s":          {},
+	"strong":     {},
+	"sub":        {},
+	"sup":        {},
+	"table":      {},
+	"tbody":      {},
+	"td":         {"align": true},
+	"th":         {"align": true},
+	"thead":      {},
+	"tr":         {},
+	"ul":         {},
+}
+
+// dropped are the elements removed together with their content.
+var dropped = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"iframe":   true,
+	"object":   true,
+	"embed":    true,
+	"noscript": true,
+	"template": true,
+	"textarea": true,
+	"title":    true,
+	"svg":      true,
+	"math":     true,
+}
+
+var void = map[string]bool{
+	"br":  true,
+	"hr":  true,
+	"img": true,
+}
+
+// block elements separate words when HTML is reduced to text.
+var block = map[string]bool{
+	"blockquote": true, "br": true, "div": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "hr": true, "li": true, "p": true,
+	"pre": true, "td": true, "th": true, "tr": true,
+}
+
+var schemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+var (
+	startTag  = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9-]*)((?:\s+[^\s"'>/=]+(?:\s*=\s*(?:"[^"]*"|'[^']*'|[^\s"'=<>` + "`" + `]+))?)*)\s*/?>`)
+	endTag    = regexp.MustCompile(`^</([A-Za-z][A-Za-z0-9-]*)\s*>`)
+	attr      = regexp.MustCompile(`([^\s"'>/=]+)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'=<>` + "`" + `]+)))?`)
+	codeClass = regexp.MustCompile(`^language-[A-Za-z0-9_+#.-]+$`)
+	align     = regexp.MustCompile(`^(left|right|center)$`)
+	number    = regexp.MustCompile(`^[0-9]{1,9}$`)
+)
+
+var escaper = strings.NewReplacer(`&`, "&amp;", `<`, "&lt;", `>`, "&gt;", `"`, "&quot;")
+
+// HTML keeps the allowed elements and attributes and escapes or removes
+// everything else. Links may only use http, https and mailto URLs, and the
+// elements are balanced so the result can be embedded in a page.
+func HTML(s string) string {
+	var b strings.Builder
+	open := make([]string, 0)
+
+	for len(s) > 0 {
+		i := strings.IndexByte(s, '<')
+		if i < 0 {
+			b.WriteString(escapeText(s))
+			break
+		}
+		b.WriteString(escapeText(s[:i]))
+		s = s[i:]
+
+		if n := skipMarkup(s); n > 0 {
+			s = s[n:]
+			continue
+		}
+
+		if m := endTag.FindStringSubmatch(s); m != nil {
+			s = s[len(m[0]):]
+
+			name := strings.ToLower(m[1])
+			for j := len(open) - 1; j >= 0; j-- {
+				if open[j] == name {
+					for k := len(open) - 1; k >= j; k-- {
+						b.WriteString("</" + open[k] + ">")
+					}
+					open = open[:j]
+					break
+				}
+			}
+			continue
+		}
+
+		m := startTag.FindStringSubmatch(s)
+		if m == nil {
+			b.WriteString("&lt;")
+			s = s[1:]
+			continue
+		}
+		s = s[len(m[0]):]
+
+		name := strings.ToLower(m[1])
+		if dropped[name] {
+			s = skipElement(s, name)
+			continue
+		}
+
+		attrs, ok := allowed[name]
+		if !ok {
+			continue
+		}
+
+		b.WriteString("<" + name)
+		for _, a := range attr.FindAllStringSubmatch(m[2], -1) {
+			key := strings.ToLower(a[1])
+			value := html.UnescapeString(a[2] + a[3] + a[4])
+			if !attrs[key] || !validAttr(name, key, value) {
+				continue
+			}
+			b.WriteString(" " + key + `="` + escaper.Replace(value) + `"`)
+		}
+		if name == "a" {
+			b.WriteString(` rel="nofollow noopener noreferrer"`)
+		}
+
+		if void[name] {
+			b.WriteString(" />")
+			continue
+		}
+		b.WriteString(">")
+		open = append(open, name)
+	}
+
+	for j := len(open) - 1; j >= 0; j-- {
+		b.WriteString("</" + open[j] + ">")
+	}
+
+	return b.String()
+}
+
+func validAttr(element, key, value string) bool {
+	switch key {
+	case "href", "src":
+		return safeURL(value)
+	case "class":
+		return element == "code" && codeClass.MatchString(value)
+	case "align":
+		return align.MatchString(value)
+	case "start":
+		return number.MatchString(value)
+	}
+
+	return true
+}
+
+// safeURL allows relative URLs and absolute ones with an allowed scheme.
+// Browsers ignore control characters and whitespace in a scheme, so they are
+// removed before it is checked.
+func safeURL(s string) bool {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, s)
+
+	i := strings.IndexAny(s, ":/?#")
+	if i < 0 || s[i] != ':' {
+		return true
+	}
+
+	return schemes[strings.ToLower(s[:i])]
+}
+
+// skipMarkup returns the length of a comment, doctype or processing
+// instruction at the start of s, or 0.
+func skipMarkup(s string) int {
+	var end string
+	switch {
+	case strings.HasPrefix(s, "<!--"):
+		end = "-->"
+	case strings.HasPrefix(s, "<![CDATA["):
+		end = "]]>"
+	case strings.HasPrefix(s, "<!"), strings.HasPrefix(s, "<?"):
+		end = ">"
+	default:
+		return 0
+	}
+
+	i := strings.Index(s[2:], end)
+	if i < 0 {
+		return len(s)
+	}
+
+	return i + 2 + len(end)
+}
+
+// skipElement skips past the end tag of a dropped element.
+func skipElement(s, name string) string {
+	lower := strings.ToLower(s)
+	for i := 0; ; {
+		j := strings.Index(lower[i:], "</"+name)
+		if j < 0 {
+			return ""
+		}
+		i += j
+
+		if m := endTag.FindStringSubmatch(s[i:]); m != nil && strings.ToLower(m[1]) == name {
+			return s[i+len(m[0]):]
+		}
+		i += 2
+	}
+}
+
+// escapeText escapes text while keeping the entities that are already in it.
+func escapeText(s string) string {
+	return escaper.Replace(html.UnescapeString(s))
+}
+
+// Text reduces HTML to its text, with whitespace collapsed.
+func Text(s string) string {
+	var b strings.Builder
+
+	for len(s) > 0 {
+		i := strings.IndexByte(s, '<')
+		if i < 0 {
+			b.WriteString(html.UnescapeString(s))
+			break
+		}
+		b.WriteString(html.UnescapeString(s[:i]))
+		s = s[i:]
+
+		if n := skipMarkup(s); n > 0 {
+			s = s[n:]
+			continue
+		}
+		if m := endTag.FindStringSubmatch(s); m != nil {
+			s = s[len(m[0]):]
+			if block[strings.ToLower(m[1])] {
+				b.WriteString(" ")
+			}
+			continue
+		}
+		if m := startTag.FindStringSubmatch(s); m != nil {
+			s = s[len(m[0]):]
+			name := strings.ToLower(m[1])
+			if dropped[name] {
+				s = skipElement(s, name)
+			}
+			if block[name] {
+				b.WriteString(" ")
+			}
+			continue
+		}
+
+		b.WriteString("<")
+		s = s[1:]
+	}
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
@@ -0,0 +1,38 @@
+package sanitize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		in, out string
+	}{
+		{`<p>Hello <strong>world</strong></p>`, `<p>Hello <strong>world</strong></p>`},
+		{`<p onclick="alert(1)">x</p>`, `<p>x</p>`},
+		{`<script>alert(1)</script><p>x</p>`, `<p>x</p>`},
+		{`<SCRIPT>alert(1)</script >y`, `y`},
+		{`<div><p>x</p></div>`, `<p>x</p>`},
+		{`<a href="javascript:alert(1)">x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{`<a href="jav&#x09;ascript:alert(1)">x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{`<a href="/path?a=1&amp;b=2" title='t"'>x</a>`, `<a href="/path?a=1&amp;b=2" title="t&quot;" rel="nofollow noopener noreferrer">x</a>`},
+		{`<img src="data:image/png;base64,AAAA" alt="a">`, `<img alt="a" />`},
+		{`<img src="https://example.com/a.png" onerror="x">`, `<img src="https://example.com/a.png" />`},
+		{`<code class="language-go">x</code><code class="evil">y</code>`, `<code class="language-go">x</code><code>y</code>`},
+		{`<em><strong>x</em></strong>`, `<em><strong>x</strong></em>`},
+		{`<ul><li>x`, `<ul><li>x</li></ul>`},
+		{`a < b && c > d`, `a &lt; b &amp;&amp; c &gt; d`},
+		{`<!-- hidden -->x<!DOCTYPE html>`, `x`},
+		{`<td align="center">x</td><td align="x">y</td>`, `<td align="center">x</td><td>y</td>`},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.out, HTML(test.in), test.in)
+	}
+}
+
+func TestText(t *testing.T) {
+	assert.Equal(t, "Title First & second. Item", Text("<h1>Title</h1>\n<p>First &amp; <em>second</em>.</p><style>p{}</style><ul><li>Item</li></ul>"))
+}
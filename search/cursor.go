@@ -0,0 +1,35 @@
+package search
+
+import (
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/pagination"
+)
+
+// cursorToken is the position of the last result of a page. The query is
+// kept to reject a cursor used with another search.
+type cursorToken struct {
+	Query string  `json:"q"`
+	Rank  float64 `json:"r"`
+	Type  string  `json:"t"`
+	ID    int64   `json:"id"`
+}
+
+func newCursorToken(query string, result models.SearchResult) cursorToken {
+	return cursorToken{
+		Query: query,
+		Rank:  result.Rank,
+		Type:  result.Type,
+		ID:    result.ID,
+	}
+}
+
+func encodeCursor(token cursorToken) string {
+	return pagination.Encode(token)
+}
+
+func decodeCursor(s string) (cursorToken, error) {
+	var token cursorToken
+	err := pagination.Decode(s, &token)
+
+	return token, err
+}
@@ -0,0 +1,164 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/bagus2x/recovy/models"
+)
+
+const (
+	// startSel and stopSel mark the matched words in headlines. They cannot
+	// occur in user text, so the text can be escaped before they are turned
+	// into tags.
+	startSel = "\x02"
+	stopSel  = "\x03"
+
+	titleOptions   = "HighlightAll=true, StartSel=" + startSel + ", StopSel=" + stopSel
+	snippetOptions = "MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=\" … \", StartSel=" + startSel + ", StopSel=" + stopSel
+)
+
+// configs are the text search configurations of each language and the
+// document column indexed with them.
+var configs = map[string]struct {
+	name   string
+	column string
+}{
+	LangEnglish:    {name: "english", column: "search_en"},
+	LangIndonesian: {name: "indonesian", column: "search_id"},
+}
+
+// sources are the tables searched for each content type. Only published
+// articles are searched, and nothing hidden by the moderators.
+var sources = map[string]struct {
+	table    string
+	category string
+	where    string
+}{
+	TypeArticle:    {table: "Article", category: "x.category", where: "x.status = 'published' AND x.hidden_at = 0"},
+	TypeDiscussion: {table: "Discussion", category: "x.category", where: "x.hidden_at = 0"},
+	TypeWebinar:    {table: "Webinar", category: "x.category", where: "x.hidden_at = 0"},
+	TypePodcast:    {table: "Podcast", category: "''", where: "x.hidden_at = 0"},
+}
+
+type Repository interface {
+	Search(ctx context.Context, params *Params, cursor *cursorToken) ([]models.SearchResult, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+// search builds a query over the content types, ranked by relevance with the
+// type and id as tie breakers. The page is cut before the headlines are
+// made, as they are costly.
+func search(params *Params, cursor *cursorToken) (string, []interface{}) {
+	query := strings.Builder{}
+	args := make([]interface{}, 0)
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	langs := []string{LangEnglish, LangIndonesian}
+	if params.Lang != "" {
+		langs = []string{params.Lang}
+	}
+	q := arg(params.Query)
+
+	matches := make([]string, 0, len(langs))
+	ranks := make([]string, 0, len(langs))
+	config := "CASE"
+	for _, lang := range langs {
+		c := configs[lang]
+		tsquery := fmt.Sprintf("websearch_to_tsquery('%s', %s)", c.name, q)
+		matches = append(matches, fmt.Sprintf("x.%s @@ %s", c.column, tsquery))
+		ranks = append(ranks, fmt.Sprintf("ts_rank(x.%s, %s)", c.column, tsquery))
+		config += fmt.Sprintf(" WHEN x.%s @@ %s THEN '%s'", c.column, tsquery, c.name)
+	}
+	config += " END"
+
+	rank := ranks[0]
+	if len(ranks) > 1 {
+		rank = "GREATEST(" + strings.Join(ranks, ", ") + ")"
+	}
+
+	selects := make([]string, 0, len(params.Types))
+	for _, typ := range params.Types {
+		source := sources[typ]
+		selects = append(selects, fmt.Sprintf(`
+			SELECT
+				'%s' AS type, x.id, x.title, x.description, x.picture, %s AS category, %s::FLOAT8 AS rank, %s AS config, x.created_at
+			FROM
+				%s x
+			WHERE
+				%s AND (%s)`,
+			typ, source.category, rank, config, source.table, source.where, strings.Join(matches, " OR "),
+		))
+	}
+
+	page := strings.Builder{}
+	page.WriteString("SELECT * FROM (" + strings.Join(selects, " UNION ALL ") + ") s WHERE TRUE")
+	if cursor != nil {
+		fmt.Fprintf(&page, " AND (s.rank, s.type, s.id) < (%s, %s, %s)", arg(cursor.Rank), arg(cursor.Type), arg(cursor.ID))
+	}
+	fmt.Fprintf(&page, " ORDER BY s.rank DESC, s.type DESC, s.id DESC LIMIT %s", arg(params.Limit))
+
+	fmt.Fprintf(
+		&query,
+		`SELECT
+			p.type, p.id,
+			ts_headline(p.config::REGCONFIG, p.title, websearch_to_tsquery(p.config::REGCONFIG, %[1]s), %[2]s),
+			ts_headline(p.config::REGCONFIG, p.description, websearch_to_tsquery(p.config::REGCONFIG, %[1]s), %[3]s),
+			p.picture, p.category, p.rank, p.config, p.created_at
+		FROM
+			(%[4]s) p
+		ORDER BY
+			p.rank DESC, p.type DESC, p.id DESC`,
+		q, arg(titleOptions), arg(snippetOptions), page.String(),
+	)
+
+	return query.String(), args
+}
+
+func (r *repository) Search(ctx context.Context, params *Params, cursor *cursorToken) ([]models.SearchResult, error) {
+	results := make([]models.SearchResult, 0)
+
+	query, args := search(params, cursor)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return results, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var result models.SearchResult
+
+		err := rows.Scan(
+			&result.Type,
+			&result.ID,
+			&result.Title,
+			&result.Snippet,
+			&result.Picture,
+			&result.Category,
+			&result.Rank,
+			&result.Config,
+			&result.CreatedAt,
+		)
+		if err != nil {
+			return results, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
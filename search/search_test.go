@@ -0,0 +1,37 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bagus2x/recovy/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchQuery(t *testing.T) {
+	cursor := cursorToken{Query: "sleep", Rank: 0.25, Type: TypeWebinar, ID: 7}
+
+	query, args := search(&Params{Query: "sleep", Types: []string{TypeArticle, TypePodcast}, Limit: 10}, &cursor)
+	assert.Contains(t, query, "FROM\n\t\t\t\tArticle x\n\t\t\tWHERE\n\t\t\t\tx.status = 'published' AND x.hidden_at = 0 AND (x.search_en @@ websearch_to_tsquery('english', $1) OR x.search_id @@ websearch_to_tsquery('indonesian', $1))")
+	assert.Contains(t, query, "GREATEST(ts_rank(x.search_en, websearch_to_tsquery('english', $1)), ts_rank(x.search_id, websearch_to_tsquery('indonesian', $1)))")
+	assert.Contains(t, query, "Podcast x")
+	assert.NotContains(t, query, "Discussion x")
+	assert.Contains(t, query, "(s.rank, s.type, s.id) < ($2, $3, $4) ORDER BY s.rank DESC, s.type DESC, s.id DESC LIMIT $5")
+	assert.Equal(t, []interface{}{"sleep", 0.25, TypeWebinar, int64(7), int64(10), titleOptions, snippetOptions}, args)
+
+	query, _ = search(&Params{Query: "tidur", Types: []string{TypeDiscussion}, Lang: LangIndonesian, Limit: 10}, nil)
+	assert.NotContains(t, query, "search_en")
+	assert.False(t, strings.Contains(query, "GREATEST"))
+}
+
+func TestHighlight(t *testing.T) {
+	assert.Equal(t, "Better <mark>sleep</mark> &lt;b&gt;tonight&lt;/b&gt;", highlight("Better "+startSel+"sleep"+stopSel+" <b>tonight</b>"))
+}
+
+func TestCursor(t *testing.T) {
+	token := newCursorToken("sleep", models.SearchResult{Type: TypeArticle, ID: 3, Rank: 0.0607927})
+
+	decoded, err := decodeCursor(encodeCursor(token))
+	assert.NoError(t, err)
+	assert.Equal(t, token, decoded)
+}
@@ -0,0 +1,106 @@
+package search
+
+import (
+	"context"
+	"html"
+	"strings"
+
+	"github.com/bagus2x/recovy/app"
+)
+
+const (
+	defaultLimit = 10
+	maxLimit     = 50
+)
+
+var allTypes = []string{TypeArticle, TypeDiscussion, TypeWebinar, TypePodcast}
+
+var marks = strings.NewReplacer(startSel, "<mark>", stopSel, "</mark>")
+
+type Service interface {
+	Search(ctx context.Context, params *Params) (SearchResp, error)
+}
+
+type service struct {
+	searchRepo Repository
+}
+
+func NewService(searchRepo Repository) Service {
+	return &service{
+		searchRepo: searchRepo,
+	}
+}
+
+func (s *service) Search(ctx context.Context, params *Params) (SearchResp, error) {
+	params.Query = strings.TrimSpace(params.Query)
+	err := params.Validate()
+	if err != nil {
+		return SearchResp{}, err
+	}
+
+	params.Types = dedupe(params.Types)
+	if len(params.Types) == 0 {
+		params.Types = allTypes
+	}
+
+	if params.Limit <= 0 {
+		params.Limit = defaultLimit
+	} else if params.Limit > maxLimit {
+		params.Limit = maxLimit
+	}
+
+	var cursor *cursorToken
+	if params.Cursor != "" {
+		token, err := decodeCursor(params.Cursor)
+		if err != nil || token.Query != params.Query {
+			return SearchResp{}, app.NewError(err, app.EBadRequest, "Invalid cursor")
+		}
+		cursor = &token
+	}
+
+	results, err := s.searchRepo.Search(ctx, params, cursor)
+	if err != nil {
+		return SearchResp{}, err
+	}
+
+	resp := SearchResp{
+		Results: make([]Result, 0, len(results)),
+	}
+	for _, result := range results {
+		resp.Results = append(resp.Results, Result{
+			Type:      result.Type,
+			ID:        result.ID,
+			Title:     highlight(result.Title),
+			Snippet:   highlight(result.Snippet),
+			Picture:   result.Picture,
+			Category:  result.Category,
+			Rank:      result.Rank,
+			CreatedAt: result.CreatedAt,
+		})
+	}
+
+	if int64(len(results)) == params.Limit {
+		resp.Cursor.Next = encodeCursor(newCursorToken(params.Query, results[len(results)-1]))
+	}
+
+	return resp, nil
+}
+
+// highlight escapes a headline and turns its selection markers, which
+// escaping leaves alone, into <mark> tags.
+func highlight(headline string) string {
+	return marks.Replace(html.EscapeString(headline))
+}
+
+func dedupe(types []string) []string {
+	seen := make(map[string]bool)
+	deduped := make([]string, 0, len(types))
+	for _, typ := range types {
+		if !seen[typ] {
+			seen[typ] = true
+			deduped = append(deduped, typ)
+		}
+	}
+
+	return deduped
+}
@@ -0,0 +1,59 @@
+package search
+
+import (
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	TypeArticle    = models.ContentArticle
+	TypeDiscussion = models.ContentDiscussion
+	TypeWebinar    = models.ContentWebinar
+	TypePodcast    = "podcast"
+
+	LangEnglish    = "en"
+	LangIndonesian = "id"
+)
+
+// Params searches the content types in Types, all of them when it is empty.
+// Query takes the web search syntax: quoted phrases, OR and -word. Lang
+// restricts the search to English or Indonesian; by default an item matches
+// in either.
+type Params struct {
+	Query  string   `validate:"required,lte=256"`
+	Types  []string `validate:"max=4,dive,oneof=article discussion webinar podcast"`
+	Lang   string   `validate:"omitempty,oneof=en id"`
+	Cursor string
+	Limit  int64
+}
+
+func (p *Params) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(p)
+
+	return app.ValidateAndTranslate(validate, err)
+}
+
+// Result has the matched words of its title and snippet wrapped in <mark>;
+// the rest of the text is HTML escaped.
+type Result struct {
+	Type      string  `json:"type"`
+	ID        int64   `json:"id"`
+	Title     string  `json:"title"`
+	Snippet   string  `json:"snippet"`
+	Picture   string  `json:"picture"`
+	Category  string  `json:"category,omitempty"`
+	Rank      float64 `json:"rank"`
+	CreatedAt int64   `json:"createdAt"`
+}
+
+// Cursor only pages forward, results being ranked.
+type Cursor struct {
+	Next string `json:"next"`
+}
+
+type SearchResp struct {
+	Cursor  Cursor   `json:"cursor"`
+	Results []Result `json:"results"`
+}
@@ -0,0 +1,519 @@
+package taxonomy
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+	"github.com/lib/pq"
+)
+
+type Repository interface {
+	Create(ctx context.Context, term *models.Term) error
+	FindByID(ctx context.Context, termID int64) (models.Term, error)
+	FindByKind(ctx context.Context, kind string) ([]models.TermCount, error)
+	FindByNames(ctx context.Context, kind string, names []string) ([]models.Term, error)
+	FindByContents(ctx context.Context, contentType string, contentIDs []int64) (map[int64][]models.Term, error)
+	Update(ctx context.Context, term *models.Term) error
+	Link(ctx context.Context, contentType string, contentID int64, termIDs []int64) error
+	Merge(ctx context.Context, from, into models.Term) error
+	Delete(ctx context.Context, term models.Term) error
+}
+
+// link is the table linking the items of a content type to their terms.
+type link struct {
+	table  string
+	column string
+}
+
+var links = map[string]link{
+	models.ContentArticle:    {table: "Article_Term", column: "article_id"},
+	models.ContentDiscussion: {table: "Discussion_Term", column: "discussion_id"},
+	models.ContentWebinar:    {table: "Webinar_Term", column: "webinar_id"},
+}
+
+// contents are the tables of each content type, for moving their primary
+// category when categories are merged.
+var contents = map[string]string{
+	models.ContentArticle:    "Article",
+	models.ContentDiscussion: "Discussion",
+	models.ContentWebinar:    "Webinar",
+}
+
+func linkOf(contentType string) (link, error) {
+	l, ok := links[contentType]
+	if !ok {
+		return link{}, fmt.Errorf("unknown content type %q", contentType)
+	}
+
+	return l, nil
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+var create = `
+	INSERT INTO
+		Term
+		(kind, slug, name, translations, created_at, updated_at)
+	VALUES
+		($1, $2, $3, $4, $5, $6)
+	RETURNING
+		id
+`
+
+func (r *repository) Create(ctx context.Context, term *models.Term) error {
+	translations, err := json.Marshal(translationsOf(term))
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRowContext(
+		ctx,
+		create,
+		term.Kind,
+		term.Slug,
+		term.Name,
+		translations,
+		term.CreatedAt,
+		term.UpdatedAt,
+	).Scan(&term.ID)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return app.NewError(err, app.Econflict)
+	}
+
+	return err
+}
+
+var findByID = `
+	SELECT
+		id, kind, slug, name, translations, created_at, updated_at
+	FROM
+		Term
+	WHERE
+		id = $1
+`
+
+func (r *repository) FindByID(ctx context.Context, termID int64) (models.Term, error) {
+	var (
+		term         models.Term
+		translations []byte
+	)
+
+	err := r.db.QueryRowContext(ctx, findByID, termID).Scan(
+		&term.ID,
+		&term.Kind,
+		&term.Slug,
+		&term.Name,
+		&translations,
+		&term.CreatedAt,
+		&term.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return models.Term{}, app.NewError(err, app.ENotFound)
+	} else if err != nil {
+		return models.Term{}, err
+	}
+
+	err = json.Unmarshal(translations, &term.Translations)
+
+	return term, err
+}
+
+var findByKind = `
+	SELECT
+		t.id, t.kind, t.slug, t.name, t.translations, t.created_at, t.updated_at,
+		(SELECT COUNT(*) FROM Article_Term at JOIN Article a ON a.id = at.article_id WHERE at.term_id = t.id AND a.status = 'published'),
+		(SELECT COUNT(*) FROM Discussion_Term dt WHERE dt.term_id = t.id),
+		(SELECT COUNT(*) FROM Webinar_Term wt WHERE wt.term_id = t.id)
+	FROM
+		Term t
+	WHERE
+		t.kind = $1
+	ORDER BY
+		t.name ASC
+`
+
+func (r *repository) FindByKind(ctx context.Context, kind string) ([]models.TermCount, error) {
+	counts := make([]models.TermCount, 0)
+
+	rows, err := r.db.QueryContext(ctx, findByKind, kind)
+	if err != nil {
+		return counts, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			count        models.TermCount
+			translations []byte
+		)
+
+		err := rows.Scan(
+			&count.Term.ID,
+			&count.Term.Kind,
+			&count.Term.Slug,
+			&count.Term.Name,
+			&translations,
+			&count.Term.CreatedAt,
+			&count.Term.UpdatedAt,
+			&count.Articles,
+			&count.Discussions,
+			&count.Webinars,
+		)
+		if err != nil {
+			return counts, err
+		}
+
+		err = json.Unmarshal(translations, &count.Term.Translations)
+		if err != nil {
+			return counts, err
+		}
+
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
+
+var findByNames = `
+	SELECT
+		t.id, t.kind, t.slug, t.name, t.translations, t.created_at, t.updated_at
+	FROM
+		Term t
+	WHERE
+		t.kind = $1
+	AND (
+		t.slug = ANY($2) OR LOWER(t.name) = ANY($3)
+		OR EXISTS (SELECT 1 FROM JSONB_EACH_TEXT(t.translations) tr WHERE LOWER(tr.value) = ANY($3))
+	)
+`
+
+// FindByNames finds the terms of the kind whose slug is one of the slugs of
+// names, or whose name or one of its translations is one of names, ignoring
+// case. names must be lowercased.
+func (r *repository) FindByNames(ctx context.Context, kind string, names []string) ([]models.Term, error) {
+	terms := make([]models.Term, 0)
+
+	slugs := make([]string, 0, len(names))
+	for _, name := range names {
+		slugs = append(slugs, Slugify(name))
+	}
+
+	rows, err := r.db.QueryContext(ctx, findByNames, kind, pq.Array(slugs), pq.Array(names))
+	if err != nil {
+		return terms, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			term         models.Term
+			translations []byte
+		)
+
+		err := rows.Scan(
+			&term.ID,
+			&term.Kind,
+			&term.Slug,
+			&term.Name,
+			&translations,
+			&term.CreatedAt,
+			&term.UpdatedAt,
+		)
+		if err != nil {
+			return terms, err
+		}
+
+		err = json.Unmarshal(translations, &term.Translations)
+		if err != nil {
+			return terms, err
+		}
+
+		terms = append(terms, term)
+	}
+
+	return terms, rows.Err()
+}
+
+var findByContents = `
+	SELECT
+		l.%[2]s, t.id, t.kind, t.slug, t.name, t.translations, t.created_at, t.updated_at
+	FROM
+		%[1]s l
+	JOIN
+		Term t
+	ON
+		t.id = l.term_id
+	WHERE
+		l.%[2]s = ANY($1)
+	ORDER BY
+		l.%[2]s, l.position ASC
+`
+
+// FindByContents returns the terms of each item, in order.
+func (r *repository) FindByContents(ctx context.Context, contentType string, contentIDs []int64) (map[int64][]models.Term, error) {
+	terms := make(map[int64][]models.Term)
+
+	l, err := linkOf(contentType)
+	if err != nil {
+		return terms, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(findByContents, l.table, l.column), pq.Array(contentIDs))
+	if err != nil {
+		return terms, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			contentID    int64
+			term         models.Term
+			translations []byte
+		)
+
+		err := rows.Scan(
+			&contentID,
+			&term.ID,
+			&term.Kind,
+			&term.Slug,
+			&term.Name,
+			&translations,
+			&term.CreatedAt,
+			&term.UpdatedAt,
+		)
+		if err != nil {
+			return terms, err
+		}
+
+		err = json.Unmarshal(translations, &term.Translations)
+		if err != nil {
+			return terms, err
+		}
+
+		terms[contentID] = append(terms[contentID], term)
+	}
+
+	return terms, rows.Err()
+}
+
+var update = `
+	UPDATE
+		Term
+	SET
+		slug = $2, name = $3, translations = $4, updated_at = $5
+	WHERE
+		id = $1
+`
+
+var lockSlug = `
+	SELECT
+		slug
+	FROM
+		Term
+	WHERE
+		id = $1
+	FOR UPDATE
+`
+
+var updateContentCategory = `
+	UPDATE
+		%s
+	SET
+		category = $2
+	WHERE
+		category = $1
+`
+
+// Update saves the term. Items whose primary category is the term follow a
+// change of its slug.
+func (r *repository) Update(ctx context.Context, term *models.Term) error {
+	translations, err := json.Marshal(translationsOf(term))
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var old string
+	err = tx.QueryRowContext(ctx, lockSlug, term.ID).Scan(&old)
+	if err == sql.ErrNoRows {
+		return app.NewError(err, app.ENotFound)
+	} else if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, update, term.ID, term.Slug, term.Name, translations, term.UpdatedAt)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return app.NewError(err, app.Econflict)
+	} else if err != nil {
+		return err
+	}
+
+	if term.Kind == models.TermCategory && old != term.Slug {
+		err = moveCategory(ctx, tx, old, term.Slug)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func moveCategory(ctx context.Context, tx *sql.Tx, from, to string) error {
+	for _, table := range contents {
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(updateContentCategory, table), from, to)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var deleteLinks = `
+	DELETE FROM
+		%s
+	WHERE
+		%s = $1
+`
+
+var createLink = `
+	INSERT INTO
+		%s
+		(%s, term_id, position)
+	VALUES
+		($1, $2, $3)
+	ON CONFLICT DO NOTHING
+`
+
+// Link replaces the terms of an item, keeping their order.
+func (r *repository) Link(ctx context.Context, contentType string, contentID int64, termIDs []int64) error {
+	l, err := linkOf(contentType)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(deleteLinks, l.table, l.column), contentID)
+	if err != nil {
+		return err
+	}
+
+	for i, termID := range termIDs {
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(createLink, l.table, l.column), contentID, termID, i)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+var mergeLinks = `
+	INSERT INTO
+		%[1]s
+		(%[2]s, term_id, position)
+	SELECT
+		%[2]s, $2, position
+	FROM
+		%[1]s
+	WHERE
+		term_id = $1
+	ON CONFLICT DO NOTHING
+`
+
+// Merge moves the items of one term to another and deletes it.
+func (r *repository) Merge(ctx context.Context, from, into models.Term) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, l := range links {
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(mergeLinks, l.table, l.column), from.ID, into.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if from.Kind == models.TermCategory {
+		err = moveCategory(ctx, tx, from.Slug, into.Slug)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, delete, from.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+var delete = `
+	DELETE FROM
+		Term
+	WHERE
+		id = $1
+`
+
+var countPrimary = `
+	SELECT
+		COUNT(*)
+	FROM
+		%s
+	WHERE
+		category = $1
+`
+
+// Delete removes the term from all items. A category that is still the
+// primary category of an item cannot be deleted, only merged into another.
+func (r *repository) Delete(ctx context.Context, term models.Term) error {
+	if term.Kind == models.TermCategory {
+		for _, table := range contents {
+			var count int64
+			err := r.db.QueryRowContext(ctx, fmt.Sprintf(countPrimary, table), term.Slug).Scan(&count)
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				return app.NewError(nil, app.Econflict)
+			}
+		}
+	}
+
+	_, err := r.db.ExecContext(ctx, delete, term.ID)
+
+	return err
+}
+
+func translationsOf(term *models.Term) map[string]string {
+	if term.Translations == nil {
+		return map[string]string{}
+	}
+
+	return term.Translations
+}
@@ -0,0 +1,382 @@
+package taxonomy
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/auth"
+	"github.com/bagus2x/recovy/models"
+)
+
+// maxTerms bounds the categories and the tags of an item.
+const maxTerms = 10
+
+type Service interface {
+	Get(ctx context.Context, kind, lang string) ([]TermCount, error)
+	Create(ctx context.Context, req *CreateTermReq) (Term, error)
+	Update(ctx context.Context, req *UpdateTermReq) (Term, error)
+	Merge(ctx context.Context, req *MergeTermReq) error
+	Delete(ctx context.Context, kind string, termID, userID int64) error
+	Resolve(ctx context.Context, categories, tags []string) ([]models.Term, error)
+	Link(ctx context.Context, contentType string, contentID int64, terms []models.Term) error
+	Terms(ctx context.Context, contentType string, contentIDs ...int64) (map[int64][]models.Term, error)
+}
+
+type service struct {
+	termRepo Repository
+	userRepo auth.Repository
+}
+
+func NewService(termRepo Repository, userRepo auth.Repository) Service {
+	return &service{
+		termRepo: termRepo,
+		userRepo: userRepo,
+	}
+}
+
+// Get lists the terms of a kind with the number of items of each type that
+// have them.
+func (s *service) Get(ctx context.Context, kind, lang string) ([]TermCount, error) {
+	counts, err := s.termRepo.FindByKind(ctx, kind)
+	if err != nil {
+		return make([]TermCount, 0), err
+	}
+
+	resp := make([]TermCount, 0, len(counts))
+	for _, count := range counts {
+		resp = append(resp, TermCount{
+			Term: Translate(count.Term, lang),
+			Counts: Counts{
+				Articles:    count.Articles,
+				Discussions: count.Discussions,
+				Webinars:    count.Webinars,
+			},
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *service) Create(ctx context.Context, req *CreateTermReq) (Term, error) {
+	err := req.Validate()
+	if err != nil {
+		return Term{}, err
+	}
+
+	err = s.authorize(ctx, req.UserID)
+	if err != nil {
+		return Term{}, err
+	}
+
+	slug := req.Slug
+	if slug == "" {
+		slug = req.Name
+	}
+
+	term := models.Term{
+		Kind:         req.Kind,
+		Slug:         Slugify(slug),
+		Name:         strings.TrimSpace(req.Name),
+		Translations: req.Translations,
+		CreatedAt:    time.Now().Unix(),
+		UpdatedAt:    time.Now().Unix(),
+	}
+
+	err = s.termRepo.Create(ctx, &term)
+	if app.ErrorCode(err) == app.Econflict {
+		return Term{}, app.NewError(err, app.Econflict, "Slug has already been used")
+	} else if err != nil {
+		return Term{}, err
+	}
+
+	return Translate(term, ""), nil
+}
+
+func (s *service) Update(ctx context.Context, req *UpdateTermReq) (Term, error) {
+	err := req.Validate()
+	if err != nil {
+		return Term{}, err
+	}
+
+	err = s.authorize(ctx, req.UserID)
+	if err != nil {
+		return Term{}, err
+	}
+
+	term, err := s.find(ctx, req.Kind, req.TermID)
+	if err != nil {
+		return Term{}, err
+	}
+
+	if req.Slug != nil {
+		term.Slug = Slugify(*req.Slug)
+	}
+	if req.Name != nil {
+		term.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Translations != nil {
+		term.Translations = req.Translations
+	}
+	term.UpdatedAt = time.Now().Unix()
+
+	err = s.termRepo.Update(ctx, &term)
+	if app.ErrorCode(err) == app.Econflict {
+		return Term{}, app.NewError(err, app.Econflict, "Slug has already been used")
+	} else if app.ErrorCode(err) == app.ENotFound {
+		return Term{}, app.NewError(err, app.ENotFound, "Term not found")
+	} else if err != nil {
+		return Term{}, err
+	}
+
+	return Translate(term, ""), nil
+}
+
+// Merge is how duplicates such as "anxiety" and "kecemasan" are cleaned up.
+func (s *service) Merge(ctx context.Context, req *MergeTermReq) error {
+	err := req.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = s.authorize(ctx, req.UserID)
+	if err != nil {
+		return err
+	}
+
+	from, err := s.find(ctx, req.Kind, req.TermID)
+	if err != nil {
+		return err
+	}
+
+	into, err := s.find(ctx, req.Kind, req.Into)
+	if err != nil {
+		return err
+	}
+
+	return s.termRepo.Merge(ctx, from, into)
+}
+
+func (s *service) Delete(ctx context.Context, kind string, termID, userID int64) error {
+	err := s.authorize(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	term, err := s.find(ctx, kind, termID)
+	if err != nil {
+		return err
+	}
+
+	err = s.termRepo.Delete(ctx, term)
+	if app.ErrorCode(err) == app.Econflict {
+		return app.NewError(err, app.Econflict, "Category is still in use, merge it into another one instead")
+	}
+
+	return err
+}
+
+func (s *service) find(ctx context.Context, kind string, termID int64) (models.Term, error) {
+	term, err := s.termRepo.FindByID(ctx, termID)
+	if app.ErrorCode(err) == app.ENotFound || (err == nil && term.Kind != kind) {
+		return models.Term{}, app.NewError(err, app.ENotFound, "Term not found")
+	}
+
+	return term, err
+}
+
+// authorize lets only editors manage the taxonomy.
+func (s *service) authorize(ctx context.Context, userID int64) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil && app.ErrorCode(err) != app.ENotFound {
+		return err
+	}
+	if !user.IsEditor() {
+		return app.NewError(nil, app.EForbidden, "Only editors can manage categories and tags")
+	}
+
+	return nil
+}
+
+// Resolve finds the terms an item is given, categories first, in order and
+// without duplicates. Categories are looked up by slug, name or translation
+// and must exist; tags that do not exist yet are created, and need a letter
+// or digit to make their slug of.
+func (s *service) Resolve(ctx context.Context, categories, tags []string) ([]models.Term, error) {
+	categories, tags = normalize(categories), normalize(tags)
+	if len(categories) == 0 {
+		return nil, app.NewError(nil, app.EBadRequest, "Category is required")
+	}
+	if len(categories) > maxTerms || len(tags) > maxTerms {
+		return nil, app.NewError(nil, app.EBadRequest, "An item can have at most 10 categories and 10 tags")
+	}
+	for _, name := range tags {
+		if slugOf(name) == "" {
+			return nil, app.NewError(nil, app.EBadRequest, "Tag "+name+" must have a letter or digit")
+		}
+	}
+
+	found, err := s.termRepo.FindByNames(ctx, models.TermCategory, categories)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := make([]models.Term, 0, len(categories)+len(tags))
+	for _, name := range categories {
+		term, ok := match(found, name)
+		if !ok {
+			return nil, app.NewError(nil, app.EBadRequest, "Unknown category "+name)
+		}
+		terms = appendTerm(terms, term)
+	}
+
+	if len(tags) == 0 {
+		return terms, nil
+	}
+
+	found, err = s.termRepo.FindByNames(ctx, models.TermTag, tags)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range tags {
+		term, ok := match(found, name)
+		if !ok {
+			term, err = s.createTag(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			found = append(found, term)
+		}
+		terms = appendTerm(terms, term)
+	}
+
+	return terms, nil
+}
+
+// createTag creates the tag, or finds it when someone else created it first.
+func (s *service) createTag(ctx context.Context, name string) (models.Term, error) {
+	term := models.Term{
+		Kind:      models.TermTag,
+		Slug:      Slugify(name),
+		Name:      name,
+		CreatedAt: time.Now().Unix(),
+		UpdatedAt: time.Now().Unix(),
+	}
+
+	err := s.termRepo.Create(ctx, &term)
+	if app.ErrorCode(err) != app.Econflict {
+		return term, err
+	}
+
+	found, findErr := s.termRepo.FindByNames(ctx, models.TermTag, []string{name})
+	if findErr != nil {
+		return models.Term{}, findErr
+	}
+	if existing, ok := match(found, name); ok {
+		return existing, nil
+	}
+
+	return models.Term{}, app.NewError(err, app.Econflict, "Tag "+name+" has been changed by someone else, try again")
+}
+
+func (s *service) Link(ctx context.Context, contentType string, contentID int64, terms []models.Term) error {
+	termIDs := make([]int64, 0, len(terms))
+	for _, term := range terms {
+		termIDs = append(termIDs, term.ID)
+	}
+
+	return s.termRepo.Link(ctx, contentType, contentID, termIDs)
+}
+
+func (s *service) Terms(ctx context.Context, contentType string, contentIDs ...int64) (map[int64][]models.Term, error) {
+	if len(contentIDs) == 0 {
+		return make(map[int64][]models.Term), nil
+	}
+
+	return s.termRepo.FindByContents(ctx, contentType, contentIDs)
+}
+
+// normalize lowercases and trims the names, dropping empty ones.
+func normalize(names []string) []string {
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" {
+			normalized = append(normalized, name)
+		}
+	}
+
+	return normalized
+}
+
+// match finds the term a lowercased name refers to, preferring a match of
+// the slug over one of the name or a translation.
+func match(terms []models.Term, name string) (models.Term, bool) {
+	slug := Slugify(name)
+	for _, term := range terms {
+		if term.Slug == slug {
+			return term, true
+		}
+	}
+
+	for _, term := range terms {
+		if strings.ToLower(term.Name) == name {
+			return term, true
+		}
+		for _, translation := range term.Translations {
+			if strings.ToLower(translation) == name {
+				return term, true
+			}
+		}
+	}
+
+	return models.Term{}, false
+}
+
+func appendTerm(terms []models.Term, term models.Term) []models.Term {
+	for _, t := range terms {
+		if t.ID == term.ID {
+			return terms
+		}
+	}
+
+	return append(terms, term)
+}
+
+// Translate converts the term for a response, naming it in the language when
+// it has a translation in it.
+func Translate(term models.Term, lang string) Term {
+	name := term.Name
+	if translation, ok := term.Translations[lang]; ok && lang != "" {
+		name = translation
+	}
+
+	translations := term.Translations
+	if translations == nil {
+		translations = map[string]string{}
+	}
+
+	return Term{
+		ID:           term.ID,
+		Slug:         term.Slug,
+		Name:         name,
+		Translations: translations,
+	}
+}
+
+// Split converts the terms of an item for a response, separating its
+// categories from its tags.
+func Split(terms []models.Term) ([]Term, []Term) {
+	categories, tags := make([]Term, 0), make([]Term, 0)
+	for _, term := range terms {
+		if term.Kind == models.TermCategory {
+			categories = append(categories, Translate(term, ""))
+		} else {
+			tags = append(tags, Translate(term, ""))
+		}
+	}
+
+	return categories, tags
+}
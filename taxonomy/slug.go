@@ -0,0 +1,66 @@
+package taxonomy
+
+import (
+	"strings"
+
+	"github.com/bagus2x/recovy/models"
+)
+
+// Slugify lowercases the name and joins its runs of letters and digits with
+// dashes, the same way the taxonomy migration normalized the old categories.
+func Slugify(name string) string {
+	slug := slugOf(name)
+	if slug == "" {
+		return "uncategorized"
+	}
+
+	return slug
+}
+
+// slugOf is Slugify without the fallback, empty when the name has no letters
+// or digits.
+func slugOf(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+
+	return b.String()
+}
+
+// Revise returns the categories and tags an item has after an edit. Those
+// given replace the current ones, and category, when set, becomes the
+// primary category. The current primary category is kept first otherwise.
+func Revise(current []models.Term, primary string, category *string, categories, tags []string) ([]string, []string) {
+	if categories == nil {
+		categories = make([]string, 0)
+		for _, term := range current {
+			if term.Kind == models.TermCategory {
+				categories = append(categories, term.Slug)
+			}
+		}
+	}
+	if tags == nil {
+		tags = make([]string, 0)
+		for _, term := range current {
+			if term.Kind == models.TermTag {
+				tags = append(tags, term.Slug)
+			}
+		}
+	}
+
+	if category != nil {
+		primary = *category
+	}
+
+	return append([]string{primary}, categories...), tags
+}
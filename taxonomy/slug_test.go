@@ -0,0 +1,34 @@
+package taxonomy
+
+import (
+	"testing"
+
+	"github.com/bagus2x/recovy/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlugify(t *testing.T) {
+	assert.Equal(t, "anxiety", Slugify(" Anxiety "))
+	assert.Equal(t, "self-harm", Slugify("Self  Harm"))
+	assert.Equal(t, "covid-19-recovery", Slugify("--COVID-19 & recovery!"))
+	assert.Equal(t, "uncategorized", Slugify("  ¿? "))
+	assert.Equal(t, "", slugOf("  ¿? "))
+	assert.Equal(t, "self-harm", slugOf("Self  Harm"))
+}
+
+func TestRevise(t *testing.T) {
+	current := []models.Term{
+		{Kind: models.TermCategory, Slug: "anxiety"},
+		{Kind: models.TermCategory, Slug: "sleep"},
+		{Kind: models.TermTag, Slug: "tips"},
+	}
+
+	categories, tags := Revise(current, "anxiety", nil, nil, []string{"breathing"})
+	assert.Equal(t, []string{"anxiety", "anxiety", "sleep"}, categories)
+	assert.Equal(t, []string{"breathing"}, tags)
+
+	category := "depression"
+	categories, tags = Revise(current, "anxiety", &category, []string{}, nil)
+	assert.Equal(t, []string{"depression"}, categories)
+	assert.Equal(t, []string{"tips"}, tags)
+}
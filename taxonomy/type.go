@@ -0,0 +1,75 @@
+package taxonomy
+
+import (
+	"github.com/bagus2x/recovy/app"
+	"github.com/go-playground/validator/v10"
+)
+
+// Term is a category or tag as shown with an item. Name is translated when a
+// language was asked for and the term has a translation in it.
+type Term struct {
+	ID           int64             `json:"id"`
+	Slug         string            `json:"slug"`
+	Name         string            `json:"name"`
+	Translations map[string]string `json:"translations"`
+}
+
+type Counts struct {
+	Articles    int64 `json:"articles"`
+	Discussions int64 `json:"discussions"`
+	Webinars    int64 `json:"webinars"`
+}
+
+type TermCount struct {
+	Term
+	Counts Counts `json:"counts"`
+}
+
+// CreateTermReq derives the slug from the name when it is not given.
+type CreateTermReq struct {
+	UserID       int64             `json:"userID" validate:"required,gt=0"`
+	Kind         string            `json:"kind" validate:"required,oneof=category tag"`
+	Slug         string            `json:"slug" validate:"omitempty,lte=128"`
+	Name         string            `json:"name" validate:"required,lte=128"`
+	Translations map[string]string `json:"translations" validate:"max=20,dive,keys,min=2,max=16,endkeys,required,lte=128"`
+}
+
+func (r *CreateTermReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+
+	return app.ValidateAndTranslate(validate, err)
+}
+
+// UpdateTermReq edits the fields that are set. Translations replaces all the
+// translations of the term.
+type UpdateTermReq struct {
+	UserID       int64             `json:"userID" validate:"required,gt=0"`
+	TermID       int64             `json:"termID" validate:"required,gt=0"`
+	Kind         string            `json:"kind" validate:"required,oneof=category tag"`
+	Slug         *string           `json:"slug" validate:"omitempty,min=1,lte=128"`
+	Name         *string           `json:"name" validate:"omitempty,min=1,lte=128"`
+	Translations map[string]string `json:"translations" validate:"omitempty,max=20,dive,keys,min=2,max=16,endkeys,required,lte=128"`
+}
+
+func (r *UpdateTermReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+
+	return app.ValidateAndTranslate(validate, err)
+}
+
+// MergeTermReq moves the items of term TermID to term Into and deletes it.
+type MergeTermReq struct {
+	UserID int64  `json:"userID" validate:"required,gt=0"`
+	TermID int64  `json:"termID" validate:"required,gt=0"`
+	Kind   string `json:"kind" validate:"required,oneof=category tag"`
+	Into   int64  `json:"into" validate:"required,gt=0,nefield=TermID"`
+}
+
+func (r *MergeTermReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+
+	return app.ValidateAndTranslate(validate, err)
+}
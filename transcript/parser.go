@@ -0,0 +1,194 @@
+package transcript
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Cue struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+var (
+	timingLine = regexp.MustCompile(`^((?:\d+:)?\d{2}:\d{2}[.,]\d{3})\s+-->\s+((?:\d+:)?\d{2}:\d{2}[.,]\d{3})(?:\s+.*)?$`)
+	markupTag  = regexp.MustCompile(`<[^>]*>`)
+)
+
+// ParseVTT parses a WebVTT document into cues. NOTE, STYLE and REGION blocks
+// are skipped, cue settings after the timing are ignored.
+func ParseVTT(src string) ([]Cue, error) {
+	blocks := splitBlocks(src)
+	if len(blocks) == 0 || !strings.HasPrefix(blocks[0][0], "WEBVTT") {
+		return nil, fmt.Errorf("missing WEBVTT header")
+	}
+
+	cues := make([]Cue, 0)
+	for _, block := range blocks[1:] {
+		head := block[0]
+		if strings.HasPrefix(head, "NOTE") || head == "STYLE" || head == "REGION" {
+			continue
+		}
+
+		cue, err := parseCue(block, false)
+		if err != nil {
+			return nil, err
+		}
+		cues = append(cues, cue)
+	}
+
+	return checkCues(cues)
+}
+
+// ParseSRT parses a SubRip document into cues.
+func ParseSRT(src string) ([]Cue, error) {
+	cues := make([]Cue, 0)
+	for _, block := range splitBlocks(src) {
+		cue, err := parseCue(block, true)
+		if err != nil {
+			return nil, err
+		}
+		cues = append(cues, cue)
+	}
+
+	return checkCues(cues)
+}
+
+// RenderVTT writes cues back out as a normalized WebVTT document.
+func RenderVTT(cues []Cue) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n")
+	for _, cue := range cues {
+		fmt.Fprintf(&b, "\n%s --> %s\n%s\n", formatTimestamp(cue.Start), formatTimestamp(cue.End), cue.Text)
+	}
+
+	return b.String()
+}
+
+// PlainText joins the cue texts with markup such as <v Speaker> removed.
+func PlainText(cues []Cue) string {
+	lines := make([]string, 0, len(cues))
+	for _, cue := range cues {
+		text := strings.TrimSpace(markupTag.ReplaceAllString(cue.Text, ""))
+		if text != "" {
+			lines = append(lines, strings.Join(strings.Fields(text), " "))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func splitBlocks(src string) [][]string {
+	src = strings.TrimPrefix(src, "\ufeff")
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+
+	blocks := make([][]string, 0)
+	block := make([]string, 0)
+	scanner := bufio.NewScanner(strings.NewReader(src))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" {
+			if len(block) > 0 {
+				blocks = append(blocks, block)
+				block = make([]string, 0)
+			}
+			continue
+		}
+		block = append(block, line)
+	}
+	if len(block) > 0 {
+		blocks = append(blocks, block)
+	}
+
+	return blocks
+}
+
+func parseCue(block []string, srt bool) (Cue, error) {
+	i := 0
+	if !strings.Contains(block[0], "-->") {
+		if srt {
+			if _, err := strconv.Atoi(strings.TrimSpace(block[0])); err != nil {
+				return Cue{}, fmt.Errorf("invalid cue index %q", block[0])
+			}
+		}
+		i++
+	}
+	if i >= len(block) {
+		return Cue{}, fmt.Errorf("cue %q has no timing", block[0])
+	}
+
+	m := timingLine.FindStringSubmatch(block[i])
+	if m == nil {
+		return Cue{}, fmt.Errorf("invalid cue timing %q", block[i])
+	}
+
+	start, err := parseTimestamp(m[1])
+	if err != nil {
+		return Cue{}, err
+	}
+	end, err := parseTimestamp(m[2])
+	if err != nil {
+		return Cue{}, err
+	}
+	if end <= start {
+		return Cue{}, fmt.Errorf("cue %q ends before it starts", block[i])
+	}
+
+	text := strings.Join(block[i+1:], "\n")
+	if strings.TrimSpace(text) == "" {
+		return Cue{}, fmt.Errorf("cue %q has no text", block[i])
+	}
+
+	return Cue{Start: start, End: end, Text: text}, nil
+}
+
+func checkCues(cues []Cue) ([]Cue, error) {
+	if len(cues) == 0 {
+		return nil, fmt.Errorf("transcript has no cues")
+	}
+	for i := 1; i < len(cues); i++ {
+		if cues[i].Start < cues[i-1].Start {
+			return nil, fmt.Errorf("cue at %s is out of order", formatTimestamp(cues[i].Start))
+		}
+	}
+
+	return cues, nil
+}
+
+func parseTimestamp(s string) (time.Duration, error) {
+	s = strings.Replace(s, ",", ".", 1)
+	parts := strings.Split(s, ":")
+	if len(parts) == 2 {
+		parts = append([]string{"0"}, parts...)
+	}
+
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil || minutes > 59 {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	seconds, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil || seconds >= 60 {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+
+	d := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute
+	d += time.Duration(seconds*1000+0.5) * time.Millisecond
+
+	return d, nil
+}
+
+func formatTimestamp(d time.Duration) string {
+	ms := d.Milliseconds()
+
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
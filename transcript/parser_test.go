@@ -0,0 +1,48 @@
+package transcript
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVTT(t *testing.T) {
+	src := "WEBVTT - episode 1\n\nNOTE recorded live\n\nintro\n00:01.000 --> 00:04.500 align:start\n<v Dina>Welcome back</v>\n\n01:00:00.000 --> 01:00:02.250\nSee you\nnext week\n"
+
+	cues, err := ParseVTT(src)
+	assert.NoError(t, err)
+	assert.Len(t, cues, 2)
+	assert.Equal(t, time.Second, cues[0].Start)
+	assert.Equal(t, 4500*time.Millisecond, cues[0].End)
+	assert.Equal(t, time.Hour, cues[1].Start)
+	assert.Equal(t, "Welcome back\nSee you next week", PlainText(cues))
+	assert.Equal(t, "WEBVTT\n\n00:00:01.000 --> 00:00:04.500\n<v Dina>Welcome back</v>\n\n01:00:00.000 --> 01:00:02.250\nSee you\nnext week\n", RenderVTT(cues))
+}
+
+func TestParseSRT(t *testing.T) {
+	src := "1\r\n00:00:01,000 --> 00:00:02,000\r\nHalo\r\n\r\n2\r\n00:00:02,500 --> 00:00:03,000\r\nApa kabar?\r\n"
+
+	cues, err := ParseSRT(src)
+	assert.NoError(t, err)
+	assert.Len(t, cues, 2)
+	assert.Equal(t, 2500*time.Millisecond, cues[1].Start)
+	assert.Equal(t, "Halo\nApa kabar?", PlainText(cues))
+}
+
+func TestParseInvalid(t *testing.T) {
+	invalid := map[string]func(string) ([]Cue, error){
+		"00:01.000 --> 00:02.000\nno header":                                 ParseVTT,
+		"WEBVTT\n\n00:03.000 --> 00:02.000\nbackwards":                       ParseVTT,
+		"WEBVTT\n\n00:05.000 --> 00:06.000\nb\n\n00:01.000 --> 00:02.000\na": ParseVTT,
+		"WEBVTT\n": ParseVTT,
+		"x\n00:00:01,000 --> 00:00:02,000\nbad index":   ParseSRT,
+		"1\n00:00:01,000 --> 00:00:02,000\n":            ParseSRT,
+		"1\n00:00:61,000 --> 00:00:62,000\nbad seconds": ParseSRT,
+	}
+
+	for src, parse := range invalid {
+		_, err := parse(src)
+		assert.Error(t, err, src)
+	}
+}
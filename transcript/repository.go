@@ -0,0 +1,156 @@
+package transcript
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+)
+
+type Repository interface {
+	SaveTranscript(ctx context.Context, transcript *models.Transcript) error
+	FindTranscript(ctx context.Context, podcastID int64) (models.Transcript, error)
+	SaveChapters(ctx context.Context, chapters *models.PodcastChapters) error
+	FindChapters(ctx context.Context, podcastID int64) (models.PodcastChapters, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+var saveTranscript = `
+	INSERT INTO
+		Podcast_Transcript
+		(podcast_id, vtt, plain_text, created_at, updated_at)
+	VALUES
+		($1, $2, $3, $4, $5)
+	ON CONFLICT (podcast_id) DO UPDATE SET
+		vtt = EXCLUDED.vtt, plain_text = EXCLUDED.plain_text, updated_at = EXCLUDED.updated_at
+	RETURNING
+		created_at
+`
+
+func (r *repository) SaveTranscript(ctx context.Context, transcript *models.Transcript) error {
+	err := r.db.QueryRowContext(
+		ctx,
+		saveTranscript,
+		transcript.PodcastID,
+		transcript.VTT,
+		transcript.PlainText,
+		transcript.CreatedAt,
+		transcript.UpdatedAt,
+	).Scan(&transcript.CreatedAt)
+
+	return err
+}
+
+var findTranscript = `
+	SELECT
+		tr.podcast_id, tr.vtt, tr.plain_text, tr.created_at, tr.updated_at
+	FROM
+		Podcast_Transcript tr
+	JOIN
+		Podcast pt
+	ON
+		tr.podcast_id = pt.id
+	WHERE
+		tr.podcast_id = $1 AND pt.hidden_at = 0
+`
+
+// FindTranscript finds the transcript of a podcast that is not hidden.
+
+func (r *repository) FindTranscript(ctx context.Context, podcastID int64) (models.Transcript, error) {
+	var transcript models.Transcript
+
+	err := r.db.QueryRowContext(ctx, findTranscript, podcastID).Scan(
+		&transcript.PodcastID,
+		&transcript.VTT,
+		&transcript.PlainText,
+		&transcript.CreatedAt,
+		&transcript.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return models.Transcript{}, app.NewError(err, app.ENotFound)
+	} else if err != nil {
+		return models.Transcript{}, err
+	}
+
+	return transcript, nil
+}
+
+var saveChapters = `
+	INSERT INTO
+		Podcast_Chapter
+		(podcast_id, chapters, created_at, updated_at)
+	VALUES
+		($1, $2, $3, $4)
+	ON CONFLICT (podcast_id) DO UPDATE SET
+		chapters = EXCLUDED.chapters, updated_at = EXCLUDED.updated_at
+	RETURNING
+		created_at
+`
+
+func (r *repository) SaveChapters(ctx context.Context, chapters *models.PodcastChapters) error {
+	data, err := json.Marshal(chapters.Chapters)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRowContext(
+		ctx,
+		saveChapters,
+		chapters.PodcastID,
+		data,
+		chapters.CreatedAt,
+		chapters.UpdatedAt,
+	).Scan(&chapters.CreatedAt)
+
+	return err
+}
+
+var findChapters = `
+	SELECT
+		pc.podcast_id, pc.chapters, pc.created_at, pc.updated_at
+	FROM
+		Podcast_Chapter pc
+	JOIN
+		Podcast pt
+	ON
+		pc.podcast_id = pt.id
+	WHERE
+		pc.podcast_id = $1 AND pt.hidden_at = 0
+`
+
+// FindChapters finds the chapters of a podcast that is not hidden.
+
+func (r *repository) FindChapters(ctx context.Context, podcastID int64) (models.PodcastChapters, error) {
+	var chapters models.PodcastChapters
+	var data []byte
+
+	err := r.db.QueryRowContext(ctx, findChapters, podcastID).Scan(
+		&chapters.PodcastID,
+		&data,
+		&chapters.CreatedAt,
+		&chapters.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return models.PodcastChapters{}, app.NewError(err, app.ENotFound)
+	} else if err != nil {
+		return models.PodcastChapters{}, err
+	}
+
+	err = json.Unmarshal(data, &chapters.Chapters)
+	if err != nil {
+		return models.PodcastChapters{}, err
+	}
+
+	return chapters, nil
+}
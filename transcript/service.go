@@ -0,0 +1,160 @@
+package transcript
+
+import (
+	"context"
+	"time"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/podcast"
+)
+
+type Service interface {
+	UploadTranscript(ctx context.Context, req *UploadTranscriptReq) (UploadTranscriptResp, error)
+	GetTranscript(ctx context.Context, podcastID int64, format string) (string, error)
+	UploadChapters(ctx context.Context, req *UploadChaptersReq) (GetChaptersResp, error)
+	GetChapters(ctx context.Context, podcastID int64) (GetChaptersResp, error)
+}
+
+type service struct {
+	transcriptRepo Repository
+	podcastRepo    podcast.Repository
+}
+
+func NewService(transcriptRepo Repository, podcastRepo podcast.Repository) Service {
+	return &service{
+		transcriptRepo: transcriptRepo,
+		podcastRepo:    podcastRepo,
+	}
+}
+
+func (s *service) UploadTranscript(ctx context.Context, req *UploadTranscriptReq) (UploadTranscriptResp, error) {
+	err := req.Validate()
+	if err != nil {
+		return UploadTranscriptResp{}, err
+	}
+
+	err = s.checkAuthor(ctx, req.PodcastID, req.AuthorID)
+	if err != nil {
+		return UploadTranscriptResp{}, err
+	}
+
+	var cues []Cue
+	if req.Format == FormatSRT {
+		cues, err = ParseSRT(req.Content)
+	} else {
+		cues, err = ParseVTT(req.Content)
+	}
+	if err != nil {
+		return UploadTranscriptResp{}, app.NewError(nil, app.EBadRequest, "Invalid transcript: "+err.Error())
+	}
+
+	transcript := models.Transcript{
+		PodcastID: req.PodcastID,
+		VTT:       RenderVTT(cues),
+		PlainText: PlainText(cues),
+		CreatedAt: time.Now().Unix(),
+		UpdatedAt: time.Now().Unix(),
+	}
+
+	err = s.transcriptRepo.SaveTranscript(ctx, &transcript)
+	if err != nil {
+		return UploadTranscriptResp{}, err
+	}
+
+	res := UploadTranscriptResp{
+		PodcastID: transcript.PodcastID,
+		Cues:      len(cues),
+		CreatedAt: transcript.CreatedAt,
+		UpdatedAt: transcript.UpdatedAt,
+	}
+
+	return res, nil
+}
+
+func (s *service) GetTranscript(ctx context.Context, podcastID int64, format string) (string, error) {
+	if format != FormatVTT && format != FormatText {
+		return "", app.NewError(nil, app.EBadRequest, "Format must be one of [vtt text]")
+	}
+
+	transcript, err := s.transcriptRepo.FindTranscript(ctx, podcastID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return "", app.NewError(err, app.ENotFound, "Transcript not found")
+	} else if err != nil {
+		return "", err
+	}
+
+	if format == FormatText {
+		return transcript.PlainText, nil
+	}
+
+	return transcript.VTT, nil
+}
+
+func (s *service) UploadChapters(ctx context.Context, req *UploadChaptersReq) (GetChaptersResp, error) {
+	err := req.Validate()
+	if err != nil {
+		return GetChaptersResp{}, err
+	}
+
+	err = s.checkAuthor(ctx, req.PodcastID, req.AuthorID)
+	if err != nil {
+		return GetChaptersResp{}, err
+	}
+
+	chapters := models.PodcastChapters{
+		PodcastID: req.PodcastID,
+		Chapters:  make([]models.Chapter, 0, len(req.Chapters)),
+		CreatedAt: time.Now().Unix(),
+		UpdatedAt: time.Now().Unix(),
+	}
+	for _, chapter := range req.Chapters {
+		chapters.Chapters = append(chapters.Chapters, models.Chapter(chapter))
+	}
+
+	err = s.transcriptRepo.SaveChapters(ctx, &chapters)
+	if err != nil {
+		return GetChaptersResp{}, err
+	}
+
+	return toChaptersResp(chapters), nil
+}
+
+func (s *service) GetChapters(ctx context.Context, podcastID int64) (GetChaptersResp, error) {
+	chapters, err := s.transcriptRepo.FindChapters(ctx, podcastID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return GetChaptersResp{}, app.NewError(err, app.ENotFound, "Chapters not found")
+	} else if err != nil {
+		return GetChaptersResp{}, err
+	}
+
+	return toChaptersResp(chapters), nil
+}
+
+func (s *service) checkAuthor(ctx context.Context, podcastID, authorID int64) error {
+	podcast, err := s.podcastRepo.FindByID(ctx, podcastID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return app.NewError(err, app.ENotFound, "Podcast not found")
+	} else if err != nil {
+		return err
+	}
+
+	if podcast.Author.ID != authorID {
+		return app.NewError(nil, app.EForbidden, "Forbidden access, podcast not found")
+	}
+
+	return nil
+}
+
+func toChaptersResp(chapters models.PodcastChapters) GetChaptersResp {
+	res := GetChaptersResp{
+		PodcastID: chapters.PodcastID,
+		Chapters:  make([]Chapter, 0, len(chapters.Chapters)),
+		UpdatedAt: chapters.UpdatedAt,
+	}
+	for _, chapter := range chapters.Chapters {
+		res.Chapters = append(res.Chapters, Chapter(chapter))
+	}
+
+	return res
+}
@@ -0,0 +1,67 @@
+package transcript
+
+import (
+	"github.com/bagus2x/recovy/app"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	FormatVTT  = "vtt"
+	FormatSRT  = "srt"
+	FormatText = "text"
+)
+
+type UploadTranscriptReq struct {
+	PodcastID int64  `json:"podcastID" validate:"required,gt=0"`
+	AuthorID  int64  `json:"authorID" validate:"required,gt=0"`
+	Format    string `json:"format" validate:"required,oneof=vtt srt"`
+	Content   string `json:"content" validate:"required,lte=2097152"`
+}
+
+func (r *UploadTranscriptReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+
+	return app.ValidateAndTranslate(validate, err)
+}
+
+type UploadTranscriptResp struct {
+	PodcastID int64 `json:"podcastID"`
+	Cues      int   `json:"cues"`
+	CreatedAt int64 `json:"createdAt"`
+	UpdatedAt int64 `json:"updatedAt"`
+}
+
+type Chapter struct {
+	Start float64 `json:"start" validate:"gte=0"`
+	Title string  `json:"title" validate:"required,lte=255"`
+	URL   string  `json:"url,omitempty" validate:"omitempty,url,lte=512"`
+}
+
+type UploadChaptersReq struct {
+	PodcastID int64     `json:"podcastID" validate:"required,gt=0"`
+	AuthorID  int64     `json:"authorID" validate:"required,gt=0"`
+	Chapters  []Chapter `json:"chapters" validate:"required,min=1,max=500,dive"`
+}
+
+func (r *UploadChaptersReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+	if err != nil {
+		return app.ValidateAndTranslate(validate, err)
+	}
+
+	for i := 1; i < len(r.Chapters); i++ {
+		if r.Chapters[i].Start <= r.Chapters[i-1].Start {
+			return app.NewError(nil, app.EBadRequest, "Chapters must be sorted by start time without duplicates")
+		}
+	}
+
+	return nil
+}
+
+type GetChaptersResp struct {
+	PodcastID int64     `json:"podcastID"`
+	Chapters  []Chapter `json:"chapters"`
+	UpdatedAt int64     `json:"updatedAt"`
+}
@@ -0,0 +1,241 @@
+package webinar
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+)
+
+// checkInGrace is how long before the start and after the end of an
+// occurrence attendees can check in.
+const checkInGrace = 30 * 60
+
+// checkInAlphabet leaves out characters that are easily confused when read
+// out loud or off a slide.
+const checkInAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const checkInCodeLength = 6
+
+// GetCheckIn returns the code and QR token attendees check in with to the
+// running or next occurrence, generating them the first time they are asked
+// for it, so the ones of an earlier occurrence stop working.
+func (s *service) GetCheckIn(ctx context.Context, webinarID, userID int64) (CheckIn, error) {
+	webinar, err := s.webinarRepo.FindByID(ctx, webinarID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return CheckIn{}, app.NewError(err, app.ENotFound, "Webinar not found")
+	} else if err != nil {
+		return CheckIn{}, err
+	}
+
+	if !webinar.IsHost(userID) {
+		return CheckIn{}, app.NewError(nil, app.EForbidden, "Forbidden access, webinar not found")
+	}
+
+	o, ok := checkInOccurrence(webinar, time.Now().Unix())
+	if !ok {
+		return CheckIn{}, app.NewError(nil, app.EBadRequest, "Webinar has no upcoming occurrence")
+	}
+
+	if webinar.CheckInCode == "" || webinar.CheckInOccurrence != o.RecurrenceID {
+		code, err := checkInCode()
+		if err != nil {
+			return CheckIn{}, err
+		}
+		token, err := checkInToken()
+		if err != nil {
+			return CheckIn{}, err
+		}
+
+		err = s.webinarRepo.SaveCheckIn(ctx, webinar.ID, o.RecurrenceID, code, token)
+		if err != nil {
+			return CheckIn{}, err
+		}
+
+		// Another organizer may have saved theirs first.
+		webinar, err = s.webinarRepo.FindByID(ctx, webinarID)
+		if err != nil {
+			return CheckIn{}, err
+		}
+	}
+
+	return CheckIn{
+		WebinarID: webinar.ID,
+		Code:      webinar.CheckInCode,
+		Token:     webinar.CheckInToken,
+	}, nil
+}
+
+// CheckIn records the user's attendance of the occurrence that is running.
+// Checking in again is harmless.
+func (s *service) CheckIn(ctx context.Context, req *CheckInReq) (Attendee, error) {
+	err := req.Validate()
+	if err != nil {
+		return Attendee{}, err
+	}
+
+	webinar, err := s.webinarRepo.FindByID(ctx, req.WebinarID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return Attendee{}, app.NewError(err, app.ENotFound, "Webinar not found")
+	} else if err != nil {
+		return Attendee{}, err
+	}
+
+	o, running := runningOccurrence(webinar, time.Now().Unix())
+	if !validCheckIn(webinar, o.RecurrenceID, req.Code, req.Token) {
+		return Attendee{}, app.NewError(nil, app.EBadRequest, "Invalid check-in code")
+	}
+	if webinar.CancelledAt > 0 {
+		return Attendee{}, app.NewError(nil, app.EBadRequest, "Webinar has been cancelled")
+	}
+
+	if !running {
+		return Attendee{}, app.NewError(nil, app.EBadRequest, "Check-in is only open around the webinar")
+	}
+
+	attendance := models.WebinarAttendance{
+		WebinarID:   webinar.ID,
+		Occurrence:  o.RecurrenceID,
+		User:        models.User{ID: req.UserID},
+		CheckedInAt: time.Now().Unix(),
+	}
+
+	err = s.attendanceRepo.CheckIn(ctx, &attendance)
+	if err != nil {
+		return Attendee{}, err
+	}
+
+	return Attendee{
+		ID:          attendance.User.ID,
+		Occurrence:  attendance.Occurrence,
+		CheckedInAt: attendance.CheckedInAt,
+	}, nil
+}
+
+// GetAttendance lists who checked in, by occurrence.
+func (s *service) GetAttendance(ctx context.Context, webinarID, userID int64) ([]Attendee, error) {
+	webinar, err := s.webinarRepo.FindByID(ctx, webinarID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return make([]Attendee, 0), app.NewError(err, app.ENotFound, "Webinar not found")
+	} else if err != nil {
+		return make([]Attendee, 0), err
+	}
+
+	if !webinar.IsHost(userID) {
+		return make([]Attendee, 0), app.NewError(nil, app.EForbidden, "Forbidden access, webinar not found")
+	}
+
+	attendances, err := s.attendanceRepo.FindByWebinarID(ctx, webinarID)
+	if err != nil {
+		return make([]Attendee, 0), err
+	}
+
+	resp := make([]Attendee, 0, len(attendances))
+	for _, attendance := range attendances {
+		resp = append(resp, Attendee{
+			ID:          attendance.User.ID,
+			Name:        attendance.User.Name,
+			Picture:     attendance.User.Picture,
+			Occurrence:  attendance.Occurrence,
+			CheckedInAt: attendance.CheckedInAt,
+		})
+	}
+
+	return resp, nil
+}
+
+// GetCertificate renders the participation certificate of an attendee as a
+// PDF. Attendees get their own; organizers get anyone's on the attendance
+// list.
+func (s *service) GetCertificate(ctx context.Context, webinarID, occurrence, attendeeID, userID int64) ([]byte, error) {
+	webinar, err := s.webinarRepo.FindByID(ctx, webinarID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return nil, app.NewError(err, app.ENotFound, "Webinar not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	if attendeeID != userID && !webinar.IsHost(userID) {
+		return nil, app.NewError(nil, app.EForbidden, "Forbidden access, webinar not found")
+	}
+
+	attendance, err := s.attendanceRepo.FindByWebinarIDAndUserID(ctx, webinarID, occurrence, attendeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	startAt := webinar.StartAt
+	if o, ok := findOccurrence(webinar, occurrence); ok {
+		startAt = o.StartAt
+	}
+
+	return certificate(webinar, attendance, startAt), nil
+}
+
+// runningOccurrence finds the occurrence attendees can check in to at now.
+func runningOccurrence(webinar models.Webinar, now int64) (occurrence, bool) {
+	for _, o := range expand(webinar, now-checkInGrace, now+checkInGrace+1) {
+		if !o.Cancelled {
+			return o, true
+		}
+	}
+
+	return occurrence{}, false
+}
+
+// checkInOccurrence finds the occurrence that is running at now, or else the
+// next one.
+func checkInOccurrence(webinar models.Webinar, now int64) (occurrence, bool) {
+	for _, o := range expand(webinar, now-checkInGrace, now+upcomingRange) {
+		if !o.Cancelled {
+			return o, true
+		}
+	}
+
+	return occurrence{}, false
+}
+
+// validCheckIn checks the code or token against the ones of the occurrence.
+// It compares in constant time so the code cannot be guessed character by
+// character.
+func validCheckIn(webinar models.Webinar, occurrence int64, code, token string) bool {
+	if webinar.CheckInCode == "" || webinar.CheckInOccurrence != occurrence {
+		return false
+	}
+
+	if code != "" {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		return subtle.ConstantTimeCompare([]byte(code), []byte(webinar.CheckInCode)) == 1
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(webinar.CheckInToken)) == 1
+}
+
+func checkInCode() (string, error) {
+	code := make([]byte, checkInCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(checkInAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = checkInAlphabet[n.Int64()]
+	}
+
+	return string(code), nil
+}
+
+func checkInToken() (string, error) {
+	token := make([]byte, 32)
+	_, err := rand.Read(token)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(token), nil
+}
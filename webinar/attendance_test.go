@@ -0,0 +1,104 @@
+package webinar
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/bagus2x/recovy/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunningOccurrence(t *testing.T) {
+	start := time.Date(2021, 9, 6, 12, 0, 0, 0, time.UTC).Unix()
+	webinar := models.Webinar{
+		StartAt:    start,
+		EndAt:      openEnded,
+		Timezone:   "UTC",
+		Sessions:   []models.WebinarSession{{StartAt: start, EndAt: start + 3600}},
+		Recurrence: "FREQ=WEEKLY",
+		Exceptions: []models.WebinarOccurrence{{RecurrenceID: start + 7*86400, StartAt: start + 7*86400, EndAt: start + 7*86400 + 3600, Cancelled: true}},
+	}
+
+	o, ok := runningOccurrence(webinar, start-checkInGrace)
+	assert.True(t, ok)
+	assert.Equal(t, start, o.RecurrenceID)
+
+	_, ok = runningOccurrence(webinar, start-checkInGrace-1)
+	assert.False(t, ok)
+
+	o, ok = runningOccurrence(webinar, start+3600+checkInGrace-1)
+	assert.True(t, ok)
+	assert.Equal(t, start, o.RecurrenceID)
+
+	_, ok = runningOccurrence(webinar, start+7*86400+600)
+	assert.False(t, ok, "cancelled occurrence")
+
+	o, ok = runningOccurrence(webinar, start+14*86400+600)
+	assert.True(t, ok)
+	assert.Equal(t, start+14*86400, o.RecurrenceID)
+}
+
+func TestCheckInOccurrence(t *testing.T) {
+	start := time.Date(2021, 9, 6, 12, 0, 0, 0, time.UTC).Unix()
+	webinar := models.Webinar{
+		StartAt:    start,
+		EndAt:      openEnded,
+		Timezone:   "UTC",
+		Sessions:   []models.WebinarSession{{StartAt: start, EndAt: start + 3600}},
+		Recurrence: "FREQ=WEEKLY",
+		Exceptions: []models.WebinarOccurrence{{RecurrenceID: start + 7*86400, StartAt: start + 7*86400, EndAt: start + 7*86400 + 3600, Cancelled: true}},
+	}
+
+	o, ok := checkInOccurrence(webinar, start-86400)
+	assert.True(t, ok)
+	assert.Equal(t, start, o.RecurrenceID)
+
+	o, ok = checkInOccurrence(webinar, start+600)
+	assert.True(t, ok)
+	assert.Equal(t, start, o.RecurrenceID)
+
+	// The cancelled occurrence is skipped.
+	o, ok = checkInOccurrence(webinar, start+86400)
+	assert.True(t, ok)
+	assert.Equal(t, start+14*86400, o.RecurrenceID)
+
+	_, ok = checkInOccurrence(models.Webinar{StartAt: start, EndAt: start + 3600}, start+86400)
+	assert.False(t, ok)
+}
+
+func TestValidCheckIn(t *testing.T) {
+	week := int64(7 * 86400)
+	webinar := models.Webinar{CheckInCode: "AB23CD", CheckInToken: "f00d", CheckInOccurrence: 1630929600}
+
+	assert.True(t, validCheckIn(webinar, 1630929600, " ab23cd ", ""))
+	assert.True(t, validCheckIn(webinar, 1630929600, "", "f00d"))
+	assert.False(t, validCheckIn(webinar, 1630929600, "AB23CE", ""))
+	assert.False(t, validCheckIn(webinar, 1630929600, "", "f00"))
+	assert.False(t, validCheckIn(models.Webinar{}, 0, "", ""))
+	// Last week's code does not work this week.
+	assert.False(t, validCheckIn(webinar, 1630929600+week, "AB23CD", ""))
+	assert.False(t, validCheckIn(webinar, 1630929600+week, "", "f00d"))
+
+	code, err := checkInCode()
+	assert.NoError(t, err)
+	assert.Len(t, code, checkInCodeLength)
+}
+
+func TestCertificate(t *testing.T) {
+	webinar := models.Webinar{
+		ID:       7,
+		Author:   models.User{Name: "Recovy"},
+		Title:    "Coping with anxiety at work",
+		Timezone: "Asia/Jakarta",
+		Speakers: []models.WebinarSpeaker{{Name: "Dr. Sari"}, {Name: "Budi"}},
+	}
+	attendance := models.WebinarAttendance{WebinarID: 7, User: models.User{ID: 3, Name: "Ayu"}, CheckedInAt: 1630929600}
+
+	out := certificate(webinar, attendance, 1630929600)
+	assert.True(t, bytes.HasPrefix(out, []byte("%PDF-")))
+	assert.Contains(t, string(out), "(Ayu) Tj")
+	assert.Contains(t, string(out), "(Coping with anxiety at work) Tj")
+	assert.Contains(t, string(out), "(held on 6 September 2021) Tj")
+	assert.Contains(t, string(out), "(No. W7-0-3) Tj")
+}
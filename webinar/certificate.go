@@ -0,0 +1,79 @@
+package webinar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/pdf"
+)
+
+// certificateNumber identifies a certificate by what it certifies, so the
+// same attendance always yields the same number.
+func certificateNumber(attendance models.WebinarAttendance) string {
+	return fmt.Sprintf("W%d-%d-%d", attendance.WebinarID, attendance.Occurrence, attendance.User.ID)
+}
+
+// certificate renders a participation certificate on an A4 landscape page.
+// startAt is the start of the attended occurrence.
+func certificate(webinar models.Webinar, attendance models.WebinarAttendance, startAt int64) []byte {
+	doc := pdf.New(pdf.A4Width, pdf.A4Height)
+	margin := 40.0
+	width := doc.Width - 2*margin
+
+	doc.Color(0.16, 0.42, 0.55)
+	doc.Rect(20, 20, doc.Width-40, doc.Height-40, 3)
+	doc.Rect(28, 28, doc.Width-56, doc.Height-56, 1)
+
+	doc.CenteredText(470, pdf.HelveticaBold, 34, "CERTIFICATE OF PARTICIPATION")
+	doc.Line(doc.Width/2-120, 452, doc.Width/2+120, 452, 1)
+
+	doc.Color(0.2, 0.2, 0.2)
+	doc.CenteredText(405, pdf.Helvetica, 14, "This certifies that")
+	fitText(doc, 360, pdf.HelveticaBold, 30, width, attendance.User.Name)
+	doc.CenteredText(320, pdf.Helvetica, 14, "has attended the webinar")
+	fitText(doc, 280, pdf.HelveticaBold, 22, width, webinar.Title)
+
+	date := time.Unix(startAt, 0).In(location(webinar.Timezone)).Format("2 January 2006")
+	doc.CenteredText(245, pdf.Helvetica, 14, "held on "+date)
+
+	if len(webinar.Speakers) > 0 {
+		names := make([]string, 0, len(webinar.Speakers))
+		for _, speaker := range webinar.Speakers {
+			names = append(names, speaker.Name)
+		}
+		line := "Speakers: " + strings.Join(names, ", ")
+		fitText(doc, 205, pdf.Helvetica, 12, width, line)
+	}
+
+	doc.Line(doc.Width/2-110, 125, doc.Width/2+110, 125, 0.75)
+	fitText(doc, 108, pdf.HelveticaBold, 12, 220, webinar.Author.Name)
+	doc.CenteredText(92, pdf.Helvetica, 10, "Organizer")
+
+	doc.Color(0.45, 0.45, 0.45)
+	doc.Text(margin+10, 45, pdf.Helvetica, 9, "No. "+certificateNumber(attendance))
+	checkedIn := time.Unix(attendance.CheckedInAt, 0).In(location(webinar.Timezone)).Format(time.RFC3339)
+	doc.Text(doc.Width-margin-10-pdf.TextWidth("Checked in "+checkedIn, 9), 45, pdf.Helvetica, 9, "Checked in "+checkedIn)
+
+	return doc.Bytes()
+}
+
+// fitText centers s on the page, shrinking the font size down to two thirds
+// of size to fit in width and cutting off what still does not fit.
+func fitText(doc *pdf.Document, y float64, font pdf.Font, size, width float64, s string) {
+	min := size * 2 / 3
+	for size > min && pdf.TextWidth(s, size) > width {
+		size--
+	}
+
+	if pdf.TextWidth(s, size) > width {
+		runes := []rune(s)
+		for len(runes) > 0 && pdf.TextWidth(string(runes)+"...", size) > width {
+			runes = runes[:len(runes)-1]
+		}
+		s = string(runes) + "..."
+	}
+
+	doc.CenteredText(y, font, size, s)
+}
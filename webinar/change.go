@@ -0,0 +1,316 @@
+package webinar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/taxonomy"
+)
+
+// KindChangeNotice is the job kind of notices about an edited webinar.
+const KindChangeNotice = "webinar.change"
+
+// maxHistory bounds the changes listed with a webinar.
+const maxHistory = 20
+
+type changePayload struct {
+	WebinarID int64  `json:"webinarID"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+}
+
+// Update edits the webinar and records what changed. When the webinar moves
+// or its link changes, everyone who registered for or bookmarked it is notified.
+// A raised or removed capacity gives the seats it frees to the waitlist, whose
+// promoted users are notified; a lowered one keeps the seats already taken.
+func (s *service) Update(ctx context.Context, req *UpdateWebinarReq) (GetWebinarResp, error) {
+	err := req.Validate()
+	if err != nil {
+		return GetWebinarResp{}, err
+	}
+
+	webinar, err := s.webinarRepo.FindByID(ctx, req.WebinarID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return GetWebinarResp{}, app.NewError(err, app.ENotFound, "Webinar not found")
+	} else if err != nil {
+		return GetWebinarResp{}, err
+	}
+
+	if !webinar.IsHost(req.AuthorID) {
+		return GetWebinarResp{}, app.NewError(nil, app.EForbidden, "Forbidden access, webinar not found")
+	}
+
+	if webinar.CancelledAt > 0 {
+		return GetWebinarResp{}, app.NewError(nil, app.Econflict, "Webinar has been cancelled")
+	}
+
+	rescheduled := len(req.Sessions) > 0 || req.Timezone != nil
+	if _, ok := recurrence(webinar); ok && rescheduled {
+		return GetWebinarResp{}, app.NewError(nil, app.EBadRequest, "The schedule of a recurring webinar is changed through its occurrences")
+	}
+
+	updated := applyUpdate(webinar, req)
+
+	retagged := req.Category != nil || req.Categories != nil || req.Tags != nil
+	var terms []models.Term
+	if retagged {
+		current, err := s.taxonomyService.Terms(ctx, models.ContentWebinar, webinar.ID)
+		if err != nil {
+			return GetWebinarResp{}, err
+		}
+
+		categories, tags := taxonomy.Revise(current[webinar.ID], webinar.Category, req.Category, req.Categories, req.Tags)
+		terms, err = s.taxonomyService.Resolve(ctx, categories, tags)
+		if err != nil {
+			return GetWebinarResp{}, err
+		}
+		updated.Category = terms[0].Slug
+	}
+	if rescheduled {
+		err = validateSchedule(updated.Timezone, req.Sessions)
+		if err != nil {
+			return GetWebinarResp{}, err
+		}
+	}
+	if len(req.Sessions) > 0 {
+		setSessions(&updated, req.Sessions)
+	}
+
+	changes := diff(webinar, updated)
+	if len(changes) == 0 {
+		err = s.link(ctx, webinar.ID, terms, retagged)
+		if err != nil {
+			return GetWebinarResp{}, err
+		}
+
+		return s.GetByID(ctx, webinar.ID, "")
+	}
+
+	updated.UpdatedAt = time.Now().Unix()
+	change := models.WebinarChange{
+		User:      models.User{ID: req.AuthorID},
+		Changes:   changes,
+		CreatedAt: updated.UpdatedAt,
+	}
+
+	promoted, err := s.webinarRepo.Update(ctx, &updated, &change, len(req.Sessions) > 0)
+	if app.ErrorCode(err) == app.ENotFound {
+		return GetWebinarResp{}, app.NewError(err, app.ENotFound, "Webinar not found")
+	} else if err != nil {
+		return GetWebinarResp{}, err
+	}
+
+	// The terms are linked once the update is saved, so a failed update
+	// leaves the old ones.
+	err = s.link(ctx, webinar.ID, terms, retagged)
+	if err != nil {
+		return GetWebinarResp{}, err
+	}
+
+	if len(promoted) > 0 {
+		err = s.notifyPromoted(ctx, updated, promoted)
+		if err != nil {
+			return GetWebinarResp{}, err
+		}
+	}
+
+	moved := updated.StartAt != webinar.StartAt
+	if moved {
+		err = s.rescheduleReminders(ctx, webinar.ID)
+		if err != nil {
+			return GetWebinarResp{}, err
+		}
+	}
+
+	if moved || updated.Link != webinar.Link {
+		err = s.enqueueChangeNotice(ctx, updated, moved)
+		if err != nil {
+			return GetWebinarResp{}, err
+		}
+	}
+
+	return s.GetByID(ctx, webinar.ID, "")
+}
+
+func (s *service) link(ctx context.Context, webinarID int64, terms []models.Term, retagged bool) error {
+	if !retagged {
+		return nil
+	}
+
+	return s.taxonomyService.Link(ctx, models.ContentWebinar, webinarID, terms)
+}
+
+// notifyPromoted tells the users moved off the waitlist that they have a seat.
+func (s *service) notifyPromoted(ctx context.Context, webinar models.Webinar, promoted []models.WebinarRegistration) error {
+	users := make([]models.User, 0, len(promoted))
+	for _, registration := range promoted {
+		users = append(users, registration.User)
+	}
+
+	return s.notifier.Notify(ctx, users, Notification{
+		WebinarID: webinar.ID,
+		Title:     fmt.Sprintf("You have a seat at %s", webinar.Title),
+		Body:      "More seats were opened and you were moved off the waitlist.",
+	})
+}
+
+// applyUpdate returns the webinar with the fields set in the request
+// replaced, except for the sessions and the categories.
+func applyUpdate(webinar models.Webinar, req *UpdateWebinarReq) models.Webinar {
+	if req.Picture != nil {
+		webinar.Picture = *req.Picture
+	}
+	if req.Title != nil {
+		webinar.Title = *req.Title
+	}
+	if req.Description != nil {
+		webinar.Description = *req.Description
+	}
+	if req.Link != nil {
+		webinar.Link = *req.Link
+	}
+	if req.Timezone != nil {
+		webinar.Timezone = *req.Timezone
+	}
+	if req.Capacity != nil {
+		webinar.Capacity = *req.Capacity
+	}
+
+	return webinar
+}
+
+// diff lists the fields that differ between the two versions of a webinar.
+// Times are shown in the timezone of each version.
+func diff(old, new models.Webinar) []models.FieldChange {
+	changes := make([]models.FieldChange, 0)
+	add := func(field, from, to string) {
+		if from != to {
+			changes = append(changes, models.FieldChange{Field: field, From: from, To: to})
+		}
+	}
+
+	add("picture", old.Picture, new.Picture)
+	add("title", old.Title, new.Title)
+	if old.Description != new.Description {
+		changes = append(changes, models.FieldChange{Field: "description"})
+	}
+	add("category", old.Category, new.Category)
+	add("link", old.Link, new.Link)
+	add("timezone", old.Timezone, new.Timezone)
+
+	oldLoc, newLoc := location(old.Timezone), location(new.Timezone)
+	if old.StartAt != new.StartAt {
+		add("startAt", formatTime(old.StartAt, oldLoc), formatTime(new.StartAt, newLoc))
+	}
+	if old.EndAt != new.EndAt {
+		add("endAt", formatTime(old.EndAt, oldLoc), formatTime(new.EndAt, newLoc))
+	}
+	if len(old.Sessions) > 1 || len(new.Sessions) > 1 {
+		add("sessions", formatSessions(old.Sessions, oldLoc), formatSessions(new.Sessions, newLoc))
+	}
+	add("capacity", strconv.FormatInt(old.Capacity, 10), strconv.FormatInt(new.Capacity, 10))
+
+	return changes
+}
+
+func formatTime(t int64, loc *time.Location) string {
+	return time.Unix(t, 0).In(loc).Format(time.RFC3339)
+}
+
+func formatSessions(sessions []models.WebinarSession, loc *time.Location) string {
+	formatted := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		formatted = append(formatted, formatTime(session.StartAt, loc)+"/"+formatTime(session.EndAt, loc))
+	}
+
+	return strings.Join(formatted, ", ")
+}
+
+func changeReference(webinarID int64) string {
+	return fmt.Sprintf("webinar-change:%d", webinarID)
+}
+
+// enqueueChangeNotice enqueues the notice about the webinar's new start or
+// link. The link itself is left out, since followers who did not register
+// must not get it.
+func (s *service) enqueueChangeNotice(ctx context.Context, webinar models.Webinar, moved bool) error {
+	payload := changePayload{
+		WebinarID: webinar.ID,
+		Title:     fmt.Sprintf("%s has changed", webinar.Title),
+		Body:      "The link to join has changed.",
+	}
+	if moved {
+		payload.Title = fmt.Sprintf("%s has been rescheduled", webinar.Title)
+		payload.Body = "It now starts " + time.Unix(webinar.StartAt, 0).In(location(webinar.Timezone)).Format("Monday, 2 January 2006 15:04 MST") + "."
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	job := models.Job{
+		Kind:      KindChangeNotice,
+		Reference: changeReference(webinar.ID),
+		Payload:   data,
+		RunAt:     time.Now().Unix(),
+		CreatedAt: time.Now().Unix(),
+		UpdatedAt: time.Now().Unix(),
+	}
+
+	return s.jobRepo.Enqueue(ctx, &job)
+}
+
+// HandleChange notifies the followers of an edited webinar. Notices about a
+// webinar that was since deleted are dropped.
+func (s *service) HandleChange(ctx context.Context, job models.Job) error {
+	var payload changePayload
+	err := json.Unmarshal(job.Payload, &payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.webinarRepo.FindByID(ctx, payload.WebinarID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	users, err := s.webinarRepo.FindFollowers(ctx, payload.WebinarID)
+	if err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return nil
+	}
+
+	return s.notifier.Notify(ctx, users, Notification{
+		WebinarID: payload.WebinarID,
+		Title:     payload.Title,
+		Body:      payload.Body,
+	})
+}
+
+func toChanges(changes []models.WebinarChange) []Change {
+	resp := make([]Change, 0, len(changes))
+	for _, change := range changes {
+		resp = append(resp, Change{
+			Editor: Author{
+				ID:      change.User.ID,
+				Name:    change.User.Name,
+				Picture: change.User.Picture,
+			},
+			Changes:   change.Changes,
+			CreatedAt: change.CreatedAt,
+		})
+	}
+
+	return resp
+}
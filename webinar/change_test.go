@@ -0,0 +1,66 @@
+package webinar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bagus2x/recovy/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	start := time.Date(2021, 9, 6, 12, 0, 0, 0, time.UTC).Unix()
+	old := models.Webinar{
+		Title:       "Coping with stress",
+		Description: "About stress",
+		Timezone:    "Asia/Jakarta",
+		StartAt:     start,
+		EndAt:       start + 3600,
+		Sessions:    []models.WebinarSession{{StartAt: start, EndAt: start + 3600}},
+		Capacity:    10,
+	}
+
+	assert.Empty(t, diff(old, old))
+
+	title, link, description, capacity := "Coping with anxiety", "https://meet.example.com/abc", "About anxiety", int64(20)
+	updated := applyUpdate(old, &UpdateWebinarReq{
+		Title:       &title,
+		Description: &description,
+		Link:        &link,
+		Capacity:    &capacity,
+	})
+	setSessions(&updated, []SessionReq{{StartAt: start + 7200, EndAt: start + 10800}})
+
+	assert.Equal(t, []models.FieldChange{
+		{Field: "title", From: "Coping with stress", To: "Coping with anxiety"},
+		{Field: "description"},
+		{Field: "link", To: "https://meet.example.com/abc"},
+		{Field: "startAt", From: "2021-09-06T19:00:00+07:00", To: "2021-09-06T21:00:00+07:00"},
+		{Field: "endAt", From: "2021-09-06T20:00:00+07:00", To: "2021-09-06T22:00:00+07:00"},
+		{Field: "capacity", From: "10", To: "20"},
+	}, diff(old, updated))
+}
+
+func TestUpdateWebinarReqValidate(t *testing.T) {
+	empty, short, link := "", "Hey", "not a link"
+
+	req := UpdateWebinarReq{WebinarID: 1, AuthorID: 1, Link: &empty}
+	assert.NoError(t, req.Validate())
+
+	req.Link = &link
+	assert.Error(t, req.Validate())
+
+	req = UpdateWebinarReq{WebinarID: 1, AuthorID: 1, Title: &short}
+	assert.Error(t, req.Validate())
+}
+
+func TestSeatsToPromote(t *testing.T) {
+	// Raised capacity fills the free seats from the waitlist.
+	assert.Equal(t, int64(2), seatsToPromote(12, 10, 5))
+	assert.Equal(t, int64(3), seatsToPromote(20, 10, 3))
+	// Unlimited capacity takes the whole waitlist.
+	assert.Equal(t, int64(5), seatsToPromote(0, 10, 5))
+	// Lowered capacity keeps the registered seats and promotes no one.
+	assert.Equal(t, int64(0), seatsToPromote(8, 10, 5))
+	assert.Equal(t, int64(0), seatsToPromote(10, 10, 5))
+}
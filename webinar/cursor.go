@@ -0,0 +1,29 @@
+package webinar
+
+import (
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/pagination"
+)
+
+// cursorToken is the position of a webinar in the start time order. Clients
+// only see it as an opaque string.
+type cursorToken struct {
+	Order   string `json:"o"`
+	StartAt int64  `json:"s"`
+	ID      int64  `json:"id"`
+}
+
+func newCursorToken(order string, webinar models.Webinar) cursorToken {
+	return cursorToken{Order: order, StartAt: webinar.StartAt, ID: webinar.ID}
+}
+
+func encodeCursor(token cursorToken) string {
+	return pagination.Encode(token)
+}
+
+func decodeCursor(s string) (cursorToken, error) {
+	var token cursorToken
+	err := pagination.Decode(s, &token)
+
+	return token, err
+}
@@ -0,0 +1,79 @@
+package webinar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bagus2x/recovy/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	token := newCursorToken(OrderDesc, models.Webinar{ID: 3, StartAt: 1630497600})
+
+	decoded, err := decodeCursor(encodeCursor(token))
+	assert.NoError(t, err)
+	assert.Equal(t, token, decoded)
+
+	_, err = decodeCursor("not a cursor!")
+	assert.Error(t, err)
+}
+
+func TestFindKeyset(t *testing.T) {
+	cursor := cursorToken{Order: OrderAsc, StartAt: 100, ID: 7}
+
+	query, args := find(&Params{Limit: 5, Order: OrderAsc, Statuses: []string{StatusScheduled, StatusLive}, Category: "anxiety"}, &cursor, 50)
+	assert.Contains(t, query, "((w.cancelled_at = 0 AND (w.start_at > $1 OR (w.recurrence <> '' AND w.start_at <= $1 AND w.end_at > $1))) OR (w.cancelled_at = 0 AND w.start_at <= $1 AND w.end_at > $1))")
+	assert.Contains(t, query, "t.kind = 'category' AND t.slug = $2")
+	assert.Contains(t, query, "(w.start_at, w.id) > ($3, $4)")
+	assert.True(t, strings.HasSuffix(query, "ORDER BY w.start_at ASC, w.id ASC LIMIT $5"))
+	assert.Equal(t, []interface{}{int64(50), "anxiety", int64(100), int64(7), int64(5)}, args)
+
+	query, _ = find(&Params{Limit: 5, Order: OrderAsc, Direction: DirectionPrevious}, &cursor, 50)
+	assert.Contains(t, query, "(w.start_at, w.id) < ($1, $2)")
+	assert.Contains(t, query, "ORDER BY w.start_at DESC, w.id DESC")
+}
+
+func TestStatus(t *testing.T) {
+	webinar := models.Webinar{StartAt: 100, EndAt: 200}
+
+	assert.Equal(t, StatusScheduled, status(webinar, 50))
+	assert.Equal(t, StatusLive, status(webinar, 100))
+	assert.Equal(t, StatusEnded, status(webinar, 200))
+
+	webinar.CancelledAt = 60
+	assert.Equal(t, StatusCancelled, status(webinar, 150))
+}
+
+func TestStatusRecurring(t *testing.T) {
+	start := time.Date(2021, 9, 6, 12, 0, 0, 0, time.UTC).Unix()
+	week := int64(7 * 86400)
+	webinar := models.Webinar{
+		StartAt:    start,
+		EndAt:      start + 2*week + 3600,
+		Timezone:   "UTC",
+		Sessions:   []models.WebinarSession{{StartAt: start, EndAt: start + 3600}},
+		Recurrence: "FREQ=WEEKLY;COUNT=3",
+		Exceptions: []models.WebinarOccurrence{{RecurrenceID: start + week, StartAt: start + week, EndAt: start + week + 3600, Cancelled: true}},
+	}
+
+	assert.Equal(t, StatusScheduled, status(webinar, start-1))
+	assert.Equal(t, StatusLive, status(webinar, start+600))
+	// Between occurrences the series is scheduled again.
+	assert.Equal(t, StatusScheduled, status(webinar, start+86400))
+	assert.Equal(t, StatusScheduled, status(webinar, start+week+600), "cancelled occurrence")
+	assert.Equal(t, StatusLive, status(webinar, start+2*week+600))
+	assert.Equal(t, StatusEnded, status(webinar, start+2*week+3600))
+
+	webinar.CancelledAt = start
+	assert.Equal(t, StatusCancelled, status(webinar, start+86400))
+}
+
+func TestWithStatus(t *testing.T) {
+	webinars := []models.Webinar{{ID: 1, StartAt: 100, EndAt: 200}, {ID: 2, StartAt: 300, EndAt: 400}}
+
+	assert.Equal(t, webinars, withStatus(webinars, nil, 150))
+	assert.Equal(t, []models.Webinar{{ID: 2, StartAt: 300, EndAt: 400}}, withStatus(webinars, []string{StatusScheduled}, 150))
+	assert.Equal(t, webinars, withStatus(webinars, []string{StatusLive, StatusScheduled}, 150))
+}
@@ -0,0 +1,91 @@
+package webinar
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bagus2x/recovy/mail"
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/notification"
+	"github.com/sirupsen/logrus"
+)
+
+// Notification is a message to users about a webinar.
+type Notification struct {
+	WebinarID int64
+	Title     string
+	Body      string
+}
+
+// Notifier delivers notifications to users.
+type Notifier interface {
+	Notify(ctx context.Context, users []models.User, notification Notification) error
+}
+
+type logNotifier struct{}
+
+// NewLogNotifier returns a Notifier that only logs, for running without a
+// delivery channel.
+func NewLogNotifier() Notifier {
+	return logNotifier{}
+}
+
+func (logNotifier) Notify(ctx context.Context, users []models.User, notification Notification) error {
+	for _, user := range users {
+		logrus.Infof("notify user %d about webinar %d: %s", user.ID, notification.WebinarID, notification.Title)
+	}
+
+	return nil
+}
+
+type notifier struct {
+	notificationRepo notification.Repository
+	sender           mail.Sender
+}
+
+// NewNotifier records an in-app notification for every user and emails the
+// ones with an address.
+func NewNotifier(notificationRepo notification.Repository, sender mail.Sender) Notifier {
+	return &notifier{
+		notificationRepo: notificationRepo,
+		sender:           sender,
+	}
+}
+
+// Notify fails only when the in-app notifications cannot be stored. Emails
+// are best effort, so that a retry does not record the notifications twice.
+func (n *notifier) Notify(ctx context.Context, users []models.User, notification Notification) error {
+	notifications := make([]models.Notification, 0, len(users))
+	for _, user := range users {
+		notifications = append(notifications, models.Notification{
+			User:      user,
+			Reference: fmt.Sprintf("webinar:%d", notification.WebinarID),
+			Title:     notification.Title,
+			Body:      notification.Body,
+			CreatedAt: time.Now().Unix(),
+		})
+	}
+
+	err := n.notificationRepo.Create(ctx, notifications)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		if user.Email == "" {
+			continue
+		}
+
+		err := n.sender.Send(ctx, mail.Message{
+			To:      user.Email,
+			Subject: notification.Title,
+			Body:    notification.Body,
+		})
+		if err != nil {
+			logrus.Error(err)
+		}
+	}
+
+	return nil
+}
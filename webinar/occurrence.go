@@ -0,0 +1,169 @@
+package webinar
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/rrule"
+)
+
+// openEnded is stored as the end of a series without COUNT or UNTIL, so that
+// it overlaps every range after its start.
+const openEnded = math.MaxInt32
+
+type occurrence struct {
+	RecurrenceID int64
+	StartAt      int64
+	EndAt        int64
+	Cancelled    bool
+}
+
+// recurrence parses the webinar's rule. It reports false for webinars that do
+// not recur.
+func recurrence(webinar models.Webinar) (rrule.Rule, bool) {
+	if webinar.Recurrence == "" {
+		return rrule.Rule{}, false
+	}
+
+	rule, err := rrule.Parse(webinar.Recurrence)
+	if err != nil {
+		return rrule.Rule{}, false
+	}
+
+	return rule, true
+}
+
+// dtstart is the first occurrence of a series in the webinar's timezone,
+// which the rule is expanded in.
+func dtstart(webinar models.Webinar) time.Time {
+	return time.Unix(webinar.StartAt, 0).In(location(webinar.Timezone))
+}
+
+// duration is the length of every occurrence of a recurring webinar, which
+// has a single session.
+func duration(webinar models.Webinar) int64 {
+	if len(webinar.Sessions) == 0 {
+		return 0
+	}
+
+	return webinar.Sessions[0].EndAt - webinar.Sessions[0].StartAt
+}
+
+// seriesEnd is the end of the last occurrence, or openEnded.
+func seriesEnd(rule rrule.Rule, start time.Time, duration int64) int64 {
+	last, ok := rule.Last(start)
+	if !ok {
+		return openEnded
+	}
+
+	return last.Unix() + duration
+}
+
+// expand returns the occurrences of the webinar overlapping [from, to),
+// sorted by start. Moved occurrences are found at their new time; cancelled
+// ones are kept and flagged.
+func expand(webinar models.Webinar, from, to int64) []occurrence {
+	rule, ok := recurrence(webinar)
+	if !ok {
+		if webinar.StartAt < to && webinar.EndAt > from {
+			return []occurrence{{StartAt: webinar.StartAt, EndAt: webinar.EndAt}}
+		}
+
+		return make([]occurrence, 0)
+	}
+
+	length := duration(webinar)
+	start := dtstart(webinar)
+	exceptions := make(map[int64]models.WebinarOccurrence, len(webinar.Exceptions))
+	for _, exception := range webinar.Exceptions {
+		exceptions[exception.RecurrenceID] = exception
+	}
+
+	expanded := make(map[int64]bool)
+	occurrences := make([]occurrence, 0)
+	add := func(o occurrence) {
+		if o.StartAt < to && o.EndAt > from {
+			occurrences = append(occurrences, o)
+		}
+	}
+
+	for _, t := range rule.Between(start, time.Unix(from-length, 0), time.Unix(to, 0)) {
+		o := occurrence{RecurrenceID: t.Unix(), StartAt: t.Unix(), EndAt: t.Unix() + length}
+		if exception, ok := exceptions[o.RecurrenceID]; ok {
+			o.StartAt, o.EndAt, o.Cancelled = exception.StartAt, exception.EndAt, exception.Cancelled
+		}
+		expanded[o.RecurrenceID] = true
+		add(o)
+	}
+
+	// Occurrences moved into the range from outside of it.
+	for _, exception := range webinar.Exceptions {
+		if expanded[exception.RecurrenceID] || exception.Cancelled {
+			continue
+		}
+		if !rule.Includes(start, time.Unix(exception.RecurrenceID, 0).In(start.Location())) {
+			continue
+		}
+
+		add(occurrence{
+			RecurrenceID: exception.RecurrenceID,
+			StartAt:      exception.StartAt,
+			EndAt:        exception.EndAt,
+		})
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].StartAt < occurrences[j].StartAt
+	})
+
+	return occurrences
+}
+
+// findOccurrence looks up an occurrence of a recurring webinar by its
+// recurrence ID.
+func findOccurrence(webinar models.Webinar, recurrenceID int64) (occurrence, bool) {
+	rule, ok := recurrence(webinar)
+	if !ok {
+		return occurrence{}, false
+	}
+
+	start := dtstart(webinar)
+	if !rule.Includes(start, time.Unix(recurrenceID, 0).In(start.Location())) {
+		return occurrence{}, false
+	}
+
+	o := occurrence{RecurrenceID: recurrenceID, StartAt: recurrenceID, EndAt: recurrenceID + duration(webinar)}
+	for _, exception := range webinar.Exceptions {
+		if exception.RecurrenceID == recurrenceID {
+			o.StartAt, o.EndAt, o.Cancelled = exception.StartAt, exception.EndAt, exception.Cancelled
+		}
+	}
+
+	return o, true
+}
+
+// HasOccurrence reports whether the webinar runs at the occurrence, which is
+// 0 for webinars that do not recur. Cancelled occurrences are included.
+func HasOccurrence(webinar models.Webinar, recurrenceID int64) bool {
+	if _, ok := recurrence(webinar); !ok {
+		return recurrenceID == 0
+	}
+
+	_, ok := findOccurrence(webinar, recurrenceID)
+
+	return ok
+}
+
+// exdates returns the recurrence IDs of the cancelled occurrences.
+func exdates(webinar models.Webinar) []int64 {
+	dates := make([]int64, 0)
+	for _, exception := range webinar.Exceptions {
+		if exception.Cancelled {
+			dates = append(dates, exception.RecurrenceID)
+		}
+	}
+
+	return dates
+}
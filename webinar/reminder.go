@@ -0,0 +1,153 @@
+package webinar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+)
+
+// KindReminder is the job kind of webinar reminders.
+const KindReminder = "webinar.reminder"
+
+// reminderLeads are how long before a webinar starts its reminders are sent.
+var reminderLeads = []time.Duration{24 * time.Hour, 15 * time.Minute}
+
+type reminderPayload struct {
+	WebinarID int64 `json:"webinarID"`
+	StartAt   int64 `json:"startAt"`
+	Lead      int64 `json:"lead"`
+}
+
+func reminderReference(webinarID int64) string {
+	return fmt.Sprintf("webinar:%d", webinarID)
+}
+
+// scheduleReminders replaces the pending reminders of the webinar with ones
+// for its next start. Reminders that are already due are skipped.
+func (s *service) scheduleReminders(ctx context.Context, webinar models.Webinar) error {
+	err := s.jobRepo.CancelByReference(ctx, reminderReference(webinar.ID))
+	if err != nil {
+		return err
+	}
+
+	if webinar.CancelledAt > 0 {
+		return nil
+	}
+
+	now := time.Now()
+	for _, lead := range reminderLeads {
+		err := s.enqueueReminder(ctx, webinar, lead, now.Add(lead).Unix())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// enqueueReminder enqueues a reminder for the first start of the webinar
+// after the given time.
+func (s *service) enqueueReminder(ctx context.Context, webinar models.Webinar, lead time.Duration, after int64) error {
+	startAt, ok := nextStart(webinar, after)
+	if !ok {
+		return nil
+	}
+
+	payload, err := json.Marshal(reminderPayload{
+		WebinarID: webinar.ID,
+		StartAt:   startAt,
+		Lead:      int64(lead / time.Second),
+	})
+	if err != nil {
+		return err
+	}
+
+	job := models.Job{
+		Kind:      KindReminder,
+		Key:       fmt.Sprintf("webinar-reminder:%d:%d:%d", webinar.ID, int64(lead/time.Second), startAt),
+		Reference: reminderReference(webinar.ID),
+		Payload:   payload,
+		RunAt:     startAt - int64(lead/time.Second),
+		CreatedAt: time.Now().Unix(),
+		UpdatedAt: time.Now().Unix(),
+	}
+
+	return s.jobRepo.Enqueue(ctx, &job)
+}
+
+// nextStart returns the start of the first occurrence that is not cancelled
+// and starts after the given time.
+func nextStart(webinar models.Webinar, after int64) (int64, bool) {
+	for _, o := range expand(webinar, after, after+upcomingRange) {
+		if !o.Cancelled && o.StartAt > after {
+			return o.StartAt, true
+		}
+	}
+
+	return 0, false
+}
+
+// HandleReminder notifies the users who asked to be reminded of the webinar.
+// Reminders for a start that was since moved or cancelled are dropped; a
+// recurring webinar gets the reminder for its next occurrence enqueued.
+func (s *service) HandleReminder(ctx context.Context, job models.Job) error {
+	var payload reminderPayload
+	err := json.Unmarshal(job.Payload, &payload)
+	if err != nil {
+		return err
+	}
+
+	webinar, err := s.webinarRepo.FindByID(ctx, payload.WebinarID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if webinar.CancelledAt > 0 {
+		return nil
+	}
+
+	lead := time.Duration(payload.Lead) * time.Second
+
+	if startAt, ok := nextStart(webinar, payload.StartAt-1); ok && startAt == payload.StartAt {
+		reminders, err := s.reminderRepo.FindByWebinarID(ctx, webinar.ID)
+		if err != nil {
+			return err
+		}
+
+		users := make([]models.User, 0, len(reminders))
+		for _, reminder := range reminders {
+			users = append(users, reminder.User)
+		}
+
+		if len(users) > 0 {
+			err = s.notifier.Notify(ctx, users, Notification{
+				WebinarID: webinar.ID,
+				Title:     fmt.Sprintf("%s starts in %s", webinar.Title, formatLead(lead)),
+				Body:      time.Unix(payload.StartAt, 0).In(location(webinar.Timezone)).Format("Monday, 2 January 2006 15:04 MST"),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, ok := recurrence(webinar); ok {
+		return s.enqueueReminder(ctx, webinar, lead, payload.StartAt)
+	}
+
+	return nil
+}
+
+func formatLead(lead time.Duration) string {
+	if lead >= time.Hour {
+		return fmt.Sprintf("%d hours", int64(lead/time.Hour))
+	}
+
+	return fmt.Sprintf("%d minutes", int64(lead/time.Minute))
+}
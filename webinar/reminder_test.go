@@ -0,0 +1,39 @@
+package webinar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bagus2x/recovy/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextStart(t *testing.T) {
+	start := time.Date(2021, 9, 6, 12, 0, 0, 0, time.UTC).Unix()
+	webinar := models.Webinar{
+		StartAt:    start,
+		EndAt:      openEnded,
+		Timezone:   "UTC",
+		Sessions:   []models.WebinarSession{{StartAt: start, EndAt: start + 3600}},
+		Recurrence: "FREQ=WEEKLY",
+		Exceptions: []models.WebinarOccurrence{{RecurrenceID: start + 7*86400, Cancelled: true}},
+	}
+
+	next, ok := nextStart(webinar, start)
+	assert.True(t, ok)
+	assert.Equal(t, start+14*86400, next)
+
+	webinar.Recurrence = ""
+	webinar.EndAt = start + 3600
+	_, ok = nextStart(webinar, start)
+	assert.False(t, ok)
+
+	next, ok = nextStart(webinar, start-1)
+	assert.True(t, ok)
+	assert.Equal(t, start, next)
+}
+
+func TestFormatLead(t *testing.T) {
+	assert.Equal(t, "24 hours", formatLead(24*time.Hour))
+	assert.Equal(t, "15 minutes", formatLead(15*time.Minute))
+}
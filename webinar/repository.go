@@ -3,41 +3,84 @@ package webinar
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/bagus2x/recovy/app"
 	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/pagination"
+	"github.com/lib/pq"
 )
 
 type Repository interface {
 	Create(ctx context.Context, webinar *models.Webinar) error
 	FindByID(ctx context.Context, webinarID int64) (models.Webinar, error)
-	Find(ctx context.Context) ([]models.Webinar, error)
-	FindByCategory(ctx context.Context, category string) ([]models.Webinar, error)
+	Find(ctx context.Context, params *Params) ([]models.Webinar, Cursor, error)
+	FindByAttendeeID(ctx context.Context, userID int64) ([]models.Webinar, error)
+	FindBetween(ctx context.Context, from, to int64) ([]models.Webinar, error)
+	SaveException(ctx context.Context, exception *models.WebinarOccurrence) error
+	Reschedule(ctx context.Context, webinar *models.Webinar, delta int64) error
+	Split(ctx context.Context, webinar, following *models.Webinar, recurrenceID, delta int64) error
+	Update(ctx context.Context, webinar *models.Webinar, change *models.WebinarChange, sessions bool) ([]models.WebinarRegistration, error)
+	FindChanges(ctx context.Context, webinarID, limit int64) ([]models.WebinarChange, error)
+	FindFollowers(ctx context.Context, webinarID int64) ([]models.User, error)
+	Cancel(ctx context.Context, webinarID, cancelledAt int64) error
+	ReplaceSpeakers(ctx context.Context, webinarID int64, speakers []models.WebinarSpeaker) error
+	AddCohost(ctx context.Context, webinarID, userID, createdAt int64) error
+	DeleteCohost(ctx context.Context, webinarID, userID int64) error
+	SaveCheckIn(ctx context.Context, webinarID, occurrence int64, code, token string) error
 	Delete(ctx context.Context, webinarID int64) error
 }
 
 type repository struct {
-	db sql.DB
+	db *sql.DB
 }
 
 func NewRepository(db *sql.DB) Repository {
 	return &repository{
-		db: *db,
+		db: db,
 	}
 }
 
 var create = `
 	INSERT INTO
 		Webinar
-		(author_id, picture, title, description, category, start_date, last_date, time, created_at, updated_at)
+		(author_id, picture, title, description, category, link, start_at, end_at, timezone, recurrence, capacity, created_at, updated_at)
 	VALUES
-		($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	RETURNING
+		id
+`
+
+var createSession = `
+	INSERT INTO
+		Webinar_Session
+		(webinar_id, start_at, end_at)
+	VALUES
+		($1, $2, $3)
 	RETURNING
 		id
 `
 
 func (r *repository) Create(ctx context.Context, webinar *models.Webinar) error {
-	err := r.db.QueryRowContext(
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = insert(ctx, tx, webinar)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insert(ctx context.Context, tx *sql.Tx, webinar *models.Webinar) error {
+	err := tx.QueryRowContext(
 		ctx,
 		create,
 		webinar.Author.ID,
@@ -45,19 +88,70 @@ func (r *repository) Create(ctx context.Context, webinar *models.Webinar) error
 		webinar.Title,
 		webinar.Description,
 		webinar.Category,
-		webinar.StartDate,
-		webinar.LastDate,
-		webinar.Time,
+		webinar.Link,
+		webinar.StartAt,
+		webinar.EndAt,
+		webinar.Timezone,
+		webinar.Recurrence,
+		webinar.Capacity,
 		webinar.CreatedAt,
 		webinar.UpdatedAt,
 	).Scan(&webinar.ID)
+	if err != nil {
+		return err
+	}
 
-	return err
+	err = insertSessions(ctx, tx, webinar)
+	if err != nil {
+		return err
+	}
+
+	err = insertSpeakers(ctx, tx, webinar.ID, webinar.Speakers)
+	if err != nil {
+		return err
+	}
+
+	for i := range webinar.Exceptions {
+		exception := &webinar.Exceptions[i]
+		exception.WebinarID = webinar.ID
+
+		_, err = tx.ExecContext(
+			ctx,
+			saveException,
+			exception.WebinarID,
+			exception.RecurrenceID,
+			exception.StartAt,
+			exception.EndAt,
+			exception.Cancelled,
+			exception.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func insertSessions(ctx context.Context, tx *sql.Tx, webinar *models.Webinar) error {
+	for i := range webinar.Sessions {
+		session := &webinar.Sessions[i]
+		session.WebinarID = webinar.ID
+
+		err := tx.QueryRowContext(ctx, createSession, session.WebinarID, session.StartAt, session.EndAt).Scan(&session.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 var findByID = `
 	SELECT
-		w.id, au.id, au.name, au.picture, w.picture, w.title, w.description, w.category, w.start_date, w.last_date, w.time, w.created_at, w.updated_at
+		w.id, au.id, au.name, au.picture, w.picture, w.title, w.description, w.category, w.link, w.start_at, w.end_at, w.timezone, w.recurrence, w.capacity, w.sequence,
+		(SELECT COUNT(*) FROM Webinar_Registration wr WHERE wr.webinar_id = w.id AND wr.status = 'registered'),
+		w.cancelled_at, w.check_in_code, w.check_in_token, w.check_in_occurrence, w.created_at, w.updated_at
 	FROM
 		Webinar w
 	JOIN
@@ -65,54 +159,203 @@ var findByID = `
 	ON
 		w.author_id = au.id
 	WHERE
-		w.id = $1
+		w.id = $1 AND w.hidden_at = 0
 `
 
 func (r *repository) FindByID(ctx context.Context, webinarID int64) (models.Webinar, error) {
-	var webinar models.Webinar
-
-	err := r.db.QueryRowContext(ctx, findByID, webinarID).Scan(
-		&webinar.ID,
-		&webinar.Author.ID,
-		&webinar.Author.Name,
-		&webinar.Author.Picture,
-		&webinar.Picture,
-		&webinar.Title,
-		&webinar.Description,
-		&webinar.Category,
-		&webinar.StartDate,
-		&webinar.LastDate,
-		&webinar.Time,
-		&webinar.CreatedAt,
-		&webinar.UpdatedAt,
+	webinars, err := r.find(ctx, findByID, webinarID)
+	if err != nil {
+		return models.Webinar{}, err
+	}
+	if len(webinars) == 0 {
+		return models.Webinar{}, app.NewError(sql.ErrNoRows, app.ENotFound)
+	}
+
+	return webinars[0], nil
+}
+
+// runningSeries matches the recurring webinars whose series has started and
+// not ended. Whether they are scheduled, live or ended depends on their
+// occurrences, so they match every status here and Find refines them.
+const runningSeries = "(w.recurrence <> '' AND w.start_at <= %[1]s AND w.end_at > %[1]s)"
+
+var statusConditions = map[string]string{
+	StatusScheduled: "(w.cancelled_at = 0 AND (w.start_at > %[1]s OR " + runningSeries + "))",
+	StatusLive:      "(w.cancelled_at = 0 AND w.start_at <= %[1]s AND w.end_at > %[1]s)",
+	StatusEnded:     "(w.cancelled_at = 0 AND (w.end_at <= %[1]s OR " + runningSeries + "))",
+	StatusCancelled: "(w.cancelled_at > 0)",
+}
+
+func find(params *Params, cursor *cursorToken, now int64) (string, []interface{}) {
+	query := strings.Builder{}
+	args := make([]interface{}, 0)
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	query.WriteString(`
+		SELECT
+			w.id, au.id, au.name, au.picture, w.picture, w.title, w.description, w.category, w.link, w.start_at, w.end_at, w.timezone, w.recurrence, w.capacity, w.sequence,
+			(SELECT COUNT(*) FROM Webinar_Registration wr WHERE wr.webinar_id = w.id AND wr.status = 'registered'),
+			w.cancelled_at, w.check_in_code, w.check_in_token, w.check_in_occurrence, w.created_at, w.updated_at
+		FROM
+			Webinar w
+		JOIN
+			App_User au
+		ON
+			w.author_id = au.id
+		WHERE
+			w.hidden_at = 0`,
 	)
-	if err == sql.ErrNoRows {
-		return models.Webinar{}, app.NewError(err, app.ENotFound)
+
+	// Dynamic query
+	if len(params.Statuses) > 0 {
+		n := arg(now)
+		conditions := make([]string, 0, len(params.Statuses))
+		for _, status := range params.Statuses {
+			conditions = append(conditions, fmt.Sprintf(statusConditions[status], n))
+		}
+		fmt.Fprintf(&query, " AND (%s) ", strings.Join(conditions, " OR "))
+	}
+	if params.From != 0 {
+		fmt.Fprintf(&query, " AND w.end_at > %s ", arg(params.From))
+	}
+	if params.To != 0 {
+		fmt.Fprintf(&query, " AND w.start_at < %s ", arg(params.To))
+	}
+	if params.AuthorID != 0 {
+		fmt.Fprintf(&query, " AND w.author_id = %s ", arg(params.AuthorID))
+	}
+	if params.Category != "" {
+		fmt.Fprintf(&query, " AND EXISTS (SELECT 1 FROM Webinar_Term wt JOIN Term t ON t.id = wt.term_id WHERE wt.webinar_id = w.id AND t.kind = 'category' AND t.slug = %s) ", arg(params.Category))
+	}
+	if params.Tag != "" {
+		fmt.Fprintf(&query, " AND EXISTS (SELECT 1 FROM Webinar_Term wt JOIN Term t ON t.id = wt.term_id WHERE wt.webinar_id = w.id AND t.kind = 'tag' AND t.slug = %s) ", arg(params.Tag))
+	}
+
+	// Cursor
+	desc := params.Order == OrderDesc
+	if params.Direction == DirectionPrevious {
+		desc = !desc
+	}
+	op, order := ">", "ASC"
+	if desc {
+		op, order = "<", "DESC"
+	}
+
+	if cursor != nil {
+		fmt.Fprintf(&query, " AND (w.start_at, w.id) %s (%s, %s) ", op, arg(cursor.StartAt), arg(cursor.ID))
+	}
+
+	// Limit
+	fmt.Fprintf(&query, " ORDER BY w.start_at %s, w.id %s LIMIT %s", order, order, arg(params.Limit))
+
+	return query.String(), args
+}
+
+// Find lists webinars by start time, ascending unless the order is desc.
+// Statuses are evaluated at the time of the query, so a page can have fewer
+// webinars than the limit when recurring ones are left out by theirs.
+func (r *repository) Find(ctx context.Context, params *Params) ([]models.Webinar, Cursor, error) {
+	if params.Order == "" {
+		params.Order = OrderAsc
+	}
+	if params.Order != OrderAsc && params.Order != OrderDesc {
+		return nil, Cursor{}, app.NewError(nil, app.EBadRequest, "Order must be one of [asc desc]")
+	}
+	for _, status := range params.Statuses {
+		if _, ok := statusConditions[status]; !ok {
+			return nil, Cursor{}, app.NewError(nil, app.EBadRequest, "Status must be one of [scheduled live ended cancelled]")
+		}
+	}
+
+	params.Limit = pagination.Limit(params.Limit)
+
+	var cursor *cursorToken
+	if params.Cursor != "" {
+		token, err := decodeCursor(params.Cursor)
+		if err != nil || token.Order != params.Order {
+			return nil, Cursor{}, app.NewError(err, app.EBadRequest, "Invalid cursor")
+		}
+		cursor = &token
+	}
+
+	now := time.Now().Unix()
+	query, args := find(params, cursor, now)
+	webinars, err := r.find(ctx, query, args...)
+	if err != nil {
+		return nil, Cursor{}, err
+	}
+
+	if params.Direction == DirectionPrevious {
+		pagination.Reverse(webinars)
+	}
+
+	var res Cursor
+	if len(webinars) > 0 {
+		res.Next = encodeCursor(newCursorToken(params.Order, webinars[len(webinars)-1]))
+		res.Previous = encodeCursor(newCursorToken(params.Order, webinars[0]))
+	}
+
+	return withStatus(webinars, params.Statuses, now), res, nil
+}
+
+// withStatus keeps the webinars that have one of the statuses at now, or all
+// of them when no status is given.
+func withStatus(webinars []models.Webinar, statuses []string, now int64) []models.Webinar {
+	if len(statuses) == 0 {
+		return webinars
+	}
+
+	kept := make([]models.Webinar, 0, len(webinars))
+	for _, webinar := range webinars {
+		current := status(webinar, now)
+		for _, s := range statuses {
+			if s == current {
+				kept = append(kept, webinar)
+				break
+			}
+		}
 	}
 
-	return webinar, nil
+	return kept
 }
 
-var find = `
+var findByAttendeeID = `
 	SELECT
-		w.id, au.id, au.name, au.picture, w.picture, w.title, w.description, w.category, w.start_date, w.last_date, w.time, w.created_at, w.updated_at
+		w.id, au.id, au.name, au.picture, w.picture, w.title, w.description, w.category, w.link, w.start_at, w.end_at, w.timezone, w.recurrence, w.capacity, w.sequence,
+		(SELECT COUNT(*) FROM Webinar_Registration wr WHERE wr.webinar_id = w.id AND wr.status = 'registered'),
+		w.cancelled_at, w.check_in_code, w.check_in_token, w.check_in_occurrence, w.created_at, w.updated_at
 	FROM
 		Webinar w
 	JOIN
 		App_User au
 	ON
 		w.author_id = au.id
-	LIMIT
-		100
+	WHERE
+		w.hidden_at = 0 AND w.id IN (
+			SELECT webinar_id FROM Webinar_Registration WHERE user_id = $1
+			UNION
+			SELECT webinar_id FROM Webinar_Reaction WHERE user_id = $1 AND kind = 'bookmark'
+		)
+	ORDER BY
+		w.start_at ASC
 `
 
-func (r *repository) Find(ctx context.Context) ([]models.Webinar, error) {
+// FindByAttendeeID returns the webinars the user registered for or bookmarked.
+func (r *repository) FindByAttendeeID(ctx context.Context, userID int64) ([]models.Webinar, error) {
+	return r.find(ctx, findByAttendeeID, userID)
+}
+
+func (r *repository) find(ctx context.Context, query string, args ...interface{}) ([]models.Webinar, error) {
 	webinars := make([]models.Webinar, 0)
 
-	rows, err := r.db.QueryContext(ctx, find)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return webinars, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		var webinar models.Webinar
@@ -126,9 +369,18 @@ func (r *repository) Find(ctx context.Context) ([]models.Webinar, error) {
 			&webinar.Title,
 			&webinar.Description,
 			&webinar.Category,
-			&webinar.StartDate,
-			&webinar.LastDate,
-			&webinar.Time,
+			&webinar.Link,
+			&webinar.StartAt,
+			&webinar.EndAt,
+			&webinar.Timezone,
+			&webinar.Recurrence,
+			&webinar.Capacity,
+			&webinar.Sequence,
+			&webinar.Registered,
+			&webinar.CancelledAt,
+			&webinar.CheckInCode,
+			&webinar.CheckInToken,
+			&webinar.CheckInOccurrence,
 			&webinar.CreatedAt,
 			&webinar.UpdatedAt,
 		)
@@ -138,16 +390,35 @@ func (r *repository) Find(ctx context.Context) ([]models.Webinar, error) {
 
 		webinars = append(webinars, webinar)
 	}
-	if rows.Err() != nil {
+	if err := rows.Err(); err != nil {
+		return webinars, err
+	}
+
+	err = r.findSessions(ctx, webinars)
+	if err != nil {
+		return webinars, err
+	}
+
+	err = r.findExceptions(ctx, webinars)
+	if err != nil {
+		return webinars, err
+	}
+
+	err = r.findSpeakers(ctx, webinars)
+	if err != nil {
 		return webinars, err
 	}
 
-	return webinars, nil
+	err = r.findCohosts(ctx, webinars)
+
+	return webinars, err
 }
 
-var findByCategory = `
+var findBetween = `
 	SELECT
-		w.id, au.id, au.name, au.picture, w.picture, w.title, w.description, w.category, w.start_date, w.last_date, w.time, w.created_at, w.updated_at
+		w.id, au.id, au.name, au.picture, w.picture, w.title, w.description, w.category, w.link, w.start_at, w.end_at, w.timezone, w.recurrence, w.capacity, w.sequence,
+		(SELECT COUNT(*) FROM Webinar_Registration wr WHERE wr.webinar_id = w.id AND wr.status = 'registered'),
+		w.cancelled_at, w.check_in_code, w.check_in_token, w.check_in_occurrence, w.created_at, w.updated_at
 	FROM
 		Webinar w
 	JOIN
@@ -155,59 +426,922 @@ var findByCategory = `
 	ON
 		w.author_id = au.id
 	WHERE
-		w.category = $1
+		w.hidden_at = 0 AND ((w.start_at < $2 AND w.end_at > $1) OR w.id IN (
+			SELECT webinar_id FROM Webinar_Occurrence WHERE start_at < $2 AND end_at > $1 AND NOT cancelled
+		))
+	ORDER BY
+		w.start_at ASC, w.id ASC
 	LIMIT
-		100
+		500
 `
 
-func (r *repository) FindByCategory(ctx context.Context, category string) ([]models.Webinar, error) {
-	webinars := make([]models.Webinar, 0)
+// FindBetween returns the webinars whose schedule overlaps [from, to). A
+// recurring webinar spans from its first occurrence to the end of its last,
+// or has an occurrence moved into the range, so its occurrences still have to
+// be expanded to see which fall in range.
+func (r *repository) FindBetween(ctx context.Context, from, to int64) ([]models.Webinar, error) {
+	return r.find(ctx, findBetween, from, to)
+}
+
+var findSessions = `
+	SELECT
+		id, webinar_id, start_at, end_at
+	FROM
+		Webinar_Session
+	WHERE
+		webinar_id = ANY($1)
+	ORDER BY
+		start_at ASC, id ASC
+`
+
+// findSessions loads the sessions of all webinars with a single query.
+func (r *repository) findSessions(ctx context.Context, webinars []models.Webinar) error {
+	if len(webinars) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(webinars))
+	index := make(map[int64]int, len(webinars))
+	for i, webinar := range webinars {
+		ids = append(ids, webinar.ID)
+		index[webinar.ID] = i
+		webinars[i].Sessions = make([]models.WebinarSession, 0)
+	}
 
-	rows, err := r.db.QueryContext(ctx, findByCategory, category)
+	rows, err := r.db.QueryContext(ctx, findSessions, pq.Array(ids))
 	if err != nil {
-		return webinars, err
+		return err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
-		var webinar models.Webinar
+		var session models.WebinarSession
 
 		err := rows.Scan(
-			&webinar.ID,
-			&webinar.Author.ID,
-			&webinar.Author.Name,
-			&webinar.Author.Picture,
-			&webinar.Picture,
-			&webinar.Title,
-			&webinar.Description,
-			&webinar.Category,
-			&webinar.StartDate,
-			&webinar.LastDate,
-			&webinar.Time,
-			&webinar.CreatedAt,
-			&webinar.UpdatedAt,
+			&session.ID,
+			&session.WebinarID,
+			&session.StartAt,
+			&session.EndAt,
 		)
 		if err != nil {
-			return webinars, err
+			return err
 		}
 
-		webinars = append(webinars, webinar)
+		i := index[session.WebinarID]
+		webinars[i].Sessions = append(webinars[i].Sessions, session)
 	}
-	if rows.Err() != nil {
-		return webinars, err
+
+	return rows.Err()
+}
+
+var findExceptions = `
+	SELECT
+		webinar_id, recurrence_id, start_at, end_at, cancelled, updated_at
+	FROM
+		Webinar_Occurrence
+	WHERE
+		webinar_id = ANY($1)
+	ORDER BY
+		recurrence_id ASC
+`
+
+func (r *repository) findExceptions(ctx context.Context, webinars []models.Webinar) error {
+	if len(webinars) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(webinars))
+	index := make(map[int64]int, len(webinars))
+	for i, webinar := range webinars {
+		ids = append(ids, webinar.ID)
+		index[webinar.ID] = i
+		webinars[i].Exceptions = make([]models.WebinarOccurrence, 0)
+	}
+
+	rows, err := r.db.QueryContext(ctx, findExceptions, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var exception models.WebinarOccurrence
+
+		err := rows.Scan(
+			&exception.WebinarID,
+			&exception.RecurrenceID,
+			&exception.StartAt,
+			&exception.EndAt,
+			&exception.Cancelled,
+			&exception.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		i := index[exception.WebinarID]
+		webinars[i].Exceptions = append(webinars[i].Exceptions, exception)
 	}
 
-	return webinars, nil
+	return rows.Err()
 }
 
-var delete = `
+var findSpeakers = `
+	SELECT
+		ws.id, ws.webinar_id, COALESCE(au.id, 0), COALESCE(NULLIF(ws.name, ''), au.name, ''), ws.bio, COALESCE(NULLIF(ws.photo, ''), au.picture, ''), ws.position
+	FROM
+		Webinar_Speaker ws
+	LEFT JOIN
+		App_User au
+	ON
+		ws.user_id = au.id
+	WHERE
+		ws.webinar_id = ANY($1)
+	ORDER BY
+		ws.position ASC, ws.id ASC
+`
+
+func (r *repository) findSpeakers(ctx context.Context, webinars []models.Webinar) error {
+	if len(webinars) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(webinars))
+	index := make(map[int64]int, len(webinars))
+	for i, webinar := range webinars {
+		ids = append(ids, webinar.ID)
+		index[webinar.ID] = i
+		webinars[i].Speakers = make([]models.WebinarSpeaker, 0)
+	}
+
+	rows, err := r.db.QueryContext(ctx, findSpeakers, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var speaker models.WebinarSpeaker
+
+		err := rows.Scan(
+			&speaker.ID,
+			&speaker.WebinarID,
+			&speaker.User.ID,
+			&speaker.Name,
+			&speaker.Bio,
+			&speaker.Photo,
+			&speaker.Position,
+		)
+		if err != nil {
+			return err
+		}
+
+		i := index[speaker.WebinarID]
+		webinars[i].Speakers = append(webinars[i].Speakers, speaker)
+	}
+
+	return rows.Err()
+}
+
+var findCohosts = `
+	SELECT
+		wc.webinar_id, au.id, au.name, au.picture
+	FROM
+		Webinar_Cohost wc
+	JOIN
+		App_User au
+	ON
+		wc.user_id = au.id
+	WHERE
+		wc.webinar_id = ANY($1)
+	ORDER BY
+		wc.created_at ASC
+`
+
+func (r *repository) findCohosts(ctx context.Context, webinars []models.Webinar) error {
+	if len(webinars) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(webinars))
+	index := make(map[int64]int, len(webinars))
+	for i, webinar := range webinars {
+		ids = append(ids, webinar.ID)
+		index[webinar.ID] = i
+		webinars[i].Cohosts = make([]models.User, 0)
+	}
+
+	rows, err := r.db.QueryContext(ctx, findCohosts, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var webinarID int64
+		var cohost models.User
+
+		err := rows.Scan(
+			&webinarID,
+			&cohost.ID,
+			&cohost.Name,
+			&cohost.Picture,
+		)
+		if err != nil {
+			return err
+		}
+
+		i := index[webinarID]
+		webinars[i].Cohosts = append(webinars[i].Cohosts, cohost)
+	}
+
+	return rows.Err()
+}
+
+var saveException = `
+	INSERT INTO
+		Webinar_Occurrence
+		(webinar_id, recurrence_id, start_at, end_at, cancelled, updated_at)
+	VALUES
+		($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (webinar_id, recurrence_id) DO UPDATE SET
+		start_at = EXCLUDED.start_at, end_at = EXCLUDED.end_at, cancelled = EXCLUDED.cancelled, updated_at = EXCLUDED.updated_at
+`
+
+var bumpSequence = `
+	UPDATE
+		Webinar
+	SET
+		sequence = sequence + 1, updated_at = $2
+	WHERE
+		id = $1
+`
+
+// SaveException moves or cancels a single occurrence. The webinar's sequence
+// is bumped so calendars pick up the change.
+func (r *repository) SaveException(ctx context.Context, exception *models.WebinarOccurrence) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(
+		ctx,
+		saveException,
+		exception.WebinarID,
+		exception.RecurrenceID,
+		exception.StartAt,
+		exception.EndAt,
+		exception.Cancelled,
+		exception.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = mustAffect(tx.ExecContext(ctx, bumpSequence, exception.WebinarID, exception.UpdatedAt))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+var updateSchedule = `
+	UPDATE
+		Webinar
+	SET
+		start_at = $2, end_at = $3, recurrence = $4, sequence = sequence + 1, updated_at = $5
+	WHERE
+		id = $1
+`
+
+var deleteSessions = `
+	DELETE FROM
+		Webinar_Session
+	WHERE
+		webinar_id = $1
+`
+
+var deleteExceptions = `
 	DELETE FROM
+		Webinar_Occurrence
+	WHERE
+		webinar_id = $1 AND recurrence_id >= $2
+`
+
+var shiftRegistrations = `
+	UPDATE
+		Webinar_Registration
+	SET
+		webinar_id = $2, occurrence = occurrence + $4
+	WHERE
+		webinar_id = $1 AND occurrence >= $3
+`
+
+var parkRegistrations = `
+	UPDATE
+		Webinar_Registration
+	SET
+		occurrence = -occurrence
+	WHERE
+		webinar_id = $1 AND occurrence > 0
+`
+
+var unparkRegistrations = `
+	UPDATE
+		Webinar_Registration
+	SET
+		occurrence = -occurrence + $2
+	WHERE
+		webinar_id = $1 AND occurrence < 0
+`
+
+// Reschedule replaces the schedule of a whole series. Exceptions are
+// dropped and registrations follow their occurrence by delta seconds.
+func (r *repository) Reschedule(ctx context.Context, webinar *models.Webinar, delta int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = updateWebinarSchedule(ctx, tx, webinar)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, deleteSessions, webinar.ID)
+	if err != nil {
+		return err
+	}
+
+	err = insertSessions(ctx, tx, webinar)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, deleteExceptions, webinar.ID, 0)
+	if err != nil {
+		return err
+	}
+
+	// Shifting in place could collide with another occurrence of the same
+	// user halfway through the update, so registrations are first parked on
+	// negated occurrences.
+	_, err = tx.ExecContext(ctx, parkRegistrations, webinar.ID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, unparkRegistrations, webinar.ID, delta)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+var copyReactions = `
+	INSERT INTO
+		Webinar_Reaction
+		(webinar_id, user_id, kind, created_at)
+	SELECT
+		$2, user_id, kind, created_at
+	FROM
+		Webinar_Reaction
+	WHERE
+		webinar_id = $1
+`
+
+// Split ends the series of webinar before recurrenceID and continues it as
+// the new webinar following. Exceptions from recurrenceID on are dropped,
+// registrations move to the new series shifted by delta seconds and likes and
+// bookmarks are copied.
+func (r *repository) Split(ctx context.Context, webinar, following *models.Webinar, recurrenceID, delta int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = updateWebinarSchedule(ctx, tx, webinar)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, deleteExceptions, webinar.ID, recurrenceID)
+	if err != nil {
+		return err
+	}
+
+	err = insert(ctx, tx, following)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, shiftRegistrations, webinar.ID, following.ID, recurrenceID, delta)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, copyReactions, webinar.ID, following.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+var lockForUpdate = `
+	SELECT
+		id
+	FROM
 		Webinar
 	WHERE
 		id = $1
+	FOR UPDATE
 `
 
-func (r *repository) Delete(ctx context.Context, webinarID int64) error {
-	_, err := r.db.ExecContext(ctx, delete, webinarID)
+var update = `
+	UPDATE
+		Webinar
+	SET
+		picture = $2, title = $3, description = $4, category = $5, link = $6, timezone = $7, start_at = $8, end_at = $9,
+		capacity = $10, sequence = sequence + 1, updated_at = $11
+	WHERE
+		id = $1
+`
+
+var createChange = `
+	INSERT INTO
+		Webinar_Change
+		(webinar_id, user_id, changes, created_at)
+	VALUES
+		($1, NULLIF($2, 0), $3, $4)
+	RETURNING
+		id
+`
+
+var countSeats = `
+	SELECT
+		occurrence,
+		COUNT(*) FILTER (WHERE status = 'registered'),
+		COUNT(*) FILTER (WHERE status = 'waitlisted')
+	FROM
+		Webinar_Registration
+	WHERE
+		webinar_id = $1
+	GROUP BY
+		occurrence
+`
+
+var promoteWaitlisted = `
+	WITH promoted AS (
+		UPDATE
+			Webinar_Registration
+		SET
+			status = 'registered', updated_at = $4
+		WHERE
+			id IN (
+				SELECT
+					id
+				FROM
+					Webinar_Registration
+				WHERE
+					webinar_id = $1 AND occurrence = $2 AND status = 'waitlisted'
+				ORDER BY
+					created_at ASC, id ASC
+				LIMIT
+					$3
+			)
+		RETURNING
+			id, webinar_id, occurrence, user_id, status, created_at, updated_at
+	)
+	SELECT
+		p.id, p.webinar_id, p.occurrence, au.id, au.name, au.email, au.picture, p.status, p.created_at, p.updated_at
+	FROM
+		promoted p
+	JOIN
+		App_User au
+	ON
+		p.user_id = au.id
+`
+
+// Update saves the edited fields of the webinar, replacing its sessions when
+// asked to, and records the change. The webinar row is locked as registrations
+// lock it, so that a new capacity is seen by every registration after it.
+// Seats freed by the new capacity go to the waitlist of each occurrence, whose
+// promoted registrations are returned.
+func (r *repository) Update(ctx context.Context, webinar *models.Webinar, change *models.WebinarChange, sessions bool) ([]models.WebinarRegistration, error) {
+	changes, err := json.Marshal(change.Changes)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRowContext(ctx, lockForUpdate, webinar.ID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, app.NewError(err, app.ENotFound)
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = mustAffect(tx.ExecContext(
+		ctx,
+		update,
+		webinar.ID,
+		webinar.Picture,
+		webinar.Title,
+		webinar.Description,
+		webinar.Category,
+		webinar.Link,
+		webinar.Timezone,
+		webinar.StartAt,
+		webinar.EndAt,
+		webinar.Capacity,
+		webinar.UpdatedAt,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	if sessions {
+		_, err = tx.ExecContext(ctx, deleteSessions, webinar.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		err = insertSessions(ctx, tx, webinar)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	change.WebinarID = webinar.ID
+	err = tx.QueryRowContext(ctx, createChange, change.WebinarID, change.User.ID, changes, change.CreatedAt).Scan(&change.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	promoted, err := promoteSeats(ctx, tx, webinar)
+	if err != nil {
+		return nil, err
+	}
+
+	return promoted, tx.Commit()
+}
+
+// promoteSeats moves as many of the waitlist of every occurrence to the
+// seats left under the capacity of the webinar, first come first served.
+func promoteSeats(ctx context.Context, tx *sql.Tx, webinar *models.Webinar) ([]models.WebinarRegistration, error) {
+	rows, err := tx.QueryContext(ctx, countSeats, webinar.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	seats := make(map[int64]int64)
+	for rows.Next() {
+		var occurrence, registered, waitlisted int64
+
+		err := rows.Scan(&occurrence, &registered, &waitlisted)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		if n := seatsToPromote(webinar.Capacity, registered, waitlisted); n > 0 {
+			seats[occurrence] = n
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	promoted := make([]models.WebinarRegistration, 0)
+	for occurrence, n := range seats {
+		rows, err := tx.QueryContext(ctx, promoteWaitlisted, webinar.ID, occurrence, n, webinar.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var registration models.WebinarRegistration
+
+			err := rows.Scan(
+				&registration.ID,
+				&registration.WebinarID,
+				&registration.Occurrence,
+				&registration.User.ID,
+				&registration.User.Name,
+				&registration.User.Email,
+				&registration.User.Picture,
+				&registration.Status,
+				&registration.CreatedAt,
+				&registration.UpdatedAt,
+			)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+
+			promoted = append(promoted, registration)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return promoted, nil
+}
+
+// seatsToPromote is how many of the waitlist get a seat: all of it when the
+// capacity is unlimited, else up to the seats left. A capacity lowered below
+// the registered count keeps the registered seats and promotes no one, so
+// the waitlist waits until enough seats are freed.
+func seatsToPromote(capacity, registered, waitlisted int64) int64 {
+	if capacity == 0 {
+		return waitlisted
+	}
+
+	free := capacity - registered
+	if free <= 0 {
+		return 0
+	}
+	if free > waitlisted {
+		return waitlisted
+	}
+
+	return free
+}
+
+var findChanges = `
+	SELECT
+		wc.id, wc.webinar_id, COALESCE(au.id, 0), COALESCE(au.name, ''), COALESCE(au.picture, ''), wc.changes, wc.created_at
+	FROM
+		Webinar_Change wc
+	LEFT JOIN
+		App_User au
+	ON
+		wc.user_id = au.id
+	WHERE
+		wc.webinar_id = $1
+	ORDER BY
+		wc.created_at DESC, wc.id DESC
+	LIMIT
+		$2
+`
+
+// FindChanges returns the newest changes of the webinar first.
+func (r *repository) FindChanges(ctx context.Context, webinarID, limit int64) ([]models.WebinarChange, error) {
+	changes := make([]models.WebinarChange, 0)
+
+	rows, err := r.db.QueryContext(ctx, findChanges, webinarID, limit)
+	if err != nil {
+		return changes, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var change models.WebinarChange
+		var fields []byte
+
+		err := rows.Scan(
+			&change.ID,
+			&change.WebinarID,
+			&change.User.ID,
+			&change.User.Name,
+			&change.User.Picture,
+			&fields,
+			&change.CreatedAt,
+		)
+		if err != nil {
+			return changes, err
+		}
+
+		err = json.Unmarshal(fields, &change.Changes)
+		if err != nil {
+			return changes, err
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
+
+var findFollowers = `
+	SELECT
+		au.id, au.name, au.email, au.picture
+	FROM
+		App_User au
+	WHERE
+		au.id IN (
+			SELECT user_id FROM Webinar_Registration WHERE webinar_id = $1
+			UNION
+			SELECT user_id FROM Webinar_Reaction WHERE webinar_id = $1 AND kind = 'bookmark'
+		)
+`
+
+// FindFollowers returns the users who registered for or bookmarked the webinar.
+func (r *repository) FindFollowers(ctx context.Context, webinarID int64) ([]models.User, error) {
+	users := make([]models.User, 0)
+
+	rows, err := r.db.QueryContext(ctx, findFollowers, webinarID)
+	if err != nil {
+		return users, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user models.User
+
+		err := rows.Scan(
+			&user.ID,
+			&user.Name,
+			&user.Email,
+			&user.Picture,
+		)
+		if err != nil {
+			return users, err
+		}
+
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+var cancel = `
+	UPDATE
+		Webinar
+	SET
+		cancelled_at = $2, sequence = sequence + 1, updated_at = $2
+	WHERE
+		id = $1 AND cancelled_at = 0
+`
+
+func (r *repository) Cancel(ctx context.Context, webinarID, cancelledAt int64) error {
+	return mustAffect(r.db.ExecContext(ctx, cancel, webinarID, cancelledAt))
+}
+
+var createSpeaker = `
+	INSERT INTO
+		Webinar_Speaker
+		(webinar_id, user_id, name, bio, photo, position)
+	VALUES
+		($1, NULLIF($2, 0), $3, $4, $5, $6)
+	RETURNING
+		id
+`
+
+var deleteSpeakers = `
+	DELETE FROM
+		Webinar_Speaker
+	WHERE
+		webinar_id = $1
+`
+
+func insertSpeakers(ctx context.Context, tx *sql.Tx, webinarID int64, speakers []models.WebinarSpeaker) error {
+	for i := range speakers {
+		speaker := &speakers[i]
+		speaker.WebinarID = webinarID
+		speaker.Position = int64(i)
+
+		err := tx.QueryRowContext(
+			ctx,
+			createSpeaker,
+			speaker.WebinarID,
+			speaker.User.ID,
+			speaker.Name,
+			speaker.Bio,
+			speaker.Photo,
+			speaker.Position,
+		).Scan(&speaker.ID)
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return app.NewError(err, app.EBadRequest, "Speaker user not found")
+		} else if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReplaceSpeakers sets the webinar's speakers in the given order.
+func (r *repository) ReplaceSpeakers(ctx context.Context, webinarID int64, speakers []models.WebinarSpeaker) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, deleteSpeakers, webinarID)
+	if err != nil {
+		return err
+	}
+
+	err = insertSpeakers(ctx, tx, webinarID, speakers)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+var addCohost = `
+	INSERT INTO
+		Webinar_Cohost
+		(webinar_id, user_id, created_at)
+	VALUES
+		($1, $2, $3)
+`
+
+func (r *repository) AddCohost(ctx context.Context, webinarID, userID, createdAt int64) error {
+	_, err := r.db.ExecContext(ctx, addCohost, webinarID, userID, createdAt)
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code {
+		case "23505":
+			return app.NewError(err, app.Econflict, "User is already a co-host")
+		case "23503":
+			return app.NewError(err, app.ENotFound, "User not found")
+		}
+	}
 
 	return err
 }
+
+var deleteCohost = `
+	DELETE FROM
+		Webinar_Cohost
+	WHERE
+		webinar_id = $1 AND user_id = $2
+`
+
+func (r *repository) DeleteCohost(ctx context.Context, webinarID, userID int64) error {
+	return mustAffect(r.db.ExecContext(ctx, deleteCohost, webinarID, userID))
+}
+
+var saveCheckIn = `
+	UPDATE
+		Webinar
+	SET
+		check_in_code = $2, check_in_token = $3, check_in_occurrence = $4
+	WHERE
+		id = $1 AND (check_in_code = '' OR check_in_occurrence <> $4)
+`
+
+// SaveCheckIn stores the check-in code and token of the occurrence, unless
+// the webinar already has them, so concurrent callers keep the first ones.
+func (r *repository) SaveCheckIn(ctx context.Context, webinarID, occurrence int64, code, token string) error {
+	_, err := r.db.ExecContext(ctx, saveCheckIn, webinarID, code, token, occurrence)
+
+	return err
+}
+
+func updateWebinarSchedule(ctx context.Context, tx *sql.Tx, webinar *models.Webinar) error {
+	return mustAffect(tx.ExecContext(
+		ctx,
+		updateSchedule,
+		webinar.ID,
+		webinar.StartAt,
+		webinar.EndAt,
+		webinar.Recurrence,
+		webinar.UpdatedAt,
+	))
+}
+
+func mustAffect(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return app.NewError(sql.ErrNoRows, app.ENotFound)
+	}
+
+	return nil
+}
+
+var delete = `
+	DELETE FROM
+		Webinar
+	WHERE
+		id = $1
+`
+
+func (r *repository) Delete(ctx context.Context, webinarID int64) error {
+	return mustAffect(r.db.ExecContext(ctx, delete, webinarID))
+}
@@ -2,27 +2,82 @@ package webinar
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	"github.com/bagus2x/recovy/app"
 	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/queue"
+	"github.com/bagus2x/recovy/reaction"
+	"github.com/bagus2x/recovy/taxonomy"
+	"github.com/bagus2x/recovy/webinarattendance"
+	"github.com/bagus2x/recovy/webinarregistration"
+	"github.com/bagus2x/recovy/webinarreminder"
 )
 
 type Service interface {
 	Create(ctx context.Context, req *CreateWebinarReq) (CreateWebinarResp, error)
-	GetByID(ctx context.Context, webinarID int64) (GetWebinarResp, error)
-	GetByCategory(ctx context.Context, category string) ([]GetWebinarResp, error)
-	Get(ctx context.Context) ([]GetWebinarResp, error)
+	GetByID(ctx context.Context, webinarID int64, timezone string) (GetWebinarResp, error)
+	GetByCategory(ctx context.Context, category string, params *Params, timezone string) (GetWebinarsResp, error)
+	GetByParams(ctx context.Context, params *Params, timezone string) (GetWebinarsResp, error)
+	GetOccurrences(ctx context.Context, req *OccurrencesReq, timezone string) ([]WebinarOccurrence, error)
+	Update(ctx context.Context, req *UpdateWebinarReq) (GetWebinarResp, error)
+	UpdateOccurrence(ctx context.Context, req *UpdateOccurrenceReq) (GetWebinarResp, error)
 	Delete(ctx context.Context, webinarID, authorID int64) error
+	Cancel(ctx context.Context, webinarID, authorID int64) error
+	Register(ctx context.Context, req *RegisterReq) (RegisterResp, error)
+	CancelRegistration(ctx context.Context, webinarID, occurrence, userID int64) error
+	GetRegistrants(ctx context.Context, webinarID, authorID int64) ([]Registrant, error)
+	RemindMe(ctx context.Context, req *RemindReq) error
+	CancelReminder(ctx context.Context, req *RemindReq) error
+	HandleReminder(ctx context.Context, job models.Job) error
+	HandleChange(ctx context.Context, job models.Job) error
+	ReplaceSpeakers(ctx context.Context, req *ReplaceSpeakersReq) ([]Speaker, error)
+	AddCohost(ctx context.Context, req *CohostReq) error
+	RemoveCohost(ctx context.Context, req *CohostReq) error
+	GetCheckIn(ctx context.Context, webinarID, userID int64) (CheckIn, error)
+	CheckIn(ctx context.Context, req *CheckInReq) (Attendee, error)
+	GetAttendance(ctx context.Context, webinarID, userID int64) ([]Attendee, error)
+	GetCertificate(ctx context.Context, webinarID, occurrence, attendeeID, userID int64) ([]byte, error)
 }
 
+const (
+	// upcomingRange and maxUpcoming bound the occurrences listed with a
+	// recurring webinar.
+	upcomingRange = 366 * 24 * 60 * 60
+	maxUpcoming   = 10
+)
+
 type service struct {
-	webinarRepo Repository
+	webinarRepo      Repository
+	registrationRepo webinarregistration.Repository
+	reminderRepo     webinarreminder.Repository
+	attendanceRepo   webinarattendance.Repository
+	jobRepo          queue.Repository
+	notifier         Notifier
+	taxonomyService  taxonomy.Service
+	reactionService  reaction.Service
 }
 
-func NewService(webinarRepo Repository) Service {
+func NewService(
+	webinarRepo Repository,
+	registrationRepo webinarregistration.Repository,
+	reminderRepo webinarreminder.Repository,
+	attendanceRepo webinarattendance.Repository,
+	jobRepo queue.Repository,
+	notifier Notifier,
+	taxonomyService taxonomy.Service,
+	reactionService reaction.Service,
+) Service {
 	return &service{
-		webinarRepo: webinarRepo,
+		webinarRepo:      webinarRepo,
+		registrationRepo: registrationRepo,
+		reminderRepo:     reminderRepo,
+		attendanceRepo:   attendanceRepo,
+		jobRepo:          jobRepo,
+		notifier:         notifier,
+		taxonomyService:  taxonomyService,
+		reactionService:  reactionService,
 	}
 }
 
@@ -32,6 +87,11 @@ func (s *service) Create(ctx context.Context, req *CreateWebinarReq) (CreateWebi
 		return CreateWebinarResp{}, err
 	}
 
+	terms, err := s.taxonomyService.Resolve(ctx, append([]string{req.Category}, req.Categories...), req.Tags)
+	if err != nil {
+		return CreateWebinarResp{}, err
+	}
+
 	webinar := models.Webinar{
 		Author: models.User{
 			ID: req.AuthorID,
@@ -39,19 +99,47 @@ func (s *service) Create(ctx context.Context, req *CreateWebinarReq) (CreateWebi
 		Picture:     req.Picture,
 		Title:       req.Title,
 		Description: req.Description,
-		Category:    req.Category,
-		StartDate:   req.StartDate,
-		LastDate:    req.LastDate,
-		Time:        req.Time,
+		Category:    terms[0].Slug,
+		Link:        req.Link,
+		Timezone:    req.Timezone,
+		Recurrence:  req.Recurrence,
+		Speakers:    toSpeakerModels(req.Speakers),
+		Capacity:    req.Capacity,
 		CreatedAt:   time.Now().Unix(),
 		UpdatedAt:   time.Now().Unix(),
 	}
+	setSessions(&webinar, req.Sessions)
+
+	if rule, ok := recurrence(webinar); ok {
+		webinar.EndAt = seriesEnd(rule, dtstart(webinar), duration(webinar))
+		webinar.Exceptions = make([]models.WebinarOccurrence, 0, len(req.Exdates))
+		for _, exdate := range req.Exdates {
+			webinar.Exceptions = append(webinar.Exceptions, models.WebinarOccurrence{
+				RecurrenceID: exdate,
+				StartAt:      exdate,
+				EndAt:        exdate + duration(webinar),
+				Cancelled:    true,
+				UpdatedAt:    webinar.UpdatedAt,
+			})
+		}
+	}
 
 	err = s.webinarRepo.Create(ctx, &webinar)
 	if err != nil {
 		return CreateWebinarResp{}, err
 	}
 
+	err = s.taxonomyService.Link(ctx, models.ContentWebinar, webinar.ID, terms)
+	if err != nil {
+		return CreateWebinarResp{}, err
+	}
+
+	err = s.scheduleReminders(ctx, webinar)
+	if err != nil {
+		return CreateWebinarResp{}, err
+	}
+	categories, tags := taxonomy.Split(terms)
+
 	res := CreateWebinarResp{
 		ID:          webinar.ID,
 		AuthorID:    webinar.Author.ID,
@@ -59,17 +147,33 @@ func (s *service) Create(ctx context.Context, req *CreateWebinarReq) (CreateWebi
 		Title:       webinar.Title,
 		Description: webinar.Description,
 		Category:    webinar.Category,
-		StartDate:   webinar.StartDate,
-		LastDate:    webinar.LastDate,
-		Time:        webinar.Time,
+		Categories:  categories,
+		Tags:        tags,
+		Link:        webinar.Link,
+		StartAt:     webinar.StartAt,
+		EndAt:       webinar.EndAt,
+		Timezone:    webinar.Timezone,
+		Sessions:    toSessions(webinar.Sessions, location(webinar.Timezone)),
+		Recurrence:  webinar.Recurrence,
+		Exdates:     exdates(webinar),
+		Speakers:    toSpeakers(webinar.Speakers),
+		Capacity:    webinar.Capacity,
 		CreatedAt:   webinar.CreatedAt,
 		UpdatedAt:   webinar.UpdatedAt,
 	}
+	if webinar.EndAt == openEnded {
+		res.EndAt = 0
+	}
 
 	return res, nil
 }
 
-func (s *service) GetByID(ctx context.Context, webinarID int64) (GetWebinarResp, error) {
+func (s *service) GetByID(ctx context.Context, webinarID int64, timezone string) (GetWebinarResp, error) {
+	loc, err := displayLocation(timezone)
+	if err != nil {
+		return GetWebinarResp{}, err
+	}
+
 	webinar, err := s.webinarRepo.FindByID(ctx, webinarID)
 	if app.ErrorCode(err) == app.ENotFound {
 		return GetWebinarResp{}, app.NewError(err, app.ENotFound, "Webinar not found")
@@ -77,87 +181,331 @@ func (s *service) GetByID(ctx context.Context, webinarID int64) (GetWebinarResp,
 		return GetWebinarResp{}, err
 	}
 
-	resp := GetWebinarResp{
-		ID: webinar.ID,
-		Author: Author{
-			ID:      webinar.Author.ID,
-			Name:    webinar.Author.Name,
-			Picture: webinar.Author.Picture,
-		},
-		Picture:     webinar.Picture,
-		Title:       webinar.Title,
-		Description: webinar.Description,
-		Category:    webinar.Category,
-		StartDate:   webinar.StartDate,
-		LastDate:    webinar.LastDate,
-		Time:        webinar.Time,
-		CreatedAt:   webinar.CreatedAt,
-		UpdatedAt:   webinar.UpdatedAt,
+	resp := toWebinarResp(webinar, loc)
+
+	terms, err := s.taxonomyService.Terms(ctx, models.ContentWebinar, webinar.ID)
+	if err != nil {
+		return GetWebinarResp{}, err
+	}
+	resp.Categories, resp.Tags = taxonomy.Split(terms[webinar.ID])
+
+	userID, ok := ctx.Value("userID").(int64)
+
+	summaries, err := s.reactionService.Summaries(ctx, models.ContentWebinar, userID, webinar.ID)
+	if err != nil {
+		return GetWebinarResp{}, err
+	}
+	resp.Summary = summaries[webinar.ID]
+
+	statuses := make(map[int64]string)
+	if ok {
+		registrations, err := s.registrationRepo.FindByWebinarIDAndUserID(ctx, webinarID, userID)
+		if err != nil {
+			return GetWebinarResp{}, err
+		}
+		for _, registration := range registrations {
+			statuses[registration.Occurrence] = registration.Status
+		}
+		resp.RegistrationStatus = statuses[0]
+
+		reminder, err := s.reminderRepo.FindByWebinarIDAndUserID(ctx, webinarID, userID)
+		if err != nil && app.ErrorCode(err) != app.ENotFound {
+			return GetWebinarResp{}, err
+		}
+		resp.RemindMe = reminder.WebinarID != 0
+
+		if webinar.IsHost(userID) || len(registrations) > 0 {
+			resp.Link = webinar.Link
+		}
+	}
+
+	changes, err := s.webinarRepo.FindChanges(ctx, webinarID, maxHistory)
+	if err != nil {
+		return GetWebinarResp{}, err
+	}
+	resp.History = toChanges(changes)
+
+	if _, ok := recurrence(webinar); ok {
+		counts, err := s.registrationRepo.CountByWebinarIDs(ctx, []int64{webinar.ID})
+		if err != nil {
+			return GetWebinarResp{}, err
+		}
+
+		now := time.Now().Unix()
+		upcoming := expand(webinar, now, now+upcomingRange)
+		if len(upcoming) > maxUpcoming {
+			upcoming = upcoming[:maxUpcoming]
+		}
+
+		resp.Occurrences = make([]Occurrence, 0, len(upcoming))
+		for _, o := range upcoming {
+			occurrence := toOccurrence(webinar, o, counts[webinar.ID][o.RecurrenceID], resp.DisplayTimezone)
+			occurrence.RegistrationStatus = statuses[o.RecurrenceID]
+			resp.Occurrences = append(resp.Occurrences, occurrence)
+		}
 	}
 
 	return resp, nil
 }
 
-func (s *service) GetByCategory(ctx context.Context, category string) ([]GetWebinarResp, error) {
-	webinars, err := s.webinarRepo.FindByCategory(ctx, category)
+func (s *service) GetByCategory(ctx context.Context, category string, params *Params, timezone string) (GetWebinarsResp, error) {
+	params.Category = category
+
+	return s.GetByParams(ctx, params, timezone)
+}
+
+func (s *service) GetByParams(ctx context.Context, params *Params, timezone string) (GetWebinarsResp, error) {
+	loc, err := displayLocation(timezone)
 	if err != nil {
-		return make([]GetWebinarResp, 0), err
+		return GetWebinarsResp{}, err
 	}
 
-	resp := make([]GetWebinarResp, 0)
+	webinars, cursor, err := s.webinarRepo.Find(ctx, params)
+	if err != nil {
+		return GetWebinarsResp{}, err
+	}
+
+	resp := GetWebinarsResp{
+		Cursor:   cursor,
+		Webinars: make([]GetWebinarResp, 0),
+	}
 	for _, webinar := range webinars {
-		resp = append(resp, GetWebinarResp{
-			ID: webinar.ID,
-			Author: Author{
-				ID:      webinar.Author.ID,
-				Name:    webinar.Author.Name,
-				Picture: webinar.Author.Picture,
-			},
-			Picture:     webinar.Picture,
-			Title:       webinar.Title,
-			Description: webinar.Description,
-			Category:    webinar.Category,
-			StartDate:   webinar.StartDate,
-			LastDate:    webinar.LastDate,
-			Time:        webinar.Time,
-			CreatedAt:   webinar.CreatedAt,
-			UpdatedAt:   webinar.UpdatedAt,
-		})
+		resp.Webinars = append(resp.Webinars, toWebinarResp(webinar, loc))
+	}
+
+	err = s.addTerms(ctx, resp.Webinars)
+	if err != nil {
+		return GetWebinarsResp{}, err
+	}
+
+	err = s.addSummaries(ctx, resp.Webinars)
+	if err != nil {
+		return GetWebinarsResp{}, err
 	}
 
 	return resp, nil
 }
 
-func (s *service) Get(ctx context.Context) ([]GetWebinarResp, error) {
-	webinars, err := s.webinarRepo.Find(ctx)
+// GetOccurrences expands the occurrences of every webinar in the range.
+func (s *service) GetOccurrences(ctx context.Context, req *OccurrencesReq, timezone string) ([]WebinarOccurrence, error) {
+	err := req.Validate()
+	if err != nil {
+		return make([]WebinarOccurrence, 0), err
+	}
+
+	loc, err := displayLocation(timezone)
+	if err != nil {
+		return make([]WebinarOccurrence, 0), err
+	}
+
+	webinars, err := s.webinarRepo.FindBetween(ctx, req.From, req.To)
 	if err != nil {
-		return make([]GetWebinarResp, 0), err
+		return make([]WebinarOccurrence, 0), err
 	}
 
-	resp := make([]GetWebinarResp, 0)
+	ids := make([]int64, 0, len(webinars))
 	for _, webinar := range webinars {
-		resp = append(resp, GetWebinarResp{
-			ID: webinar.ID,
-			Author: Author{
-				ID:      webinar.Author.ID,
-				Name:    webinar.Author.Name,
-				Picture: webinar.Author.Picture,
-			},
-			Picture:     webinar.Picture,
-			Title:       webinar.Title,
-			Description: webinar.Description,
-			Category:    webinar.Category,
-			StartDate:   webinar.StartDate,
-			LastDate:    webinar.LastDate,
-			Time:        webinar.Time,
-			CreatedAt:   webinar.CreatedAt,
-			UpdatedAt:   webinar.UpdatedAt,
-		})
+		ids = append(ids, webinar.ID)
 	}
 
+	counts, err := s.registrationRepo.CountByWebinarIDs(ctx, ids)
+	if err != nil {
+		return make([]WebinarOccurrence, 0), err
+	}
+
+	resp := make([]WebinarOccurrence, 0)
+	for _, webinar := range webinars {
+		display := webinar.Timezone
+		if loc != nil {
+			display = loc.String()
+		}
+
+		for _, o := range expand(webinar, req.From, req.To) {
+			resp = append(resp, WebinarOccurrence{
+				WebinarID: webinar.ID,
+				Author: Author{
+					ID:      webinar.Author.ID,
+					Name:    webinar.Author.Name,
+					Picture: webinar.Author.Picture,
+				},
+				Picture:    webinar.Picture,
+				Title:      webinar.Title,
+				Category:   webinar.Category,
+				Timezone:   webinar.Timezone,
+				Occurrence: toOccurrence(webinar, o, counts[webinar.ID][o.RecurrenceID], display),
+			})
+		}
+	}
+
+	sort.SliceStable(resp, func(i, j int) bool {
+		return resp[i].StartAt < resp[j].StartAt
+	})
+
 	return resp, nil
 }
 
+// UpdateOccurrence moves or cancels a single occurrence, or reschedules an
+// occurrence and all following ones. The latter ends the series before the
+// occurrence and continues it as a new webinar, unless the occurrence is the
+// first one. Registrations follow their occurrence; those that no longer fall
+// on an occurrence of a changed recurrence are cancelled.
+func (s *service) UpdateOccurrence(ctx context.Context, req *UpdateOccurrenceReq) (GetWebinarResp, error) {
+	err := req.Validate()
+	if err != nil {
+		return GetWebinarResp{}, err
+	}
+
+	webinar, err := s.webinarRepo.FindByID(ctx, req.WebinarID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return GetWebinarResp{}, app.NewError(err, app.ENotFound, "Webinar not found")
+	} else if err != nil {
+		return GetWebinarResp{}, err
+	}
+
+	if !webinar.IsHost(req.AuthorID) {
+		return GetWebinarResp{}, app.NewError(nil, app.EForbidden, "Forbidden access, webinar not found")
+	}
+
+	rule, ok := recurrence(webinar)
+	if !ok {
+		return GetWebinarResp{}, app.NewError(nil, app.EBadRequest, "Webinar does not recur")
+	}
+
+	o, ok := findOccurrence(webinar, req.RecurrenceID)
+	if !ok {
+		return GetWebinarResp{}, app.NewError(nil, app.ENotFound, "Occurrence not found")
+	}
+
+	if req.Scope == ScopeThis {
+		exception := models.WebinarOccurrence{
+			WebinarID:    webinar.ID,
+			RecurrenceID: o.RecurrenceID,
+			StartAt:      req.StartAt,
+			EndAt:        req.EndAt,
+			Cancelled:    req.Cancelled,
+			UpdatedAt:    time.Now().Unix(),
+		}
+		if req.Cancelled {
+			exception.StartAt, exception.EndAt = o.StartAt, o.EndAt
+		}
+
+		err = s.webinarRepo.SaveException(ctx, &exception)
+		if err != nil {
+			return GetWebinarResp{}, err
+		}
+
+		err = s.rescheduleReminders(ctx, webinar.ID)
+		if err != nil {
+			return GetWebinarResp{}, err
+		}
+
+		return s.GetByID(ctx, webinar.ID, "")
+	}
+
+	next := rule
+	if req.Recurrence != "" {
+		next, err = validateRecurrence(req.Recurrence, webinar.Timezone, req.StartAt)
+		if err != nil {
+			return GetWebinarResp{}, err
+		}
+	}
+
+	start := dtstart(webinar)
+	before := rule.Between(start, start, time.Unix(o.RecurrenceID, 0))
+	if req.Recurrence == "" && rule.Count > 0 {
+		next.Count = rule.Count - len(before)
+	}
+
+	following := webinar
+	following.StartAt = req.StartAt
+	following.Recurrence = next.String()
+	following.Exceptions = nil
+	following.UpdatedAt = time.Now().Unix()
+	setSessions(&following, []SessionReq{{StartAt: req.StartAt, EndAt: req.EndAt}})
+
+	followingStart := dtstart(following)
+	if !next.Includes(followingStart, followingStart) {
+		return GetWebinarResp{}, app.NewError(nil, app.EBadRequest, "Start must be an occurrence of the recurrence")
+	}
+	following.EndAt = seriesEnd(next, followingStart, duration(following))
+
+	delta := req.StartAt - o.RecurrenceID
+	if len(before) == 0 {
+		err = s.webinarRepo.Reschedule(ctx, &following, delta)
+	} else {
+		if rule.Count > 0 {
+			rule.Count = len(before)
+		} else {
+			rule.Until = time.Unix(o.RecurrenceID-1, 0).UTC()
+		}
+		webinar.Recurrence = rule.String()
+		webinar.EndAt = before[len(before)-1].Unix() + duration(webinar)
+		webinar.UpdatedAt = following.UpdatedAt
+
+		following.ID = 0
+		following.Sequence = 0
+		following.CreatedAt = following.UpdatedAt
+		err = s.webinarRepo.Split(ctx, &webinar, &following, o.RecurrenceID, delta)
+	}
+	if err != nil {
+		return GetWebinarResp{}, err
+	}
+
+	if req.Recurrence != "" {
+		err = s.cancelOrphans(ctx, following)
+		if err != nil {
+			return GetWebinarResp{}, err
+		}
+	}
+
+	err = s.rescheduleReminders(ctx, webinar.ID, following.ID)
+	if err != nil {
+		return GetWebinarResp{}, err
+	}
+
+	return s.GetByID(ctx, following.ID, "")
+}
+
+// rescheduleReminders reloads the webinars and schedules their reminders
+// for their current schedule.
+func (s *service) rescheduleReminders(ctx context.Context, webinarIDs ...int64) error {
+	for _, webinarID := range webinarIDs {
+		webinar, err := s.webinarRepo.FindByID(ctx, webinarID)
+		if err != nil {
+			return err
+		}
+
+		err = s.scheduleReminders(ctx, webinar)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cancelOrphans cancels the registrations that do not fall on an occurrence
+// of the webinar.
+func (s *service) cancelOrphans(ctx context.Context, webinar models.Webinar) error {
+	registrations, err := s.registrationRepo.FindByWebinarID(ctx, webinar.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, registration := range registrations {
+		if _, ok := findOccurrence(webinar, registration.Occurrence); ok {
+			continue
+		}
+
+		_, err := s.registrationRepo.Cancel(ctx, webinar.ID, registration.Occurrence, registration.User.ID)
+		if err != nil && app.ErrorCode(err) != app.ENotFound {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s *service) Delete(ctx context.Context, webinarID, authorID int64) error {
 	webinar, err := s.webinarRepo.FindByID(ctx, webinarID)
 	if app.ErrorCode(err) == app.ENotFound {
@@ -170,5 +518,508 @@ func (s *service) Delete(ctx context.Context, webinarID, authorID int64) error {
 		return app.NewError(nil, app.EForbidden, "Forbidden access, webinar not found")
 	}
 
-	return s.webinarRepo.Delete(ctx, webinarID)
+	err = s.webinarRepo.Delete(ctx, webinarID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return app.NewError(err, app.ENotFound, "Webinar not found")
+	} else if err != nil {
+		return err
+	}
+
+	return s.jobRepo.CancelByReference(ctx, reminderReference(webinarID))
+}
+
+// Cancel marks the webinar as cancelled. Registrations are kept so that
+// attendees can still see what was cancelled.
+func (s *service) Cancel(ctx context.Context, webinarID, authorID int64) error {
+	webinar, err := s.webinarRepo.FindByID(ctx, webinarID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return app.NewError(err, app.ENotFound, "Webinar not found")
+	} else if err != nil {
+		return err
+	}
+
+	if !webinar.IsHost(authorID) {
+		return app.NewError(nil, app.EForbidden, "Forbidden access, webinar not found")
+	}
+
+	switch status(webinar, time.Now().Unix()) {
+	case StatusCancelled:
+		return app.NewError(nil, app.Econflict, "Webinar has been cancelled")
+	case StatusEnded:
+		return app.NewError(nil, app.EBadRequest, "Webinar has ended")
+	}
+
+	err = s.webinarRepo.Cancel(ctx, webinarID, time.Now().Unix())
+	if app.ErrorCode(err) == app.ENotFound {
+		return app.NewError(err, app.Econflict, "Webinar has been cancelled")
+	} else if err != nil {
+		return err
+	}
+
+	return s.jobRepo.CancelByReference(ctx, reminderReference(webinarID))
+}
+
+func (s *service) Register(ctx context.Context, req *RegisterReq) (RegisterResp, error) {
+	err := req.Validate()
+	if err != nil {
+		return RegisterResp{}, err
+	}
+
+	webinar, err := s.webinarRepo.FindByID(ctx, req.WebinarID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return RegisterResp{}, app.NewError(err, app.ENotFound, "Webinar not found")
+	} else if err != nil {
+		return RegisterResp{}, err
+	}
+
+	switch status(webinar, time.Now().Unix()) {
+	case StatusCancelled:
+		return RegisterResp{}, app.NewError(nil, app.EBadRequest, "Webinar has been cancelled")
+	case StatusEnded:
+		return RegisterResp{}, app.NewError(nil, app.EBadRequest, "Webinar has ended")
+	}
+
+	// Users register for a single occurrence of a recurring webinar.
+	if _, ok := recurrence(webinar); ok {
+		o, ok := findOccurrence(webinar, req.Occurrence)
+		if !ok {
+			return RegisterResp{}, app.NewError(nil, app.ENotFound, "Occurrence not found")
+		}
+		if o.Cancelled {
+			return RegisterResp{}, app.NewError(nil, app.EBadRequest, "Occurrence has been cancelled")
+		}
+	} else if req.Occurrence != 0 {
+		return RegisterResp{}, app.NewError(nil, app.EBadRequest, "Webinar does not recur")
+	}
+
+	registration := models.WebinarRegistration{
+		WebinarID:  req.WebinarID,
+		Occurrence: req.Occurrence,
+		User:       models.User{ID: req.UserID},
+		CreatedAt:  time.Now().Unix(),
+		UpdatedAt:  time.Now().Unix(),
+	}
+
+	err = s.registrationRepo.Register(ctx, &registration)
+	if app.ErrorCode(err) == app.ENotFound {
+		return RegisterResp{}, app.NewError(err, app.ENotFound, "Webinar not found")
+	} else if err != nil {
+		return RegisterResp{}, err
+	}
+
+	res := RegisterResp{
+		WebinarID:  registration.WebinarID,
+		Occurrence: registration.Occurrence,
+		UserID:     registration.User.ID,
+		Status:     registration.Status,
+		CreatedAt:  registration.CreatedAt,
+	}
+
+	return res, nil
+}
+
+func (s *service) CancelRegistration(ctx context.Context, webinarID, occurrence, userID int64) error {
+	_, err := s.registrationRepo.Cancel(ctx, webinarID, occurrence, userID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return app.NewError(err, app.ENotFound, "Registration not found")
+	}
+
+	return err
+}
+
+func (s *service) GetRegistrants(ctx context.Context, webinarID, authorID int64) ([]Registrant, error) {
+	webinar, err := s.webinarRepo.FindByID(ctx, webinarID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return make([]Registrant, 0), app.NewError(err, app.ENotFound, "Webinar not found")
+	} else if err != nil {
+		return make([]Registrant, 0), err
+	}
+
+	if !webinar.IsHost(authorID) {
+		return make([]Registrant, 0), app.NewError(nil, app.EForbidden, "Forbidden access, webinar not found")
+	}
+
+	registrations, err := s.registrationRepo.FindByWebinarID(ctx, webinarID)
+	if err != nil {
+		return make([]Registrant, 0), err
+	}
+
+	resp := make([]Registrant, 0)
+	for _, registration := range registrations {
+		resp = append(resp, Registrant{
+			ID:           registration.User.ID,
+			Name:         registration.User.Name,
+			Picture:      registration.User.Picture,
+			Occurrence:   registration.Occurrence,
+			Status:       registration.Status,
+			RegisteredAt: registration.CreatedAt,
+		})
+	}
+
+	return resp, nil
+}
+
+// RemindMe asks for reminders before the webinar, or before each occurrence
+// of a recurring one, starts.
+func (s *service) RemindMe(ctx context.Context, req *RemindReq) error {
+	err := req.Validate()
+	if err != nil {
+		return err
+	}
+
+	webinar, err := s.webinarRepo.FindByID(ctx, req.WebinarID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return app.NewError(err, app.ENotFound, "Webinar not found")
+	} else if err != nil {
+		return err
+	}
+
+	switch status(webinar, time.Now().Unix()) {
+	case StatusCancelled:
+		return app.NewError(nil, app.EBadRequest, "Webinar has been cancelled")
+	case StatusEnded:
+		return app.NewError(nil, app.EBadRequest, "Webinar has ended")
+	}
+
+	return s.reminderRepo.Create(ctx, &models.WebinarReminder{
+		WebinarID: req.WebinarID,
+		User:      models.User{ID: req.UserID},
+		CreatedAt: time.Now().Unix(),
+	})
+}
+
+func (s *service) CancelReminder(ctx context.Context, req *RemindReq) error {
+	err := req.Validate()
+	if err != nil {
+		return err
+	}
+
+	return s.reminderRepo.Delete(ctx, req.WebinarID, req.UserID)
+}
+
+// ReplaceSpeakers sets the speakers of the webinar in the given order.
+func (s *service) ReplaceSpeakers(ctx context.Context, req *ReplaceSpeakersReq) ([]Speaker, error) {
+	err := req.Validate()
+	if err != nil {
+		return make([]Speaker, 0), err
+	}
+
+	webinar, err := s.webinarRepo.FindByID(ctx, req.WebinarID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return make([]Speaker, 0), app.NewError(err, app.ENotFound, "Webinar not found")
+	} else if err != nil {
+		return make([]Speaker, 0), err
+	}
+
+	if !webinar.IsHost(req.AuthorID) {
+		return make([]Speaker, 0), app.NewError(nil, app.EForbidden, "Forbidden access, webinar not found")
+	}
+
+	err = s.webinarRepo.ReplaceSpeakers(ctx, webinar.ID, toSpeakerModels(req.Speakers))
+	if err != nil {
+		return make([]Speaker, 0), err
+	}
+
+	// Reload to resolve the names and photos of linked users.
+	webinar, err = s.webinarRepo.FindByID(ctx, webinar.ID)
+	if err != nil {
+		return make([]Speaker, 0), err
+	}
+
+	return toSpeakers(webinar.Speakers), nil
+}
+
+// AddCohost lets another user edit the webinar. Only the author manages
+// co-hosts.
+func (s *service) AddCohost(ctx context.Context, req *CohostReq) error {
+	err := req.Validate()
+	if err != nil {
+		return err
+	}
+
+	webinar, err := s.webinarRepo.FindByID(ctx, req.WebinarID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return app.NewError(err, app.ENotFound, "Webinar not found")
+	} else if err != nil {
+		return err
+	}
+
+	if webinar.Author.ID != req.AuthorID {
+		return app.NewError(nil, app.EForbidden, "Forbidden access, webinar not found")
+	}
+
+	return s.webinarRepo.AddCohost(ctx, webinar.ID, req.UserID, time.Now().Unix())
+}
+
+func (s *service) RemoveCohost(ctx context.Context, req *CohostReq) error {
+	err := req.Validate()
+	if err != nil {
+		return err
+	}
+
+	webinar, err := s.webinarRepo.FindByID(ctx, req.WebinarID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return app.NewError(err, app.ENotFound, "Webinar not found")
+	} else if err != nil {
+		return err
+	}
+
+	if webinar.Author.ID != req.AuthorID {
+		return app.NewError(nil, app.EForbidden, "Forbidden access, webinar not found")
+	}
+
+	err = s.webinarRepo.DeleteCohost(ctx, webinar.ID, req.UserID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return app.NewError(err, app.ENotFound, "Co-host not found")
+	}
+
+	return err
+}
+
+// addTerms fills in the categories and tags of the webinars.
+func (s *service) addTerms(ctx context.Context, resp []GetWebinarResp) error {
+	ids := make([]int64, 0, len(resp))
+	for _, webinar := range resp {
+		ids = append(ids, webinar.ID)
+	}
+
+	terms, err := s.taxonomyService.Terms(ctx, models.ContentWebinar, ids...)
+	if err != nil {
+		return err
+	}
+
+	for i := range resp {
+		resp[i].Categories, resp[i].Tags = taxonomy.Split(terms[resp[i].ID])
+	}
+
+	return nil
+}
+
+// addSummaries sets the likes of each webinar and whether the viewer liked
+// or bookmarked it.
+func (s *service) addSummaries(ctx context.Context, resp []GetWebinarResp) error {
+	ids := make([]int64, 0, len(resp))
+	for _, webinar := range resp {
+		ids = append(ids, webinar.ID)
+	}
+
+	userID, _ := ctx.Value("userID").(int64)
+	summaries, err := s.reactionService.Summaries(ctx, models.ContentWebinar, userID, ids...)
+	if err != nil {
+		return err
+	}
+
+	for i := range resp {
+		resp[i].Summary = summaries[resp[i].ID]
+	}
+
+	return nil
+}
+
+// toWebinarResp renders the schedule in loc, or in the webinar's own
+// timezone when the viewer did not ask for one.
+func toWebinarResp(webinar models.Webinar, loc *time.Location) GetWebinarResp {
+	if loc == nil {
+		loc = location(webinar.Timezone)
+	}
+
+	resp := GetWebinarResp{
+		ID: webinar.ID,
+		Author: Author{
+			ID:      webinar.Author.ID,
+			Name:    webinar.Author.Name,
+			Picture: webinar.Author.Picture,
+		},
+		Picture:         webinar.Picture,
+		Title:           webinar.Title,
+		Description:     webinar.Description,
+		Category:        webinar.Category,
+		StartAt:         webinar.StartAt,
+		EndAt:           webinar.EndAt,
+		Start:           time.Unix(webinar.StartAt, 0).In(loc).Format(time.RFC3339),
+		End:             time.Unix(webinar.EndAt, 0).In(loc).Format(time.RFC3339),
+		Timezone:        webinar.Timezone,
+		DisplayTimezone: loc.String(),
+		Sessions:        toSessions(webinar.Sessions, loc),
+		Recurrence:      webinar.Recurrence,
+		Exdates:         exdates(webinar),
+		Speakers:        toSpeakers(webinar.Speakers),
+		Cohosts:         toAuthors(webinar.Cohosts),
+		Capacity:        webinar.Capacity,
+		Registered:      webinar.Registered,
+		Status:          status(webinar, time.Now().Unix()),
+		CancelledAt:     webinar.CancelledAt,
+		CreatedAt:       webinar.CreatedAt,
+		UpdatedAt:       webinar.UpdatedAt,
+	}
+
+	// A series without an end has no end time; seats are counted per
+	// occurrence.
+	if webinar.EndAt == openEnded {
+		resp.EndAt = 0
+		resp.End = ""
+	}
+	if webinar.Recurrence != "" {
+		return resp
+	}
+
+	// A capacity of zero means the webinar has no seat limit.
+	if webinar.Capacity > 0 {
+		seatsLeft := webinar.Capacity - webinar.Registered
+		if seatsLeft < 0 {
+			seatsLeft = 0
+		}
+		resp.SeatsLeft = &seatsLeft
+	}
+
+	return resp
+}
+
+// status is derived from the time: a webinar is live from the start of its
+// first session to the end of its last. A recurring webinar is live while one
+// of its occurrences runs, and scheduled in between as long as another one is
+// coming.
+func status(webinar models.Webinar, now int64) string {
+	if _, ok := recurrence(webinar); ok && webinar.CancelledAt == 0 {
+		for _, o := range expand(webinar, now, now+1) {
+			if !o.Cancelled && o.StartAt <= now {
+				return StatusLive
+			}
+		}
+		if _, ok := nextStart(webinar, now); ok {
+			return StatusScheduled
+		}
+
+		return StatusEnded
+	}
+
+	switch {
+	case webinar.CancelledAt > 0:
+		return StatusCancelled
+	case now < webinar.StartAt:
+		return StatusScheduled
+	case now < webinar.EndAt:
+		return StatusLive
+	default:
+		return StatusEnded
+	}
+}
+
+func toOccurrence(webinar models.Webinar, o occurrence, registered int64, timezone string) Occurrence {
+	loc := location(timezone)
+
+	resp := Occurrence{
+		RecurrenceID: o.RecurrenceID,
+		StartAt:      o.StartAt,
+		EndAt:        o.EndAt,
+		Start:        time.Unix(o.StartAt, 0).In(loc).Format(time.RFC3339),
+		End:          time.Unix(o.EndAt, 0).In(loc).Format(time.RFC3339),
+		Cancelled:    o.Cancelled,
+		Registered:   registered,
+	}
+
+	if webinar.Capacity > 0 {
+		seatsLeft := webinar.Capacity - registered
+		if seatsLeft < 0 {
+			seatsLeft = 0
+		}
+		resp.SeatsLeft = &seatsLeft
+	}
+
+	return resp
+}
+
+func toSessions(sessions []models.WebinarSession, loc *time.Location) []Session {
+	resp := make([]Session, 0, len(sessions))
+	for _, session := range sessions {
+		resp = append(resp, Session{
+			StartAt: session.StartAt,
+			EndAt:   session.EndAt,
+			Start:   time.Unix(session.StartAt, 0).In(loc).Format(time.RFC3339),
+			End:     time.Unix(session.EndAt, 0).In(loc).Format(time.RFC3339),
+		})
+	}
+
+	return resp
+}
+
+// setSessions replaces the webinar's sessions; the webinar spans from the
+// first session's start to the last session's end. Sessions must be sorted.
+func setSessions(webinar *models.Webinar, sessions []SessionReq) {
+	webinar.Sessions = make([]models.WebinarSession, 0, len(sessions))
+	for _, session := range sessions {
+		webinar.Sessions = append(webinar.Sessions, models.WebinarSession{
+			StartAt: session.StartAt,
+			EndAt:   session.EndAt,
+		})
+	}
+
+	webinar.StartAt = sessions[0].StartAt
+	webinar.EndAt = sessions[len(sessions)-1].EndAt
+}
+
+// location loads a stored timezone, falling back to UTC for names the tz
+// database on this machine does not know.
+func location(timezone string) *time.Location {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+func displayLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return nil, nil
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "Local" {
+		return nil, app.NewError(nil, app.EBadRequest, "Timezone must be a valid IANA time zone, e.g. Asia/Jakarta")
+	}
+
+	return loc, nil
+}
+
+func toSpeakerModels(speakers []SpeakerReq) []models.WebinarSpeaker {
+	res := make([]models.WebinarSpeaker, 0, len(speakers))
+	for _, speaker := range speakers {
+		res = append(res, models.WebinarSpeaker{
+			User:  models.User{ID: speaker.UserID},
+			Name:  speaker.Name,
+			Bio:   speaker.Bio,
+			Photo: speaker.Photo,
+		})
+	}
+
+	return res
+}
+
+func toSpeakers(speakers []models.WebinarSpeaker) []Speaker {
+	resp := make([]Speaker, 0, len(speakers))
+	for _, speaker := range speakers {
+		resp = append(resp, Speaker{
+			ID:     speaker.ID,
+			UserID: speaker.User.ID,
+			Name:   speaker.Name,
+			Bio:    speaker.Bio,
+			Photo:  speaker.Photo,
+		})
+	}
+
+	return resp
+}
+
+func toAuthors(users []models.User) []Author {
+	resp := make([]Author, 0, len(users))
+	for _, user := range users {
+		resp = append(resp, Author{
+			ID:      user.ID,
+			Name:    user.Name,
+			Picture: user.Picture,
+		})
+	}
+
+	return resp
 }
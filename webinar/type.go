@@ -1,40 +1,209 @@
 package webinar
 
 import (
+	"sort"
+	"time"
+
 	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/pagination"
+	"github.com/bagus2x/recovy/reaction"
+	"github.com/bagus2x/recovy/rrule"
+	"github.com/bagus2x/recovy/taxonomy"
 	"github.com/go-playground/validator/v10"
 )
 
+const (
+	StatusScheduled = "scheduled"
+	StatusLive      = "live"
+	StatusEnded     = "ended"
+	StatusCancelled = "cancelled"
+
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+
+	DirectionNext     = pagination.DirectionNext
+	DirectionPrevious = pagination.DirectionPrevious
+)
+
+// Params filters the webinar list. From and To select webinars overlapping
+// the range. Category and Tag are slugs.
+type Params struct {
+	Cursor    string
+	Limit     int64
+	Direction string
+	Order     string
+	Statuses  []string
+	From      int64
+	To        int64
+	AuthorID  int64
+	Category  string
+	Tag       string
+}
+
+type Cursor = pagination.Cursor
+
 type CreateWebinarReq struct {
-	AuthorID    int64  `json:"authorID" validate:"required,gt=0"`
-	Picture     string `json:"picture" validate:"lte=512"`
-	Title       string `json:"title" validate:"required,gte=5,lte=255"`
-	Description string `json:"description" validate:"required"`
-	Category    string `json:"category" validate:"required"`
-	StartDate   int64  `json:"startDate" validate:"required,gt=0"`
-	LastDate    int64  `json:"lastDate" validate:"required,gt=0"`
-	Time        string `json:"time" validate:"required"`
+	AuthorID    int64        `json:"authorID" validate:"required,gt=0"`
+	Picture     string       `json:"picture" validate:"lte=512"`
+	Title       string       `json:"title" validate:"required,gte=5,lte=255"`
+	Description string       `json:"description" validate:"required"`
+	Category    string       `json:"category" validate:"required,lte=128"`
+	Categories  []string     `json:"categories" validate:"max=10,dive,lte=128"`
+	Tags        []string     `json:"tags" validate:"max=10,dive,lte=128"`
+	Link        string       `json:"link" validate:"omitempty,url,lte=512"`
+	Timezone    string       `json:"timezone" validate:"required,lte=64"`
+	Sessions    []SessionReq `json:"sessions" validate:"required,min=1,max=100,dive"`
+	Recurrence  string       `json:"recurrence" validate:"lte=255"`
+	Exdates     []int64      `json:"exdates" validate:"max=366"`
+	Speakers    []SpeakerReq `json:"speakers" validate:"max=20,dive"`
+	Capacity    int64        `json:"capacity" validate:"gte=0"`
+}
+
+// SpeakerReq links a speaker to a user of the app by UserID, or describes an
+// external guest by Name.
+type SpeakerReq struct {
+	UserID int64  `json:"userID" validate:"gte=0"`
+	Name   string `json:"name" validate:"required_without=UserID,lte=255"`
+	Bio    string `json:"bio" validate:"lte=2000"`
+	Photo  string `json:"photo" validate:"lte=512"`
+}
+
+type SessionReq struct {
+	StartAt int64 `json:"startAt" validate:"required,gt=0"`
+	EndAt   int64 `json:"endAt" validate:"required,gtfield=StartAt"`
 }
 
 func (r *CreateWebinarReq) Validate() error {
 	validate := validator.New()
 	err := validate.Struct(r)
+	if err != nil {
+		return app.ValidateAndTranslate(validate, err)
+	}
 
-	return app.ValidateAndTranslate(validate, err)
+	err = validateSchedule(r.Timezone, r.Sessions)
+	if err != nil {
+		return err
+	}
+
+	if r.Recurrence == "" {
+		if len(r.Exdates) > 0 {
+			return app.NewError(nil, app.EBadRequest, "Exdates require a recurrence")
+		}
+
+		return nil
+	}
+
+	if len(r.Sessions) != 1 {
+		return app.NewError(nil, app.EBadRequest, "A recurring webinar must have exactly one session")
+	}
+
+	rule, err := validateRecurrence(r.Recurrence, r.Timezone, r.Sessions[0].StartAt)
+	if err != nil {
+		return err
+	}
+	r.Recurrence = rule.String()
+
+	dtstart := time.Unix(r.Sessions[0].StartAt, 0).In(location(r.Timezone))
+	for _, exdate := range r.Exdates {
+		if !rule.Includes(dtstart, time.Unix(exdate, 0).In(dtstart.Location())) {
+			return app.NewError(nil, app.EBadRequest, "Exdates must be occurrences of the recurrence")
+		}
+	}
+
+	return nil
+}
+
+// validateRecurrence parses an RRULE and checks that the series starts with
+// its first occurrence.
+func validateRecurrence(recurrence, timezone string, startAt int64) (rrule.Rule, error) {
+	rule, err := rrule.Parse(recurrence)
+	if err != nil {
+		return rrule.Rule{}, app.NewError(err, app.EBadRequest, "Recurrence must be a valid RRULE: "+err.Error())
+	}
+
+	dtstart := time.Unix(startAt, 0).In(location(timezone))
+	if !rule.Includes(dtstart, dtstart) {
+		return rrule.Rule{}, app.NewError(nil, app.EBadRequest, "Start must be an occurrence of the recurrence")
+	}
+
+	return rule, nil
+}
+
+// validateSchedule checks that the timezone is a known IANA zone and sorts
+// the sessions, rejecting any that overlap.
+func validateSchedule(timezone string, sessions []SessionReq) error {
+	_, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "Local" {
+		return app.NewError(nil, app.EBadRequest, "Timezone must be a valid IANA time zone, e.g. Asia/Jakarta")
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].StartAt < sessions[j].StartAt
+	})
+	for i := 1; i < len(sessions); i++ {
+		if sessions[i].StartAt < sessions[i-1].EndAt {
+			return app.NewError(nil, app.EBadRequest, "Sessions must not overlap")
+		}
+	}
+
+	return nil
 }
 
 type CreateWebinarResp struct {
-	ID          int64  `json:"id"`
-	AuthorID    int64  `json:"authorID"`
-	Picture     string `json:"picture"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Category    string `json:"category"`
-	StartDate   int64  `json:"startDate"`
-	LastDate    int64  `json:"lastDate"`
-	Time        string `json:"time"`
-	CreatedAt   int64  `json:"createdAt"`
-	UpdatedAt   int64  `json:"updatedAt"`
+	ID          int64           `json:"id"`
+	AuthorID    int64           `json:"authorID"`
+	Picture     string          `json:"picture"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Category    string          `json:"category"`
+	Categories  []taxonomy.Term `json:"categories"`
+	Tags        []taxonomy.Term `json:"tags"`
+	Link        string          `json:"link"`
+	StartAt     int64           `json:"startAt"`
+	EndAt       int64           `json:"endAt"`
+	Timezone    string          `json:"timezone"`
+	Sessions    []Session       `json:"sessions"`
+	Recurrence  string          `json:"recurrence,omitempty"`
+	Exdates     []int64         `json:"exdates,omitempty"`
+	Speakers    []Speaker       `json:"speakers"`
+	Capacity    int64           `json:"capacity"`
+	CreatedAt   int64           `json:"createdAt"`
+	UpdatedAt   int64           `json:"updatedAt"`
+}
+
+// Session carries both the instants and their rendering, as RFC 3339 in the
+// timezone the viewer asked for.
+type Session struct {
+	StartAt int64  `json:"startAt"`
+	EndAt   int64  `json:"endAt"`
+	Start   string `json:"start"`
+	End     string `json:"end"`
+}
+
+// Occurrence is a single run of a webinar. RecurrenceID identifies it within
+// a recurring webinar and stays the same when the occurrence is moved; it is
+// 0 for webinars that do not recur.
+type Occurrence struct {
+	RecurrenceID       int64  `json:"recurrenceID"`
+	StartAt            int64  `json:"startAt"`
+	EndAt              int64  `json:"endAt"`
+	Start              string `json:"start"`
+	End                string `json:"end"`
+	Cancelled          bool   `json:"cancelled"`
+	Registered         int64  `json:"registered"`
+	SeatsLeft          *int64 `json:"seatsLeft"`
+	RegistrationStatus string `json:"registrationStatus,omitempty"`
+}
+
+type WebinarOccurrence struct {
+	WebinarID int64  `json:"webinarID"`
+	Author    Author `json:"author"`
+	Picture   string `json:"picture"`
+	Title     string `json:"title"`
+	Category  string `json:"category"`
+	Timezone  string `json:"timezone"`
+	Occurrence
 }
 
 type Author struct {
@@ -43,16 +212,258 @@ type Author struct {
 	Picture string `json:"picture"`
 }
 
+// Speaker has a UserID of 0 for external guests.
+type Speaker struct {
+	ID     int64  `json:"id"`
+	UserID int64  `json:"userID"`
+	Name   string `json:"name"`
+	Bio    string `json:"bio"`
+	Photo  string `json:"photo"`
+}
+
+// GetWebinarResp lists the upcoming occurrences of a recurring webinar in
+// Occurrences. Link and History are only filled in for a single webinar, and
+// Link only for its hosts and registrants.
 type GetWebinarResp struct {
+	ID                 int64           `json:"id"`
+	Author             Author          `json:"author"`
+	Picture            string          `json:"picture"`
+	Title              string          `json:"title"`
+	Description        string          `json:"description"`
+	Category           string          `json:"category"`
+	Categories         []taxonomy.Term `json:"categories"`
+	Tags               []taxonomy.Term `json:"tags"`
+	Link               string          `json:"link,omitempty"`
+	StartAt            int64           `json:"startAt"`
+	EndAt              int64           `json:"endAt"`
+	Start              string          `json:"start"`
+	End                string          `json:"end"`
+	Timezone           string          `json:"timezone"`
+	DisplayTimezone    string          `json:"displayTimezone"`
+	Sessions           []Session       `json:"sessions"`
+	Recurrence         string          `json:"recurrence,omitempty"`
+	Exdates            []int64         `json:"exdates,omitempty"`
+	Occurrences        []Occurrence    `json:"occurrences,omitempty"`
+	Speakers           []Speaker       `json:"speakers"`
+	Cohosts            []Author        `json:"cohosts"`
+	Capacity           int64           `json:"capacity"`
+	Registered         int64           `json:"registered"`
+	SeatsLeft          *int64          `json:"seatsLeft"`
+	Status             string          `json:"status"`
+	CancelledAt        int64           `json:"cancelledAt,omitempty"`
+	RegistrationStatus string          `json:"registrationStatus,omitempty"`
+	RemindMe           bool            `json:"remindMe"`
+	History            []Change        `json:"history,omitempty"`
+	CreatedAt          int64           `json:"createdAt"`
+	UpdatedAt          int64           `json:"updatedAt"`
+	reaction.Summary
+}
+
+// Change is an edit of a webinar, listed newest first in its history.
+type Change struct {
+	Editor    Author               `json:"editor"`
+	Changes   []models.FieldChange `json:"changes"`
+	CreatedAt int64                `json:"createdAt"`
+}
+
+type GetWebinarsResp struct {
+	Cursor   Cursor           `json:"cursor"`
+	Webinars []GetWebinarResp `json:"webinars"`
+}
+
+// UpdateWebinarReq edits the fields that are set. Categories and Tags replace
+// the other categories and the tags of the webinar. The schedule of a
+// recurring webinar is edited through its occurrences instead.
+type UpdateWebinarReq struct {
+	WebinarID   int64        `json:"webinarID" validate:"required,gt=0"`
+	AuthorID    int64        `json:"authorID" validate:"required,gt=0"`
+	Picture     *string      `json:"picture" validate:"omitempty,lte=512"`
+	Title       *string      `json:"title" validate:"omitempty,gte=5,lte=255"`
+	Description *string      `json:"description" validate:"omitempty,min=1"`
+	Category    *string      `json:"category" validate:"omitempty,min=1,lte=128"`
+	Categories  []string     `json:"categories" validate:"omitempty,max=10,dive,lte=128"`
+	Tags        []string     `json:"tags" validate:"omitempty,max=10,dive,lte=128"`
+	Link        *string      `json:"link" validate:"omitempty,lte=512"`
+	Timezone    *string      `json:"timezone" validate:"omitempty,lte=64"`
+	Sessions    []SessionReq `json:"sessions" validate:"omitempty,min=1,max=100,dive"`
+	Capacity    *int64       `json:"capacity" validate:"omitempty,gte=0"`
+}
+
+func (r *UpdateWebinarReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+	if err != nil {
+		return app.ValidateAndTranslate(validate, err)
+	}
+
+	// An empty link removes it.
+	if r.Link != nil && *r.Link != "" && validate.Var(*r.Link, "url") != nil {
+		return app.NewError(nil, app.EBadRequest, "Link must be a valid URL")
+	}
+
+	return nil
+}
+
+type RegisterReq struct {
+	WebinarID  int64 `json:"webinarID" validate:"required,gt=0"`
+	Occurrence int64 `json:"occurrence" validate:"gte=0"`
+	UserID     int64 `json:"userID" validate:"required,gt=0"`
+}
+
+func (r *RegisterReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+
+	return app.ValidateAndTranslate(validate, err)
+}
+
+type RegisterResp struct {
+	WebinarID  int64  `json:"webinarID"`
+	Occurrence int64  `json:"occurrence"`
+	UserID     int64  `json:"userID"`
+	Status     string `json:"status"`
+	CreatedAt  int64  `json:"createdAt"`
+}
+
+type Registrant struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	Picture      string `json:"picture"`
+	Occurrence   int64  `json:"occurrence"`
+	Status       string `json:"status"`
+	RegisteredAt int64  `json:"registeredAt"`
+}
+
+const (
+	ScopeThis      = "this"
+	ScopeFollowing = "following"
+)
+
+// UpdateOccurrenceReq edits one occurrence of a recurring webinar, or that
+// occurrence and all following ones. Only the latter may change the
+// recurrence; only the former may cancel.
+type UpdateOccurrenceReq struct {
+	WebinarID    int64  `json:"webinarID" validate:"required,gt=0"`
+	AuthorID     int64  `json:"authorID" validate:"required,gt=0"`
+	RecurrenceID int64  `json:"recurrenceID" validate:"required,gt=0"`
+	Scope        string `json:"scope" validate:"required,oneof=this following"`
+	StartAt      int64  `json:"startAt" validate:"required_without=Cancelled,omitempty,gt=0"`
+	EndAt        int64  `json:"endAt" validate:"required_without=Cancelled,omitempty,gtfield=StartAt"`
+	Recurrence   string `json:"recurrence" validate:"lte=255"`
+	Cancelled    bool   `json:"cancelled"`
+}
+
+func (r *UpdateOccurrenceReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+	if err != nil {
+		return app.ValidateAndTranslate(validate, err)
+	}
+
+	if r.Scope == ScopeThis && r.Recurrence != "" {
+		return app.NewError(nil, app.EBadRequest, "Recurrence can only be changed for all following occurrences")
+	}
+	if r.Scope == ScopeFollowing && r.Cancelled {
+		return app.NewError(nil, app.EBadRequest, "Only a single occurrence can be cancelled")
+	}
+
+	return nil
+}
+
+type OccurrencesReq struct {
+	From int64 `json:"from" validate:"required,gt=0"`
+	To   int64 `json:"to" validate:"required,gtfield=From"`
+}
+
+// maxOccurrenceRange bounds how far occurrences are expanded in one request.
+const maxOccurrenceRange = 92 * 24 * 60 * 60
+
+func (r *OccurrencesReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+	if err != nil {
+		return app.ValidateAndTranslate(validate, err)
+	}
+
+	if r.To-r.From > maxOccurrenceRange {
+		return app.NewError(nil, app.EBadRequest, "Range must not be longer than 92 days")
+	}
+
+	return nil
+}
+
+type RemindReq struct {
+	WebinarID int64 `json:"webinarID" validate:"required,gt=0"`
+	UserID    int64 `json:"userID" validate:"required,gt=0"`
+}
+
+func (r *RemindReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+
+	return app.ValidateAndTranslate(validate, err)
+}
+
+type ReplaceSpeakersReq struct {
+	WebinarID int64        `json:"webinarID" validate:"required,gt=0"`
+	AuthorID  int64        `json:"authorID" validate:"required,gt=0"`
+	Speakers  []SpeakerReq `json:"speakers" validate:"max=20,dive"`
+}
+
+func (r *ReplaceSpeakersReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+
+	return app.ValidateAndTranslate(validate, err)
+}
+
+type CohostReq struct {
+	WebinarID int64 `json:"webinarID" validate:"required,gt=0"`
+	AuthorID  int64 `json:"authorID" validate:"required,gt=0"`
+	UserID    int64 `json:"userID" validate:"required,gt=0"`
+}
+
+func (r *CohostReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+	if err != nil {
+		return app.ValidateAndTranslate(validate, err)
+	}
+
+	if r.UserID == r.AuthorID {
+		return app.NewError(nil, app.EBadRequest, "The author cannot be a co-host")
+	}
+
+	return nil
+}
+
+// CheckInReq checks a user in with the code announced during the webinar or
+// the token scanned from its QR code.
+type CheckInReq struct {
+	WebinarID int64  `json:"webinarID" validate:"required,gt=0"`
+	UserID    int64  `json:"userID" validate:"required,gt=0"`
+	Code      string `json:"code" validate:"required_without=Token,lte=16"`
+	Token     string `json:"token" validate:"required_without=Code,lte=64"`
+}
+
+func (r *CheckInReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+
+	return app.ValidateAndTranslate(validate, err)
+}
+
+// CheckIn is shown to organizers. Clients render Token as a QR code.
+type CheckIn struct {
+	WebinarID int64  `json:"webinarID"`
+	Code      string `json:"code"`
+	Token     string `json:"token"`
+}
+
+type Attendee struct {
 	ID          int64  `json:"id"`
-	Author      Author `json:"author"`
+	Name        string `json:"name"`
 	Picture     string `json:"picture"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Category    string `json:"category"`
-	StartDate   int64  `json:"startDate"`
-	LastDate    int64  `json:"lastDate"`
-	Time        string `json:"time"`
-	CreatedAt   int64  `json:"createdAt"`
-	UpdatedAt   int64  `json:"updatedAt"`
+	Occurrence  int64  `json:"occurrence"`
+	CheckedInAt int64  `json:"checkedInAt"`
 }
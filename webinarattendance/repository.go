@@ -0,0 +1,130 @@
+package webinarattendance
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+)
+
+type Repository interface {
+	CheckIn(ctx context.Context, attendance *models.WebinarAttendance) error
+	FindByWebinarID(ctx context.Context, webinarID int64) ([]models.WebinarAttendance, error)
+	FindByWebinarIDAndUserID(ctx context.Context, webinarID, occurrence, userID int64) (models.WebinarAttendance, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+var checkIn = `
+	INSERT INTO
+		Webinar_Attendance
+		(webinar_id, occurrence, user_id, checked_in_at)
+	VALUES
+		($1, $2, $3, $4)
+	ON CONFLICT (webinar_id, occurrence, user_id) DO UPDATE SET
+		checked_in_at = Webinar_Attendance.checked_in_at
+	RETURNING
+		checked_in_at
+`
+
+// CheckIn records the attendance. Checking in again keeps the time of the
+// first check-in.
+func (r *repository) CheckIn(ctx context.Context, attendance *models.WebinarAttendance) error {
+	return r.db.QueryRowContext(
+		ctx,
+		checkIn,
+		attendance.WebinarID,
+		attendance.Occurrence,
+		attendance.User.ID,
+		attendance.CheckedInAt,
+	).Scan(&attendance.CheckedInAt)
+}
+
+var findByWebinarID = `
+	SELECT
+		wa.webinar_id, wa.occurrence, au.id, au.name, au.email, au.picture, wa.checked_in_at
+	FROM
+		Webinar_Attendance wa
+	JOIN
+		App_User au
+	ON
+		wa.user_id = au.id
+	WHERE
+		wa.webinar_id = $1
+	ORDER BY
+		wa.occurrence ASC, wa.checked_in_at ASC
+`
+
+func (r *repository) FindByWebinarID(ctx context.Context, webinarID int64) ([]models.WebinarAttendance, error) {
+	attendances := make([]models.WebinarAttendance, 0)
+
+	rows, err := r.db.QueryContext(ctx, findByWebinarID, webinarID)
+	if err != nil {
+		return attendances, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var attendance models.WebinarAttendance
+
+		err := rows.Scan(
+			&attendance.WebinarID,
+			&attendance.Occurrence,
+			&attendance.User.ID,
+			&attendance.User.Name,
+			&attendance.User.Email,
+			&attendance.User.Picture,
+			&attendance.CheckedInAt,
+		)
+		if err != nil {
+			return attendances, err
+		}
+
+		attendances = append(attendances, attendance)
+	}
+
+	return attendances, rows.Err()
+}
+
+var findByWebinarIDAndUserID = `
+	SELECT
+		wa.webinar_id, wa.occurrence, au.id, au.name, au.email, au.picture, wa.checked_in_at
+	FROM
+		Webinar_Attendance wa
+	JOIN
+		App_User au
+	ON
+		wa.user_id = au.id
+	WHERE
+		wa.webinar_id = $1 AND wa.occurrence = $2 AND wa.user_id = $3
+`
+
+func (r *repository) FindByWebinarIDAndUserID(ctx context.Context, webinarID, occurrence, userID int64) (models.WebinarAttendance, error) {
+	var attendance models.WebinarAttendance
+
+	err := r.db.QueryRowContext(ctx, findByWebinarIDAndUserID, webinarID, occurrence, userID).Scan(
+		&attendance.WebinarID,
+		&attendance.Occurrence,
+		&attendance.User.ID,
+		&attendance.User.Name,
+		&attendance.User.Email,
+		&attendance.User.Picture,
+		&attendance.CheckedInAt,
+	)
+	if err == sql.ErrNoRows {
+		return models.WebinarAttendance{}, app.NewError(err, app.ENotFound, "Attendance not found")
+	} else if err != nil {
+		return models.WebinarAttendance{}, err
+	}
+
+	return attendance, nil
+}
@@ -0,0 +1,507 @@
+package webinarlive
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+	"github.com/lib/pq"
+)
+
+type Repository interface {
+	CreateQuestion(ctx context.Context, question *models.WebinarQuestion) error
+	FindQuestionByID(ctx context.Context, questionID int64) (models.WebinarQuestion, error)
+	FindQuestions(ctx context.Context, webinarID, occurrence int64) ([]models.WebinarQuestion, error)
+	Upvote(ctx context.Context, questionID, userID, createdAt int64) error
+	RemoveUpvote(ctx context.Context, questionID, userID int64) error
+	Answer(ctx context.Context, questionID, answeredAt int64) error
+	CreatePoll(ctx context.Context, poll *models.WebinarPoll) error
+	FindPollByID(ctx context.Context, pollID int64) (models.WebinarPoll, error)
+	FindPolls(ctx context.Context, webinarID, occurrence int64) ([]models.WebinarPoll, error)
+	Vote(ctx context.Context, pollID, optionID, userID, createdAt int64) error
+	ClosePoll(ctx context.Context, pollID, closedAt int64) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+var createQuestion = `
+	INSERT INTO
+		Webinar_Question
+		(webinar_id, occurrence, user_id, body, created_at)
+	VALUES
+		($1, $2, $3, $4, $5)
+	RETURNING
+		id
+`
+
+func (r *repository) CreateQuestion(ctx context.Context, question *models.WebinarQuestion) error {
+	return r.db.QueryRowContext(
+		ctx,
+		createQuestion,
+		question.WebinarID,
+		question.Occurrence,
+		question.User.ID,
+		question.Body,
+		question.CreatedAt,
+	).Scan(&question.ID)
+}
+
+var findQuestionByID = `
+	SELECT
+		wq.id, wq.webinar_id, wq.occurrence, au.id, au.name, au.picture, wq.body, wq.upvotes, wq.answered_at, wq.created_at
+	FROM
+		Webinar_Question wq
+	JOIN
+		App_User au
+	ON
+		wq.user_id = au.id
+	WHERE
+		wq.id = $1
+`
+
+func (r *repository) FindQuestionByID(ctx context.Context, questionID int64) (models.WebinarQuestion, error) {
+	questions, err := r.findQuestions(ctx, findQuestionByID, questionID)
+	if err != nil {
+		return models.WebinarQuestion{}, err
+	}
+	if len(questions) == 0 {
+		return models.WebinarQuestion{}, app.NewError(sql.ErrNoRows, app.ENotFound, "Question not found")
+	}
+
+	return questions[0], nil
+}
+
+var findQuestions = `
+	SELECT
+		wq.id, wq.webinar_id, wq.occurrence, au.id, au.name, au.picture, wq.body, wq.upvotes, wq.answered_at, wq.created_at
+	FROM
+		Webinar_Question wq
+	JOIN
+		App_User au
+	ON
+		wq.user_id = au.id
+	WHERE
+		wq.webinar_id = $1 AND wq.occurrence = $2
+	ORDER BY
+		wq.upvotes DESC, wq.created_at ASC, wq.id ASC
+`
+
+// FindQuestions lists the questions of an occurrence, most upvoted first.
+func (r *repository) FindQuestions(ctx context.Context, webinarID, occurrence int64) ([]models.WebinarQuestion, error) {
+	return r.findQuestions(ctx, findQuestions, webinarID, occurrence)
+}
+
+func (r *repository) findQuestions(ctx context.Context, query string, args ...interface{}) ([]models.WebinarQuestion, error) {
+	questions := make([]models.WebinarQuestion, 0)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return questions, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var question models.WebinarQuestion
+
+		err := rows.Scan(
+			&question.ID,
+			&question.WebinarID,
+			&question.Occurrence,
+			&question.User.ID,
+			&question.User.Name,
+			&question.User.Picture,
+			&question.Body,
+			&question.Upvotes,
+			&question.AnsweredAt,
+			&question.CreatedAt,
+		)
+		if err != nil {
+			return questions, err
+		}
+
+		questions = append(questions, question)
+	}
+
+	return questions, rows.Err()
+}
+
+var upvote = `
+	INSERT INTO
+		Webinar_Question_Upvote
+		(question_id, user_id, created_at)
+	VALUES
+		($1, $2, $3)
+	ON CONFLICT (question_id, user_id) DO NOTHING
+`
+
+var removeUpvote = `
+	DELETE FROM
+		Webinar_Question_Upvote
+	WHERE
+		question_id = $1 AND user_id = $2
+`
+
+var updateUpvotes = `
+	UPDATE
+		Webinar_Question
+	SET
+		upvotes = upvotes + $2
+	WHERE
+		id = $1
+`
+
+func (r *repository) Upvote(ctx context.Context, questionID, userID, createdAt int64) error {
+	return r.changeUpvotes(ctx, questionID, 1, upvote, questionID, userID, createdAt)
+}
+
+func (r *repository) RemoveUpvote(ctx context.Context, questionID, userID int64) error {
+	return r.changeUpvotes(ctx, questionID, -1, removeUpvote, questionID, userID)
+}
+
+// changeUpvotes runs the upvote insert or delete and keeps the count on the
+// question in step with it.
+func (r *repository) changeUpvotes(ctx context.Context, questionID, delta int64, query string, args ...interface{}) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+		return app.NewError(err, app.ENotFound, "Question not found")
+	} else if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 && delta > 0 {
+		return app.NewError(nil, app.Econflict, "User has upvoted")
+	}
+	if affected == 0 {
+		return app.NewError(nil, app.ENotFound, "Upvote not found")
+	}
+
+	_, err = tx.ExecContext(ctx, updateUpvotes, questionID, delta)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+var answer = `
+	UPDATE
+		Webinar_Question
+	SET
+		answered_at = $2
+	WHERE
+		id = $1 AND answered_at = 0
+`
+
+func (r *repository) Answer(ctx context.Context, questionID, answeredAt int64) error {
+	res, err := r.db.ExecContext(ctx, answer, questionID, answeredAt)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return app.NewError(nil, app.Econflict, "Question has been answered")
+	}
+
+	return nil
+}
+
+var createPoll = `
+	INSERT INTO
+		Webinar_Poll
+		(webinar_id, occurrence, author_id, question, created_at)
+	VALUES
+		($1, $2, $3, $4, $5)
+	RETURNING
+		id
+`
+
+var createPollOption = `
+	INSERT INTO
+		Webinar_Poll_Option
+		(poll_id, body, position)
+	VALUES
+		($1, $2, $3)
+	RETURNING
+		id
+`
+
+func (r *repository) CreatePoll(ctx context.Context, poll *models.WebinarPoll) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(
+		ctx,
+		createPoll,
+		poll.WebinarID,
+		poll.Occurrence,
+		poll.AuthorID,
+		poll.Question,
+		poll.CreatedAt,
+	).Scan(&poll.ID)
+	if err != nil {
+		return err
+	}
+
+	for i := range poll.Options {
+		option := &poll.Options[i]
+		option.PollID = poll.ID
+		option.Position = int64(i)
+
+		err = tx.QueryRowContext(ctx, createPollOption, option.PollID, option.Body, option.Position).Scan(&option.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+var findPollByID = `
+	SELECT
+		id, webinar_id, occurrence, author_id, question, closed_at, created_at
+	FROM
+		Webinar_Poll
+	WHERE
+		id = $1
+`
+
+func (r *repository) FindPollByID(ctx context.Context, pollID int64) (models.WebinarPoll, error) {
+	polls, err := r.findPolls(ctx, findPollByID, pollID)
+	if err != nil {
+		return models.WebinarPoll{}, err
+	}
+	if len(polls) == 0 {
+		return models.WebinarPoll{}, app.NewError(sql.ErrNoRows, app.ENotFound, "Poll not found")
+	}
+
+	return polls[0], nil
+}
+
+var findPolls = `
+	SELECT
+		id, webinar_id, occurrence, author_id, question, closed_at, created_at
+	FROM
+		Webinar_Poll
+	WHERE
+		webinar_id = $1 AND occurrence = $2
+	ORDER BY
+		created_at ASC, id ASC
+`
+
+func (r *repository) FindPolls(ctx context.Context, webinarID, occurrence int64) ([]models.WebinarPoll, error) {
+	return r.findPolls(ctx, findPolls, webinarID, occurrence)
+}
+
+func (r *repository) findPolls(ctx context.Context, query string, args ...interface{}) ([]models.WebinarPoll, error) {
+	polls := make([]models.WebinarPoll, 0)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return polls, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var poll models.WebinarPoll
+
+		err := rows.Scan(
+			&poll.ID,
+			&poll.WebinarID,
+			&poll.Occurrence,
+			&poll.AuthorID,
+			&poll.Question,
+			&poll.ClosedAt,
+			&poll.CreatedAt,
+		)
+		if err != nil {
+			return polls, err
+		}
+
+		polls = append(polls, poll)
+	}
+	if err := rows.Err(); err != nil {
+		return polls, err
+	}
+
+	err = r.findOptions(ctx, polls)
+
+	return polls, err
+}
+
+var findOptions = `
+	SELECT
+		id, poll_id, body, position, votes
+	FROM
+		Webinar_Poll_Option
+	WHERE
+		poll_id = ANY($1)
+	ORDER BY
+		position ASC
+`
+
+// findOptions loads the options of all polls with a single query.
+func (r *repository) findOptions(ctx context.Context, polls []models.WebinarPoll) error {
+	if len(polls) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(polls))
+	index := make(map[int64]int, len(polls))
+	for i, poll := range polls {
+		ids = append(ids, poll.ID)
+		index[poll.ID] = i
+		polls[i].Options = make([]models.WebinarPollOption, 0)
+	}
+
+	rows, err := r.db.QueryContext(ctx, findOptions, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var option models.WebinarPollOption
+
+		err := rows.Scan(
+			&option.ID,
+			&option.PollID,
+			&option.Body,
+			&option.Position,
+			&option.Votes,
+		)
+		if err != nil {
+			return err
+		}
+
+		i := index[option.PollID]
+		polls[i].Options = append(polls[i].Options, option)
+	}
+
+	return rows.Err()
+}
+
+var lockPoll = `
+	SELECT
+		closed_at
+	FROM
+		Webinar_Poll
+	WHERE
+		id = $1
+	FOR UPDATE
+`
+
+var vote = `
+	INSERT INTO
+		Webinar_Poll_Vote
+		(poll_id, user_id, option_id, created_at)
+	SELECT
+		poll_id, $3, id, $4
+	FROM
+		Webinar_Poll_Option
+	WHERE
+		id = $2 AND poll_id = $1
+`
+
+var countVote = `
+	UPDATE
+		Webinar_Poll_Option
+	SET
+		votes = votes + 1
+	WHERE
+		id = $1
+`
+
+// Vote counts the user's vote for the option. The poll is locked so that no
+// vote gets in after it is closed.
+func (r *repository) Vote(ctx context.Context, pollID, optionID, userID, createdAt int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var closedAt int64
+	err = tx.QueryRowContext(ctx, lockPoll, pollID).Scan(&closedAt)
+	if err == sql.ErrNoRows {
+		return app.NewError(err, app.ENotFound, "Poll not found")
+	} else if err != nil {
+		return err
+	}
+	if closedAt > 0 {
+		return app.NewError(nil, app.EBadRequest, "Poll has been closed")
+	}
+
+	res, err := tx.ExecContext(ctx, vote, pollID, optionID, userID, createdAt)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return app.NewError(err, app.Econflict, "User has voted")
+	} else if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return app.NewError(nil, app.ENotFound, "Option not found")
+	}
+
+	_, err = tx.ExecContext(ctx, countVote, optionID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+var closePoll = `
+	UPDATE
+		Webinar_Poll
+	SET
+		closed_at = $2
+	WHERE
+		id = $1 AND closed_at = 0
+`
+
+func (r *repository) ClosePoll(ctx context.Context, pollID, closedAt int64) error {
+	res, err := r.db.ExecContext(ctx, closePoll, pollID, closedAt)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return app.NewError(nil, app.Econflict, "Poll has been closed")
+	}
+
+	return nil
+}
@@ -0,0 +1,437 @@
+package webinarlive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/pubsub"
+	"github.com/bagus2x/recovy/webinar"
+	"github.com/sirupsen/logrus"
+)
+
+// Service runs the audience interaction of a webinar occurrence. Every change
+// is stored first and then published to the occurrence's topic, which live
+// connections on all instances are subscribed to.
+type Service interface {
+	GetSnapshot(ctx context.Context, webinarID, occurrence int64) (Snapshot, error)
+	Subscribe(ctx context.Context, webinarID, occurrence int64) (<-chan []byte, func(), error)
+	Handle(ctx context.Context, webinarID, occurrence, userID int64, msg Message) error
+	Ask(ctx context.Context, req *AskReq) (Question, error)
+	Upvote(ctx context.Context, req *QuestionReq) (Question, error)
+	RemoveUpvote(ctx context.Context, req *QuestionReq) (Question, error)
+	Answer(ctx context.Context, req *QuestionReq) (Question, error)
+	CreatePoll(ctx context.Context, req *CreatePollReq) (Poll, error)
+	Vote(ctx context.Context, req *VoteReq) (Poll, error)
+	ClosePoll(ctx context.Context, req *PollReq) (Poll, error)
+}
+
+type service struct {
+	repo        Repository
+	webinarRepo webinar.Repository
+	pubSub      pubsub.PubSub
+}
+
+func NewService(repo Repository, webinarRepo webinar.Repository, pubSub pubsub.PubSub) Service {
+	return &service{
+		repo:        repo,
+		webinarRepo: webinarRepo,
+		pubSub:      pubSub,
+	}
+}
+
+func (s *service) GetSnapshot(ctx context.Context, webinarID, occurrence int64) (Snapshot, error) {
+	_, err := s.findWebinar(ctx, webinarID, occurrence)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	questions, err := s.repo.FindQuestions(ctx, webinarID, occurrence)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	polls, err := s.repo.FindPolls(ctx, webinarID, occurrence)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	snapshot := Snapshot{
+		Questions: make([]Question, 0, len(questions)),
+		Polls:     make([]Poll, 0, len(polls)),
+	}
+	for _, question := range questions {
+		snapshot.Questions = append(snapshot.Questions, toQuestion(question))
+	}
+	for _, poll := range polls {
+		snapshot.Polls = append(snapshot.Polls, toPoll(poll))
+	}
+
+	return snapshot, nil
+}
+
+// Subscribe returns the encoded events of the occurrence. Callers should
+// subscribe before taking a snapshot so that no change is missed in between.
+func (s *service) Subscribe(ctx context.Context, webinarID, occurrence int64) (<-chan []byte, func(), error) {
+	_, err := s.findWebinar(ctx, webinarID, occurrence)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	events, cancel := s.pubSub.Subscribe(topic(webinarID, occurrence))
+
+	return events, cancel, nil
+}
+
+// Handle runs an action sent over a live connection.
+func (s *service) Handle(ctx context.Context, webinarID, occurrence, userID int64, msg Message) error {
+	var err error
+
+	switch msg.Type {
+	case ActionAsk:
+		_, err = s.Ask(ctx, &AskReq{WebinarID: webinarID, Occurrence: occurrence, UserID: userID, Body: msg.Body})
+	case ActionUpvote:
+		_, err = s.Upvote(ctx, &QuestionReq{WebinarID: webinarID, QuestionID: msg.QuestionID, UserID: userID})
+	case ActionRemoveUpvote:
+		_, err = s.RemoveUpvote(ctx, &QuestionReq{WebinarID: webinarID, QuestionID: msg.QuestionID, UserID: userID})
+	case ActionAnswer:
+		_, err = s.Answer(ctx, &QuestionReq{WebinarID: webinarID, QuestionID: msg.QuestionID, UserID: userID})
+	case ActionCreatePoll:
+		_, err = s.CreatePoll(ctx, &CreatePollReq{
+			WebinarID:  webinarID,
+			Occurrence: occurrence,
+			UserID:     userID,
+			Question:   msg.Question,
+			Options:    msg.Options,
+		})
+	case ActionVote:
+		_, err = s.Vote(ctx, &VoteReq{WebinarID: webinarID, PollID: msg.PollID, OptionID: msg.OptionID, UserID: userID})
+	case ActionClosePoll:
+		_, err = s.ClosePoll(ctx, &PollReq{WebinarID: webinarID, PollID: msg.PollID, UserID: userID})
+	default:
+		err = app.NewError(nil, app.EBadRequest, "Unknown message type")
+	}
+
+	return err
+}
+
+func (s *service) Ask(ctx context.Context, req *AskReq) (Question, error) {
+	err := req.Validate()
+	if err != nil {
+		return Question{}, err
+	}
+
+	w, err := s.findWebinar(ctx, req.WebinarID, req.Occurrence)
+	if err != nil {
+		return Question{}, err
+	}
+	if w.CancelledAt > 0 {
+		return Question{}, app.NewError(nil, app.EBadRequest, "Webinar has been cancelled")
+	}
+
+	question := models.WebinarQuestion{
+		WebinarID:  req.WebinarID,
+		Occurrence: req.Occurrence,
+		User:       models.User{ID: req.UserID},
+		Body:       req.Body,
+		CreatedAt:  time.Now().Unix(),
+	}
+
+	err = s.repo.CreateQuestion(ctx, &question)
+	if err != nil {
+		return Question{}, err
+	}
+
+	return s.publishQuestion(ctx, EventQuestionCreated, question.ID)
+}
+
+func (s *service) Upvote(ctx context.Context, req *QuestionReq) (Question, error) {
+	err := req.Validate()
+	if err != nil {
+		return Question{}, err
+	}
+
+	_, err = s.findQuestion(ctx, req.WebinarID, req.QuestionID)
+	if err != nil {
+		return Question{}, err
+	}
+
+	err = s.repo.Upvote(ctx, req.QuestionID, req.UserID, time.Now().Unix())
+	if err != nil {
+		return Question{}, err
+	}
+
+	return s.publishQuestion(ctx, EventQuestionUpdated, req.QuestionID)
+}
+
+func (s *service) RemoveUpvote(ctx context.Context, req *QuestionReq) (Question, error) {
+	err := req.Validate()
+	if err != nil {
+		return Question{}, err
+	}
+
+	_, err = s.findQuestion(ctx, req.WebinarID, req.QuestionID)
+	if err != nil {
+		return Question{}, err
+	}
+
+	err = s.repo.RemoveUpvote(ctx, req.QuestionID, req.UserID)
+	if err != nil {
+		return Question{}, err
+	}
+
+	return s.publishQuestion(ctx, EventQuestionUpdated, req.QuestionID)
+}
+
+// Answer marks a question as answered. Only hosts answer questions.
+func (s *service) Answer(ctx context.Context, req *QuestionReq) (Question, error) {
+	err := req.Validate()
+	if err != nil {
+		return Question{}, err
+	}
+
+	err = s.mustHost(ctx, req.WebinarID, req.UserID)
+	if err != nil {
+		return Question{}, err
+	}
+
+	_, err = s.findQuestion(ctx, req.WebinarID, req.QuestionID)
+	if err != nil {
+		return Question{}, err
+	}
+
+	err = s.repo.Answer(ctx, req.QuestionID, time.Now().Unix())
+	if err != nil {
+		return Question{}, err
+	}
+
+	return s.publishQuestion(ctx, EventQuestionUpdated, req.QuestionID)
+}
+
+func (s *service) CreatePoll(ctx context.Context, req *CreatePollReq) (Poll, error) {
+	err := req.Validate()
+	if err != nil {
+		return Poll{}, err
+	}
+
+	w, err := s.findWebinar(ctx, req.WebinarID, req.Occurrence)
+	if err != nil {
+		return Poll{}, err
+	}
+	if !w.IsHost(req.UserID) {
+		return Poll{}, app.NewError(nil, app.EForbidden, "Forbidden access, webinar not found")
+	}
+	if w.CancelledAt > 0 {
+		return Poll{}, app.NewError(nil, app.EBadRequest, "Webinar has been cancelled")
+	}
+
+	poll := models.WebinarPoll{
+		WebinarID:  req.WebinarID,
+		Occurrence: req.Occurrence,
+		AuthorID:   req.UserID,
+		Question:   req.Question,
+		Options:    make([]models.WebinarPollOption, 0, len(req.Options)),
+		CreatedAt:  time.Now().Unix(),
+	}
+	for _, option := range req.Options {
+		poll.Options = append(poll.Options, models.WebinarPollOption{Body: option})
+	}
+
+	err = s.repo.CreatePoll(ctx, &poll)
+	if err != nil {
+		return Poll{}, err
+	}
+
+	return s.publishPoll(ctx, EventPollCreated, poll.ID)
+}
+
+func (s *service) Vote(ctx context.Context, req *VoteReq) (Poll, error) {
+	err := req.Validate()
+	if err != nil {
+		return Poll{}, err
+	}
+
+	_, err = s.findPoll(ctx, req.WebinarID, req.PollID)
+	if err != nil {
+		return Poll{}, err
+	}
+
+	err = s.repo.Vote(ctx, req.PollID, req.OptionID, req.UserID, time.Now().Unix())
+	if err != nil {
+		return Poll{}, err
+	}
+
+	return s.publishPoll(ctx, EventPollUpdated, req.PollID)
+}
+
+// ClosePoll stops the voting. Results stay visible.
+func (s *service) ClosePoll(ctx context.Context, req *PollReq) (Poll, error) {
+	err := req.Validate()
+	if err != nil {
+		return Poll{}, err
+	}
+
+	err = s.mustHost(ctx, req.WebinarID, req.UserID)
+	if err != nil {
+		return Poll{}, err
+	}
+
+	_, err = s.findPoll(ctx, req.WebinarID, req.PollID)
+	if err != nil {
+		return Poll{}, err
+	}
+
+	err = s.repo.ClosePoll(ctx, req.PollID, time.Now().Unix())
+	if err != nil {
+		return Poll{}, err
+	}
+
+	return s.publishPoll(ctx, EventPollUpdated, req.PollID)
+}
+
+func (s *service) findWebinar(ctx context.Context, webinarID, occurrence int64) (models.Webinar, error) {
+	w, err := s.webinarRepo.FindByID(ctx, webinarID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return models.Webinar{}, app.NewError(err, app.ENotFound, "Webinar not found")
+	} else if err != nil {
+		return models.Webinar{}, err
+	}
+
+	if !webinar.HasOccurrence(w, occurrence) {
+		return models.Webinar{}, app.NewError(nil, app.ENotFound, "Occurrence not found")
+	}
+
+	return w, nil
+}
+
+func (s *service) mustHost(ctx context.Context, webinarID, userID int64) error {
+	w, err := s.webinarRepo.FindByID(ctx, webinarID)
+	if app.ErrorCode(err) == app.ENotFound {
+		return app.NewError(err, app.ENotFound, "Webinar not found")
+	} else if err != nil {
+		return err
+	}
+
+	if !w.IsHost(userID) {
+		return app.NewError(nil, app.EForbidden, "Forbidden access, webinar not found")
+	}
+
+	return nil
+}
+
+// findQuestion looks up a question of the webinar, treating questions of
+// other webinars as missing.
+func (s *service) findQuestion(ctx context.Context, webinarID, questionID int64) (models.WebinarQuestion, error) {
+	question, err := s.repo.FindQuestionByID(ctx, questionID)
+	if err != nil {
+		return models.WebinarQuestion{}, err
+	}
+	if question.WebinarID != webinarID {
+		return models.WebinarQuestion{}, app.NewError(nil, app.ENotFound, "Question not found")
+	}
+
+	return question, nil
+}
+
+func (s *service) findPoll(ctx context.Context, webinarID, pollID int64) (models.WebinarPoll, error) {
+	poll, err := s.repo.FindPollByID(ctx, pollID)
+	if err != nil {
+		return models.WebinarPoll{}, err
+	}
+	if poll.WebinarID != webinarID {
+		return models.WebinarPoll{}, app.NewError(nil, app.ENotFound, "Poll not found")
+	}
+
+	return poll, nil
+}
+
+// publishQuestion reloads the question, for the author and the counts other
+// users changed meanwhile, and publishes it.
+func (s *service) publishQuestion(ctx context.Context, eventType string, questionID int64) (Question, error) {
+	question, err := s.repo.FindQuestionByID(ctx, questionID)
+	if err != nil {
+		return Question{}, err
+	}
+
+	resp := toQuestion(question)
+	s.publish(ctx, question.WebinarID, question.Occurrence, Event{Type: eventType, Question: &resp})
+
+	return resp, nil
+}
+
+func (s *service) publishPoll(ctx context.Context, eventType string, pollID int64) (Poll, error) {
+	poll, err := s.repo.FindPollByID(ctx, pollID)
+	if err != nil {
+		return Poll{}, err
+	}
+
+	resp := toPoll(poll)
+	s.publish(ctx, poll.WebinarID, poll.Occurrence, Event{Type: eventType, Poll: &resp})
+
+	return resp, nil
+}
+
+// publish is best effort: the change is already stored and reaches clients
+// with their next snapshot.
+func (s *service) publish(ctx context.Context, webinarID, occurrence int64, event Event) {
+	message, err := json.Marshal(event)
+	if err != nil {
+		logrus.Error(err)
+		return
+	}
+
+	err = s.pubSub.Publish(ctx, topic(webinarID, occurrence), message)
+	if err != nil {
+		logrus.Error(err)
+	}
+}
+
+// topic doubles as a Postgres channel name, so it must stay a short
+// identifier.
+func topic(webinarID, occurrence int64) string {
+	return fmt.Sprintf("webinar_live_%d_%d", webinarID, occurrence)
+}
+
+func toQuestion(question models.WebinarQuestion) Question {
+	return Question{
+		ID:         question.ID,
+		WebinarID:  question.WebinarID,
+		Occurrence: question.Occurrence,
+		Author: Author{
+			ID:      question.User.ID,
+			Name:    question.User.Name,
+			Picture: question.User.Picture,
+		},
+		Body:       question.Body,
+		Upvotes:    question.Upvotes,
+		Answered:   question.AnsweredAt > 0,
+		AnsweredAt: question.AnsweredAt,
+		CreatedAt:  question.CreatedAt,
+	}
+}
+
+func toPoll(poll models.WebinarPoll) Poll {
+	resp := Poll{
+		ID:         poll.ID,
+		WebinarID:  poll.WebinarID,
+		Occurrence: poll.Occurrence,
+		Question:   poll.Question,
+		Options:    make([]PollOption, 0, len(poll.Options)),
+		Closed:     poll.ClosedAt > 0,
+		ClosedAt:   poll.ClosedAt,
+		CreatedAt:  poll.CreatedAt,
+	}
+	for _, option := range poll.Options {
+		resp.Options = append(resp.Options, PollOption{
+			ID:    option.ID,
+			Body:  option.Body,
+			Votes: option.Votes,
+		})
+		resp.TotalVotes += option.Votes
+	}
+
+	return resp
+}
@@ -0,0 +1,53 @@
+package webinarlive
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+	"github.com/bagus2x/recovy/pubsub"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToPoll(t *testing.T) {
+	poll := toPoll(models.WebinarPoll{
+		ID:       1,
+		Question: "How do you feel?",
+		Options: []models.WebinarPollOption{
+			{ID: 1, Body: "Good", Votes: 3},
+			{ID: 2, Body: "Tired", Votes: 4},
+		},
+		ClosedAt: 1630929600,
+	})
+
+	assert.Equal(t, int64(7), poll.TotalVotes)
+	assert.True(t, poll.Closed)
+	assert.Len(t, poll.Options, 2)
+}
+
+func TestTopic(t *testing.T) {
+	// Postgres truncates channel names longer than 63 bytes.
+	assert.LessOrEqual(t, len(topic(1<<31-1, 1<<31-1)), 63)
+}
+
+func TestPublish(t *testing.T) {
+	ps := pubsub.NewMemory()
+	s := &service{pubSub: ps}
+
+	events, cancel := ps.Subscribe(topic(1, 0))
+	defer cancel()
+
+	s.publish(context.Background(), 1, 0, Event{Type: EventQuestionCreated, Question: &Question{ID: 5}})
+
+	var event Event
+	assert.NoError(t, json.Unmarshal(<-events, &event))
+	assert.Equal(t, EventQuestionCreated, event.Type)
+	assert.Equal(t, int64(5), event.Question.ID)
+}
+
+func TestHandleUnknown(t *testing.T) {
+	err := (&service{}).Handle(context.Background(), 1, 0, 1, Message{Type: "nope"})
+	assert.Equal(t, app.EBadRequest, app.ErrorCode(err))
+}
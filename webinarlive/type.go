@@ -0,0 +1,161 @@
+package webinarlive
+
+import (
+	"github.com/bagus2x/recovy/app"
+	"github.com/go-playground/validator/v10"
+)
+
+// Event types sent to live clients. Questions and polls are always sent
+// whole, so clients can replace what they have by ID.
+const (
+	EventSnapshot        = "snapshot"
+	EventQuestionCreated = "question.created"
+	EventQuestionUpdated = "question.updated"
+	EventPollCreated     = "poll.created"
+	EventPollUpdated     = "poll.updated"
+	EventError           = "error"
+)
+
+// Actions live clients send over the WebSocket. Clients on the SSE fallback
+// use the equivalent REST endpoints.
+const (
+	ActionAsk          = "question.ask"
+	ActionUpvote       = "question.upvote"
+	ActionRemoveUpvote = "question.remove_upvote"
+	ActionAnswer       = "question.answer"
+	ActionCreatePoll   = "poll.create"
+	ActionVote         = "poll.vote"
+	ActionClosePoll    = "poll.close"
+)
+
+type Author struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Picture string `json:"picture"`
+}
+
+type Question struct {
+	ID         int64  `json:"id"`
+	WebinarID  int64  `json:"webinarID"`
+	Occurrence int64  `json:"occurrence"`
+	Author     Author `json:"author"`
+	Body       string `json:"body"`
+	Upvotes    int64  `json:"upvotes"`
+	Answered   bool   `json:"answered"`
+	AnsweredAt int64  `json:"answeredAt,omitempty"`
+	CreatedAt  int64  `json:"createdAt"`
+}
+
+type Poll struct {
+	ID         int64        `json:"id"`
+	WebinarID  int64        `json:"webinarID"`
+	Occurrence int64        `json:"occurrence"`
+	Question   string       `json:"question"`
+	Options    []PollOption `json:"options"`
+	TotalVotes int64        `json:"totalVotes"`
+	Closed     bool         `json:"closed"`
+	ClosedAt   int64        `json:"closedAt,omitempty"`
+	CreatedAt  int64        `json:"createdAt"`
+}
+
+type PollOption struct {
+	ID    int64  `json:"id"`
+	Body  string `json:"body"`
+	Votes int64  `json:"votes"`
+}
+
+// Snapshot is the state of an occurrence, sent to live clients when they
+// connect.
+type Snapshot struct {
+	Questions []Question `json:"questions"`
+	Polls     []Poll     `json:"polls"`
+}
+
+type Event struct {
+	Type     string           `json:"type"`
+	Snapshot *Snapshot        `json:"snapshot,omitempty"`
+	Question *Question        `json:"question,omitempty"`
+	Poll     *Poll            `json:"poll,omitempty"`
+	Error    *app.ErrorDetail `json:"error,omitempty"`
+}
+
+// Message is an action sent by a live client. Only the fields of its type
+// are read.
+type Message struct {
+	Type       string   `json:"type"`
+	Body       string   `json:"body"`
+	QuestionID int64    `json:"questionID"`
+	PollID     int64    `json:"pollID"`
+	OptionID   int64    `json:"optionID"`
+	Question   string   `json:"question"`
+	Options    []string `json:"options"`
+}
+
+type AskReq struct {
+	WebinarID  int64  `json:"webinarID" validate:"required,gt=0"`
+	Occurrence int64  `json:"occurrence" validate:"gte=0"`
+	UserID     int64  `json:"userID" validate:"required,gt=0"`
+	Body       string `json:"body" validate:"required,lte=1000"`
+}
+
+func (r *AskReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+
+	return app.ValidateAndTranslate(validate, err)
+}
+
+type QuestionReq struct {
+	WebinarID  int64 `json:"webinarID" validate:"required,gt=0"`
+	QuestionID int64 `json:"questionID" validate:"required,gt=0"`
+	UserID     int64 `json:"userID" validate:"required,gt=0"`
+}
+
+func (r *QuestionReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+
+	return app.ValidateAndTranslate(validate, err)
+}
+
+type CreatePollReq struct {
+	WebinarID  int64    `json:"webinarID" validate:"required,gt=0"`
+	Occurrence int64    `json:"occurrence" validate:"gte=0"`
+	UserID     int64    `json:"userID" validate:"required,gt=0"`
+	Question   string   `json:"question" validate:"required,lte=255"`
+	Options    []string `json:"options" validate:"required,min=2,max=10,dive,required,lte=255"`
+}
+
+func (r *CreatePollReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+
+	return app.ValidateAndTranslate(validate, err)
+}
+
+type VoteReq struct {
+	WebinarID int64 `json:"webinarID" validate:"required,gt=0"`
+	PollID    int64 `json:"pollID" validate:"required,gt=0"`
+	OptionID  int64 `json:"optionID" validate:"required,gt=0"`
+	UserID    int64 `json:"userID" validate:"required,gt=0"`
+}
+
+func (r *VoteReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+
+	return app.ValidateAndTranslate(validate, err)
+}
+
+type PollReq struct {
+	WebinarID int64 `json:"webinarID" validate:"required,gt=0"`
+	PollID    int64 `json:"pollID" validate:"required,gt=0"`
+	UserID    int64 `json:"userID" validate:"required,gt=0"`
+}
+
+func (r *PollReq) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+
+	return app.ValidateAndTranslate(validate, err)
+}
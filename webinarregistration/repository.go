@@ -0,0 +1,308 @@
+package webinarregistration
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+	"github.com/lib/pq"
+)
+
+type Repository interface {
+	Register(ctx context.Context, registration *models.WebinarRegistration) error
+	FindByWebinarIDAndUserID(ctx context.Context, webinarID, userID int64) ([]models.WebinarRegistration, error)
+	FindByWebinarID(ctx context.Context, webinarID int64) ([]models.WebinarRegistration, error)
+	CountByWebinarIDs(ctx context.Context, webinarIDs []int64) (map[int64]map[int64]int64, error)
+	Cancel(ctx context.Context, webinarID, occurrence, userID int64) (models.WebinarRegistration, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+var lockWebinar = `
+	SELECT
+		capacity
+	FROM
+		Webinar
+	WHERE
+		id = $1
+	FOR UPDATE
+`
+
+var countRegistered = `
+	SELECT
+		COUNT(*)
+	FROM
+		Webinar_Registration
+	WHERE
+		webinar_id = $1 AND occurrence = $2 AND status = 'registered'
+`
+
+var create = `
+	INSERT INTO
+		Webinar_Registration
+		(webinar_id, occurrence, user_id, status, created_at, updated_at)
+	VALUES
+		($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (webinar_id, user_id, occurrence) DO NOTHING
+	RETURNING
+		id
+`
+
+// Register takes a seat of the occurrence if one is left and joins its
+// waitlist otherwise. The webinar row is locked for the whole transaction, so
+// concurrent registrations for the same webinar are serialized and can never
+// overbook it.
+func (r *repository) Register(ctx context.Context, registration *models.WebinarRegistration) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var capacity int64
+	err = tx.QueryRowContext(ctx, lockWebinar, registration.WebinarID).Scan(&capacity)
+	if err == sql.ErrNoRows {
+		return app.NewError(err, app.ENotFound)
+	} else if err != nil {
+		return err
+	}
+
+	var registered int64
+	err = tx.QueryRowContext(ctx, countRegistered, registration.WebinarID, registration.Occurrence).Scan(&registered)
+	if err != nil {
+		return err
+	}
+
+	registration.Status = models.RegistrationRegistered
+	if capacity > 0 && registered >= capacity {
+		registration.Status = models.RegistrationWaitlisted
+	}
+
+	err = tx.QueryRowContext(
+		ctx,
+		create,
+		registration.WebinarID,
+		registration.Occurrence,
+		registration.User.ID,
+		registration.Status,
+		registration.CreatedAt,
+		registration.UpdatedAt,
+	).Scan(&registration.ID)
+	if err == sql.ErrNoRows {
+		return app.NewError(err, app.Econflict, "User has registered")
+	} else if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+var findByWebinarIDAndUserID = `
+	SELECT
+		wr.id, wr.webinar_id, wr.occurrence, au.id, au.name, au.picture, wr.status, wr.created_at, wr.updated_at
+	FROM
+		Webinar_Registration wr
+	JOIN
+		App_User au
+	ON
+		wr.user_id = au.id
+	WHERE
+		wr.webinar_id = $1 AND wr.user_id = $2
+	ORDER BY
+		wr.occurrence ASC
+`
+
+// FindByWebinarIDAndUserID returns the user's registrations for the webinar,
+// one per occurrence.
+func (r *repository) FindByWebinarIDAndUserID(ctx context.Context, webinarID, userID int64) ([]models.WebinarRegistration, error) {
+	return r.find(ctx, findByWebinarIDAndUserID, webinarID, userID)
+}
+
+var findByWebinarID = `
+	SELECT
+		wr.id, wr.webinar_id, wr.occurrence, au.id, au.name, au.picture, wr.status, wr.created_at, wr.updated_at
+	FROM
+		Webinar_Registration wr
+	JOIN
+		App_User au
+	ON
+		wr.user_id = au.id
+	WHERE
+		wr.webinar_id = $1
+	ORDER BY
+		wr.occurrence ASC, wr.status ASC, wr.created_at ASC, wr.id ASC
+`
+
+// FindByWebinarID lists, per occurrence, registered users first, then the
+// waitlist in the order it will be promoted.
+func (r *repository) FindByWebinarID(ctx context.Context, webinarID int64) ([]models.WebinarRegistration, error) {
+	return r.find(ctx, findByWebinarID, webinarID)
+}
+
+func (r *repository) find(ctx context.Context, query string, args ...interface{}) ([]models.WebinarRegistration, error) {
+	registrations := make([]models.WebinarRegistration, 0)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return registrations, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var registration models.WebinarRegistration
+
+		err := rows.Scan(
+			&registration.ID,
+			&registration.WebinarID,
+			&registration.Occurrence,
+			&registration.User.ID,
+			&registration.User.Name,
+			&registration.User.Picture,
+			&registration.Status,
+			&registration.CreatedAt,
+			&registration.UpdatedAt,
+		)
+		if err != nil {
+			return registrations, err
+		}
+
+		registrations = append(registrations, registration)
+	}
+	if err := rows.Err(); err != nil {
+		return registrations, err
+	}
+
+	return registrations, nil
+}
+
+var countByWebinarIDs = `
+	SELECT
+		webinar_id, occurrence, COUNT(*)
+	FROM
+		Webinar_Registration
+	WHERE
+		webinar_id = ANY($1) AND status = 'registered'
+	GROUP BY
+		webinar_id, occurrence
+`
+
+// CountByWebinarIDs counts the registered users of every occurrence, keyed
+// by webinar ID and then occurrence.
+func (r *repository) CountByWebinarIDs(ctx context.Context, webinarIDs []int64) (map[int64]map[int64]int64, error) {
+	counts := make(map[int64]map[int64]int64)
+
+	rows, err := r.db.QueryContext(ctx, countByWebinarIDs, pq.Array(webinarIDs))
+	if err != nil {
+		return counts, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var webinarID, occurrence, count int64
+
+		err := rows.Scan(&webinarID, &occurrence, &count)
+		if err != nil {
+			return counts, err
+		}
+
+		if counts[webinarID] == nil {
+			counts[webinarID] = make(map[int64]int64)
+		}
+		counts[webinarID][occurrence] = count
+	}
+
+	return counts, rows.Err()
+}
+
+var delete = `
+	DELETE FROM
+		Webinar_Registration
+	WHERE
+		webinar_id = $1 AND occurrence = $2 AND user_id = $3
+	RETURNING
+		status
+`
+
+var promote = `
+	UPDATE
+		Webinar_Registration
+	SET
+		status = 'registered', updated_at = EXTRACT(EPOCH FROM NOW())::INT
+	WHERE
+		id = (
+			SELECT
+				id
+			FROM
+				Webinar_Registration
+			WHERE
+				webinar_id = $1 AND occurrence = $2 AND status = 'waitlisted'
+			ORDER BY
+				created_at ASC, id ASC
+			LIMIT
+				1
+		)
+	RETURNING
+		id, webinar_id, occurrence, user_id, status, created_at, updated_at
+`
+
+// Cancel removes the user's registration for the occurrence. When a seat is
+// freed, the first person on its waitlist is promoted and returned; otherwise
+// the returned registration is empty.
+func (r *repository) Cancel(ctx context.Context, webinarID, occurrence, userID int64) (models.WebinarRegistration, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.WebinarRegistration{}, err
+	}
+	defer tx.Rollback()
+
+	var capacity int64
+	err = tx.QueryRowContext(ctx, lockWebinar, webinarID).Scan(&capacity)
+	if err == sql.ErrNoRows {
+		return models.WebinarRegistration{}, app.NewError(err, app.ENotFound)
+	} else if err != nil {
+		return models.WebinarRegistration{}, err
+	}
+
+	var status string
+	err = tx.QueryRowContext(ctx, delete, webinarID, occurrence, userID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return models.WebinarRegistration{}, app.NewError(err, app.ENotFound)
+	} else if err != nil {
+		return models.WebinarRegistration{}, err
+	}
+
+	var promoted models.WebinarRegistration
+	if status == models.RegistrationRegistered {
+		var registered int64
+		err = tx.QueryRowContext(ctx, countRegistered, webinarID, occurrence).Scan(&registered)
+		if err != nil {
+			return models.WebinarRegistration{}, err
+		}
+
+		if capacity == 0 || registered < capacity {
+			err = tx.QueryRowContext(ctx, promote, webinarID, occurrence).Scan(
+				&promoted.ID,
+				&promoted.WebinarID,
+				&promoted.Occurrence,
+				&promoted.User.ID,
+				&promoted.Status,
+				&promoted.CreatedAt,
+				&promoted.UpdatedAt,
+			)
+			if err != nil && err != sql.ErrNoRows {
+				return models.WebinarRegistration{}, err
+			}
+		}
+	}
+
+	return promoted, tx.Commit()
+}
@@ -0,0 +1,145 @@
+package webinarreminder
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bagus2x/recovy/app"
+	"github.com/bagus2x/recovy/models"
+)
+
+type Repository interface {
+	Create(ctx context.Context, reminder *models.WebinarReminder) error
+	FindByWebinarIDAndUserID(ctx context.Context, webinarID, userID int64) (models.WebinarReminder, error)
+	FindByWebinarID(ctx context.Context, webinarID int64) ([]models.WebinarReminder, error)
+	Delete(ctx context.Context, webinarID, userID int64) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+var create = `
+	INSERT INTO
+		Webinar_Reminder
+		(webinar_id, user_id, created_at)
+	VALUES
+		($1, $2, $3)
+	ON CONFLICT (webinar_id, user_id) DO NOTHING
+`
+
+func (r *repository) Create(ctx context.Context, reminder *models.WebinarReminder) error {
+	res, err := r.db.ExecContext(ctx, create, reminder.WebinarID, reminder.User.ID, reminder.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return app.NewError(nil, app.Econflict, "Reminder has been set")
+	}
+
+	return nil
+}
+
+var findByWebinarIDAndUserID = `
+	SELECT
+		webinar_id, user_id, created_at
+	FROM
+		Webinar_Reminder
+	WHERE
+		webinar_id = $1 AND user_id = $2
+`
+
+func (r *repository) FindByWebinarIDAndUserID(ctx context.Context, webinarID, userID int64) (models.WebinarReminder, error) {
+	var reminder models.WebinarReminder
+
+	err := r.db.QueryRowContext(ctx, findByWebinarIDAndUserID, webinarID, userID).Scan(
+		&reminder.WebinarID,
+		&reminder.User.ID,
+		&reminder.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return models.WebinarReminder{}, app.NewError(err, app.ENotFound)
+	} else if err != nil {
+		return models.WebinarReminder{}, err
+	}
+
+	return reminder, nil
+}
+
+var findByWebinarID = `
+	SELECT
+		wr.webinar_id, au.id, au.name, au.email, au.picture, wr.created_at
+	FROM
+		Webinar_Reminder wr
+	JOIN
+		App_User au
+	ON
+		wr.user_id = au.id
+	WHERE
+		wr.webinar_id = $1
+`
+
+func (r *repository) FindByWebinarID(ctx context.Context, webinarID int64) ([]models.WebinarReminder, error) {
+	reminders := make([]models.WebinarReminder, 0)
+
+	rows, err := r.db.QueryContext(ctx, findByWebinarID, webinarID)
+	if err != nil {
+		return reminders, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var reminder models.WebinarReminder
+
+		err := rows.Scan(
+			&reminder.WebinarID,
+			&reminder.User.ID,
+			&reminder.User.Name,
+			&reminder.User.Email,
+			&reminder.User.Picture,
+			&reminder.CreatedAt,
+		)
+		if err != nil {
+			return reminders, err
+		}
+
+		reminders = append(reminders, reminder)
+	}
+
+	return reminders, rows.Err()
+}
+
+var delete = `
+	DELETE FROM
+		Webinar_Reminder
+	WHERE
+		webinar_id = $1 AND user_id = $2
+`
+
+func (r *repository) Delete(ctx context.Context, webinarID, userID int64) error {
+	res, err := r.db.ExecContext(ctx, delete, webinarID, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return app.NewError(nil, app.ENotFound, "Reminder not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,311 @@
+// Package websocket implements the server side of RFC 6455 on top of a
+// hijacked fiber connection. It supports what browsers need: text and binary
+// messages, fragmentation, ping and pong, and the closing handshake. No
+// extensions or subprotocols are negotiated.
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+	PingMessage   = 9
+	PongMessage   = 10
+
+	continuationFrame = 0
+)
+
+// Close codes.
+const (
+	CloseNormal        = 1000
+	CloseGoingAway     = 1001
+	CloseProtocolError = 1002
+	CloseTooBig        = 1009
+)
+
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// DefaultMaxMessageSize bounds the messages read from clients.
+const DefaultMaxMessageSize = 64 * 1024
+
+var ErrBadHandshake = errors.New("websocket: bad handshake")
+
+// CloseError is returned by ReadMessage once the peer closed the connection.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket: closed with code %d %s", e.Code, e.Reason)
+}
+
+// IsUpgrade reports whether the request asks for a WebSocket connection.
+func IsUpgrade(c *fiber.Ctx) bool {
+	return strings.EqualFold(c.Get(fiber.HeaderUpgrade), "websocket") &&
+		hasToken(c.Get(fiber.HeaderConnection), "upgrade")
+}
+
+// Upgrade completes the opening handshake and runs handler with the
+// connection once the response has been sent. The request context must not
+// be used from handler; the connection is closed when handler returns.
+func Upgrade(c *fiber.Ctx, handler func(*Conn)) error {
+	key := c.Get("Sec-WebSocket-Key")
+	if c.Method() != fiber.MethodGet || !IsUpgrade(c) || c.Get("Sec-WebSocket-Version") != "13" {
+		return ErrBadHandshake
+	}
+	if nonce, err := base64.StdEncoding.DecodeString(key); err != nil || len(nonce) != 16 {
+		return ErrBadHandshake
+	}
+
+	c.Status(fiber.StatusSwitchingProtocols)
+	c.Set(fiber.HeaderUpgrade, "websocket")
+	c.Set(fiber.HeaderConnection, "Upgrade")
+	c.Set("Sec-WebSocket-Accept", AcceptKey(key))
+
+	c.Context().Hijack(func(conn net.Conn) {
+		ws := NewConn(conn)
+		defer ws.conn.Close()
+
+		handler(ws)
+	})
+
+	return nil
+}
+
+// AcceptKey computes the Sec-WebSocket-Accept header for a client key.
+func AcceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + acceptGUID))
+
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func hasToken(header, token string) bool {
+	for _, t := range strings.Split(header, ",") {
+		if strings.EqualFold(strings.TrimSpace(t), token) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Conn is a server side WebSocket connection. ReadMessage must be called from
+// a single goroutine; writes may come from any.
+type Conn struct {
+	conn           net.Conn
+	r              *bufio.Reader
+	MaxMessageSize int64
+	// ReadTimeout, when set, fails reads once no frame, pongs included, has
+	// arrived for that long.
+	ReadTimeout time.Duration
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func NewConn(conn net.Conn) *Conn {
+	return &Conn{
+		conn:           conn,
+		r:              bufio.NewReader(conn),
+		MaxMessageSize: DefaultMaxMessageSize,
+	}
+}
+
+type frame struct {
+	fin     bool
+	opcode  int
+	payload []byte
+}
+
+// ReadMessage returns the next text or binary message. Pings are answered
+// and pongs skipped on the way. When the peer closes the connection the
+// close is echoed and a *CloseError returned.
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	var opcode int
+	var message []byte
+
+	for {
+		f, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch f.opcode {
+		case PingMessage:
+			err = c.WriteMessage(PongMessage, f.payload)
+			if err != nil {
+				return 0, nil, err
+			}
+			continue
+		case PongMessage:
+			continue
+		case CloseMessage:
+			closeErr := &CloseError{Code: CloseNormal}
+			if len(f.payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(f.payload))
+				closeErr.Reason = string(f.payload[2:])
+			}
+			c.WriteClose(closeErr.Code, "")
+
+			return 0, nil, closeErr
+		case TextMessage, BinaryMessage:
+			if opcode != 0 {
+				return 0, nil, c.fail(CloseProtocolError, "expected continuation frame")
+			}
+			opcode = f.opcode
+		case continuationFrame:
+			if opcode == 0 {
+				return 0, nil, c.fail(CloseProtocolError, "unexpected continuation frame")
+			}
+		default:
+			return 0, nil, c.fail(CloseProtocolError, "unknown opcode")
+		}
+
+		if int64(len(message)+len(f.payload)) > c.MaxMessageSize {
+			return 0, nil, c.fail(CloseTooBig, "message too big")
+		}
+		message = append(message, f.payload...)
+
+		if f.fin {
+			return opcode, message, nil
+		}
+	}
+}
+
+func (c *Conn) readFrame() (frame, error) {
+	if c.ReadTimeout > 0 {
+		c.conn.SetReadDeadline(time.Now().Add(c.ReadTimeout))
+	}
+
+	var header [2]byte
+	_, err := io.ReadFull(c.r, header[:])
+	if err != nil {
+		return frame{}, err
+	}
+
+	f := frame{
+		fin:    header[0]&0x80 != 0,
+		opcode: int(header[0] & 0x0f),
+	}
+	if header[0]&0x70 != 0 {
+		return frame{}, c.fail(CloseProtocolError, "reserved bits set")
+	}
+
+	// Clients must mask every frame.
+	if header[1]&0x80 == 0 {
+		return frame{}, c.fail(CloseProtocolError, "frame not masked")
+	}
+
+	length := int64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		_, err = io.ReadFull(c.r, ext[:])
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		_, err = io.ReadFull(c.r, ext[:])
+		length = int64(binary.BigEndian.Uint64(ext[:]))
+	}
+	if err != nil {
+		return frame{}, err
+	}
+
+	if f.opcode >= CloseMessage && (length > 125 || !f.fin) {
+		return frame{}, c.fail(CloseProtocolError, "invalid control frame")
+	}
+	if length < 0 || length > c.MaxMessageSize {
+		return frame{}, c.fail(CloseTooBig, "message too big")
+	}
+
+	var mask [4]byte
+	_, err = io.ReadFull(c.r, mask[:])
+	if err != nil {
+		return frame{}, err
+	}
+
+	f.payload = make([]byte, length)
+	_, err = io.ReadFull(c.r, f.payload)
+	if err != nil {
+		return frame{}, err
+	}
+	for i := range f.payload {
+		f.payload[i] ^= mask[i%4]
+	}
+
+	return f, nil
+}
+
+// WriteMessage sends data as a single unmasked frame.
+func (c *Conn) WriteMessage(opcode int, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return net.ErrClosed
+	}
+	if opcode == CloseMessage {
+		c.closed = true
+	}
+
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | byte(opcode)
+	switch {
+	case len(data) < 126:
+		header[1] = byte(len(data))
+	case len(data) <= 0xffff:
+		header[1] = 126
+		header = header[:4]
+		binary.BigEndian.PutUint16(header[2:], uint16(len(data)))
+	default:
+		header[1] = 127
+		header = header[:10]
+		binary.BigEndian.PutUint64(header[2:], uint64(len(data)))
+	}
+
+	_, err := c.conn.Write(append(header, data...))
+
+	return err
+}
+
+// WriteClose starts or answers the closing handshake. Nothing can be written
+// afterwards.
+func (c *Conn) WriteClose(code int, reason string) error {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+
+	return c.WriteMessage(CloseMessage, append(payload, reason...))
+}
+
+// Ping asks the client to prove it is still there; its pong is consumed by
+// ReadMessage.
+func (c *Conn) Ping() error {
+	return c.WriteMessage(PingMessage, nil)
+}
+
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Conn) fail(code int, reason string) error {
+	c.WriteClose(code, reason)
+
+	return &CloseError{Code: code, Reason: reason}
+}
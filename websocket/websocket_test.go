@@ -0,0 +1,128 @@
+package websocket
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// Example from RFC 6455 section 1.3.
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+}
+
+// clientFrame encodes a masked frame as a browser would send it.
+func clientFrame(fin bool, opcode int, payload []byte) []byte {
+	b := []byte{byte(opcode), 0x80}
+	if fin {
+		b[0] |= 0x80
+	}
+	switch {
+	case len(payload) < 126:
+		b[1] |= byte(len(payload))
+	default:
+		b[1] |= 126
+		b = append(b, 0, 0)
+		binary.BigEndian.PutUint16(b[2:], uint16(len(payload)))
+	}
+
+	mask := []byte{1, 2, 3, 4}
+	b = append(b, mask...)
+	for i, p := range payload {
+		b = append(b, p^mask[i%4])
+	}
+
+	return b
+}
+
+// readServerFrame decodes an unmasked frame sent by the server.
+func readServerFrame(t *testing.T, r io.Reader) (int, []byte) {
+	var header [2]byte
+	_, err := io.ReadFull(r, header[:])
+	assert.NoError(t, err)
+	assert.Zero(t, header[1]&0x80, "server frames are not masked")
+
+	length := int(header[1] & 0x7f)
+	if length == 126 {
+		var ext [2]byte
+		io.ReadFull(r, ext[:])
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	}
+
+	payload := make([]byte, length)
+	_, err = io.ReadFull(r, payload)
+	assert.NoError(t, err)
+
+	return int(header[0] & 0x0f), payload
+}
+
+func TestReadMessage(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	conn := NewConn(server)
+
+	go func() {
+		client.Write(clientFrame(false, TextMessage, []byte("hel")))
+		client.Write(clientFrame(true, PingMessage, []byte("p")))
+		client.Write(clientFrame(true, continuationFrame, []byte("lo")))
+	}()
+
+	// The ping in the middle of the fragmented message is answered.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		opcode, payload := readServerFrame(t, client)
+		assert.Equal(t, PongMessage, opcode)
+		assert.Equal(t, "p", string(payload))
+	}()
+
+	opcode, message, err := conn.ReadMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, TextMessage, opcode)
+	assert.Equal(t, "hello", string(message))
+	<-done
+
+	long := make([]byte, 300)
+	go func() {
+		conn.WriteMessage(TextMessage, long)
+	}()
+	opcode, payload := readServerFrame(t, client)
+	assert.Equal(t, TextMessage, opcode)
+	assert.Len(t, payload, 300)
+}
+
+func TestClose(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	conn := NewConn(server)
+
+	go client.Write(clientFrame(true, CloseMessage, []byte{0x03, 0xe9}))
+	go func() {
+		opcode, payload := readServerFrame(t, client)
+		assert.Equal(t, CloseMessage, opcode)
+		assert.Equal(t, []byte{0x03, 0xe9}, payload)
+	}()
+
+	_, _, err := conn.ReadMessage()
+	closeErr, ok := err.(*CloseError)
+	assert.True(t, ok)
+	assert.Equal(t, CloseGoingAway, closeErr.Code)
+	assert.Error(t, conn.WriteMessage(TextMessage, []byte("late")))
+}
+
+func TestUnmaskedFrame(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	conn := NewConn(server)
+
+	go client.Write([]byte{0x81, 0x01, 'x'})
+	go readServerFrame(t, client)
+
+	_, _, err := conn.ReadMessage()
+	closeErr, ok := err.(*CloseError)
+	assert.True(t, ok)
+	assert.Equal(t, CloseProtocolError, closeErr.Code)
+}